@@ -3,19 +3,29 @@ import express, { Request, Response } from "express";
 import cors from "cors";
 import bodyParser from "body-parser";
 import morgan from "morgan";
-import { KubeConfig, CoreV1Api, CustomObjectsApi } from "@kubernetes/client-node";
+import { KubeConfig, CoreV1Api, CustomObjectsApi, PatchStrategy, setHeaderOptions } from "@kubernetes/client-node";
 import { releaseHeader } from './middleware/releaseHeader.js';
+import { bearerAuth, authEnabled } from './middleware/auth.js';
+import { sendProblem, notFound, problemHandler } from './middleware/problem.js';
 import { components } from './types/generated/api.js';
 import crypto from 'crypto';
 import Redis from 'ioredis';
-import { S3Client, HeadBucketCommand, CreateBucketCommand, HeadObjectCommand, GetObjectCommand, PutObjectCommand } from "@aws-sdk/client-s3";
-import { pipeline } from 'stream/promises';
+import { S3Client, HeadBucketCommand, CreateBucketCommand, HeadObjectCommand, PutObjectCommand } from "@aws-sdk/client-s3";
+import { getSignedUrl } from "@aws-sdk/s3-request-presigner";
+import * as fs from 'fs';
+import { parseS3Location, prepareBackup, withBackupRestore } from './backups.js';
+import { maskingPolicyError } from './masking.js';
 
 type Database = components['schemas']['Database'];
 type Project = components['schemas']['Project'];
 type CreateProjectRequest = components['schemas']['CreateProjectRequest'];
 type DatabaseType = components['schemas']['DatabaseType'];
 type DatabaseCredentials = components['schemas']['DatabaseCredentials'];
+type Snapshot = components['schemas']['Snapshot'];
+type CreateBackupUploadRequest = components['schemas']['CreateBackupUploadRequest'];
+type BackupUpload = components['schemas']['BackupUpload'];
+type Catalog = components['schemas']['Catalog'];
+type StopDatabaseRequest = components['schemas']['StopDatabaseRequest'];
 
 const app = express();
 const port: number = 5000;
@@ -27,12 +37,13 @@ app.use(morgan('combined'));
 app.use(cors({
   origin: true,
   credentials: true,
-  exposedHeaders: ['Content-Length', 'Content-Type'],
+  exposedHeaders: ['Content-Length', 'Content-Type', 'WWW-Authenticate'],
   methods: ['GET', 'POST', 'PUT', 'DELETE', 'OPTIONS']
 }));
 
 app.use(bodyParser.json());
 app.use(releaseHeader);
+app.use(bearerAuth);
 
 // Configure server timeout and keep-alive
 const server = app.listen(port, () => {
@@ -61,6 +72,140 @@ const SHARED_NAMESPACE = fs.existsSync('/var/run/secrets/kubernetes.io/serviceac
   ? fs.readFileSync('/var/run/secrets/kubernetes.io/serviceaccount/namespace', 'utf8')
   : 'default';
 const POSTGRES_SERVICE_NAME = 'shared-postgres-service';
+// Databases created with a time-to-live carry their expiry in this pod
+// annotation; "devdb admin reap" deletes or stops them once it has passed
+const EXPIRES_AT_ANNOTATION = 'devdb/expires-at';
+const MIN_TTL_SECONDS = 60;
+// Version of the project data a database or base snapshot was made from;
+// refreshing a project increases it, so new databases get new base data
+const DATA_VERSION_LABEL = 'devdb/data-version';
+// Snapshot names are chosen per project, but VolumeSnapshots share the
+// namespace; the objects are named after the project and keep the name
+// the user gave in this annotation
+const SNAPSHOT_NAME_ANNOTATION = 'devdb/snapshot-name';
+
+// How each database engine is run: its image (tagged with the project's
+// version), port, data directory, the variables that create the project's
+// user and database on first start and, when the image's own would not do,
+// the arguments of its entrypoint. Versions are the supported release series; a
+// project's version is one of them or a release in one. Name, versions and
+// extensions make up the catalog served by GET /catalog. New projects get
+// user and database as their default credentials; Redis authenticates its
+// "default" user and numbers its databases.
+interface Engine {
+  name: string;
+  image: string;
+  port: number;
+  dataPath: string;
+  versions: string[];
+  extensions: string[];
+  user: string;
+  database: string;
+  env: (project: Project) => { name: string; value: string }[];
+  args?: string[];
+}
+
+const ENGINES: Record<DatabaseType, Engine> = {
+  postgres: {
+    name: 'PostgreSQL',
+    image: 'postgres',
+    port: 5432,
+    dataPath: '/var/lib/postgresql/data',
+    versions: ['17', '16', '15', '14', '13', '12'],
+    extensions: [
+      'btree_gin', 'btree_gist', 'citext', 'cube', 'hstore', 'intarray',
+      'pg_stat_statements', 'pg_trgm', 'pgcrypto', 'tablefunc', 'unaccent', 'uuid-ossp',
+    ],
+    user: 'devdb',
+    database: 'devdb',
+    env: project => [
+      { name: 'POSTGRES_DB', value: project.defaultCredentials.database },
+      { name: 'POSTGRES_USER', value: project.defaultCredentials.username },
+      { name: 'POSTGRES_PASSWORD', value: project.defaultCredentials.password },
+    ],
+  },
+  mysql: {
+    name: 'MySQL',
+    image: 'mysql',
+    port: 3306,
+    dataPath: '/var/lib/mysql',
+    versions: ['9.1', '8.4', '8.0'],
+    extensions: [],
+    user: 'devdb',
+    database: 'devdb',
+    env: project => [
+      { name: 'MYSQL_DATABASE', value: project.defaultCredentials.database },
+      { name: 'MYSQL_USER', value: project.defaultCredentials.username },
+      { name: 'MYSQL_PASSWORD', value: project.defaultCredentials.password },
+      { name: 'MYSQL_RANDOM_ROOT_PASSWORD', value: 'yes' },
+    ],
+  },
+  mariadb: {
+    name: 'MariaDB',
+    image: 'mariadb',
+    port: 3306,
+    dataPath: '/var/lib/mysql',
+    versions: ['11.4', '10.11', '10.6', '10.5'],
+    extensions: [],
+    user: 'devdb',
+    database: 'devdb',
+    env: project => [
+      { name: 'MARIADB_DATABASE', value: project.defaultCredentials.database },
+      { name: 'MARIADB_USER', value: project.defaultCredentials.username },
+      { name: 'MARIADB_PASSWORD', value: project.defaultCredentials.password },
+      { name: 'MARIADB_RANDOM_ROOT_PASSWORD', value: 'yes' },
+    ],
+  },
+  redis: {
+    name: 'Redis',
+    image: 'redis',
+    port: 6379,
+    dataPath: '/data',
+    versions: ['7.4', '7.2', '6.2'],
+    extensions: [],
+    user: 'default',
+    database: '0',
+    env: project => [
+      { name: 'REDIS_PASSWORD', value: project.defaultCredentials.password },
+    ],
+    // requirepass sets the password of the default user. These are the
+    // arguments of the image's entrypoint, which drops root before starting
+    // redis-server; Kubernetes substitutes $(REDIS_PASSWORD) from env
+    args: ['redis-server', '--requirepass', '$(REDIS_PASSWORD)'],
+  },
+  mongodb: {
+    name: 'MongoDB',
+    image: 'mongo',
+    port: 27017,
+    dataPath: '/data/db',
+    versions: ['8.0', '7.0', '6.0'],
+    extensions: [],
+    user: 'devdb',
+    database: 'devdb',
+    env: project => [
+      { name: 'MONGO_INITDB_ROOT_USERNAME', value: project.defaultCredentials.username },
+      { name: 'MONGO_INITDB_ROOT_PASSWORD', value: project.defaultCredentials.password },
+      { name: 'MONGO_INITDB_DATABASE', value: project.defaultCredentials.database },
+    ],
+  },
+};
+
+function engineOf(project: Project): Engine {
+  return ENGINES[project.dbType] ?? ENGINES.postgres;
+}
+
+// Returns why a database type and version cannot be used, or null
+function invalidDatabase(dbType: string, dbVersion: string): string | null {
+  const engine = ENGINES[dbType as DatabaseType];
+  if (!engine) {
+    return `Unknown database type "${dbType}" (want ${Object.keys(ENGINES).join(', ')})`;
+  }
+  if (!/^[0-9]+(\.[0-9]+){0,2}$/.test(dbVersion)
+    || !engine.versions.some(v => dbVersion === v || dbVersion.startsWith(`${v}.`))) {
+    return `${dbType} ${dbVersion} is not supported (supported: ${engine.versions.join(', ')})`;
+  }
+  return null;
+}
 
 // Initialize S3 client
 const s3Client = new S3Client({ region: AWS_REGION });
@@ -135,49 +280,58 @@ function generateProjectId(): string {
   return crypto.randomUUID();
 }
 
-import * as fs from 'fs';
-import * as path from 'path';
+// How long presigned backup upload URLs work, in seconds
+const UPLOAD_EXPIRY_SECONDS = 3600;
+const BACKUP_FORMATS = ['custom', 'plain', 'directory', 'rdb', 'archive'];
+
+// List the engines projects can be created with
+app.get("/catalog", (req: Request, res: Response) => {
+  const catalog: Catalog = {
+    engines: (Object.keys(ENGINES) as DatabaseType[]).map(type => ({
+      type,
+      name: ENGINES[type].name,
+      defaultPort: ENGINES[type].port,
+      versions: ENGINES[type].versions,
+      extensions: ENGINES[type].extensions,
+    })),
+  };
+  res.json(catalog);
+});
 
-// Function to validate and download backup
-async function prepareBackup(backupUrl: string): Promise<string | null> {
+app.post("/backups/uploads", async (req: Request, res: Response) => {
   try {
-    // Parse S3 URL
-    const url = new URL(backupUrl);
-    if (!url.hostname.startsWith('s3://')) {
-      throw new Error('Invalid S3 URL format');
+    const uploadData: CreateBackupUploadRequest = req.body;
+
+    const filename = uploadData.filename;
+    if (!filename || filename.includes('/') || filename.includes('\\') || /^\.+$/.test(filename)) {
+      return sendProblem(res, 400, "Filename must be a file name without directories");
     }
-    
-    const bucket = url.hostname.replace('s3://', '');
-    const key = url.pathname.substring(1); // Remove leading slash
-    
-    // Check if backup exists
-    try {
-      await s3Client.send(new HeadObjectCommand({ Bucket: bucket, Key: key }));
-    } catch (error) {
-      console.error('Backup file not found in S3:', error);
-      return null;
+    if (!Number.isInteger(uploadData.sizeBytes) || uploadData.sizeBytes < 1) {
+      return sendProblem(res, 400, "Size must be at least 1 byte");
     }
-    
-    // Create local backup directory if it doesn't exist
-    const backupDir = '/tmp/backups';
-    await fs.promises.mkdir(backupDir, { recursive: true });
-    
-    // Download backup
-    const localPath = `${backupDir}/${key.split('/').pop()}`;
-    const writeStream = fs.createWriteStream(localPath);
-    const response = await s3Client.send(new GetObjectCommand({ Bucket: bucket, Key: key }));
-    
-    if (!response.Body) {
-      throw new Error('Empty response from S3');
+    if (!BACKUP_FORMATS.includes(uploadData.format)) {
+      return sendProblem(res, 400, `Unsupported backup format "${uploadData.format}"`);
     }
-    
-    await pipeline(response.Body as any, writeStream);
-    return localPath;
+
+    // The signature covers the length, so S3 refuses truncated uploads
+    const key = `uploads/${crypto.randomUUID()}/${filename}`;
+    const uploadUrl = await getSignedUrl(s3Client, new PutObjectCommand({
+      Bucket: S3_BUCKET,
+      Key: key,
+      ContentLength: uploadData.sizeBytes
+    }), { expiresIn: UPLOAD_EXPIRY_SECONDS });
+
+    const upload: BackupUpload = {
+      uploadUrl,
+      location: `s3://${S3_BUCKET}/${key}`,
+      expiresAt: new Date(Date.now() + UPLOAD_EXPIRY_SECONDS * 1000).toISOString()
+    };
+    res.status(201).json(upload);
   } catch (error) {
-    console.error('Error preparing backup:', error);
-    return null;
+    console.error('Error presigning backup upload:', error);
+    sendProblem(res, 500);
   }
-}
+});
 
 app.post("/projects", async (req: Request, res: Response) => {
   try {
@@ -185,12 +339,29 @@ app.post("/projects", async (req: Request, res: Response) => {
 
     // Validate required fields per OpenAPI spec
     if (!projectData.owner || !projectData.name || !projectData.dbType || !projectData.dbVersion) {
-      return res.status(400).send("Missing required fields: owner, name, dbType, and dbVersion are required");
+      return sendProblem(res, 400, "Missing required fields: owner, name, dbType, and dbVersion are required");
+    }
+    const invalid = invalidDatabase(projectData.dbType, projectData.dbVersion);
+    if (invalid) {
+      return sendProblem(res, 400, `Invalid database: ${invalid}`);
     }
 
     // Validate backup location format if provided
-    if (projectData.backupLocation && !projectData.backupLocation.startsWith('s3://')) {
-      return res.status(400).send("Backup location must be an S3 URL (e.g., s3://bucket-name/path/to/backup.dump)");
+    if (projectData.backupLocation && !parseS3Location(projectData.backupLocation)) {
+      return sendProblem(res, 400, "Backup location must be an S3 URL (e.g., s3://bucket-name/path/to/backup.dump)");
+    }
+    if (projectData.maskingPolicy !== undefined && typeof projectData.maskingPolicy !== 'string') {
+      return sendProblem(res, 400, "Masking policy must be the text of a policy file");
+    }
+    const policyError = projectData.maskingPolicy ? maskingPolicyError(projectData.maskingPolicy) : null;
+    if (policyError) {
+      return sendProblem(res, 400, `Invalid masking policy: ${policyError}`);
+    }
+
+    // Project names are unique per owner, so they can be used in place of IDs
+    const existing = await listProjects(projectData.owner);
+    if (existing.some(project => project.name === projectData.name)) {
+      return sendProblem(res, 409, `Project ${projectData.name} already exists for owner ${projectData.owner}`);
     }
 
     const projectId = generateProjectId();
@@ -202,10 +373,12 @@ app.post("/projects", async (req: Request, res: Response) => {
       dbType: projectData.dbType,
       dbVersion: projectData.dbVersion,
       backupLocation: projectData.backupLocation || '',
+      ...(projectData.maskingPolicy ? { maskingPolicy: projectData.maskingPolicy } : {}),
+      dataVersion: 1,
       defaultCredentials: {
-        username: 'devdb',
+        username: ENGINES[projectData.dbType].user,
         password: generateSecurePassword(),
-        database: 'devdb'
+        database: ENGINES[projectData.dbType].database
       }
     };
 
@@ -213,39 +386,24 @@ app.post("/projects", async (req: Request, res: Response) => {
     await redis.set(`project:${projectId}`, JSON.stringify(newProject));
     
     // Return project without credentials
-    const { defaultCredentials, ...projectWithoutCreds } = newProject;
-    res.status(201).json(projectWithoutCreds);
+    res.status(201).json(withoutSecrets(newProject));
   } catch (error) {
     console.error('Error creating project:', error);
-    res.status(500).send("Internal server error");
+    sendProblem(res, 500);
   }
 });
 
 app.get("/projects", async (req: Request, res: Response) => {
   try {
     const owner = req.query.owner as string | undefined;
-    
-    // Get all project keys
-    const projectKeys = await redis.keys('project:*');
-    const projects: Array<Omit<Project, 'defaultCredentials'>> = [];
-    
-    // Get all projects
-    for (const key of projectKeys) {
-      const projectJson = await redis.get(key);
-      if (projectJson) {
-        const project = JSON.parse(projectJson) as Project;
-        if (!owner || project.owner === owner) {
-          // Create a new object without credentials
-          const { defaultCredentials, ...projectWithoutCreds } = project;
-          projects.push(projectWithoutCreds);
-        }
-      }
-    }
-    
+
+    // Return projects without credentials
+    const projects = (await listProjects(owner)).map(withoutSecrets);
+
     res.json(projects);
   } catch (error) {
     console.error('Error fetching projects:', error);
-    res.status(500).send("Internal server error");
+    sendProblem(res, 500);
   }
 });
 
@@ -255,25 +413,26 @@ app.get("/projects/:projectId", async (req: Request, res: Response) => {
     
     const project = await getProject(projectId);
     if (!project) {
-      return res.status(404).send("Project not found");
+      return sendProblem(res, 404, `Project ${projectId} not found`);
     }
 
     // Ensure all required fields are present
     if (!project.id || !project.owner || !project.name || !project.dbType || !project.dbVersion || !project.defaultCredentials) {
       console.error('Project in Redis is missing required fields:', project);
-      return res.status(500).send("Project data is corrupted");
+      return sendProblem(res, 500, "Project data is corrupted");
     }
 
-    // Return project without credentials
-    const { defaultCredentials, ...projectWithoutCreds } = project;
+    // Credentials are only returned to clients that may see them, which
+    // need them to connect (devdb db connect, devdb db env), and so is the
+    // masking policy, whose seed would let masked values be guessed
     res.json({
-      ...projectWithoutCreds,
+      ...(showSecrets(res) ? project : withoutSecrets(project)),
       backupLocation: project.backupLocation || '', // Ensure backupLocation is always present
       databases: project.databases || [] // Ensure databases is always present
     });
   } catch (error) {
     console.error('Error getting project:', error);
-    res.status(500).send("Internal server error");
+    sendProblem(res, 500);
   }
 });
 
@@ -282,7 +441,7 @@ app.get("/projects/:projectId/databases", async (req: Request, res: Response) =>
   try {
     const project = await getProject(projectId);
     if (!project) {
-      return res.status(404).send("Project not found");
+      return sendProblem(res, 404, `Project ${projectId} not found`);
     }
 
     const pods = await k8sApi.listNamespacedPod({
@@ -290,35 +449,147 @@ app.get("/projects/:projectId/databases", async (req: Request, res: Response) =>
       labelSelector: `devdb/projectId=${projectId}`
     });
 
-    const databases = pods.items.map((pod: any) => ({
-      name: pod.metadata?.name || '',
-      status: pod.status?.phase?.toLowerCase() || 'unknown',
-      project: projectId,
-      host: `${pod.metadata?.name}.${SHARED_NAMESPACE}`,
-      port: 5432,
-      username: project.defaultCredentials.username,
-      database: project.defaultCredentials.database
-    }));
+    const databases = pods.items.map((pod: any) => podToDatabase(pod, project));
+    for (const stopped of await listStoppedDatabases(projectId)) {
+      databases.push(stoppedToDatabase(stopped, project));
+    }
 
     res.json(databases);
   } catch (error) {
     console.error(error);
-    res.status(500).send("Error listing databases");
+    sendProblem(res, 500, "Error listing databases");
+  }
+});
+
+app.get("/projects/:projectId/databases/:name", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+
+    let pod: any;
+    try {
+      pod = await k8sApi.readNamespacedPod({
+        name: name,
+        namespace: SHARED_NAMESPACE
+      });
+    } catch (error: any) {
+      if (error.code === 404 || error.response?.statusCode === 404) {
+        const stopped = await getStoppedDatabase(projectId, name);
+        if (stopped) {
+          return res.json(stoppedToDatabase(stopped, project));
+        }
+        return sendProblem(res, 404, `Database ${name} not found`);
+      }
+      throw error;
+    }
+
+    if (pod.metadata?.labels?.["devdb/projectId"] !== projectId) {
+      return sendProblem(res, 404, `Database ${name} not found`);
+    }
+
+    res.json(podToDatabase(pod, project));
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error getting database");
+  }
+});
+
+app.post("/projects/:projectId/refresh", async (req: Request, res: Response) => {
+  const { projectId } = req.params;
+  const backupLocation: string | undefined = req.body?.backupLocation;
+  const maskingPolicy: string | undefined = req.body?.maskingPolicy;
+
+  if (backupLocation !== undefined && !parseS3Location(backupLocation)) {
+    return sendProblem(res, 400, "Backup location must be an S3 URL (e.g., s3://bucket-name/path/to/backup.dump)");
+  }
+  if (maskingPolicy !== undefined && typeof maskingPolicy !== 'string') {
+    return sendProblem(res, 400, "Masking policy must be the text of a policy file");
+  }
+  // An empty policy is valid; it removes the project's policy
+  const policyError = maskingPolicy ? maskingPolicyError(maskingPolicy) : null;
+  if (policyError) {
+    return sendProblem(res, 400, `Invalid masking policy: ${policyError}`);
+  }
+
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    const location = backupLocation || project.backupLocation;
+    if (!location) {
+      return sendProblem(res, 400, `Project ${project.name} has no backup to refresh from; give a backupLocation`);
+    }
+    const s3Location = parseS3Location(location);
+    if (!s3Location) {
+      return sendProblem(res, 400, `Backup location ${location} is not an S3 URL`);
+    }
+    const { bucket, key } = s3Location;
+    try {
+      await s3Client.send(new HeadObjectCommand({ Bucket: bucket, Key: key }));
+    } catch (error) {
+      return sendProblem(res, 400, `Backup ${location} not found`);
+    }
+
+    // Existing databases and base snapshots are left alone; the next
+    // database restores the backup and becomes the new version's base
+    const previousDataVersion = dataVersionOf(project);
+    const refreshed: Project = {
+      ...project,
+      backupLocation: location,
+      dataVersion: previousDataVersion + 1,
+      dataRefreshedAt: new Date().toISOString()
+    };
+    // An empty policy removes the project's policy
+    if (maskingPolicy !== undefined) {
+      refreshed.maskingPolicy = maskingPolicy || undefined;
+    }
+    await redis.set(`project:${projectId}`, JSON.stringify(refreshed));
+
+    res.json({
+      previousDataVersion,
+      dataVersion: refreshed.dataVersion,
+      project: showSecrets(res) ? refreshed : withoutSecrets(refreshed)
+    });
+  } catch (error) {
+    console.error('Error refreshing project:', error);
+    sendProblem(res, 500);
   }
 });
 
 app.post("/projects/:projectId/databases", async (req: Request, res: Response) => {
   const { projectId } = req.params;
-  const { name, backupUrl } = req.body;
+  const { name, backupUrl, fromDatabase, fromSnapshot, ttlSeconds } = req.body;
 
   if (!name) {
-    return res.status(400).send("Name is required");
+    return sendProblem(res, 400, "Name is required");
+  }
+  if (fromDatabase && fromSnapshot) {
+    return sendProblem(res, 400, "fromDatabase and fromSnapshot cannot be combined");
+  }
+  if (ttlSeconds !== undefined && !(Number.isInteger(ttlSeconds) && ttlSeconds >= MIN_TTL_SECONDS)) {
+    return sendProblem(res, 400, `ttlSeconds must be an integer of at least ${MIN_TTL_SECONDS}`);
+  }
+  if ((fromDatabase || fromSnapshot) && !getStorageConfig().useSnapshots) {
+    return sendProblem(res, 400, "Volume snapshots are not enabled on this server");
   }
 
   try {
     const project = await getProject(projectId);
     if (!project) {
-      return res.status(404).send("Project not found");
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+
+    try {
+      await k8sApi.readNamespacedPod({ name, namespace: SHARED_NAMESPACE });
+      return sendProblem(res, 409, `Database ${name} already exists`);
+    } catch (error: any) {
+      if (error.code !== 404 && error.response?.statusCode !== 404) {
+        throw error;
+      }
     }
 
     // Check if there are any existing pods for this project
@@ -332,23 +603,57 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
     let useSnapshot = false;
     let latestSnapshot = null;
 
-    // If backupUrl is provided and this is the first database, prepare the backup
-    let backupPath = null;
-    if (backupUrl && existingPods.items.length === 0) {
-      backupPath = await prepareBackup(backupUrl);
-      if (!backupPath) {
-        return res.status(400).send("Failed to prepare backup from URL");
+    // A database created from a snapshot or another database starts from
+    // that data instead of the project's backup
+    if (fromSnapshot) {
+      latestSnapshot = await getVolumeSnapshot(fromSnapshot, project.id);
+      if (!latestSnapshot) {
+        return sendProblem(res, 404, `Snapshot ${fromSnapshot} not found`);
+      }
+      if (volumeSnapshotToSnapshot(latestSnapshot).status !== 'ready') {
+        return sendProblem(res, 409, `Snapshot ${fromSnapshot} is not ready`);
+      }
+    } else if (fromDatabase) {
+      const source = existingPods.items.find((pod: any) => pod.metadata?.name === fromDatabase);
+      if (!source) {
+        return sendProblem(res, 404, `Database ${fromDatabase} not found`);
+      }
+      if (podToDatabase(source, project).status !== 'running') {
+        return sendProblem(res, 409, `Database ${fromDatabase} is not running`);
       }
+      latestSnapshot = await createVolumeSnapshot(`${fromDatabase}-data`, SHARED_NAMESPACE, {
+        name: `${name}-source-${Date.now()}`,
+        labels: { "devdb/projectId": project.id, "devdb/source-for": name }
+      });
+      if (!latestSnapshot) {
+        return sendProblem(res, 500, `Error taking a snapshot of database ${fromDatabase}`);
+      }
+    }
+    if (latestSnapshot) {
+      useSnapshot = true;
     }
 
-    if (existingPods.items.length > 0) {
-      // This is not the first database for this project
-      latestSnapshot = await getLatestVolumeSnapshot(project.id, SHARED_NAMESPACE);
+    // Other databases start from the base snapshot of the project's
+    // current data
+    const dataVersion = dataVersionOf(project);
+    if (!useSnapshot) {
+      latestSnapshot = await getLatestVolumeSnapshot(project.id, SHARED_NAMESPACE, dataVersion);
       if (latestSnapshot) {
         useSnapshot = true;
       }
     }
 
+    // Without one, the backup is restored, e.g. for the first database or
+    // the first one after a refresh
+    const backupLocation = backupUrl || project.backupLocation;
+    let backupPath = null;
+    if (backupLocation && !useSnapshot) {
+      backupPath = await prepareBackup(s3Client, backupLocation);
+      if (!backupPath) {
+        return sendProblem(res, 400, "Failed to prepare backup from URL");
+      }
+    }
+
     // Create PVC, either from scratch or from snapshot
     if (useSnapshot && latestSnapshot) {
       await createPVCFromSnapshot(
@@ -361,6 +666,7 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
     }
 
     // Create pod manifest
+    const engine = engineOf(project);
     const podManifest: any = {
       apiVersion: "v1",
       kind: "Pod",
@@ -371,55 +677,29 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
           "devdb/type": String(project.dbType),
           "devdb/owner": project.owner,
           "devdb/projectId": project.id,
-          "app": podName
+          "app": podName,
+          // Copies of a database have its data; snapshots are unversioned
+          ...(fromSnapshot ? {} : {
+            [DATA_VERSION_LABEL]: fromDatabase
+              ? existingPods.items.find((pod: any) => pod.metadata?.name === fromDatabase)?.metadata?.labels?.[DATA_VERSION_LABEL] ?? '1'
+              : String(dataVersion)
+          })
         },
+        annotations: ttlSeconds ? {
+          [EXPIRES_AT_ANNOTATION]: new Date(Date.now() + ttlSeconds * 1000).toISOString()
+        } : {},
       },
-      spec: {
-        initContainers: backupPath ? [
-          {
-            name: "restore-backup",
-            image: "postgres:latest",
-            command: ["pg_restore", "-U", "postgres", "-d", "postgres", "/backup/backup.dump"],
-            env: [
-              {
-                name: "PGPASSWORD",
-                value: "postgres"  // This should be replaced with a secure password
-              }
-            ],
-            volumeMounts: [
-              {
-                name: "data",
-                mountPath: "/var/lib/postgresql/data"
-              },
-              {
-                name: "backup",
-                mountPath: "/backup"
-              }
-            ]
-          }
-        ] : [],
+      spec: withBackupRestore({
         containers: [
           {
             name: String(project.dbType),
-            image: `${String(project.dbType)}:${project.dbVersion}`,
-            env: [
-              {
-                name: `${String(project.dbType).toUpperCase()}_DB`,
-                value: project.name,
-              },
-              {
-                name: `${String(project.dbType).toUpperCase()}_USER`,
-                value: project.defaultCredentials.username
-              },
-              {
-                name: `${String(project.dbType).toUpperCase()}_PASSWORD`,
-                value: project.defaultCredentials.password
-              },
-            ],
+            image: `${engine.image}:${project.dbVersion}`,
+            ...(engine.args ? { args: engine.args } : {}),
+            env: engine.env(project),
             volumeMounts: [
               {
                 name: "data",
-                mountPath: "/var/lib/postgresql/data"
+                mountPath: engine.dataPath
               }
             ]
           },
@@ -430,18 +710,9 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
             persistentVolumeClaim: {
               claimName: pvcName
             }
-          },
-          ...(backupPath ? [
-            {
-              name: "backup",
-              hostPath: {
-                path: path.dirname(backupPath),
-                type: "Directory"
-              }
-            }
-          ] : [])
+          }
         ]
-      },
+      }, project.dbType, engine.dataPath, backupPath),
     };
 
     await k8sApi.createNamespacedPod({
@@ -471,8 +742,8 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
         type: "LoadBalancer",
         ports: [
           {
-            port: 5432,
-            targetPort: 5432,
+            port: engine.port,
+            targetPort: engine.port,
             protocol: "TCP"
           }
         ],
@@ -487,12 +758,15 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
       body: serviceManifest
     });
 
-    // Create volume snapshot if this is the first database
+    // A database restored from the backup becomes the base snapshot of
+    // the project's current data
     if (!useSnapshot) {
       try {
         // Wait a bit for the database to initialize
         await new Promise(resolve => setTimeout(resolve, 30000));
-        await createVolumeSnapshot(pvcName, SHARED_NAMESPACE);
+        await createVolumeSnapshot(pvcName, SHARED_NAMESPACE, {
+          labels: { "devdb/projectId": project.id, [DATA_VERSION_LABEL]: String(dataVersion) }
+        });
       } catch (error) {
         console.error('Error creating volume snapshot:', error);
         // Don't fail the request if snapshot creation fails
@@ -507,7 +781,7 @@ app.post("/projects/:projectId/databases", async (req: Request, res: Response) =
     });
   } catch (error) {
     console.error(error);
-    res.status(500).send("Error creating database pod and service.");
+    sendProblem(res, 500, "Error creating database pod and service.");
   }
 });
 
@@ -518,7 +792,7 @@ app.delete("/projects/:projectId", async (req: Request, res: Response) => {
     // Check if project exists
     const project = await getProject(projectId);
     if (!project) {
-      return res.status(404).send("Project not found");
+      return sendProblem(res, 404, `Project ${projectId} not found`);
     }
 
     // Delete the project from Redis
@@ -527,7 +801,7 @@ app.delete("/projects/:projectId", async (req: Request, res: Response) => {
     res.status(200).send("Project deleted successfully");
   } catch (error) {
     console.error('Error deleting project:', error);
-    res.status(500).send("Internal server error");
+    sendProblem(res, 500);
   }
 });
 
@@ -537,14 +811,26 @@ app.delete("/projects/:projectId/databases/:name", async (req: Request, res: Res
   try {
     const project = await getProject(projectId);
     if (!project) {
-      return res.status(404).send("Project not found");
+      return sendProblem(res, 404, `Project ${projectId} not found`);
     }
 
-    // Delete the pod
-    await k8sApi.deleteNamespacedPod({
-      name: name,
-      namespace: SHARED_NAMESPACE
-    });
+    // Snapshots don't outlive their database, and neither does the copy it
+    // was created from with fromDatabase
+    const snapshots = [
+      ...await listVolumeSnapshots(`devdb/projectId=${projectId},devdb/database=${name}`),
+      ...await listVolumeSnapshots(`devdb/projectId=${projectId},devdb/source-for=${name}`)
+    ];
+    for (const snapshot of snapshots) {
+      await deleteVolumeSnapshot(snapshot.metadata.name);
+    }
+
+    // Delete the pod; a stopped database has none
+    if (await redis.del(stoppedKey(projectId, name)) === 0) {
+      await k8sApi.deleteNamespacedPod({
+        name: name,
+        namespace: SHARED_NAMESPACE
+      });
+    }
     
     // Delete the associated service
     try {
@@ -562,14 +848,305 @@ app.delete("/projects/:projectId/databases/:name", async (req: Request, res: Res
     res.json({ message: "Database deleted successfully" });
   } catch (error) {
     console.error(error);
-    res.status(500).send("Error deleting database");
+    sendProblem(res, 500, "Error deleting database");
+  }
+});
+
+app.post("/projects/:projectId/databases/:name/reset", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    // A stopped database has no pod; resetting it also starts it
+    let pod = await getDatabasePod(name, projectId);
+    const stopped = pod ? null : await getStoppedDatabase(projectId, name);
+    if (!pod && !stopped) {
+      return sendProblem(res, 404, `Database ${name} not found`);
+    }
+
+    // Without a base snapshot of the current data, the project's backup is
+    // restored as when a database is created; it is fetched first so a
+    // failure leaves the database as it was
+    const dataVersion = dataVersionOf(project);
+    const latestSnapshot = await getLatestVolumeSnapshot(project.id, SHARED_NAMESPACE, dataVersion);
+    let backupPath = null;
+    if (!latestSnapshot && project.backupLocation) {
+      backupPath = await prepareBackup(s3Client, project.backupLocation);
+      if (!backupPath) {
+        return sendProblem(res, 500, `Failed to prepare backup ${project.backupLocation}`);
+      }
+    }
+
+    // The Service is left alone so the endpoint stays the same; the pod
+    // and its volume are recreated from the project's pristine data
+    const pvcName = `${name}-data`;
+    if (pod) {
+      await k8sApi.deleteNamespacedPod({ name, namespace: SHARED_NAMESPACE });
+      await waitForDeletion(() => k8sApi.readNamespacedPod({ name, namespace: SHARED_NAMESPACE }));
+    } else {
+      pod = { metadata: { labels: stopped!.labels, annotations: stopped!.annotations }, spec: stopped!.spec };
+      await redis.del(stoppedKey(projectId, name));
+    }
+    await k8sApi.deleteNamespacedPersistentVolumeClaim({ name: pvcName, namespace: SHARED_NAMESPACE });
+    await waitForDeletion(() => k8sApi.readNamespacedPersistentVolumeClaim({ name: pvcName, namespace: SHARED_NAMESPACE }));
+
+    if (latestSnapshot) {
+      await createPVCFromSnapshot(pvcName, SHARED_NAMESPACE, latestSnapshot.metadata.name);
+    } else {
+      await createPersistentVolumeClaim(pvcName, SHARED_NAMESPACE);
+    }
+
+    const { nodeName, ...podSpec } = pod.spec;
+    const spec = withBackupRestore(podSpec, project.dbType, engineOf(project).dataPath, backupPath);
+    const created = await k8sApi.createNamespacedPod({
+      namespace: SHARED_NAMESPACE,
+      body: {
+        apiVersion: "v1",
+        kind: "Pod",
+        metadata: {
+          name,
+          namespace: SHARED_NAMESPACE,
+          labels: { ...pod.metadata.labels, [DATA_VERSION_LABEL]: String(dataVersion) },
+          annotations: pod.metadata.annotations
+        },
+        spec
+      }
+    });
+    res.json(podToDatabase(created, project));
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error resetting database");
+  }
+});
+
+app.post("/projects/:projectId/databases/:name/stop", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    // Reapers that stop expired databases clear their expiry, so they
+    // are not stopped again once started
+    const { clearExpiry }: StopDatabaseRequest = req.body || {};
+    const withoutExpiry = (annotations?: Record<string, string>) => {
+      if (!clearExpiry || !annotations) {
+        return annotations;
+      }
+      const { [EXPIRES_AT_ANNOTATION]: _, ...rest } = annotations;
+      return rest;
+    };
+
+    const pod = await getDatabasePod(name, projectId);
+    if (!pod) {
+      const stopped = await getStoppedDatabase(projectId, name);
+      if (stopped) {
+        if (clearExpiry) {
+          stopped.annotations = withoutExpiry(stopped.annotations);
+          await redis.set(stoppedKey(projectId, name), JSON.stringify(stopped));
+        }
+        return res.json(stoppedToDatabase(stopped, project));
+      }
+      return sendProblem(res, 404, `Database ${name} not found`);
+    }
+    if (podToDatabase(pod, project).status === 'creating') {
+      return sendProblem(res, 409, `Database ${name} is still being created`);
+    }
+
+    // Pods can't be scaled, so keep what is needed to recreate it; the
+    // PVC and the Service stay
+    const { nodeName, ...spec } = pod.spec;
+    const stopped: StoppedDatabase = {
+      labels: pod.metadata.labels,
+      annotations: withoutExpiry(pod.metadata.annotations),
+      spec,
+      stoppedAt: new Date().toISOString()
+    };
+    await redis.set(stoppedKey(projectId, name), JSON.stringify(stopped));
+    await k8sApi.deleteNamespacedPod({ name, namespace: SHARED_NAMESPACE });
+    res.json(stoppedToDatabase(stopped, project));
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error stopping database");
+  }
+});
+
+app.post("/projects/:projectId/databases/:name/start", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    const stopped = await getStoppedDatabase(projectId, name);
+    if (!stopped) {
+      const pod = await getDatabasePod(name, projectId);
+      if (!pod) {
+        return sendProblem(res, 404, `Database ${name} not found`);
+      }
+      return res.json(podToDatabase(pod, project));
+    }
+
+    // Stopping doesn't wait for the pod to terminate, and its name is only
+    // free again once it is gone
+    await waitForDeletion(() => k8sApi.readNamespacedPod({ name, namespace: SHARED_NAMESPACE }));
+    // The data is already on the volume: restoring the backup again would
+    // overwrite it, and its hostPath may not exist on the node the pod
+    // lands on now
+    const spec = withBackupRestore(stopped.spec, project.dbType, engineOf(project).dataPath, null);
+    const created = await k8sApi.createNamespacedPod({
+      namespace: SHARED_NAMESPACE,
+      body: {
+        apiVersion: "v1",
+        kind: "Pod",
+        metadata: { name, namespace: SHARED_NAMESPACE, labels: stopped.labels, annotations: stopped.annotations },
+        spec
+      }
+    });
+    await redis.del(stoppedKey(projectId, name));
+    res.json(podToDatabase(created, project));
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error starting database");
+  }
+});
+
+app.post("/projects/:projectId/databases/:name/extend", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  const { ttlSeconds } = req.body;
+  if (!(Number.isInteger(ttlSeconds) && ttlSeconds >= MIN_TTL_SECONDS)) {
+    return sendProblem(res, 400, `ttlSeconds must be an integer of at least ${MIN_TTL_SECONDS}`);
+  }
+
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    const pod = await getDatabasePod(name, projectId);
+    const stopped = pod ? null : await getStoppedDatabase(projectId, name);
+    if (!pod && !stopped) {
+      return sendProblem(res, 404, `Database ${name} not found`);
+    }
+
+    // Count from the current expiry, or from now if that has passed
+    const current = (pod?.metadata?.annotations || stopped?.annotations || {})[EXPIRES_AT_ANNOTATION];
+    const from = Math.max(Date.now(), current ? new Date(current).getTime() : 0);
+    const expiresAt = new Date(from + ttlSeconds * 1000).toISOString();
+
+    if (pod) {
+      const patched = await k8sApi.patchNamespacedPod({
+        name,
+        namespace: SHARED_NAMESPACE,
+        body: { metadata: { annotations: { [EXPIRES_AT_ANNOTATION]: expiresAt } } }
+      }, setHeaderOptions('Content-Type', PatchStrategy.MergePatch));
+      return res.json(podToDatabase(patched, project));
+    }
+    stopped!.annotations = { ...stopped!.annotations, [EXPIRES_AT_ANNOTATION]: expiresAt };
+    await redis.set(stoppedKey(projectId, name), JSON.stringify(stopped));
+    res.json(stoppedToDatabase({ ...stopped!, name }, project));
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error extending database");
+  }
+});
+
+app.get("/projects/:projectId/databases/:name/snapshots", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    // Snapshots of stopped databases are kept, and listed
+    if (!await getDatabasePod(name, projectId) && !await getStoppedDatabase(projectId, name)) {
+      return sendProblem(res, 404, `Database ${name} not found`);
+    }
+
+    const snapshots = (await listVolumeSnapshots(`devdb/projectId=${projectId},devdb/database=${name}`))
+      .map(volumeSnapshotToSnapshot)
+      .sort((a, b) => new Date(a.createdAt).getTime() - new Date(b.createdAt).getTime());
+    res.json(snapshots);
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error listing snapshots");
+  }
+});
+
+app.post("/projects/:projectId/databases/:name/snapshots", async (req: Request, res: Response) => {
+  const { projectId, name } = req.params;
+  const snapshotName: string = req.body?.name || defaultSnapshotName(name);
+
+  if (!getStorageConfig().useSnapshots) {
+    return sendProblem(res, 400, "Volume snapshots are not enabled on this server");
+  }
+  if (!/^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$/.test(snapshotName)) {
+    return sendProblem(res, 400, `Invalid snapshot name ${snapshotName}: use lowercase letters, digits and dashes`);
+  }
+
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+    const pod = await getDatabasePod(name, projectId);
+    if (!pod) {
+      return sendProblem(res, 404, `Database ${name} not found`);
+    }
+    if (podToDatabase(pod, project).status !== 'running') {
+      return sendProblem(res, 409, `Database ${name} is not running`);
+    }
+    if (await getVolumeSnapshot(snapshotName, projectId)) {
+      return sendProblem(res, 409, `Snapshot ${snapshotName} already exists`);
+    }
+
+    const snapshot = await createVolumeSnapshot(`${name}-data`, SHARED_NAMESPACE, {
+      name: `${projectId}-${snapshotName}`,
+      labels: { "devdb/projectId": projectId, "devdb/database": name },
+      annotations: { [SNAPSHOT_NAME_ANNOTATION]: snapshotName }
+    });
+    if (!snapshot) {
+      return sendProblem(res, 500, "Error creating snapshot");
+    }
+    res.status(201).json(volumeSnapshotToSnapshot(snapshot));
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error creating snapshot");
+  }
+});
+
+app.delete("/projects/:projectId/databases/:name/snapshots/:snapshot", async (req: Request, res: Response) => {
+  const { projectId, name, snapshot } = req.params;
+  try {
+    const project = await getProject(projectId);
+    if (!project) {
+      return sendProblem(res, 404, `Project ${projectId} not found`);
+    }
+
+    const volumeSnapshot = await getVolumeSnapshot(snapshot, projectId);
+    if (!volumeSnapshot || volumeSnapshot.metadata?.labels?.["devdb/database"] !== name) {
+      return sendProblem(res, 404, `Snapshot ${snapshot} of database ${name} not found`);
+    }
+
+    await deleteVolumeSnapshot(volumeSnapshot.metadata.name);
+    res.json({ message: "Snapshot deleted successfully" });
+  } catch (error) {
+    console.error(error);
+    sendProblem(res, 500, "Error deleting snapshot");
   }
 });
 
 async function createVolumeSnapshot(
   pvcName: string,
   namespace: string,
-  snapshotClassName?: string
+  options: {
+    name?: string;
+    labels?: Record<string, string>;
+    annotations?: Record<string, string>;
+    snapshotClassName?: string;
+  } = {}
 ): Promise<any | null> {
   const storage = getStorageConfig();
   
@@ -578,20 +1155,22 @@ async function createVolumeSnapshot(
     return null;
   }
 
-  const snapshotName = `${pvcName}-snapshot-${Date.now()}`;
+  const snapshotName = options.name || `${pvcName}-snapshot-${Date.now()}`;
 
   const snapshotManifest = {
     apiVersion: "snapshot.storage.k8s.io/v1",
     kind: "VolumeSnapshot",
     metadata: {
       name: snapshotName,
-      namespace: namespace
+      namespace: namespace,
+      labels: options.labels || {},
+      annotations: options.annotations || {}
     },
     spec: {
       source: {
         persistentVolumeClaimName: pvcName
       },
-      volumeSnapshotClassName: snapshotClassName || storage.snapshotClass
+      volumeSnapshotClassName: options.snapshotClassName || storage.snapshotClass
     }
   };
 
@@ -613,7 +1192,8 @@ async function createVolumeSnapshot(
 
 async function getLatestVolumeSnapshot(
   projectId: string,
-  namespace: string
+  namespace: string,
+  dataVersion: number
 ): Promise<any | null> {
   const storage = getStorageConfig();
   
@@ -628,10 +1208,15 @@ async function getLatestVolumeSnapshot(
       version: "v1",
       namespace: namespace,
       plural: "volumesnapshots",
+      // User snapshots and the temporary copies made for fromDatabase are
+      // not the project's base snapshot
+      labelSelector: `devdb/projectId=${projectId},!devdb/database,!devdb/source-for`
     });
 
-    const snapshots = response.items;
-    if (!snapshots || snapshots.length === 0) {
+    // Base snapshots taken before data versions existed are of version 1
+    const snapshots = (response.items || []).filter((snapshot: any) =>
+      (snapshot.metadata?.labels?.[DATA_VERSION_LABEL] ?? '1') === String(dataVersion));
+    if (snapshots.length === 0) {
       return null;
     }
 
@@ -647,6 +1232,126 @@ async function getLatestVolumeSnapshot(
   }
 }
 
+async function listVolumeSnapshots(labelSelector: string): Promise<any[]> {
+  if (!getStorageConfig().useSnapshots) {
+    return [];
+  }
+  const response = await k8sApiExt.listNamespacedCustomObject({
+    group: "snapshot.storage.k8s.io",
+    version: "v1",
+    namespace: SHARED_NAMESPACE,
+    plural: "volumesnapshots",
+    labelSelector
+  });
+  return response.items || [];
+}
+
+// getVolumeSnapshot returns the user snapshot with the given name, or null
+// when it doesn't exist or belongs to another project.
+async function getVolumeSnapshot(name: string, projectId: string): Promise<any | null> {
+  const snapshots = await listVolumeSnapshots(`devdb/projectId=${projectId},devdb/database`);
+  return snapshots.find((snapshot: any) => userSnapshotName(snapshot) === name) || null;
+}
+
+// userSnapshotName returns the name a user snapshot was given; snapshots
+// taken before names were scoped by project are named like their object.
+function userSnapshotName(snapshot: any): string {
+  return snapshot.metadata?.annotations?.[SNAPSHOT_NAME_ANNOTATION] || snapshot.metadata?.name || '';
+}
+
+async function deleteVolumeSnapshot(name: string): Promise<void> {
+  try {
+    await k8sApiExt.deleteNamespacedCustomObject({
+      group: "snapshot.storage.k8s.io",
+      version: "v1",
+      namespace: SHARED_NAMESPACE,
+      plural: "volumesnapshots",
+      name
+    });
+  } catch (error: any) {
+    if (error.code !== 404 && error.response?.statusCode !== 404) {
+      throw error;
+    }
+  }
+}
+
+// getDatabasePod returns the pod of a database, or null when it doesn't
+// exist or belongs to another project.
+async function getDatabasePod(name: string, projectId: string): Promise<any | null> {
+  try {
+    const pod = await k8sApi.readNamespacedPod({ name, namespace: SHARED_NAMESPACE });
+    return pod.metadata?.labels?.["devdb/projectId"] === projectId ? pod : null;
+  } catch (error: any) {
+    if (error.code === 404 || error.response?.statusCode === 404) {
+      return null;
+    }
+    throw error;
+  }
+}
+
+// waitForDeletion polls read until it fails with 404, for at most two
+// minutes.
+async function waitForDeletion(read: () => Promise<any>): Promise<void> {
+  const deadline = Date.now() + 120000;
+  while (Date.now() < deadline) {
+    try {
+      await read();
+    } catch (error: any) {
+      if (error.code === 404 || error.response?.statusCode === 404) {
+        return;
+      }
+      throw error;
+    }
+    await new Promise(resolve => setTimeout(resolve, 1000));
+  }
+  throw new Error('Timed out waiting for deletion');
+}
+
+// Map a VolumeSnapshot to the API representation.
+function volumeSnapshotToSnapshot(snapshot: any): Snapshot {
+  let status: Snapshot['status'] = 'creating';
+  if (snapshot.status?.error) {
+    status = 'error';
+  } else if (snapshot.status?.readyToUse) {
+    status = 'ready';
+  }
+
+  const result: Snapshot = {
+    name: userSnapshotName(snapshot),
+    database: snapshot.metadata?.labels?.["devdb/database"] || '',
+    project: snapshot.metadata?.labels?.["devdb/projectId"],
+    status,
+    createdAt: snapshot.metadata?.creationTimestamp || new Date().toISOString()
+  };
+  if (snapshot.status?.restoreSize) {
+    const size = parseQuantity(snapshot.status.restoreSize);
+    if (size !== null) {
+      result.sizeBytes = size;
+    }
+  }
+  return result;
+}
+
+// parseQuantity converts a Kubernetes storage quantity such as "10Gi" to bytes.
+function parseQuantity(quantity: string): number | null {
+  const units: Record<string, number> = {
+    '': 1, 'k': 1e3, 'M': 1e6, 'G': 1e9, 'T': 1e12,
+    'Ki': 1024, 'Mi': 1024 ** 2, 'Gi': 1024 ** 3, 'Ti': 1024 ** 4
+  };
+  const match = /^(\d+(?:\.\d+)?)([kMGT]i?|Ki)?$/.exec(quantity);
+  if (!match) {
+    return null;
+  }
+  return Math.round(parseFloat(match[1]) * units[match[2] || '']);
+}
+
+// Snapshots default to the database name and the current UTC time, e.g.
+// mydb-20240102-150405.
+function defaultSnapshotName(database: string): string {
+  const stamp = new Date().toISOString().replace(/[-:]/g, '').replace('T', '-').slice(0, 15);
+  return `${database.slice(0, 47)}-${stamp}`;
+}
+
 async function createPVCFromSnapshot(
   name: string,
   namespace: string,
@@ -713,6 +1418,119 @@ async function createPersistentVolumeClaim(
   });
 }
 
+// Map a database pod to the API representation. Pod phases are translated
+// to the statuses defined in the OpenAPI spec; a pod only counts as running
+// once its containers report ready.
+function podToDatabase(pod: any, project: Project): Database {
+  let status: Database['status'];
+  switch (pod.status?.phase) {
+    case 'Running':
+      status = pod.status?.containerStatuses?.every((c: any) => c.ready) ? 'running' : 'creating';
+      break;
+    case 'Pending':
+      status = 'creating';
+      break;
+    case 'Succeeded':
+      status = 'stopped';
+      break;
+    default:
+      status = 'error';
+  }
+
+  return {
+    name: pod.metadata?.name || '',
+    status,
+    project: project.id,
+    host: `${pod.metadata?.name}.${SHARED_NAMESPACE}`,
+    port: engineOf(project).port,
+    username: project.defaultCredentials.username,
+    database: project.defaultCredentials.database,
+    expiresAt: pod.metadata?.annotations?.[EXPIRES_AT_ANNOTATION],
+    dataVersion: parseDataVersion(pod.metadata?.labels?.[DATA_VERSION_LABEL])
+  };
+}
+
+// A stopped database is its pod's labels and spec, kept in Redis until it
+// is started again.
+interface StoppedDatabase {
+  labels: Record<string, string>;
+  annotations?: Record<string, string>;
+  spec: any;
+  stoppedAt: string;
+}
+
+function stoppedKey(projectId: string, name: string): string {
+  return `stopped:${projectId}:${name}`;
+}
+
+async function getStoppedDatabase(projectId: string, name: string): Promise<StoppedDatabase | null> {
+  const stoppedJson = await redis.get(stoppedKey(projectId, name));
+  return stoppedJson ? JSON.parse(stoppedJson) as StoppedDatabase : null;
+}
+
+async function listStoppedDatabases(projectId: string): Promise<(StoppedDatabase & { name: string })[]> {
+  const stopped: (StoppedDatabase & { name: string })[] = [];
+  for (const key of await redis.keys(stoppedKey(projectId, '*'))) {
+    const stoppedJson = await redis.get(key);
+    if (stoppedJson) {
+      stopped.push({ ...JSON.parse(stoppedJson), name: key.slice(stoppedKey(projectId, '').length) });
+    }
+  }
+  return stopped;
+}
+
+function stoppedToDatabase(stopped: StoppedDatabase & { name?: string }, project: Project): Database {
+  const name = stopped.name || stopped.labels.app;
+  return {
+    name,
+    status: 'stopped',
+    project: project.id,
+    host: `${name}.${SHARED_NAMESPACE}`,
+    port: engineOf(project).port,
+    username: project.defaultCredentials.username,
+    database: project.defaultCredentials.database,
+    stoppedAt: stopped.stoppedAt,
+    expiresAt: stopped.annotations?.[EXPIRES_AT_ANNOTATION],
+    dataVersion: parseDataVersion(stopped.labels?.[DATA_VERSION_LABEL])
+  };
+}
+
+// Projects created before data versions existed are at version 1
+function dataVersionOf(project: Project): number {
+  return project.dataVersion ?? 1;
+}
+
+function parseDataVersion(label: string | undefined): number | undefined {
+  return label ? Number(label) : undefined;
+}
+
+// showSecrets reports whether a project's credentials and masking policy
+// may be returned. Without authentication the server is open and returns
+// them to anyone, so databases can be connected to, as the Go server does.
+function showSecrets(res: Response): boolean {
+  return !authEnabled() || res.locals.authenticated === true;
+}
+
+// withoutSecrets leaves out what only authenticated clients get to see
+function withoutSecrets(project: Project) {
+  const { defaultCredentials, maskingPolicy, ...rest } = project;
+  return rest;
+}
+
+async function listProjects(owner?: string): Promise<Project[]> {
+  const projects: Project[] = [];
+  for (const key of await redis.keys('project:*')) {
+    const projectJson = await redis.get(key);
+    if (projectJson) {
+      const project = JSON.parse(projectJson) as Project;
+      if (!owner || project.owner === owner) {
+        projects.push(project);
+      }
+    }
+  }
+  return projects;
+}
+
 async function getProject(projectId: string): Promise<Project | null> {
   const projectJson = await redis.get(`project:${projectId}`);
   if (projectJson) {
@@ -726,6 +1544,10 @@ app.get("/health", (req: Request, res: Response) => {
   res.status(200).json({ status: "healthy" });
 });
 
+// Everything else, and errors from the handlers above, get problem details
+app.use(notFound);
+app.use(problemHandler);
+
 function generateSecurePassword(length: number = 32): string {
   // Define character sets for password
   const lowercase = 'abcdefghijklmnopqrstuvwxyz';
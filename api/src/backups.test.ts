@@ -0,0 +1,213 @@
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { Readable } from 'stream';
+import { execFileSync } from 'child_process';
+import { S3Client, HeadObjectCommand } from '@aws-sdk/client-s3';
+import { parseS3Location, prepareBackup, withBackupRestore } from './backups';
+
+// fakeS3 serves objects by bucket and key and records the commands sent
+function fakeS3(objects: Record<string, string>) {
+  const sent: { command: string; bucket: string; key: string }[] = [];
+  const s3 = {
+    send: async (command: any) => {
+      const { Bucket, Key } = command.input;
+      sent.push({ command: command instanceof HeadObjectCommand ? 'HeadObjectCommand' : 'GetObjectCommand', bucket: Bucket, key: Key });
+      const body = objects[`${Bucket}/${Key}`];
+      if (body === undefined) {
+        throw new Error(`NoSuchKey: ${Bucket}/${Key}`);
+      }
+      return { Body: Readable.from([Buffer.from(body, 'binary')]) };
+    }
+  };
+  return { s3: s3 as unknown as Pick<S3Client, 'send'>, sent };
+}
+
+function tempDir(): string {
+  return fs.mkdtempSync(path.join(os.tmpdir(), 'devdb-backups-'));
+}
+
+// restoreScript returns the script a PostgreSQL database restores its
+// backup with, reading the backup from dir instead of /backup and
+// unpacking archives below it instead of /tmp
+function restoreScript(spec: any, dir: string): string {
+  const writer = spec.initContainers.find((c: any) => c.name === 'write-restore-script');
+  const script = writer.env.find((e: any) => e.name === 'SCRIPT').value;
+  return script.split('/backup/').join(`${dir}/`).split('/tmp/devdb-restore').join(`${dir}/unpacked`);
+}
+
+// runRestore runs a restore script with psql and pg_restore stubs that
+// print how they were called, and the files of a directory pg_restore
+// reads, or what psql runs when it prints the file
+function runRestore(script: string, psqlPrintsFile = false): string {
+  const bin = tempDir();
+  fs.writeFileSync(path.join(bin, 'psql'), '#!/bin/sh\necho psql "$@"\n', { mode: 0o755 });
+  fs.writeFileSync(path.join(bin, 'pg_restore'), '#!/bin/sh\necho pg_restore "$@"\nfor dir; do :; done\nif [ -d "$dir" ]; then ls "$dir"; fi\n', { mode: 0o755 });
+  if (psqlPrintsFile) {
+    fs.writeFileSync(path.join(bin, 'psql'), '#!/bin/sh\nfor file; do :; done\ncat "$file"\n', { mode: 0o755 });
+  }
+  return execFileSync('sh', ['-c', script], {
+    env: { PATH: `${bin}:${process.env.PATH}`, POSTGRES_USER: 'devdb', POSTGRES_DB: 'app' },
+    encoding: 'utf8'
+  });
+}
+
+const podSpec = {
+  containers: [{ name: 'postgres', image: 'postgres:16', volumeMounts: [{ name: 'data', mountPath: '/var/lib/postgresql/data' }] }],
+  volumes: [{ name: 'data', persistentVolumeClaim: { claimName: 'app-data' } }]
+};
+
+describe('parseS3Location', () => {
+  it('should split a bucket and a key', () => {
+    expect(parseS3Location('s3://backups/billing/2024/app.dump')).toEqual({ bucket: 'backups', key: 'billing/2024/app.dump' });
+  });
+
+  it('should reject locations that are not s3://bucket/key', () => {
+    for (const location of ['', 'backups/app.dump', 'https://backups/app.dump', 's3://backups', 's3://backups/', 's3:///app.dump']) {
+      expect(parseS3Location(location)).toBeNull();
+    }
+  });
+});
+
+describe('prepareBackup', () => {
+  it('should download the backup of a new database by its bucket and key', async () => {
+    const dir = tempDir();
+    const { s3, sent } = fakeS3({ 'backups/billing/app.sql': 'CREATE TABLE users (id int);\n' });
+
+    const backupPath = await prepareBackup(s3, 's3://backups/billing/app.sql', dir);
+
+    expect(backupPath).toBe(path.join(dir, 'backups', 'billing', 'app.sql'));
+    expect(fs.readFileSync(backupPath!, 'utf8')).toBe('CREATE TABLE users (id int);\n');
+    expect(sent).toEqual([
+      { command: 'HeadObjectCommand', bucket: 'backups', key: 'billing/app.sql' },
+      { command: 'GetObjectCommand', bucket: 'backups', key: 'billing/app.sql' }
+    ]);
+
+    // The database's pod mounts the backup's directory and restores it
+    const spec = withBackupRestore(podSpec, 'postgres', '/var/lib/postgresql/data', backupPath);
+    expect(spec.volumes).toContainEqual({ name: 'backup', hostPath: { path: path.dirname(backupPath!), type: 'Directory' } });
+    expect(spec.containers[0].volumeMounts).toContainEqual({ name: 'backup', mountPath: '/backup', readOnly: true });
+    expect(runRestore(restoreScript(spec, path.dirname(backupPath!)))).toBe(
+      `psql -v ON_ERROR_STOP=1 --username devdb --dbname app -f ${backupPath}\n`
+    );
+  });
+
+  it('should keep backups of the same name in different buckets apart', async () => {
+    const dir = tempDir();
+    const { s3 } = fakeS3({ 'one/app.sql': 'one', 'two/app.sql': 'two' });
+
+    const one = await prepareBackup(s3, 's3://one/app.sql', dir);
+    const two = await prepareBackup(s3, 's3://two/app.sql', dir);
+
+    expect(one).not.toBe(two);
+    expect(fs.readFileSync(one!, 'utf8')).toBe('one');
+    expect(fs.readFileSync(two!, 'utf8')).toBe('two');
+  });
+
+  it('should fail for missing backups, bad locations and keys leaving the bucket', async () => {
+    const dir = tempDir();
+    const { s3, sent } = fakeS3({ 'backups/../secret': 'secret' });
+
+    expect(await prepareBackup(s3, 's3://backups/missing.sql', dir)).toBeNull();
+    expect(await prepareBackup(s3, 'backups/app.sql', dir)).toBeNull();
+    expect(await prepareBackup(s3, 's3://backups/../secret', dir)).toBeNull();
+    expect(sent).toEqual([{ command: 'HeadObjectCommand', bucket: 'backups', key: 'missing.sql' }]);
+  });
+});
+
+describe('withBackupRestore', () => {
+  it('should restore PostgreSQL backups by their format', () => {
+    const dir = tempDir();
+    const dumps: Record<string, string> = {
+      'plain.sql': '--\n-- PostgreSQL database dump\n--\n',
+      'custom.dump': 'PGDMP\x01\x0e'
+    };
+
+    // The CLI archives the files of a directory dump (pg_dump -Fd) with tar
+    const directory = path.join(dir, 'directory');
+    fs.mkdirSync(directory);
+    fs.writeFileSync(path.join(directory, 'toc.dat'), 'PGDMP\x01\x0e', 'binary');
+    fs.writeFileSync(path.join(directory, '3001.dat.gz'), '');
+    execFileSync('tar', ['-cf', path.join(dir, 'directory.tar'), '-C', directory, 'toc.dat', '3001.dat.gz']);
+
+    const restored: Record<string, string> = {};
+    for (const file of ['plain.sql', 'custom.dump', 'directory.tar']) {
+      if (dumps[file] !== undefined) {
+        fs.writeFileSync(path.join(dir, file), dumps[file], 'binary');
+      }
+      const spec = withBackupRestore(podSpec, 'postgres', '/var/lib/postgresql/data', path.join(dir, file));
+      restored[file] = runRestore(restoreScript(spec, dir));
+    }
+
+    const connect = '--username devdb --dbname app';
+    expect(restored).toEqual({
+      'plain.sql': `psql -v ON_ERROR_STOP=1 ${connect} -f ${dir}/plain.sql\n`,
+      'custom.dump': `pg_restore --no-owner --no-privileges ${connect} ${dir}/custom.dump\n`,
+      'directory.tar': `pg_restore --format=directory --no-owner --no-privileges ${connect} ${dir}/unpacked\n3001.dat.gz\ntoc.dat\n`
+    });
+    expect(fs.existsSync(path.join(dir, 'unpacked'))).toBe(false);
+  });
+
+  it('should restore Redis backups only into an empty data directory', () => {
+    const dir = tempDir();
+    const data = path.join(dir, 'data');
+    fs.mkdirSync(data);
+    fs.writeFileSync(path.join(dir, 'backup.rdb'), 'REDIS0011 backup');
+    const spec = withBackupRestore(podSpec, 'redis', '/data', path.join(dir, 'backup.rdb'));
+    const [shell, flag, command] = spec.initContainers[0].command;
+    const restore = () => execFileSync(shell, [flag, command.split('/backup/').join(`${dir}/`).split('/data/').join(`${data}/`)]);
+
+    restore();
+    expect(fs.readFileSync(path.join(data, 'dump.rdb'), 'utf8')).toBe('REDIS0011 backup');
+
+    // The init container runs again when the pod is recreated, e.g. by
+    // db start, and must keep what was written since
+    fs.writeFileSync(path.join(data, 'dump.rdb'), 'REDIS0011 later');
+    restore();
+    expect(fs.readFileSync(path.join(data, 'dump.rdb'), 'utf8')).toBe('REDIS0011 later');
+  });
+
+  it('should replace the restore of an earlier backup', () => {
+    const first = withBackupRestore(podSpec, 'postgres', '/var/lib/postgresql/data', '/tmp/backups/b/one.sql');
+    const second = withBackupRestore(first, 'postgres', '/var/lib/postgresql/data', '/tmp/backups/b/two/two.sql');
+    const none = withBackupRestore(second, 'postgres', '/var/lib/postgresql/data', null);
+
+    expect(second.initContainers).toHaveLength(1);
+    expect(second.volumes.filter((v: any) => v.name === 'backup')).toEqual([
+      { name: 'backup', hostPath: { path: '/tmp/backups/b/two', type: 'Directory' } }
+    ]);
+    expect(none.initContainers).toEqual([]);
+    expect(none.containers[0].volumeMounts).toEqual(podSpec.containers[0].volumeMounts);
+    expect(none.volumes).toEqual(podSpec.volumes);
+  });
+});
+
+describe('masked backups', () => {
+  // billing.sql masked by devdb project mask apply with the policy
+  //   seed: test
+  //   columns:
+  //     users.email: {faker: email}
+  //     users.notes: null
+  const masked = [
+    '--',
+    '-- PostgreSQL database dump',
+    '--',
+    '',
+    'COPY public.users (id, email, notes) FROM stdin;',
+    '1\tnoah.yilmaz.4kghy2gwc3x3okurssp4eff56buhhgx5wpcalgy@example.com\t\\N',
+    '2\tfarid.murphy.b7rld2ubdkcen7gbal3pwpilhf33pssiy2we2gy@example.com\t\\N',
+    '\\.',
+    ''
+  ].join('\n');
+
+  it('should create a database with the masked data', async () => {
+    const dir = tempDir();
+    const { s3 } = fakeS3({ 'devdb-backups/uploads/billing.sql': masked });
+
+    const backupPath = await prepareBackup(s3, 's3://devdb-backups/uploads/billing.sql', dir);
+    const spec = withBackupRestore(podSpec, 'postgres', '/var/lib/postgresql/data', backupPath);
+    const restored = runRestore(restoreScript(spec, path.dirname(backupPath!)), true);
+
+    expect(restored).toBe(masked);
+  });
+});
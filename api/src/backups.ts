@@ -0,0 +1,209 @@
+import * as fs from 'fs';
+import * as path from 'path';
+import { pipeline } from 'stream/promises';
+import { S3Client, HeadObjectCommand, GetObjectCommand } from "@aws-sdk/client-s3";
+import type { components } from './types/generated/api.js';
+
+type DatabaseType = components['schemas']['DatabaseType'];
+
+// Where backups are downloaded to before they are restored
+const BACKUP_DIR = '/tmp/backups';
+
+// parseS3Location splits an s3://bucket/key location, or returns null when
+// location is not one.
+export function parseS3Location(location: string): { bucket: string; key: string } | null {
+  const match = /^s3:\/\/([^/]+)\/(.+)$/.exec(location);
+  return match ? { bucket: match[1], key: match[2] } : null;
+}
+
+// prepareBackup downloads the backup at an s3:// location to dir, keeping
+// its bucket and key so backups with the same file name don't clash, and
+// returns its path, or null when the backup cannot be fetched.
+export async function prepareBackup(
+  s3: Pick<S3Client, 'send'>,
+  location: string,
+  dir: string = BACKUP_DIR
+): Promise<string | null> {
+  const s3Location = parseS3Location(location);
+  if (!s3Location) {
+    console.error('Invalid S3 backup location:', location);
+    return null;
+  }
+  const { bucket, key } = s3Location;
+
+  // Keys may hold .. segments, which must not leave the bucket's directory
+  const bucketDir = path.join(dir, bucket);
+  const localPath = path.join(bucketDir, ...key.split('/'));
+  if (!localPath.startsWith(bucketDir + path.sep)) {
+    console.error('Backup key escapes the backup directory:', location);
+    return null;
+  }
+
+  try {
+    await s3.send(new HeadObjectCommand({ Bucket: bucket, Key: key }));
+  } catch (error) {
+    console.error('Backup file not found in S3:', error);
+    return null;
+  }
+
+  try {
+    const response = await s3.send(new GetObjectCommand({ Bucket: bucket, Key: key }));
+    if (!response.Body) {
+      throw new Error('Empty response from S3');
+    }
+    await fs.promises.mkdir(path.dirname(localPath), { recursive: true });
+    await pipeline(response.Body as any, fs.createWriteStream(localPath));
+    return localPath;
+  } catch (error) {
+    console.error('Error preparing backup:', error);
+    return null;
+  }
+}
+
+// How a backup is restored into a new database: by an init script running
+// pg_restore or psql, which the PostgreSQL image runs once it has created
+// the project's user and database; by the MySQL and MariaDB images themselves,
+// which run the SQL files in docker-entrypoint-initdb.d when they
+// initialize their data directory; by copying an RDB file to where
+// redis-server loads it from, unless there is one already; and by an init script running mongorestore,
+// which the MongoDB image runs once it has created its root user.
+function backupRestore(dbType: DatabaseType, dataPath: string, backupPath: string) {
+  const file = path.basename(backupPath);
+  const dataMount = { name: "data", mountPath: dataPath };
+  const backupMount = { name: "backup", mountPath: "/backup", readOnly: true };
+
+  switch (dbType) {
+    case 'mysql':
+    case 'mariadb':
+      return {
+        initContainers: [],
+        volumeMounts: [
+          {
+            name: "backup",
+            mountPath: "/docker-entrypoint-initdb.d/backup.sql",
+            subPath: file,
+            readOnly: true
+          }
+        ],
+        volumes: []
+      };
+    case 'redis':
+      return {
+        initContainers: [
+          {
+            name: "restore-backup",
+            image: "busybox:latest",
+            // Init containers run again whenever the pod is recreated, so
+            // the backup must not overwrite data written since
+            command: ["sh", "-c", `[ -f ${dataPath}/dump.rdb ] || cp /backup/${file} ${dataPath}/dump.rdb`],
+            volumeMounts: [dataMount, backupMount]
+          }
+        ],
+        volumeMounts: [],
+        volumes: []
+      };
+    case 'mongodb':
+      return {
+        initContainers: [
+          {
+            name: "write-restore-script",
+            image: "busybox:latest",
+            command: ["sh", "-c", 'printf "%s\\n" "$SCRIPT" > /initdb/restore.sh'],
+            env: [
+              {
+                name: "SCRIPT",
+                value: `mongorestore --archive="/backup/${file}" --username "$MONGO_INITDB_ROOT_USERNAME" --password "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin`
+              }
+            ],
+            volumeMounts: [{ name: "initdb", mountPath: "/initdb" }]
+          }
+        ],
+        volumeMounts: [backupMount, { name: "initdb", mountPath: "/docker-entrypoint-initdb.d" }],
+        volumes: [{ name: "initdb", emptyDir: {} }]
+      };
+  }
+
+  // Uploads don't record their format, so the script tells it by the
+  // file's magic like the CLI does: custom archives start with PGDMP, tar
+  // archives of directory dumps have ustar at offset 257, and anything
+  // else is plain SQL. The CLI archives directory dumps with tar, which is
+  // not pg_dump's tar format, so they are unpacked and restored as the
+  // directory they were.
+  const backup = shellQuote(`/backup/${file}`);
+  const connect = '--username "$POSTGRES_USER" --dbname "$POSTGRES_DB"';
+  const unpacked = '/tmp/devdb-restore';
+  return {
+    initContainers: [
+      {
+        name: "write-restore-script",
+        image: "busybox:latest",
+        command: ["sh", "-c", 'printf "%s\\n" "$SCRIPT" > /initdb/restore.sh'],
+        env: [
+          {
+            name: "SCRIPT",
+            value: [
+              `if head -c 5 ${backup} | grep -q PGDMP; then`,
+              `  pg_restore --no-owner --no-privileges ${connect} ${backup}`,
+              `elif dd if=${backup} bs=1 skip=257 count=5 2>/dev/null | grep -q ustar; then`,
+              `  mkdir -p ${unpacked}`,
+              `  tar -xf ${backup} -C ${unpacked}`,
+              `  pg_restore --format=directory --no-owner --no-privileges ${connect} ${unpacked}`,
+              `  rm -rf ${unpacked}`,
+              `else`,
+              `  psql -v ON_ERROR_STOP=1 ${connect} -f ${backup}`,
+              `fi`
+            ].join('\n')
+          }
+        ],
+        volumeMounts: [{ name: "initdb", mountPath: "/initdb" }]
+      }
+    ],
+    volumeMounts: [backupMount, { name: "initdb", mountPath: "/docker-entrypoint-initdb.d" }],
+    volumes: [{ name: "initdb", emptyDir: {} }]
+  };
+}
+
+// shellQuote quotes s as a single sh word.
+export function shellQuote(s: string): string {
+  return `'${s.replace(/'/g, `'\\''`)}'`;
+}
+
+// Volumes that only restore a backup
+export const RESTORE_VOLUMES = ['backup', 'initdb'];
+
+// withBackupRestore returns a database pod spec that restores the backup at
+// backupPath, or nothing when it is null, on first start. The restore of a
+// backup the spec had before is dropped, so reset can restore a newer one.
+export function withBackupRestore(spec: any, dbType: DatabaseType, dataPath: string, backupPath: string | null) {
+  const restore = backupPath
+    ? backupRestore(dbType, dataPath, backupPath)
+    : { initContainers: [], volumeMounts: [], volumes: [] };
+  const [container, ...sidecars] = spec.containers;
+  return {
+    ...spec,
+    initContainers: restore.initContainers,
+    containers: [
+      {
+        ...container,
+        volumeMounts: [
+          ...(container.volumeMounts || []).filter((mount: any) => !RESTORE_VOLUMES.includes(mount.name)),
+          ...restore.volumeMounts
+        ]
+      },
+      ...sidecars
+    ],
+    volumes: [
+      ...(spec.volumes || []).filter((volume: any) => !RESTORE_VOLUMES.includes(volume.name)),
+      ...(backupPath ? [
+        {
+          name: "backup",
+          hostPath: {
+            path: path.dirname(backupPath),
+            type: "Directory"
+          }
+        }
+      ] : []),
+      ...restore.volumes
+    ]
+  };
+}
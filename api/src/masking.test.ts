@@ -0,0 +1,34 @@
+import { maskingPolicyError } from './masking';
+
+describe('maskingPolicyError', () => {
+  it('should accept a valid policy', () => {
+    expect(maskingPolicyError(`seed: test-seed
+columns:
+  users.email: {faker: email}
+  users.ssn: {hash: true, length: 12}
+  users.token: hash
+  users.notes: null
+  billing.accounts.country: {constant: NL}
+`)).toBeNull();
+  });
+
+  // The cases of the CLI's masking tests
+  const invalid: [string, string, string][] = [
+    ['empty', '', 'no columns to mask'],
+    ['unknown field', 'seeds: x\ncolumns:\n  users.email: hash\n', 'field seeds not found'],
+    ['bad column', 'columns:\n  email: hash\n', 'invalid column "email"'],
+    ['duplicate', 'columns:\n  users.email: hash\n  public.users.email: null\n', 'public.users.email is listed twice'],
+    ['unknown rule', 'columns:\n  users.email: scramble\n', 'unknown rule "scramble"'],
+    ['unknown faker', 'columns:\n  users.email: {faker: iban}\n', 'unknown faker "iban"'],
+    ['two strategies', 'columns:\n  users.email: {faker: email, hash: true}\n', 'exactly one of'],
+    ['no strategy', 'columns:\n  users.email: {length: 3}\n', 'exactly one of'],
+    ['length of constant', 'columns:\n  users.email: {constant: x, length: 3}\n', 'length only applies'],
+    ['constant null', 'columns:\n  users.email: {constant: null}\n', 'use the null rule'],
+    ['not YAML', 'columns: [users.email\n', 'invalid policy'],
+  ];
+  for (const [name, policy, want] of invalid) {
+    it(`should reject a policy with ${name}`, () => {
+      expect(maskingPolicyError(policy)).toContain(want);
+    });
+  }
+});
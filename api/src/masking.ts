@@ -0,0 +1,146 @@
+import { load } from 'js-yaml';
+
+// Kinds of values faker rules can make up, as in the CLI's masking package
+const FAKERS = [
+  'address', 'city', 'company', 'date', 'email', 'first_name', 'ipv4',
+  'last_name', 'name', 'phone', 'text', 'username', 'uuid'
+];
+
+// maskingPolicyError checks a masking policy the way the CLI parses it
+// and returns what is wrong with it, or null when it is valid. See the
+// CLI's masking package for the policy format.
+export function maskingPolicyError(policy: string): string | null {
+  let doc: any;
+  try {
+    doc = load(policy) ?? {};
+  } catch (error: any) {
+    return `invalid policy: ${error.message}`;
+  }
+  if (typeof doc !== 'object' || Array.isArray(doc)) {
+    return 'invalid policy: want a mapping with seed and columns';
+  }
+  for (const field of Object.keys(doc)) {
+    if (field !== 'seed' && field !== 'columns') {
+      return `invalid policy: field ${field} not found`;
+    }
+  }
+  if (doc.seed != null && typeof doc.seed === 'object') {
+    return 'invalid policy: seed must be a string';
+  }
+  const columns = doc.columns ?? {};
+  if (typeof columns !== 'object' || Array.isArray(columns)) {
+    return 'invalid policy: columns must be a mapping';
+  }
+  if (Object.keys(columns).length === 0) {
+    return 'invalid policy: no columns to mask';
+  }
+
+  const seen = new Set<string>();
+  for (const [name, rule] of Object.entries(columns)) {
+    const column = qualify(name);
+    if (!column) {
+      return `invalid column "${name}": want table.column or schema.table.column`;
+    }
+    if (seen.has(column)) {
+      return `column ${column} is listed twice`;
+    }
+    seen.add(column);
+    const error = ruleError(rule);
+    if (error) {
+      return `column ${name}: ${error}`;
+    }
+  }
+  return null;
+}
+
+// qualify turns table.column and schema.table.column into the latter, or
+// returns null for other names.
+function qualify(name: string): string | null {
+  const parts = name.split('.');
+  if (parts.some(part => part === '')) {
+    return null;
+  }
+  switch (parts.length) {
+    case 2:
+      return `public.${name}`;
+    case 3:
+      return name;
+  }
+  return null;
+}
+
+function isScalar(value: unknown): boolean {
+  return ['string', 'number', 'boolean'].includes(typeof value);
+}
+
+function ruleError(rule: unknown): string | null {
+  if (rule === null || rule === 'null') {
+    return null;
+  }
+  if (rule === 'hash') {
+    return null;
+  }
+  if (isScalar(rule)) {
+    return `unknown rule "${rule}": want hash, null, or a mapping with faker, hash, null or constant`;
+  }
+  if (typeof rule !== 'object' || Array.isArray(rule)) {
+    return 'a rule must be hash, null, or a mapping';
+  }
+
+  let strategies = 0;
+  let faker = '';
+  let hash = false;
+  let length = 0;
+  for (const [key, value] of Object.entries(rule as Record<string, unknown>)) {
+    switch (key) {
+      case 'faker':
+        strategies++;
+        if (!isScalar(value)) {
+          return 'faker must be a string';
+        }
+        faker = String(value);
+        if (!FAKERS.includes(faker)) {
+          return `unknown faker "${faker}" (known: ${FAKERS.join(', ')})`;
+        }
+        break;
+      case 'hash':
+      case 'null':
+        strategies++;
+        if (typeof value !== 'boolean') {
+          return `${key} must be true or false`;
+        }
+        if (!value) {
+          return `${key}: false masks nothing`;
+        }
+        hash = hash || key === 'hash';
+        break;
+      case 'constant':
+        strategies++;
+        if (value === null) {
+          return 'constant: null; use the null rule instead';
+        }
+        if (!isScalar(value)) {
+          return 'constant must be a string';
+        }
+        break;
+      case 'length':
+        if (!Number.isInteger(value)) {
+          return 'length must be a whole number';
+        }
+        length = value as number;
+        if (length < 1) {
+          return 'length must be at least 1';
+        }
+        break;
+      default:
+        return `unknown field "${key}"`;
+    }
+  }
+  if (strategies !== 1) {
+    return 'a rule needs exactly one of faker, hash, null and constant';
+  }
+  if (length > 0 && !faker && !hash) {
+    return 'length only applies to faker and hash';
+  }
+  return null;
+}
@@ -0,0 +1,146 @@
+import { Request, Response, NextFunction } from 'express';
+import crypto from 'crypto';
+import { sendProblem } from './problem.js';
+
+// Paths that are reachable without credentials
+const PUBLIC_PATHS = new Set(['/health']);
+
+const digest = (value: string) => crypto.createHash('sha256').update(value).digest();
+
+// Accepted API tokens, from the comma-separated DEVDB_API_TOKENS variable.
+// Hashing them first lets timingSafeEqual compare values of any length.
+const tokens = (process.env.DEVDB_API_TOKENS || '')
+  .split(',')
+  .map(token => token.trim())
+  .filter(token => token.length > 0)
+  .map(digest);
+
+// Access tokens issued by this OpenID Connect provider are accepted as well.
+// DEVDB_OIDC_AUDIENCE, when set, must appear in the token's aud claim.
+const oidcIssuer = (process.env.DEVDB_OIDC_ISSUER || '').replace(/\/$/, '');
+const oidcAudience = process.env.DEVDB_OIDC_AUDIENCE || '';
+
+if (tokens.length === 0 && !oidcIssuer) {
+  console.warn('DEVDB_API_TOKENS and DEVDB_OIDC_ISSUER are not set, the API accepts unauthenticated requests');
+}
+
+export const authEnabled = () => tokens.length > 0 || oidcIssuer !== '';
+
+// Signing keys of the provider by key ID. They are fetched again when a
+// token names a key that is not cached, so key rotation is picked up, but
+// at most once a minute, so tokens with made-up key IDs cannot make every
+// request hit the provider.
+let signingKeys = new Map<string, crypto.KeyObject>();
+const KEY_REFETCH_INTERVAL_MS = 60 * 1000;
+let signingKeysFetchedAt = 0;
+let signingKeysLoading: Promise<void> | null = null;
+
+const fetchJSON = async (url: string) => {
+  const response = await fetch(url);
+  if (!response.ok) {
+    throw new Error(`${url} returned status code ${response.status}`);
+  }
+  return response.json();
+};
+
+const loadSigningKeys = async () => {
+  const discovery = await fetchJSON(`${oidcIssuer}/.well-known/openid-configuration`);
+  const jwks = await fetchJSON(discovery.jwks_uri);
+  const keys = new Map<string, crypto.KeyObject>();
+  for (const jwk of jwks.keys || []) {
+    if (jwk.use && jwk.use !== 'sig') continue;
+    keys.set(jwk.kid || '', crypto.createPublicKey({ key: jwk, format: 'jwk' }));
+  }
+  signingKeys = keys;
+};
+
+// refreshSigningKeys fetches the keys unless they were fetched less than a
+// minute ago. Concurrent callers share one fetch, and a failed fetch counts
+// too, so an unreachable provider is not retried on every request.
+const refreshSigningKeys = async () => {
+  if (signingKeysLoading) {
+    return signingKeysLoading;
+  }
+  if (Date.now() - signingKeysFetchedAt < KEY_REFETCH_INTERVAL_MS) {
+    return;
+  }
+  signingKeysFetchedAt = Date.now();
+  signingKeysLoading = loadSigningKeys().finally(() => {
+    signingKeysLoading = null;
+  });
+  return signingKeysLoading;
+};
+
+const decodeSegment = (segment: string) => JSON.parse(Buffer.from(segment, 'base64url').toString('utf8'));
+
+// verifyOIDCToken checks the signature (RS256 or ES256) and the iss, exp,
+// nbf and aud claims of a JWT access token.
+const verifyOIDCToken = async (token: string): Promise<boolean> => {
+  const parts = token.split('.');
+  if (parts.length !== 3) return false;
+
+  let header, claims;
+  try {
+    header = decodeSegment(parts[0]);
+    claims = decodeSegment(parts[1]);
+  } catch {
+    return false;
+  }
+
+  let key = signingKeys.get(header.kid || '');
+  if (!key) {
+    await refreshSigningKeys();
+    key = signingKeys.get(header.kid || '');
+  }
+  if (!key) return false;
+
+  const data = Buffer.from(`${parts[0]}.${parts[1]}`);
+  const signature = Buffer.from(parts[2], 'base64url');
+  let valid = false;
+  if (header.alg === 'RS256') {
+    valid = crypto.verify('sha256', data, key, signature);
+  } else if (header.alg === 'ES256') {
+    valid = crypto.verify('sha256', data, { key, dsaEncoding: 'ieee-p1363' }, signature);
+  }
+  if (!valid) return false;
+
+  const now = Math.floor(Date.now() / 1000);
+  const audiences = Array.isArray(claims.aud) ? claims.aud : [claims.aud];
+  return (claims.iss || '').replace(/\/$/, '') === oidcIssuer
+    && typeof claims.exp === 'number' && claims.exp > now
+    && (claims.nbf === undefined || claims.nbf <= now)
+    && (!oidcAudience || audiences.includes(oidcAudience));
+};
+
+// bearerAuth implements the bearerAuth security scheme of the OpenAPI spec.
+// Authenticated requests are marked with res.locals.authenticated.
+export const bearerAuth = async (req: Request, res: Response, next: NextFunction) => {
+  if (!authEnabled() || PUBLIC_PATHS.has(req.path) || req.method === 'OPTIONS') {
+    return next();
+  }
+
+  const header = req.headers.authorization || '';
+  const match = /^Bearer\s+(.+)$/i.exec(header);
+  if (match) {
+    const token = match[1].trim();
+    const presented = digest(token);
+    if (tokens.some(accepted => crypto.timingSafeEqual(accepted, presented))) {
+      res.locals.authenticated = true;
+      return next();
+    }
+
+    if (oidcIssuer) {
+      try {
+        if (await verifyOIDCToken(token)) {
+          res.locals.authenticated = true;
+          return next();
+        }
+      } catch (error) {
+        console.error('Error verifying OIDC token:', error);
+      }
+    }
+  }
+
+  res.setHeader('WWW-Authenticate', 'Bearer realm="devdb"');
+  sendProblem(res, 401, 'Missing or invalid credentials');
+};
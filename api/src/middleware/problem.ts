@@ -0,0 +1,36 @@
+import { Request, Response, NextFunction } from 'express';
+import { STATUS_CODES } from 'http';
+import { components } from '../types/generated/api.js';
+
+type Problem = components['schemas']['Problem'];
+
+// sendProblem answers with an RFC 7807 problem document, the error format
+// declared for all 4xx and 5xx responses in the OpenAPI spec.
+export const sendProblem = (res: Response, status: number, detail?: string) => {
+  const problem: Problem = {
+    type: 'about:blank',
+    title: STATUS_CODES[status] || 'Error',
+    status,
+    ...(detail ? { detail } : {}),
+    instance: res.req.originalUrl,
+  };
+  res.status(status).type('application/problem+json').send(JSON.stringify(problem));
+};
+
+// notFound answers requests for paths the API does not serve.
+export const notFound = (req: Request, res: Response) => {
+  sendProblem(res, 404, `No route for ${req.method} ${req.path}`);
+};
+
+// problemHandler turns errors passed to next(), such as malformed JSON
+// bodies rejected by body-parser, into problem documents.
+export const problemHandler = (error: any, req: Request, res: Response, next: NextFunction) => {
+  if (res.headersSent) {
+    return next(error);
+  }
+  const status = error.status || error.statusCode || 500;
+  if (status >= 500) {
+    console.error(error);
+  }
+  sendProblem(res, status, status < 500 ? error.message : undefined);
+};
@@ -20,6 +20,8 @@ export interface paths {
             "application/json": components["schemas"]["Project"][];
           };
         };
+        401: components["responses"]["Unauthorized"];
+        500: components["responses"]["InternalError"];
       };
     };
     /** Create a new project */
@@ -36,6 +38,10 @@ export interface paths {
             "application/json": components["schemas"]["Project"];
           };
         };
+        400: components["responses"]["BadRequest"];
+        401: components["responses"]["Unauthorized"];
+        409: components["responses"]["Conflict"];
+        500: components["responses"]["InternalError"];
       };
     };
   };
@@ -54,15 +60,44 @@ export interface paths {
             "application/json": components["schemas"]["Project"];
           };
         };
-        /** @description Project not found */
-        404: {
-          content: never;
-        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
       };
     };
     /** Delete a project */
     delete: operations["deleteProject"];
   };
+  "/projects/{projectId}/refresh": {
+    /**
+     * Refresh a project's data
+     * @description Points the project at a new backup, or re-reads the current one, and increases its data version. Databases created or reset afterwards get the new data; existing databases keep theirs.
+     */
+    post: {
+      parameters: {
+        path: {
+          projectId: string;
+        };
+      };
+      requestBody: {
+        content: {
+          "application/json": components["schemas"]["RefreshProjectRequest"];
+        };
+      };
+      responses: {
+        /** @description Data versions before and after the refresh, and the updated project */
+        200: {
+          content: {
+            "application/json": components["schemas"]["DataRefresh"];
+          };
+        };
+        400: components["responses"]["BadRequest"];
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
   "/projects/{projectId}/databases": {
     /** List databases in a project */
     get: {
@@ -78,6 +113,9 @@ export interface paths {
             "application/json": components["schemas"]["Database"][];
           };
         };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
       };
     };
     /** Create a new database for a project */
@@ -99,6 +137,11 @@ export interface paths {
             "application/json": components["schemas"]["Database"];
           };
         };
+        400: components["responses"]["BadRequest"];
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        409: components["responses"]["Conflict"];
+        500: components["responses"]["InternalError"];
       };
     };
   };
@@ -118,10 +161,9 @@ export interface paths {
             "application/json": components["schemas"]["Database"];
           };
         };
-        /** @description Database not found */
-        404: {
-          content: never;
-        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
       };
     };
     /** Delete a database from a project */
@@ -141,6 +183,238 @@ export interface paths {
             };
           };
         };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/projects/{projectId}/databases/{name}/reset": {
+    /**
+     * Reset a database to its project's pristine data
+     * @description Recreates the database in place from the project's latest snapshot or backup. The name and endpoint stay the same; all changes made since the database was created are lost.
+     */
+    post: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+        };
+      };
+      responses: {
+        /** @description Database is being recreated */
+        200: {
+          content: {
+            "application/json": components["schemas"]["Database"];
+          };
+        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/projects/{projectId}/databases/{name}/stop": {
+    /**
+     * Stop a database to save resources
+     * @description Scales the database's workload to zero while keeping its data. A stopped database keeps its name and endpoint and can be started again. Stopping a stopped database has no effect other than clearing its expiry when asked to; a database that is still being created cannot be stopped.
+     */
+    post: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+        };
+      };
+      requestBody?: {
+        content: {
+          "application/json": components["schemas"]["StopDatabaseRequest"];
+        };
+      };
+      responses: {
+        /** @description Database stopped */
+        200: {
+          content: {
+            "application/json": components["schemas"]["Database"];
+          };
+        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        409: components["responses"]["Conflict"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/projects/{projectId}/databases/{name}/start": {
+    /**
+     * Start a stopped database
+     * @description Brings a stopped database back with the data it had when it was stopped. Starting a database that is not stopped has no effect.
+     */
+    post: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+        };
+      };
+      responses: {
+        /** @description Database is starting */
+        200: {
+          content: {
+            "application/json": components["schemas"]["Database"];
+          };
+        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        409: components["responses"]["Conflict"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/projects/{projectId}/databases/{name}/extend": {
+    /**
+     * Extend the time-to-live of a database
+     * @description Pushes the database's expiry back by ttlSeconds, counting from its current expiry or from now, whichever is later. A database without an expiry gets one.
+     */
+    post: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+        };
+      };
+      requestBody: {
+        content: {
+          "application/json": components["schemas"]["ExtendDatabaseRequest"];
+        };
+      };
+      responses: {
+        /** @description Database with its new expiry */
+        200: {
+          content: {
+            "application/json": components["schemas"]["Database"];
+          };
+        };
+        400: components["responses"]["BadRequest"];
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/projects/{projectId}/databases/{name}/snapshots": {
+    /** List the snapshots of a database */
+    get: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+        };
+      };
+      responses: {
+        /** @description Snapshots of the database, oldest first */
+        200: {
+          content: {
+            "application/json": components["schemas"]["Snapshot"][];
+          };
+        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+    /** Take a snapshot of a database */
+    post: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+        };
+      };
+      requestBody: {
+        content: {
+          "application/json": components["schemas"]["CreateSnapshotRequest"];
+        };
+      };
+      responses: {
+        /** @description Snapshot created */
+        201: {
+          content: {
+            "application/json": components["schemas"]["Snapshot"];
+          };
+        };
+        400: components["responses"]["BadRequest"];
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        409: components["responses"]["Conflict"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/projects/{projectId}/databases/{name}/snapshots/{snapshot}": {
+    /** Delete a snapshot */
+    delete: {
+      parameters: {
+        path: {
+          projectId: string;
+          name: string;
+          snapshot: string;
+        };
+      };
+      responses: {
+        /** @description Snapshot deleted */
+        200: {
+          content: {
+            "application/json": {
+              message?: string;
+            };
+          };
+        };
+        401: components["responses"]["Unauthorized"];
+        404: components["responses"]["NotFound"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/backups/uploads": {
+    /**
+     * Start uploading a backup
+     * @description Returns a presigned URL to PUT the backup file to, with exactly sizeBytes bytes, and the location to use as a project's backupLocation once the upload is done.
+     */
+    post: {
+      requestBody: {
+        content: {
+          "application/json": components["schemas"]["CreateBackupUploadRequest"];
+        };
+      };
+      responses: {
+        /** @description Upload URL and backup location */
+        201: {
+          content: {
+            "application/json": components["schemas"]["BackupUpload"];
+          };
+        };
+        400: components["responses"]["BadRequest"];
+        401: components["responses"]["Unauthorized"];
+        500: components["responses"]["InternalError"];
+      };
+    };
+  };
+  "/catalog": {
+    /**
+     * List the database engines and versions projects can use
+     * @description The engines this server runs, with the versions a project's dbVersion must be one of, or a release in one (e.g. 16.2 for 16), and the extensions their databases can enable.
+     */
+    get: {
+      responses: {
+        /** @description Supported engines */
+        200: {
+          content: {
+            "application/json": components["schemas"]["Catalog"];
+          };
+        };
+        401: components["responses"]["Unauthorized"];
+        500: components["responses"]["InternalError"];
       };
     };
   };
@@ -150,8 +424,42 @@ export type webhooks = Record<string, never>;
 
 export interface components {
   schemas: {
-    /** @enum {string} */
-    DatabaseType: "postgres";
+    /** @description Error details as defined by RFC 7807 */
+    Problem: {
+      /**
+       * @description URI reference identifying the problem type
+       * @default about:blank
+       */
+      type?: string;
+      /** @description Short summary of the problem type */
+      title: string;
+      /** @description HTTP status code */
+      status: number;
+      /** @description Explanation specific to this occurrence of the problem */
+      detail?: string;
+      /** @description URI reference identifying this occurrence of the problem */
+      instance?: string;
+    };
+    /**
+     * @description Database engine of a project
+     * @enum {string}
+     */
+    DatabaseType: "postgres" | "mysql" | "mariadb" | "redis" | "mongodb";
+    Catalog: {
+      /** @description Supported engines, in the order they are listed to users */
+      engines: components["schemas"]["CatalogEngine"][];
+    };
+    CatalogEngine: {
+      type: components["schemas"]["DatabaseType"];
+      /** @description Name of the engine, e.g. PostgreSQL */
+      name: string;
+      /** @description Port its databases listen on */
+      defaultPort: number;
+      /** @description Supported release series, newest first */
+      versions: string[];
+      /** @description Extensions databases can enable, e.g. with CREATE EXTENSION */
+      extensions: string[];
+    };
     DatabaseCredentials: {
       username: string;
       password?: string;
@@ -175,22 +483,103 @@ export interface components {
       port?: number;
       username?: string;
       database?: string;
+      /**
+       * Format: date-time
+       * @description When the database was stopped; only set while it is stopped
+       */
+      stoppedAt?: string;
+      /**
+       * Format: date-time
+       * @description When the database is deleted or stopped automatically; databases without one never expire
+       */
+      expiresAt?: string;
+      /** @description Data version of the project the database was created or last reset from; not set for databases copied from a snapshot */
+      dataVersion?: number;
     };
     CreateDatabaseRequest: {
       /** @description Name of the database instance */
       name: string;
+      /** @description Name of a database of the project to copy the data of, instead of restoring the project's backup */
+      fromDatabase?: string;
+      /** @description Name of a snapshot of one of the project's databases to restore; cannot be combined with fromDatabase */
+      fromSnapshot?: string;
+      /**
+       * Format: int64
+       * @description Time-to-live of the database in seconds; it never expires when omitted
+       */
+      ttlSeconds?: number;
+    };
+    ExtendDatabaseRequest: {
+      /**
+       * Format: int64
+       * @description Seconds to add to the database's time-to-live
+       */
+      ttlSeconds: number;
+    };
+    StopDatabaseRequest: {
+      /** @description Also remove the database's expiry, as reapers that stop expired databases do, so it is not stopped again once it is started */
+      clearExpiry?: boolean;
+    };
+    /** @description Point-in-time copy of a database's data */
+    Snapshot: {
+      /** @description Name of the snapshot, unique within the project */
+      name: string;
+      /** @description Name of the database the snapshot was taken of */
+      database: string;
+      project?: string;
+      /** @enum {string} */
+      status: "creating" | "ready" | "error";
+      /** Format: date-time */
+      createdAt: string;
+      /**
+       * Format: int64
+       * @description Size of the snapshot's data, when known
+       */
+      sizeBytes?: number;
+    };
+    CreateSnapshotRequest: {
+      /** @description Name of the snapshot; generated from the database name and the time when omitted */
+      name?: string;
     };
     CreateProjectRequest: {
       /** @description Owner of the project */
       owner: string;
       /** @description Name of the project */
       name: string;
-      /** @description Type of database (only postgres is supported for now) */
+      /** @description Type of database */
       dbType: components["schemas"]["DatabaseType"];
-      /** @description Version of the database (e.g., '15.3' for PostgreSQL) */
+      /** @description Version of the database (e.g., '15.3' for PostgreSQL, '8.4' for MySQL or '7.2' for Redis) */
       dbVersion: string;
       /** @description S3 URL of the backup file (e.g., s3://bucket/path/to/backup.dump) */
       backupLocation?: string;
+      /** @description Masking policy (YAML mapping table.column to faker, hash, null or constant) the project's backups are masked with before upload */
+      maskingPolicy?: string;
+    };
+    /**
+     * @description Format of a backup; custom and directory are pg_dump's -Fc and -Fd (uploaded as a tar archive), plain is SQL from pg_dump or mysqldump, rdb is a Redis snapshot and archive is mongodump --archive output
+     * @enum {string}
+     */
+    BackupFormat: "custom" | "plain" | "directory" | "rdb" | "archive";
+    CreateBackupUploadRequest: {
+      /** @description Name of the backup file, without directories */
+      filename: string;
+      /**
+       * Format: int64
+       * @description Exact size of the file that will be uploaded
+       */
+      sizeBytes: number;
+      format: components["schemas"]["BackupFormat"];
+    };
+    BackupUpload: {
+      /** @description Presigned URL to upload the backup to with a PUT request */
+      uploadUrl: string;
+      /** @description S3 URL of the backup once uploaded, for CreateProjectRequest.backupLocation */
+      location: string;
+      /**
+       * Format: date-time
+       * @description When the upload URL stops working
+       */
+      expiresAt: string;
     };
     Project: {
       id: string;
@@ -198,21 +587,74 @@ export interface components {
       name: string;
       dbType: components["schemas"]["DatabaseType"];
       dbVersion: string;
-      /** @description S3 URL where the database backup (pg_dump output) is stored */
+      /** @description S3 URL where the database backup (pg_dump, mysqldump, RDB or mongodump output) is stored */
       backupLocation: string;
+      /** @description Version of the project's data, 1 when created and increased by every refresh */
+      dataVersion?: number;
+      /**
+       * Format: date-time
+       * @description When the project's data was last refreshed
+       */
+      dataRefreshedAt?: string;
+      /** @description Masking policy the project's backups are masked with before upload; like defaultCredentials, only returned to authenticated clients or by servers that do not require authentication */
+      maskingPolicy?: string;
       databases?: components["schemas"]["Database"][];
       defaultCredentials: components["schemas"]["DefaultDatabaseCredentials"];
     };
+    RefreshProjectRequest: {
+      /** @description S3 URL of the new backup; the project's current backup is read again when omitted */
+      backupLocation?: string;
+      /** @description New masking policy of the project; the current one is kept when omitted */
+      maskingPolicy?: string;
+    };
+    DataRefresh: {
+      /** @description Data version before the refresh */
+      previousDataVersion: number;
+      /** @description Data version after the refresh */
+      dataVersion: number;
+      project: components["schemas"]["Project"];
+    };
     DefaultDatabaseCredentials: {
-      /** @description Default username for database access */
+      /** @description Default username for database access; "default" for Redis */
       username: string;
       /** @description Default password for database access */
       password: string;
-      /** @description Default database name */
+      /** @description Default database name; the logical database number for Redis */
       database: string;
     };
   };
-  responses: never;
+  responses: {
+    /** @description The request is invalid */
+    BadRequest: {
+      content: {
+        "application/json": components["schemas"]["Problem"];
+      };
+    };
+    /** @description Missing or invalid credentials */
+    Unauthorized: {
+      content: {
+        "application/json": components["schemas"]["Problem"];
+      };
+    };
+    /** @description The project, database or snapshot does not exist */
+    NotFound: {
+      content: {
+        "application/json": components["schemas"]["Problem"];
+      };
+    };
+    /** @description A project, database or snapshot with this name already exists, or the operation conflicts with its current state */
+    Conflict: {
+      content: {
+        "application/json": components["schemas"]["Problem"];
+      };
+    };
+    /** @description The server failed to handle the request */
+    InternalError: {
+      content: {
+        "application/json": components["schemas"]["Problem"];
+      };
+    };
+  };
   parameters: never;
   requestBodies: never;
   headers: never;
@@ -237,10 +679,9 @@ export interface operations {
       200: {
         content: never;
       };
-      /** @description Project not found */
-      404: {
-        content: never;
-      };
+      401: components["responses"]["Unauthorized"];
+      404: components["responses"]["NotFound"];
+      500: components["responses"]["InternalError"];
     };
   };
 }
@@ -0,0 +1,4 @@
+// The parts of js-yaml the API uses; the package ships no types of its own.
+declare module 'js-yaml' {
+  export function load(str: string, options?: { filename?: string; json?: boolean }): unknown;
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/spf13/cobra"
+)
+
+var (
+    reapAction string // Action flag for the reap command
+    reapDryRun bool   // Dry-run flag for the reap command
+)
+
+var adminCmd = &cobra.Command{
+    Use:   "admin",
+    Short: "Maintenance commands for DevDB operators",
+}
+
+var adminReapCmd = &cobra.Command{
+    Use:   "reap",
+    Short: "Delete or stop expired databases",
+    Long: `Delete, or with --action stop stop, every database of every project
+whose time-to-live is up. "devdb serve" does this on its own; this command
+is meant to run periodically against other servers, e.g. as a Kubernetes
+CronJob next to the API.
+
+With --action stop, stopping also clears a database's expiry, so it stays
+up when it is started again. Stopped databases are left alone.`,
+    Example: `  # See what would be reaped
+  devdb admin reap --dry-run
+
+  # Stop instead of deleting, keeping the data around
+  devdb admin reap --action stop`,
+    Args: cobra.NoArgs,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if reapAction != "delete" && reapAction != "stop" {
+            return fmt.Errorf("invalid --action %q (want delete or stop)", reapAction)
+        }
+        cmd.SilenceUsage = true
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+        reaped, err := reapExpired(context.Background(), client)
+        if printErr := printResult(cmd, reaped, func() {
+            if len(reaped) == 0 {
+                cmd.Println("No expired databases")
+            }
+            for _, r := range reaped {
+                cmd.Printf("%s database %s of project %s (expired %s ago)\n", r.verb(), r.Database, r.Project, r.Expired)
+            }
+        }); printErr != nil {
+            return printErr
+        }
+        return err
+    },
+}
+
+// reapedDatabase is a database handled by "devdb admin reap".
+type reapedDatabase struct {
+    Project  string `json:"project"`
+    Database string `json:"database"`
+    Expired  string `json:"expired"`
+    Action   string `json:"action"`
+    DryRun   bool   `json:"dryRun,omitempty"`
+}
+
+func (r reapedDatabase) verb() string {
+    switch {
+    case r.DryRun && r.Action == "stop":
+        return "Would stop"
+    case r.DryRun:
+        return "Would delete"
+    case r.Action == "stop":
+        return "Stopped"
+    }
+    return "Deleted"
+}
+
+type reapResult []reapedDatabase
+
+func (r reapResult) Columns(wide bool) []string {
+    return []string{"PROJECT", "DATABASE", "EXPIRED", "ACTION"}
+}
+
+func (r reapResult) Rows(wide bool) [][]string {
+    rows := make([][]string, 0, len(r))
+    for _, d := range r {
+        rows = append(rows, []string{d.Project, d.Database, d.Expired + " ago", d.verb()})
+    }
+    return rows
+}
+
+// reapExpired deletes or stops the expired databases of all projects. It
+// keeps going past databases it fails to reap and returns the first
+// error along with what it did reap.
+func reapExpired(ctx context.Context, client api.ClientWithResponsesInterface) (reapResult, error) {
+    projects, err := client.GetProjectsWithResponse(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("listing projects: %w", err)
+    }
+    if projects.StatusCode() != http.StatusOK {
+        return nil, api.NewError(projects.HTTPResponse, projects.Body)
+    }
+
+    reaped := reapResult{}
+    var firstErr error
+    for _, p := range *projects.JSON200 {
+        databases, err := client.GetProjectsProjectIdDatabasesWithResponse(ctx, p.Id)
+        if err != nil {
+            return reaped, fmt.Errorf("listing databases of %s: %w", p.Name, err)
+        }
+        if databases.StatusCode() != http.StatusOK {
+            return reaped, api.NewError(databases.HTTPResponse, databases.Body)
+        }
+        for _, db := range *databases.JSON200 {
+            if db.ExpiresAt == nil || db.ExpiresAt.After(now()) {
+                continue
+            }
+            if reapAction == "stop" && db.Status == api.Stopped {
+                continue
+            }
+            r := reapedDatabase{
+                Project:  p.Name,
+                Database: db.Name,
+                Expired:  humanDuration(now().Sub(*db.ExpiresAt)),
+                Action:   reapAction,
+                DryRun:   reapDryRun,
+            }
+            if !reapDryRun {
+                if err := reapDatabase(ctx, client, p.Id, db.Name); err != nil {
+                    if firstErr == nil {
+                        firstErr = fmt.Errorf("reaping database %s of %s: %w", db.Name, p.Name, err)
+                    }
+                    continue
+                }
+            }
+            reaped = append(reaped, r)
+        }
+    }
+    return reaped, firstErr
+}
+
+func reapDatabase(ctx context.Context, client api.ClientWithResponsesInterface, projectID, name string) error {
+    if reapAction == "stop" {
+        // Like the reaper of "devdb serve", clear the expiry so the database
+        // isn't stopped again as soon as someone starts it
+        clearExpiry := true
+        resp, err := client.PostProjectsProjectIdDatabasesNameStopWithResponse(ctx, projectID, name, api.StopDatabaseRequest{ClearExpiry: &clearExpiry})
+        if err != nil {
+            return err
+        }
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+        return nil
+    }
+    resp, err := client.DeleteProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, name)
+    if err != nil {
+        return err
+    }
+    if resp.StatusCode() != http.StatusOK && !api.IsNotFound(api.NewError(resp.HTTPResponse, resp.Body)) {
+        return api.NewError(resp.HTTPResponse, resp.Body)
+    }
+    return nil
+}
+
+func init() {
+    rootCmd.AddCommand(adminCmd)
+    adminCmd.AddCommand(adminReapCmd)
+
+    adminReapCmd.Flags().StringVar(&reapAction, "action", "delete", "What to do with expired databases: delete or stop")
+    adminReapCmd.Flags().BoolVar(&reapDryRun, "dry-run", false, "Only show what would be reaped")
+}
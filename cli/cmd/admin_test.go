@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/server"
+)
+
+func TestDatabaseExpiry(t *testing.T) {
+	isolateProjectSelection(t)
+
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	originalNow := now
+	defer func() { now = originalNow }()
+	now = func() time.Time { return clock }
+
+	srv := server.New(server.NewMemoryStore(), server.NoopProvisioner{}, server.Options{Now: now})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	originalURL := apiURL
+	defer func() { apiURL = originalURL }()
+	apiURL = ts.URL
+
+	executeCommand(t, cmdTestCase{
+		name: "create project",
+		cmd:  projectCreateCmd,
+		args: []string{"billing", "--type", "postgres", "--version", "16"},
+	})
+
+	tests := []cmdTestCase{
+		{
+			name: "create with ttl",
+			cmd:  dbCreateCmd,
+			args: []string{"short", "--project", "billing", "--ttl", "1h"},
+		},
+		{
+			name: "create with long ttl",
+			cmd:  dbCreateCmd,
+			args: []string{"long", "--project", "billing", "--ttl", "72h"},
+		},
+		{
+			name: "create without ttl",
+			cmd:  dbCreateCmd,
+			args: []string{"forever", "--project", "billing"},
+		},
+		{
+			name:    "ttl too short",
+			cmd:     dbCreateCmd,
+			args:    []string{"tiny", "--project", "billing", "--ttl", "10s"},
+			wantErr: true,
+		},
+		{
+			name:    "extend requires --ttl",
+			cmd:     dbExtendCmd,
+			args:    []string{"short", "--project", "billing"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+
+	out := executeCommand(t, cmdTestCase{
+		name: "list shows expiry",
+		cmd:  dbListCmd,
+		args: []string{"--project", "billing"},
+	})
+	for _, want := range []string{"- short (Status: running, expires in 1h)", "- long (Status: running, expires in 3d)", "- forever (Status: running)\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list output %q does not contain %q", out, want)
+		}
+	}
+
+	out = executeCommand(t, cmdTestCase{
+		name: "show shows expiry",
+		cmd:  dbShowCmd,
+		args: []string{"short", "--project", "billing"},
+	})
+	if !strings.Contains(out, "  Expires: ") || !strings.Contains(out, "(in 1h)\n") {
+		t.Errorf("show output %q does not contain the expiry", out)
+	}
+
+	out = executeCommand(t, cmdTestCase{
+		name: "extend",
+		cmd:  dbExtendCmd,
+		args: []string{"short", "--project", "billing", "--ttl", "2h"},
+	})
+	if !strings.HasPrefix(out, "Database short now expires at ") || !strings.HasSuffix(out, "(in 3h)\n") {
+		t.Errorf("unexpected extend output %q", out)
+	}
+
+	clock = clock.Add(4 * time.Hour)
+	executeCommand(t, cmdTestCase{
+		name:       "dry run",
+		cmd:        adminReapCmd,
+		args:       []string{"--dry-run"},
+		wantOutput: "Would delete database short of project billing (expired 1h ago)\n",
+	})
+	executeCommand(t, cmdTestCase{
+		name:       "reap",
+		cmd:        adminReapCmd,
+		wantOutput: "Deleted database short of project billing (expired 1h ago)\n",
+	})
+
+	clock = clock.Add(72 * time.Hour)
+	executeCommand(t, cmdTestCase{
+		name:       "reap by stopping",
+		cmd:        adminReapCmd,
+		args:       []string{"--action", "stop"},
+		wantOutput: "Stopped database long of project billing (expired 4h ago)\n",
+	})
+	// Stopping clears the expiry, so a database started again stays up
+	executeCommand(t, cmdTestCase{
+		name: "start a reaped database",
+		cmd:  dbStartCmd,
+		args: []string{"long", "--project", "billing"},
+	})
+	executeCommand(t, cmdTestCase{
+		name:       "started databases are not reaped again",
+		cmd:        adminReapCmd,
+		args:       []string{"--action", "stop"},
+		wantOutput: "No expired databases\n",
+	})
+	executeCommand(t, cmdTestCase{
+		name:    "invalid action",
+		cmd:     adminReapCmd,
+		args:    []string{"--action", "archive"},
+		wantErr: true,
+	})
+
+	out = executeCommand(t, cmdTestCase{
+		name: "list after reaping",
+		cmd:  dbListCmd,
+		args: []string{"--project", "billing"},
+	})
+	if strings.Contains(out, "short") || !strings.Contains(out, "- long (Status: running)\n") {
+		t.Errorf("unexpected list output %q", out)
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+    "fmt"
+    "io"
+    "os"
+
+    "github.com/meido-ai/devdb/cli/pkg/pgdump"
+    "github.com/meido-ai/devdb/cli/pkg/subset"
+    "github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+    Use:   "backup",
+    Short: "Work with local backups",
+    Long:  `Prepare local pg_dump backups before they are used to create a project.`,
+}
+
+var (
+    backupSubsetSpec string
+    backupSubsetFile string
+)
+
+var backupSubsetCmd = &cobra.Command{
+    Use:   "subset [dump]",
+    Short: "Cut a smaller, referentially intact slice of a backup",
+    Long: `Cut a subset out of a plain-format or directory-format pg_dump backup and
+write it as a plain-format dump, to stdout or to --file. What is kept is
+given by a spec file:
+
+  roots:
+    users: {where: "country = 'NL'", percent: 10}
+  keep: [countries]
+  children: true
+  seed: any-text
+
+Rows are picked from the root tables with where, a SQL condition on their
+columns, and percent, a sample that is the same every time for a seed.
+Tables in keep are copied whole. The foreign keys in the dump are then
+followed: the rows kept rows reference are added so that the subset
+restores with its constraints and, unless children is false, so are the
+rows referencing the roots, such as the orders of the users picked.
+Other tables are dumped without rows.
+
+The subset is read several times and not kept in memory, so it can be
+cut from dumps much larger than memory. Pass the result to project create
+with --backup.`,
+    Example: `  devdb backup subset prod.sql --spec subset.yaml -f dev.sql
+  devdb backup subset prod-dir/ --spec subset.yaml > dev.sql
+  devdb project create billing --type postgres --version 16 --backup dev.sql`,
+    Args:         cobra.ExactArgs(1),
+    SilenceUsage: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        spec, err := subset.Load(backupSubsetSpec)
+        if err != nil {
+            return err
+        }
+
+        path := args[0]
+        open := func() (io.ReadCloser, error) { return pgdump.Open(path) }
+
+        if backupSubsetFile == "" {
+            result, err := spec.Subset(open, cmd.OutOrStdout())
+            if err != nil {
+                return fmt.Errorf("%s: %w", path, err)
+            }
+            printSubsetResult(cmd.ErrOrStderr(), result)
+            return nil
+        }
+
+        out, err := os.OpenFile(backupSubsetFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+        if err != nil {
+            return err
+        }
+        result, err := spec.Subset(open, out)
+        if closeErr := out.Close(); err == nil {
+            err = closeErr
+        }
+        if err != nil {
+            os.Remove(backupSubsetFile)
+            return fmt.Errorf("%s: %w", path, err)
+        }
+        printSubsetResult(cmd.ErrOrStderr(), result)
+        fmt.Fprintf(cmd.ErrOrStderr(), "Wrote the subset of %s to %s\n", path, backupSubsetFile)
+        return nil
+    },
+}
+
+// printSubsetResult prints how many rows of each table a subset kept. It
+// goes to stderr, as the subset itself may be written to stdout.
+func printSubsetResult(w io.Writer, result *subset.Result) {
+    for _, t := range result.Tables {
+        fmt.Fprintf(w, "%s: kept %d of %d rows\n", t.Table, t.Kept, t.Rows)
+    }
+}
+
+func init() {
+    rootCmd.AddCommand(backupCmd)
+    backupCmd.AddCommand(backupSubsetCmd)
+
+    backupSubsetCmd.Flags().StringVar(&backupSubsetSpec, "spec", "", "Subset spec file")
+    backupSubsetCmd.MarkFlagRequired("spec")
+    backupSubsetCmd.Flags().StringVarP(&backupSubsetFile, "file", "f", "", "Write the subset to this file instead of stdout")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSubsetDump = `--
+-- PostgreSQL database dump
+--
+
+COPY public.users (id, country) FROM stdin;
+1	NL
+2	DE
+\.
+
+COPY public.orders (id, user_id) FROM stdin;
+10	1
+11	2
+\.
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);
+`
+
+func TestBackupSubset(t *testing.T) {
+	dir := t.TempDir()
+	dump := writeTestFile(t, dir, "prod.sql", testSubsetDump)
+	spec := writeTestFile(t, dir, "subset.yaml", "roots:\n  users: {where: \"country = 'NL'\"}\n")
+	badSpec := writeTestFile(t, dir, "bad.yaml", "roots:\n  users: {percent: 200}\n")
+	missingSpec := writeTestFile(t, dir, "missing.yaml", "roots:\n  accounts:\n")
+	out := filepath.Join(dir, "dev.sql")
+
+	executeCommand(t, cmdTestCase{
+		name: "to a file",
+		cmd:  backupSubsetCmd,
+		args: []string{dump, "--spec", spec, "-f", out},
+		wantOutput: `public.users: kept 1 of 2 rows
+public.orders: kept 1 of 2 rows
+Wrote the subset of ` + dump + ` to ` + out + `
+`,
+	})
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, "\n1\tNL\n\\.\n") || !strings.Contains(got, "\n10\t1\n\\.\n") || strings.Contains(got, "DE") {
+		t.Errorf("subset =\n%s", got)
+	}
+
+	// The subset is a plain SQL dump, which the server restores with psql
+	fake, _ := newFakeAPI(t)
+	created := executeCommand(t, cmdTestCase{
+		name: "project from the subset",
+		cmd:  projectCreateCmd,
+		args: []string{"billing", "--type", "postgres", "--version", "16", "--backup", out},
+	})
+	if !strings.Contains(created, "Uploading dev.sql (") || !strings.Contains(created, ", plain format)\n") {
+		t.Errorf("output does not report a plain-format upload:\n%s", created)
+	}
+	if uploaded, ok := fake.Upload(fakeProject(t, fake, "billing").BackupLocation); !ok || string(uploaded) != string(data) {
+		t.Errorf("uploaded backup (found %v):\n%s", ok, uploaded)
+	}
+
+	output := executeCommand(t, cmdTestCase{
+		name: "to stdout",
+		cmd:  backupSubsetCmd,
+		args: []string{dump, "--spec", spec},
+	})
+	if !strings.HasPrefix(output, string(data)) {
+		t.Errorf("output = %q, want the subset first", output)
+	}
+
+	os.Remove(out)
+	tests := []cmdTestCase{
+		{name: "no spec", cmd: backupSubsetCmd, args: []string{dump}, wantErr: true},
+		{name: "invalid spec", cmd: backupSubsetCmd, args: []string{dump, "--spec", badSpec}, wantErr: true},
+		{name: "table not in the dump", cmd: backupSubsetCmd, args: []string{dump, "--spec", missingSpec, "-f", out}, wantErr: true},
+		{name: "no dump", cmd: backupSubsetCmd, args: []string{filepath.Join(dir, "none.sql"), "--spec", spec}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("a failed subset left %s behind", out)
+	}
+}
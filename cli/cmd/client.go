@@ -0,0 +1,186 @@
+package cmd
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/config"
+)
+
+// tokenEnvVar, when set, is used as the API token instead of the one
+// stored for the context. It is meant for CI.
+const tokenEnvVar = "DEVDB_TOKEN"
+
+// newAPIClient creates an API client for apiURL using the TLS settings and
+// credentials of the active context.
+func newAPIClient() (*api.ClientWithResponses, error) {
+    if contextErr != nil {
+        return nil, contextErr
+    }
+    token, err := currentToken()
+    if err != nil {
+        return nil, err
+    }
+    return newClientWithToken(token, true)
+}
+
+// newClientWithToken creates an API client that sends token. With
+// relogin set, a rejected token makes the client ask for a new one when
+// there is a terminal to ask on.
+func newClientWithToken(token string, relogin bool) (*api.ClientWithResponses, error) {
+    var settings config.TLS
+    if activeContext != nil {
+        settings = activeContext.TLS
+    }
+    httpClient, err := httpClientFor(settings)
+    if err != nil {
+        return nil, err
+    }
+
+    auth := &authenticator{token: token, doer: httpClient, relogin: relogin}
+    return api.NewClientWithResponses(apiURL,
+        api.WithHTTPClient(auth),
+        api.WithRequestEditorFn(auth.editRequest))
+}
+
+// currentToken returns the token for the active context, or an empty
+// string when none is stored.
+func currentToken() (string, error) {
+    if token := os.Getenv(tokenEnvVar); token != "" {
+        return token, nil
+    }
+    if activeContext == nil {
+        return "", nil
+    }
+    token, err := loadToken(activeContextName)
+    if err != nil || token == "" || activeContext.OIDC == nil {
+        return token, err
+    }
+    return oidcAccessToken(activeContextName, activeContext.OIDC, token)
+}
+
+// authenticator attaches the bearer token to requests and handles a 401
+// response by logging in again and retrying once.
+type authenticator struct {
+    token   string
+    doer    api.HttpRequestDoer
+    relogin bool
+}
+
+func (a *authenticator) editRequest(ctx context.Context, req *http.Request) error {
+    if a.token != "" {
+        req.Header.Set("Authorization", "Bearer "+a.token)
+    }
+    return nil
+}
+
+func (a *authenticator) Do(req *http.Request) (*http.Response, error) {
+    // Keep the body so the request can be sent again
+    var body []byte
+    if req.Body != nil {
+        var err error
+        if body, err = io.ReadAll(req.Body); err != nil {
+            return nil, err
+        }
+        req.Body.Close()
+        req.Body = io.NopCloser(bytes.NewReader(body))
+    }
+
+    resp, err := a.doer.Do(req)
+    if err != nil || resp.StatusCode != http.StatusUnauthorized {
+        return resp, err
+    }
+    resp.Body.Close()
+
+    if !a.relogin || os.Getenv(tokenEnvVar) != "" {
+        return nil, errLoginRequired()
+    }
+    fmt.Fprintf(os.Stderr, "The DevDB API at %s rejected the credentials.\n", apiURL)
+    token, err := reauthenticate(req.Context())
+    if err != nil || token == "" {
+        return nil, errLoginRequired()
+    }
+
+    retry := req.Clone(req.Context())
+    retry.Body = io.NopCloser(bytes.NewReader(body))
+    retry.Header.Set("Authorization", "Bearer "+token)
+    resp, err = a.doer.Do(retry)
+    if err != nil || resp.StatusCode == http.StatusUnauthorized {
+        return resp, err
+    }
+
+    a.token = token
+    if !usesOIDC() {
+        if _, err := storeToken(token); err != nil {
+            fmt.Fprintf(os.Stderr, "Warning: could not save the token: %v\n", err)
+        }
+    }
+    return resp, nil
+}
+
+func usesOIDC() bool {
+    return activeContext != nil && activeContext.OIDC != nil
+}
+
+// reauthenticate asks for new credentials: through the identity provider
+// for OIDC contexts, which also stores the new token, or by prompting for
+// an API token otherwise.
+func reauthenticate(ctx context.Context) (string, error) {
+    if !usesOIDC() {
+        return readSecret("API token (empty to cancel): ")
+    }
+    if !isTerminal(os.Stderr) {
+        return "", errNoTerminal
+    }
+    token, err := oidcLogin(ctx, os.Stderr, activeContextName, activeContext.OIDC)
+    if err != nil {
+        return "", err
+    }
+    return token.AccessToken, nil
+}
+
+// loginRequiredError is returned when the API rejects the credentials.
+type loginRequiredError struct {
+    context string
+}
+
+func (e *loginRequiredError) Error() string {
+    if e.context != "" {
+        return fmt.Sprintf("authentication required: run \"devdb login --context %s\"", e.context)
+    }
+    return "authentication required: run \"devdb login\""
+}
+
+func errLoginRequired() error {
+    return &loginRequiredError{context: activeContextName}
+}
+
+func httpClientFor(settings config.TLS) (*http.Client, error) {
+    if settings.CAFile == "" && !settings.InsecureSkipVerify {
+        return http.DefaultClient, nil
+    }
+
+    tlsConfig := &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+    if settings.CAFile != "" {
+        pem, err := os.ReadFile(settings.CAFile)
+        if err != nil {
+            return nil, fmt.Errorf("reading CA file: %v", err)
+        }
+        pool := x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(pem) {
+            return nil, fmt.Errorf("no certificates found in %s", settings.CAFile)
+        }
+        tlsConfig.RootCAs = pool
+    }
+
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.TLSClientConfig = tlsConfig
+    return &http.Client{Transport: transport}, nil
+}
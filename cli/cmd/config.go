@@ -1,9 +1,8 @@
 package cmd
 
 import (
-    "fmt"
+    "github.com/meido-ai/devdb/cli/pkg/config"
     "github.com/spf13/cobra"
-    "github.com/spf13/viper"
 )
 
 var configCmd = &cobra.Command{
@@ -15,31 +14,90 @@ var configCmd = &cobra.Command{
 var configSetAPICmd = &cobra.Command{
     Use:   "set-api [url]",
     Short: "Set the DevDB API URL",
-    Long:  `Set the URL for the DevDB API that the CLI will connect to.`,
+    Long: `Set the URL for the DevDB API that the CLI will connect to. The URL is
+stored in the current context; a context named "default" is created if
+there is none.`,
     Args:  cobra.ExactArgs(1),
-    Run: func(cmd *cobra.Command, args []string) {
-        apiURL := args[0]
-        viper.Set("api.url", apiURL)
-        err := viper.WriteConfig()
+    RunE: func(cmd *cobra.Command, args []string) error {
+        url := args[0]
+        name, err := updateCurrentContext(func(ctx *config.Context) { ctx.APIURL = url })
         if err != nil {
-            // If config file doesn't exist, create it
-            err = viper.SafeWriteConfig()
-            if err != nil {
-                fmt.Printf("Error writing config: %v\n", err)
-                return
-            }
+            return err
         }
-        fmt.Printf("API URL set to: %s\n", apiURL)
+        cmd.Printf("API URL set to: %s (context %s)\n", url, name)
+        return nil
     },
 }
 
+// updateCurrentContext applies fn to the context selected with --context or
+// to the current context. When no context exists yet, a context named
+// "default" for the API URL in use is created and made current.
+func updateCurrentContext(fn func(ctx *config.Context)) (string, error) {
+    var name string
+    err := updateConfig(func(cfg *config.Config) error {
+        var ctx *config.Context
+        var err error
+        name, ctx, err = cfg.Current(contextName)
+        if err == config.ErrNoContext {
+            name = config.DefaultContextName
+            if ctx = cfg.Contexts[name]; ctx == nil {
+                ctx = &config.Context{APIURL: apiURL}
+                cfg.Contexts[name] = ctx
+            }
+            cfg.CurrentContext = name
+        } else if err != nil {
+            return err
+        }
+        fn(ctx)
+        return nil
+    })
+    return name, err
+}
+
 var configViewCmd = &cobra.Command{
     Use:   "view",
     Short: "View current configuration",
     Long:  `Display all current configuration settings.`,
-    Run: func(cmd *cobra.Command, args []string) {
-        fmt.Println("Current Configuration:")
-        fmt.Printf("API URL: %s\n", viper.GetString("api.url"))
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if contextErr != nil {
+            return contextErr
+        }
+        path, err := configPath()
+        if err != nil {
+            return err
+        }
+
+        cmd.Println("Current Configuration:")
+        cmd.Printf("Config File: %s\n", path)
+        if activeContext != nil {
+            cmd.Printf("Context: %s\n", activeContextName)
+        } else {
+            cmd.Printf("Context: %s\n", none)
+        }
+        cmd.Printf("API URL: %s\n", apiURL)
+
+        // --project only exists on database commands
+        sources, err := projectSources("")
+        if err != nil {
+            return err
+        }
+        selected, from := none, ""
+        for _, s := range sources {
+            if s.Value != "" {
+                selected, from = s.Value, " (from "+s.Name+")"
+                break
+            }
+        }
+        cmd.Printf("Project: %s%s\n", selected, from)
+        cmd.Println("Project Precedence:")
+        for i, s := range sources {
+            value := s.Value
+            if value == "" {
+                value = none
+            }
+            cmd.Printf("  %d. %s: %s\n", i+1, s.Name, value)
+        }
+        return nil
     },
 }
 
@@ -47,4 +105,4 @@ func init() {
     rootCmd.AddCommand(configCmd)
     configCmd.AddCommand(configSetAPICmd)
     configCmd.AddCommand(configViewCmd)
-}
\ No newline at end of file
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+
+    "github.com/spf13/cobra"
+)
+
+// errAborted is returned by commands whose confirmation prompt was
+// declined.
+var errAborted = errors.New("aborted")
+
+// confirm asks a yes/no question on stderr and reads the answer from
+// stdin. Anything but y or yes declines. Without an answer, e.g. when
+// stdin is not a terminal, it fails and points at the flag that skips
+// the prompt.
+func confirm(cmd *cobra.Command, question, skipFlag string) (bool, error) {
+    fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N]: ", question)
+    answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+    if err != nil && (err != io.EOF || answer == "") {
+        fmt.Fprintln(cmd.ErrOrStderr())
+        return false, fmt.Errorf("no answer to the confirmation prompt; use %s to skip it", skipFlag)
+    }
+    switch strings.ToLower(strings.TrimSpace(answer)) {
+    case "y", "yes":
+        return true, nil
+    }
+    return false, nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/dbconn"
+    "github.com/meido-ai/devdb/cli/pkg/engine"
+    "github.com/spf13/cobra"
+    "github.com/spf13/viper"
+)
+
+var connectClient string // Client flag for the connect command
+
+// runClient starts the database client; tests replace it to inspect the
+// command instead of running it.
+var runClient = func(c *exec.Cmd) error {
+    return c.Run()
+}
+
+var dbConnectCmd = &cobra.Command{
+    Use:   "connect [name] [-- client-args...]",
+    Short: "Open a database client connected to a database",
+    Long: `Open a client connected to a database: psql for PostgreSQL, mysql for
+MySQL, mariadb for MariaDB, redis-cli for Redis and mongosh for MongoDB
+databases. Another client can be configured with connect.client in the
+config file (e.g. pgcli or usql) and, for the other engines, with
+connect.<type>.client, e.g. connect.mysql.client. The password never
+appears on the command line: it is passed through PG*, MYSQL_* or
+REDISCLI_AUTH environment variables, and mongosh asks for it. Arguments
+after -- are passed to the client.`,
+    Args: cobra.MinimumNArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        ctx := context.Background()
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        info, err := fetchConnInfo(ctx, client, project, name)
+        if err != nil {
+            return err
+        }
+
+        dbClient := connectClient
+        if dbClient == "" {
+            dbClient = configuredClient(info.Type)
+        }
+
+        clientArgs, err := dbconn.ClientArgs(dbClient, info, args[1:])
+        if err != nil {
+            return err
+        }
+
+        // Usage errors are behind us from here on
+        cmd.SilenceUsage = true
+
+        c := exec.Command(clientArgs[0], clientArgs[1:]...)
+        c.Env = append(os.Environ(), info.Env()...)
+        c.Stdin = os.Stdin
+        c.Stdout = cmd.OutOrStdout()
+        c.Stderr = cmd.ErrOrStderr()
+        if err := runClient(c); err != nil {
+            if exitErr, ok := err.(*exec.ExitError); ok {
+                return fmt.Errorf("%s exited with status %d", clientArgs[0], exitErr.ExitCode())
+            }
+            return fmt.Errorf("running %s: %v", clientArgs[0], err)
+        }
+        return nil
+    },
+}
+
+// configuredClient returns the client to connect to databases of type t
+// with: connect.<type>.client from the config file, connect.client for
+// postgres, or the engine's own client.
+func configuredClient(t api.DatabaseType) string {
+    if t == "" {
+        t = api.Postgres
+    }
+    if client := viper.GetString("connect." + string(t) + ".client"); client != "" {
+        return client
+    }
+    if client := viper.GetString("connect.client"); client != "" && t == api.Postgres {
+        return client
+    }
+    if e, ok := engine.Lookup(t); ok {
+        return e.Client
+    }
+    return "psql"
+}
+
+// fetchConnInfo looks up a database and its project's default credentials.
+func fetchConnInfo(ctx context.Context, client *api.ClientWithResponses, projectID, name string) (dbconn.Info, error) {
+    dbResp, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, name)
+    if err != nil {
+        return dbconn.Info{}, fmt.Errorf("getting database: %w", err)
+    }
+    if dbResp.StatusCode() != 200 || dbResp.JSON200 == nil {
+        return dbconn.Info{}, api.NewError(dbResp.HTTPResponse, dbResp.Body)
+    }
+
+    projectResp, err := client.GetProjectsProjectIdWithResponse(ctx, projectID)
+    if err != nil {
+        return dbconn.Info{}, fmt.Errorf("getting project: %w", err)
+    }
+    if projectResp.StatusCode() != 200 || projectResp.JSON200 == nil {
+        return dbconn.Info{}, api.NewError(projectResp.HTTPResponse, projectResp.Body)
+    }
+
+    return dbconn.FromAPI(*dbResp.JSON200, *projectResp.JSON200)
+}
+
+func init() {
+    dbCmd.AddCommand(dbConnectCmd)
+
+    dbConnectCmd.Flags().StringVar(&connectClient, "client", "", "Client to launch, e.g. psql, pgcli, mysql, redis-cli or usql (default from the config file, else the engine's own client)")
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/spf13/viper"
+)
+
+// newConnTestAPI points the CLI at a fake API with a project "app" that
+// has credentials, a running database "testdb" and a database "pending"
+// that has no endpoint yet.
+func newConnTestAPI(t *testing.T) {
+	t.Helper()
+	fake, testProject := newFakeAPI(t)
+	project := fake.AddProject(api.Project{Id: "app", Owner: testProject.Owner, Name: "app", DbVersion: "15.3",
+		DefaultCredentials: api.DefaultDatabaseCredentials{Username: "devdb", Password: "s3cret", Database: "app"}})
+	host, port := "db.example.com", 5432
+	fake.AddDatabase(project.Id, api.Database{Name: "testdb", Host: &host, Port: &port})
+	fake.AddDatabase(project.Id, api.Database{Name: "pending", Status: api.Creating})
+	fake.StickCreating("pending")
+}
+
+func TestDatabaseConnect(t *testing.T) {
+	newConnTestAPI(t)
+
+	var ran *exec.Cmd
+	originalRun := runClient
+	defer func() { runClient = originalRun }()
+	runClient = func(c *exec.Cmd) error {
+		ran = c
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		client   string
+		wantErr  bool
+		wantArgs []string
+	}{
+		{
+			name:     "psql by default",
+			args:     []string{"testdb", "--project", "app"},
+			wantArgs: []string{"psql"},
+		},
+		{
+			name:     "client from config with extra args",
+			args:     []string{"testdb", "--project", "app", "--", "-c", "select 1"},
+			client:   "pgcli --less-chatty",
+			wantArgs: []string{"pgcli", "--less-chatty", "-c", "select 1"},
+		},
+		{
+			name:     "usql gets a URL without the password",
+			args:     []string{"testdb", "--project", "app", "--client", "usql"},
+			wantArgs: []string{"usql", "postgres://devdb@db.example.com:5432/app"},
+		},
+		{
+			name:    "database without endpoint",
+			args:    []string{"pending", "--project", "app"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ran = nil
+			viper.Set("connect.client", tc.client)
+			defer viper.Set("connect.client", "")
+
+			executeCommand(t, cmdTestCase{
+				name:    tc.name,
+				cmd:     dbConnectCmd,
+				args:    tc.args,
+				wantErr: tc.wantErr,
+			})
+			if tc.wantErr {
+				return
+			}
+			if ran == nil {
+				t.Fatal("client was not started")
+			}
+			if strings.Join(ran.Args, " ") != strings.Join(tc.wantArgs, " ") {
+				t.Errorf("args = %q, want %q", ran.Args, tc.wantArgs)
+			}
+			for _, arg := range ran.Args {
+				if strings.Contains(arg, "s3cret") {
+					t.Errorf("password leaked into argv: %q", ran.Args)
+				}
+			}
+			env := strings.Join(ran.Env, "\n")
+			for _, want := range []string{"PGHOST=db.example.com", "PGPORT=5432", "PGUSER=devdb", "PGDATABASE=app", "PGPASSWORD=s3cret"} {
+				if !strings.Contains(env, want) {
+					t.Errorf("environment missing %s", want)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabaseConnectMySQL(t *testing.T) {
+	fake, _ := newFakeAPI(t)
+
+	project := fake.AddProject(api.Project{Owner: "testuser", Name: "shop", DbType: api.MySQL, DbVersion: "8.4",
+		DefaultCredentials: api.DefaultDatabaseCredentials{Username: "devdb", Password: "s3cret", Database: "shop"}})
+	host, port := "localhost", 3306
+	fake.AddDatabase(project.Id, api.Database{Name: "testdb", Host: &host, Port: &port})
+
+	var ran *exec.Cmd
+	originalRun := runClient
+	defer func() { runClient = originalRun }()
+	runClient = func(c *exec.Cmd) error {
+		ran = c
+		return nil
+	}
+
+	// connect.client is for postgres; MySQL has its own setting
+	viper.Set("connect.client", "pgcli")
+	defer viper.Set("connect.client", "")
+	executeCommand(t, cmdTestCase{name: "mysql by default", cmd: dbConnectCmd, args: []string{"testdb", "--project", project.Id}})
+	if ran == nil {
+		t.Fatal("client was not started")
+	}
+	if want := "mysql --protocol=TCP --user=devdb --database=shop"; strings.Join(ran.Args, " ") != want {
+		t.Errorf("args = %q, want %q", ran.Args, want)
+	}
+	env := strings.Join(ran.Env, "\n")
+	for _, want := range []string{"MYSQL_HOST=localhost", "MYSQL_TCP_PORT=3306", "MYSQL_PWD=s3cret"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("environment missing %s", want)
+		}
+	}
+
+	viper.Set("connect.mysql.client", "mycli")
+	defer viper.Set("connect.mysql.client", "")
+	executeCommand(t, cmdTestCase{name: "configured client", cmd: dbConnectCmd, args: []string{"testdb", "--project", project.Id}})
+	if ran.Args[0] != "mycli" {
+		t.Errorf("args = %q, want mycli", ran.Args)
+	}
+
+	executeCommand(t, cmdTestCase{name: "psql", cmd: dbConnectCmd, args: []string{"testdb", "--project", project.Id, "--client", "psql"}, wantErr: true})
+}
+
+func TestDatabaseConnectMongoDB(t *testing.T) {
+	fake, _ := newFakeAPI(t)
+
+	project := fake.AddProject(api.Project{Owner: "testuser", Name: "catalog", DbType: api.MongoDB, DbVersion: "7.0",
+		DefaultCredentials: api.DefaultDatabaseCredentials{Username: "devdb", Password: "s3cret", Database: "catalog"}})
+	host, port := "localhost", 27017
+	fake.AddDatabase(project.Id, api.Database{Name: "testdb", Host: &host, Port: &port})
+
+	var ran *exec.Cmd
+	originalRun := runClient
+	defer func() { runClient = originalRun }()
+	runClient = func(c *exec.Cmd) error {
+		ran = c
+		return nil
+	}
+
+	executeCommand(t, cmdTestCase{name: "mongosh by default", cmd: dbConnectCmd, args: []string{"testdb", "--project", project.Id, "--", "--quiet"}})
+	if ran == nil {
+		t.Fatal("client was not started")
+	}
+	if want := "mongosh mongodb://devdb@localhost:27017/catalog?authSource=admin --quiet"; strings.Join(ran.Args, " ") != want {
+		t.Errorf("args = %q, want %q", ran.Args, want)
+	}
+	if strings.Contains(strings.Join(ran.Args, " ")+strings.Join(ran.Env, "\n"), "s3cret") {
+		t.Error("the password was passed to mongosh")
+	}
+}
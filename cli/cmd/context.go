@@ -0,0 +1,337 @@
+package cmd
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/meido-ai/devdb/cli/pkg/config"
+    "github.com/meido-ai/devdb/cli/pkg/credentials"
+    "github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+    Use:   "context",
+    Short: "Manage connection contexts",
+    Long: `Manage named contexts. Each context holds the API URL, credentials,
+default project and TLS settings for one DevDB server, so switching between
+local, staging and shared servers is a single command.`,
+}
+
+var (
+    contextURL                string
+    contextProject            string
+    contextToken              string
+    contextCAFile             string
+    contextInsecureSkipVerify bool
+    contextUse                bool
+)
+
+// contextInfo is the printable form of a context. The token is never
+// printed.
+type contextInfo struct {
+    Name    string       `json:"name"`
+    Current bool         `json:"current"`
+    APIURL  string       `json:"apiUrl"`
+    Project string       `json:"project,omitempty"`
+    TLS     *config.TLS  `json:"tls,omitempty"`
+    OIDC    *config.OIDC `json:"oidc,omitempty"`
+}
+
+func newContextInfo(cfg *config.Config, name string) contextInfo {
+    ctx := cfg.Contexts[name]
+    info := contextInfo{
+        Name:    name,
+        Current: name == cfg.CurrentContext,
+        APIURL:  ctx.APIURL,
+        Project: ctx.Project,
+        OIDC:    ctx.OIDC,
+    }
+    if ctx.TLS != (config.TLS{}) {
+        tls := ctx.TLS
+        info.TLS = &tls
+    }
+    return info
+}
+
+// contextTable renders contexts as a table, like projectTable.
+type contextTable struct {
+    obj      interface{}
+    contexts []contextInfo
+}
+
+func (t contextTable) Unwrap() interface{} { return t.obj }
+
+func (t contextTable) Columns(wide bool) []string {
+    cols := []string{"CURRENT", "NAME", "API URL", "PROJECT"}
+    if wide {
+        cols = append(cols, "CA FILE", "INSECURE")
+    }
+    return cols
+}
+
+func (t contextTable) Rows(wide bool) [][]string {
+    rows := make([][]string, 0, len(t.contexts))
+    for _, c := range t.contexts {
+        current := ""
+        if c.Current {
+            current = "*"
+        }
+        project := c.Project
+        if project == "" {
+            project = none
+        }
+        row := []string{current, c.Name, c.APIURL, project}
+        if wide {
+            caFile, insecure := none, false
+            if c.TLS != nil {
+                if c.TLS.CAFile != "" {
+                    caFile = c.TLS.CAFile
+                }
+                insecure = c.TLS.InsecureSkipVerify
+            }
+            row = append(row, caFile, fmt.Sprint(insecure))
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+// updateConfig loads the configuration file, applies fn and saves the
+// result.
+func updateConfig(fn func(cfg *config.Config) error) error {
+    path, err := configPath()
+    if err != nil {
+        return err
+    }
+    cfg, err := config.Load(path)
+    if err != nil {
+        return err
+    }
+    if err := fn(cfg); err != nil {
+        return err
+    }
+    if err := cfg.Save(path); err != nil {
+        return fmt.Errorf("error writing config: %v", err)
+    }
+    return nil
+}
+
+func loadConfig() (*config.Config, error) {
+    path, err := configPath()
+    if err != nil {
+        return nil, err
+    }
+    return config.Load(path)
+}
+
+var contextAddCmd = &cobra.Command{
+    Use:   "add [name]",
+    Short: "Add a context",
+    Long: `Add a named context for a DevDB server. The first context added
+becomes the current context; use --use to switch to any other.`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        ctx := &config.Context{
+            APIURL:  contextURL,
+            Project: contextProject,
+            TLS: config.TLS{
+                CAFile:             contextCAFile,
+                InsecureSkipVerify: contextInsecureSkipVerify,
+            },
+        }
+
+        var current bool
+        err := updateConfig(func(cfg *config.Config) error {
+            if err := cfg.Add(name, ctx); err != nil {
+                return err
+            }
+            if contextUse || cfg.CurrentContext == "" {
+                current = true
+                return cfg.Use(name)
+            }
+            return nil
+        })
+        if err != nil {
+            return err
+        }
+        if contextToken != "" {
+            store, err := newCredentialStore()
+            if err != nil {
+                return err
+            }
+            if err := store.Set(name, contextToken); err != nil {
+                return fmt.Errorf("storing token: %v", err)
+            }
+        }
+
+        cmd.Printf("Context %s added\n", name)
+        if current {
+            cmd.Printf("Switched to context %s\n", name)
+        }
+        return nil
+    },
+}
+
+var contextUseCmd = &cobra.Command{
+    Use:   "use [name]",
+    Short: "Switch to a context",
+    Long:  `Make the named context the current context.`,
+    Args:  cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        if err := updateConfig(func(cfg *config.Config) error { return cfg.Use(name) }); err != nil {
+            return err
+        }
+        cmd.Printf("Switched to context %s\n", name)
+        return nil
+    },
+}
+
+var contextListCmd = &cobra.Command{
+    Use:   "list",
+    Short: "List contexts",
+    Long:  `List all contexts. The current context is marked with an asterisk.`,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        cfg, err := loadConfig()
+        if err != nil {
+            return err
+        }
+
+        list := []contextInfo{}
+        for _, name := range cfg.Names() {
+            list = append(list, newContextInfo(cfg, name))
+        }
+        return printResult(cmd, contextTable{obj: list, contexts: list}, func() {
+            if len(list) == 0 {
+                cmd.Println("No contexts configured")
+                return
+            }
+            for _, c := range list {
+                marker := " "
+                if c.Current {
+                    marker = "*"
+                }
+                cmd.Printf("%s %s\t%s\n", marker, c.Name, c.APIURL)
+            }
+        })
+    },
+}
+
+var contextShowCmd = &cobra.Command{
+    Use:   "show [name]",
+    Short: "Show a context",
+    Long:  `Show the settings of the named context, or of the current context.`,
+    Args:  cobra.MaximumNArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        cfg, err := loadConfig()
+        if err != nil {
+            return err
+        }
+
+        override := contextName
+        if len(args) > 0 {
+            override = args[0]
+        }
+        name, _, err := cfg.Current(override)
+        if err != nil {
+            return err
+        }
+
+        info := newContextInfo(cfg, name)
+        return printResult(cmd, contextTable{obj: info, contexts: []contextInfo{info}}, func() {
+            cmd.Printf("Context: %s\n", info.Name)
+            cmd.Printf("  API URL: %s\n", info.APIURL)
+            if info.Project != "" {
+                cmd.Printf("  Project: %s\n", info.Project)
+            }
+            if info.TLS != nil && info.TLS.CAFile != "" {
+                cmd.Printf("  CA File: %s\n", info.TLS.CAFile)
+            }
+            if info.TLS != nil && info.TLS.InsecureSkipVerify {
+                cmd.Printf("  Insecure Skip Verify: true\n")
+            }
+            if info.OIDC != nil {
+                cmd.Printf("  OIDC Issuer: %s\n", info.OIDC.Issuer)
+                cmd.Printf("  OIDC Client ID: %s\n", info.OIDC.ClientID)
+            }
+        })
+    },
+}
+
+var contextDeleteCmd = &cobra.Command{
+    Use:   "delete [name]",
+    Short: "Delete a context",
+    Long:  `Delete the named context.`,
+    Args:  cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        if err := updateConfig(func(cfg *config.Config) error { return cfg.Delete(name) }); err != nil {
+            return err
+        }
+
+        store, err := newCredentialStore()
+        if err != nil {
+            return err
+        }
+        if err := store.Delete(name); err != nil && !errors.Is(err, credentials.ErrNotFound) {
+            return fmt.Errorf("removing token: %v", err)
+        }
+        cmd.Printf("Context %s deleted\n", name)
+        return nil
+    },
+}
+
+var contextRenameCmd = &cobra.Command{
+    Use:   "rename [old] [new]",
+    Short: "Rename a context",
+    Long:  `Rename a context. If it is the current context it stays current.`,
+    Args:  cobra.ExactArgs(2),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        from, to := args[0], args[1]
+        if err := updateConfig(func(cfg *config.Config) error { return cfg.Rename(from, to) }); err != nil {
+            return err
+        }
+        if err := moveToken(from, to); err != nil {
+            return fmt.Errorf("moving token: %v", err)
+        }
+        cmd.Printf("Context %s renamed to %s\n", from, to)
+        return nil
+    },
+}
+
+// moveToken moves the stored token of a renamed context.
+func moveToken(from, to string) error {
+    store, err := newCredentialStore()
+    if err != nil {
+        return err
+    }
+    token, err := store.Get(from)
+    if errors.Is(err, credentials.ErrNotFound) {
+        return nil
+    } else if err != nil {
+        return err
+    }
+    if err := store.Set(to, token); err != nil {
+        return err
+    }
+    return store.Delete(from)
+}
+
+func init() {
+    rootCmd.AddCommand(contextCmd)
+    contextCmd.AddCommand(contextAddCmd)
+    contextCmd.AddCommand(contextUseCmd)
+    contextCmd.AddCommand(contextListCmd)
+    contextCmd.AddCommand(contextShowCmd)
+    contextCmd.AddCommand(contextDeleteCmd)
+    contextCmd.AddCommand(contextRenameCmd)
+
+    contextAddCmd.Flags().StringVar(&contextURL, "url", "", "DevDB API URL")
+    contextAddCmd.Flags().StringVar(&contextProject, "project", "", "Default project")
+    contextAddCmd.Flags().StringVar(&contextToken, "token", "", "API token (prefer \"devdb login\", which keeps it out of shell history)")
+    contextAddCmd.Flags().StringVar(&contextCAFile, "ca-file", "", "CA certificate file for verifying the API server")
+    contextAddCmd.Flags().BoolVar(&contextInsecureSkipVerify, "insecure-skip-verify", false, "Skip TLS certificate verification")
+    contextAddCmd.Flags().BoolVar(&contextUse, "use", false, "Switch to the new context")
+    contextAddCmd.MarkFlagRequired("url")
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/config"
+	"github.com/meido-ai/devdb/cli/pkg/credentials"
+)
+
+// withConfigFile points the CLI at an empty configuration file and an
+// in-memory credential store for the duration of the test.
+func withConfigFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "devdb.yaml")
+	originalFile, originalURL, originalStore := cfgFile, apiURL, newCredentialStore
+	t.Cleanup(func() {
+		cfgFile, apiURL, newCredentialStore = originalFile, originalURL, originalStore
+		activeContext, activeContextName, contextErr = nil, "", nil
+	})
+	cfgFile = path
+	t.Setenv(tokenEnvVar, "")
+
+	store := credentials.Memory{}
+	newCredentialStore = func() (credentials.Store, error) { return store, nil }
+	return path
+}
+
+// storedTokens returns the in-memory credential store installed by
+// withConfigFile.
+func storedTokens(t *testing.T) credentials.Memory {
+	t.Helper()
+	store, err := newCredentialStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store.(credentials.Memory)
+}
+
+func TestContextCommands(t *testing.T) {
+	withConfigFile(t)
+
+	tests := []cmdTestCase{
+		{
+			name:       "list without contexts",
+			cmd:        contextListCmd,
+			wantOutput: "No contexts configured\n",
+		},
+		{
+			name:       "add first context",
+			cmd:        contextAddCmd,
+			args:       []string{"local", "--url", "http://localhost:5000"},
+			wantOutput: "Context local added\nSwitched to context local\n",
+		},
+		{
+			name:       "add second context",
+			cmd:        contextAddCmd,
+			args:       []string{"staging", "--url", "https://staging.example.com", "--project", "billing", "--token", "secret"},
+			wantOutput: "Context staging added\n",
+		},
+		{
+			name:    "add existing context",
+			cmd:     contextAddCmd,
+			args:    []string{"local", "--url", "http://localhost:5000"},
+			wantErr: true,
+		},
+		{
+			name:    "add without url",
+			cmd:     contextAddCmd,
+			args:    []string{"other"},
+			wantErr: true,
+		},
+		{
+			name:       "list contexts",
+			cmd:        contextListCmd,
+			wantOutput: "* local\thttp://localhost:5000\n  staging\thttps://staging.example.com\n",
+		},
+		{
+			name:       "use context",
+			cmd:        contextUseCmd,
+			args:       []string{"staging"},
+			wantOutput: "Switched to context staging\n",
+		},
+		{
+			name:    "use missing context",
+			cmd:     contextUseCmd,
+			args:    []string{"missing"},
+			wantErr: true,
+		},
+		{
+			name:       "show current context",
+			cmd:        contextShowCmd,
+			wantOutput: "Context: staging\n  API URL: https://staging.example.com\n  Project: billing\n",
+		},
+		{
+			name: "token stored outside the config file",
+			cmd:  contextShowCmd,
+			args: []string{"staging", "-o", "jsonpath={.apiUrl}"},
+			setupMock: func() {
+				if got := storedTokens(t)["staging"]; got != "secret" {
+					t.Errorf("stored token = %q, want secret", got)
+				}
+			},
+			wantOutput: "https://staging.example.com\n",
+		},
+		{
+			name:       "rename current context",
+			cmd:        contextRenameCmd,
+			args:       []string{"staging", "stage"},
+			wantOutput: "Context staging renamed to stage\n",
+		},
+		{
+			name: "list as table",
+			cmd:  contextListCmd,
+			args: []string{"-o", "table"},
+			wantOutput: `CURRENT   NAME    API URL                       PROJECT
+          local   http://localhost:5000         <none>
+*         stage   https://staging.example.com   billing
+`,
+		},
+		{
+			name: "list as json omits token",
+			cmd:  contextListCmd,
+			args: []string{"-o", "json"},
+			wantOutput: `[
+  {
+    "apiUrl": "http://localhost:5000",
+    "current": false,
+    "name": "local"
+  },
+  {
+    "apiUrl": "https://staging.example.com",
+    "current": true,
+    "name": "stage",
+    "project": "billing"
+  }
+]
+`,
+		},
+		{
+			name:       "delete context",
+			cmd:        contextDeleteCmd,
+			args:       []string{"stage"},
+			wantOutput: "Context stage deleted\n",
+			setupMock: func() {
+				if got := storedTokens(t)["stage"]; got != "secret" {
+					t.Errorf("token after rename = %q, want secret", got)
+				}
+			},
+			teardownMock: func() {
+				if tokens := storedTokens(t); len(tokens) != 0 {
+					t.Errorf("tokens after delete = %v, want none", tokens)
+				}
+			},
+		},
+		{
+			name:    "show without current context",
+			cmd:     contextShowCmd,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
+
+func TestContextSelectsServer(t *testing.T) {
+	withConfigFile(t)
+
+	var gotAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	setup := []cmdTestCase{
+		{name: "add unreachable", cmd: contextAddCmd, args: []string{"down", "--url", "http://127.0.0.1:1"}},
+		{name: "add test server", cmd: contextAddCmd, args: []string{"test", "--url", ts.URL, "--token", "t0ken"}},
+	}
+	for _, tc := range setup {
+		executeCommand(t, tc)
+	}
+
+	// --context overrides the current context ("down")
+	apiURL = ""
+	executeCommand(t, cmdTestCase{
+		name:       "list with --context",
+		cmd:        projectListCmd,
+		args:       []string{"--context", "test"},
+		wantOutput: "No projects found\n",
+	})
+	if gotAuth != "Bearer t0ken" {
+		t.Errorf("Authorization = %q, want bearer token", gotAuth)
+	}
+
+	apiURL = ""
+	executeCommand(t, cmdTestCase{
+		name:    "unknown --context",
+		cmd:     projectListCmd,
+		args:    []string{"--context", "missing"},
+		wantErr: true,
+	})
+}
+
+func TestContextTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		settings config.TLS
+		wantErr  bool
+	}{
+		{name: "system roots reject test certificate", wantErr: true},
+		{name: "ca file", settings: config.TLS{CAFile: caFile}},
+		{name: "insecure skip verify", settings: config.TLS{InsecureSkipVerify: true}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := httpClientFor(tc.settings)
+			if err != nil {
+				t.Fatalf("httpClientFor() error = %v", err)
+			}
+			resp, err := client.Get(ts.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("GET error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	if _, err := httpClientFor(config.TLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("httpClientFor() with a missing CA file succeeded")
+	}
+}
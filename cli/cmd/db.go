@@ -3,32 +3,75 @@ package cmd
 import (
     "context"
     "fmt"
+    "time"
     "github.com/spf13/cobra"
     "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/wait"
 )
 
 var dbCmd = &cobra.Command{
     Use:   "db",
     Short: "Manage databases",
     Long: `Create and manage development databases.
-No Kubernetes knowledge required - DevDB handles all the infrastructure for you.`,
+No Kubernetes knowledge required - DevDB handles all the infrastructure for you.
+
+The project is taken from --project, then the DEVDB_PROJECT environment
+variable, then a .devdb.yaml file in the current directory or one of its
+parents, then the default project of the current context.`,
+    PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+        if err := checkOutputFlag(); err != nil {
+            return err
+        }
+        selected, _, err := selectProject(project)
+        if err != nil {
+            return err
+        }
+
+        // Accept a project name as well as an ID
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+        project, err = resolveProject(context.Background(), client, selected)
+        return err
+    },
 }
 
 var (
-    project string // Project flag for database commands
+    project          string        // Project flag for database commands
+    dbCreateWait     bool          // Wait flag for the create command
+    dbCreateFromDB   string        // Database to copy for the create command
+    dbCreateFromSnap string        // Snapshot to restore for the create command
+    dbCreateTTL      time.Duration // Time-to-live for the create command
 )
 
 var dbCreateCmd = &cobra.Command{
     Use:   "create [name]",
     Short: "Create a new database",
     Long: `Create a new database instance within a project.
-The database will inherit its configuration from the project settings.`,
+The database will inherit its configuration from the project settings.
+With --wait, the command blocks until the database is running.
+
+By default the database is restored from the project's backup. With
+--from-db it starts as a copy of another database of the project, and with
+--from-snapshot from a snapshot taken with "devdb db snapshot create".
+
+With --ttl, the database expires after the given time and is deleted, or
+stopped, by the server. "devdb db extend" gives it more time.`,
+    Example: `  # Fork your database before trying a risky migration
+  devdb db create feature-x --from-db mydb
+
+  # Go back to the state of a snapshot
+  devdb db create mydb-restored --from-snapshot before-migration
+
+  # A database for a feature branch that cleans up after itself
+  devdb db create feature-x --ttl 72h`,
     Args: cobra.ExactArgs(1),
     RunE: func(cmd *cobra.Command, args []string) error {
         name := args[0]
         ctx := context.Background()
         
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("creating client: %v", err)
         }
@@ -37,27 +80,51 @@ The database will inherit its configuration from the project settings.`,
         req := api.CreateDatabaseRequest{
             Name: name,
         }
+        if dbCreateFromDB != "" {
+            req.FromDatabase = &dbCreateFromDB
+        }
+        if dbCreateFromSnap != "" {
+            req.FromSnapshot = &dbCreateFromSnap
+        }
+        if dbCreateTTL != 0 {
+            ttl, err := ttlSeconds(dbCreateTTL)
+            if err != nil {
+                return err
+            }
+            req.TtlSeconds = &ttl
+        }
 
         resp, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, project, req)
         if err != nil {
-            return fmt.Errorf("creating database: %v", err)
+            return fmt.Errorf("creating database: %w", err)
         }
 
         if resp.StatusCode() != 201 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
         db := resp.JSON201
-        cmd.Printf("Database created successfully\nDetails:\n")
-        cmd.Printf("  Name: %s\n", db.Name)
-        cmd.Printf("  Status: %s\n", db.Status)
-        if db.Host != nil {
-            cmd.Printf("  Host: %s\n", *db.Host)
-        }
-        if db.Port != nil {
-            cmd.Printf("  Port: %d\n", *db.Port)
+        if dbCreateWait {
+            cmd.SilenceUsage = true
+            db, err = waitForDatabase(cmd, client, name, api.Running, wait.Options{AllowMissing: true, Probe: waitProbe})
+            if err != nil {
+                return err
+            }
         }
-        return nil
+        return printResult(cmd, databaseOutput(*db), func() {
+            cmd.Printf("Database created successfully\nDetails:\n")
+            cmd.Printf("  Name: %s\n", db.Name)
+            cmd.Printf("  Status: %s\n", db.Status)
+            if db.Host != nil {
+                cmd.Printf("  Host: %s\n", *db.Host)
+            }
+            if db.Port != nil {
+                cmd.Printf("  Port: %d\n", *db.Port)
+            }
+            if db.ExpiresAt != nil {
+                cmd.Printf("  Expires: %s\n", db.ExpiresAt.Local().Format(time.RFC3339))
+            }
+        })
     },
 }
 
@@ -67,37 +134,42 @@ var dbListCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         ctx := context.Background()
         
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("creating client: %v", err)
         }
 
         resp, err := client.GetProjectsProjectIdDatabasesWithResponse(ctx, project)
         if err != nil {
-            return fmt.Errorf("listing databases: %v", err)
+            return fmt.Errorf("listing databases: %w", err)
         }
 
         if resp.StatusCode() != 200 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
-        databases := resp.JSON200
-        if databases == nil || len(*databases) == 0 {
-            cmd.Println("No databases found")
-            return nil
+        var databases []api.Database
+        if resp.JSON200 != nil {
+            databases = *resp.JSON200
         }
 
-        cmd.Println("Databases:")
-        for _, db := range *databases {
-            cmd.Printf("- %s (Status: %s)\n", db.Name, db.Status)
-            if db.Host != nil {
-                cmd.Printf("  Host: %s\n", *db.Host)
+        return printResult(cmd, databasesOutput(databases), func() {
+            if len(databases) == 0 {
+                cmd.Println("No databases found")
+                return
             }
-            if db.Port != nil {
-                cmd.Printf("  Port: %d\n", *db.Port)
+
+            cmd.Println("Databases:")
+            for _, db := range databases {
+                cmd.Printf("- %s (%s)\n", db.Name, databaseSummary(db))
+                if db.Host != nil {
+                    cmd.Printf("  Host: %s\n", *db.Host)
+                }
+                if db.Port != nil {
+                    cmd.Printf("  Port: %d\n", *db.Port)
+                }
             }
-        }
-        return nil
+        })
     },
 }
 
@@ -109,31 +181,38 @@ var dbShowCmd = &cobra.Command{
         name := args[0]
         ctx := context.Background()
         
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("creating client: %v", err)
         }
 
         resp, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, project, name)
         if err != nil {
-            return fmt.Errorf("getting database: %v", err)
+            return fmt.Errorf("getting database: %w", err)
         }
 
         if resp.StatusCode() != 200 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
         db := resp.JSON200
-        cmd.Printf("Database Details:\n")
-        cmd.Printf("  Name: %s\n", db.Name)
-        cmd.Printf("  Status: %s\n", db.Status)
-        if db.Host != nil {
-            cmd.Printf("  Host: %s\n", *db.Host)
-        }
-        if db.Port != nil {
-            cmd.Printf("  Port: %d\n", *db.Port)
-        }
-        return nil
+        return printResult(cmd, databaseOutput(*db), func() {
+            cmd.Printf("Database Details:\n")
+            cmd.Printf("  Name: %s\n", db.Name)
+            cmd.Printf("  Status: %s\n", db.Status)
+            if db.Host != nil {
+                cmd.Printf("  Host: %s\n", *db.Host)
+            }
+            if db.Port != nil {
+                cmd.Printf("  Port: %d\n", *db.Port)
+            }
+            if db.ExpiresAt != nil {
+                cmd.Printf("  Expires: %s (in %s)\n", db.ExpiresAt.Local().Format(time.RFC3339), expiresIn(*db))
+            }
+            if db.DataVersion != nil {
+                cmd.Printf("  Data version: %d\n", *db.DataVersion)
+            }
+        })
     },
 }
 
@@ -145,22 +224,23 @@ var dbDeleteCmd = &cobra.Command{
         name := args[0]
         ctx := context.Background()
         
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("creating client: %v", err)
         }
 
         resp, err := client.DeleteProjectsProjectIdDatabasesNameWithResponse(ctx, project, name)
         if err != nil {
-            return fmt.Errorf("deleting database: %v", err)
+            return fmt.Errorf("deleting database: %w", err)
         }
 
         if resp.StatusCode() != 200 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
-        cmd.Printf("Database %s deleted successfully\n", name)
-        return nil
+        return printResult(cmd, deleteResult{Name: name, Status: "deleted"}, func() {
+            cmd.Printf("Database %s deleted successfully\n", name)
+        })
     },
 }
 
@@ -171,7 +251,13 @@ func init() {
     dbCmd.AddCommand(dbShowCmd)
     dbCmd.AddCommand(dbDeleteCmd)
 
+    dbCreateCmd.Flags().BoolVar(&dbCreateWait, "wait", false, "Wait until the database is running")
+    dbCreateCmd.Flags().StringVar(&dbCreateFromDB, "from-db", "", "Copy the data of another database of the project")
+    dbCreateCmd.Flags().StringVar(&dbCreateFromSnap, "from-snapshot", "", "Restore a snapshot instead of the project's backup")
+    dbCreateCmd.MarkFlagsMutuallyExclusive("from-db", "from-snapshot")
+    dbCreateCmd.Flags().DurationVar(&dbCreateTTL, "ttl", 0, "Delete the database after this long, e.g. 72h (default never)")
+    addWaitFlags(dbCreateCmd)
+
     // Add project flag to all database commands
-    dbCmd.PersistentFlags().StringVar(&project, "project", "", "Project ID (required)")
-    dbCmd.MarkPersistentFlagRequired("project")
+    dbCmd.PersistentFlags().StringVar(&project, "project", "", "Project ID or name (defaults to the selected project)")
 }
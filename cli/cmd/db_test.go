@@ -2,72 +2,57 @@ package cmd
 
 import (
     "net/http"
-    "net/http/httptest"
     "testing"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
 )
 
 func TestDatabaseCommands(t *testing.T) {
-    // Create a test server that returns mock responses
-    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        switch r.Method + " " + r.URL.Path {
-        case "POST /projects/testproject/databases":
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusCreated)
-            w.Write([]byte(`{
-                "name": "testdb",
-                "status": "running",
-                "host": "localhost",
-                "port": 5432,
-                "username": null,
-                "database": null
-            }`))
-        case "GET /projects/testproject/databases":
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusOK)
-            w.Write([]byte(`[
-                {
-                    "name": "testdb1",
-                    "status": "running",
-                    "host": "localhost",
-                    "port": 5432,
-                    "username": null,
-                    "database": null
-                },
-                {
-                    "name": "testdb2",
-                    "status": "stopped",
-                    "host": null,
-                    "port": null,
-                    "username": null,
-                    "database": null
-                }
-            ]`))
-        case "GET /projects/testproject/databases/testdb":
-            w.Header().Set("Content-Type", "application/json")
-            w.WriteHeader(http.StatusOK)
-            w.Write([]byte(`{
-                "name": "testdb",
-                "status": "running",
-                "host": "localhost",
-                "port": 5432,
-                "username": null,
-                "database": null
-            }`))
-        case "DELETE /projects/testproject/databases/testdb":
-            w.WriteHeader(http.StatusOK)
-            w.Write([]byte(`{"message": "Database deleted successfully"}`))
-        default:
-            http.Error(w, "not found", http.StatusNotFound)
-        }
-    }))
-    defer ts.Close()
-
-    // Store the original apiURL and restore it after tests
-    originalURL := apiURL
-    defer func() { apiURL = originalURL }()
-    apiURL = ts.URL
+    fake, project := newFakeAPI(t)
+    host, port := "localhost", 5432
+    fake.AddDatabase(project.Id, api.Database{Name: "testdb1", Host: &host, Port: &port})
+    fake.AddDatabase(project.Id, api.Database{Name: "testdb2", Status: api.Stopped})
 
     tests := []cmdTestCase{
+        {
+            name: "list databases",
+            cmd:  dbListCmd,
+            args: []string{"--project", "testproject"},
+            wantOutput: `Databases:
+- testdb1 (Status: running)
+  Host: localhost
+  Port: 5432
+- testdb2 (Status: stopped)
+`,
+        },
+        {
+            name: "list databases as table",
+            cmd:  dbListCmd,
+            args: []string{"--project", "testproject", "-o", "table"},
+            wantOutput: `NAME      STATUS    HOST        PORT
+testdb1   running   localhost   5432
+testdb2   stopped   <none>      <none>
+`,
+        },
+        {
+            name: "list databases with jsonpath",
+            cmd:  dbListCmd,
+            args: []string{"--project", "testproject", "-o", "jsonpath={[*].name}"},
+            wantOutput: "testdb1 testdb2\n",
+        },
+        {
+            name: "list databases with jsonpath of a missing field",
+            cmd:  dbListCmd,
+            args: []string{"--project", "testproject", "-o", "jsonpath={range [*]}{.name}={.host};{end}"},
+            wantOutput: "testdb1=localhost;testdb2=;\n",
+        },
+        {
+            name:    "list databases with jsonpath failing on missing fields",
+            cmd:     dbListCmd,
+            args:    []string{"--project", "testproject", "--allow-missing-template-keys=false", "-o", "jsonpath={range [*]}{.name}={.host};{end}"},
+            wantErr: true,
+            wantOutput: "Error: jsonpath: host is not found\n",
+        },
         {
             name: "create database",
             cmd:  dbCreateCmd,
@@ -75,7 +60,7 @@ func TestDatabaseCommands(t *testing.T) {
             wantOutput: `Database created successfully
 Details:
   Name: testdb
-  Status: running
+  Status: creating
   Host: localhost
   Port: 5432
 `,
@@ -85,17 +70,22 @@ Details:
             cmd:    dbCreateCmd,
             args:   []string{"testdb"},
             wantErr: true,
-            wantOutput: "Error: required flag(s) \"project\" not set\n",
+            wantOutput: "Error: no project selected: use --project, set DEVDB_PROJECT, add a project to .devdb.yaml or run \"devdb project use <id>\"\n",
         },
         {
-            name: "list databases",
-            cmd:  dbListCmd,
-            args: []string{"--project", "testproject"},
-            wantOutput: `Databases:
-- testdb1 (Status: running)
-  Host: localhost
-  Port: 5432
-- testdb2 (Status: stopped)
+            name: "show database as json",
+            cmd:  dbShowCmd,
+            args: []string{"testdb", "--project", "testproject", "--output", "json"},
+            wantOutput: `{
+  "dataVersion": 1,
+  "database": "devdb",
+  "host": "localhost",
+  "name": "testdb",
+  "port": 5432,
+  "project": "testproject",
+  "status": "running",
+  "username": "devdb"
+}
 `,
         },
         {
@@ -107,6 +97,7 @@ Details:
   Status: running
   Host: localhost
   Port: 5432
+  Data version: 1
 `,
         },
         {
@@ -125,14 +116,11 @@ Details:
 }
 
 func TestDatabaseClientErrors(t *testing.T) {
-    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        http.Error(w, "internal server error", http.StatusInternalServerError)
-    }))
-    defer ts.Close()
-
-    originalURL := apiURL
-    defer func() { apiURL = originalURL }()
-    apiURL = ts.URL
+    // Resolving --project still works, everything else fails
+    fake, _ := newFakeAPI(t)
+    fake.Fail("POST /projects/{projectId}/databases", http.StatusInternalServerError, 0)
+    fake.Fail("GET /projects/{projectId}/databases", http.StatusInternalServerError, 0)
+    fake.Fail("DELETE /projects/{projectId}/databases/{name}", http.StatusInternalServerError, 0)
 
     tests := []cmdTestCase{
         {
@@ -140,21 +128,21 @@ func TestDatabaseClientErrors(t *testing.T) {
             cmd:    dbCreateCmd,
             args:   []string{"testdb", "--project", "testproject"},
             wantErr: true,
-            wantOutput: "Error: API returned status code 500\n",
+            wantOutput: "Error: Internal Server Error: Fault injected by devdbfake\n",
         },
         {
             name:    "list databases server error",
             cmd:    dbListCmd,
             args:   []string{"--project", "testproject"},
             wantErr: true,
-            wantOutput: "Error: API returned status code 500\n",
+            wantOutput: "Error: Internal Server Error: Fault injected by devdbfake\n",
         },
         {
             name:    "delete database server error",
             cmd:    dbDeleteCmd,
             args:   []string{"testdb", "--project", "testproject"},
             wantErr: true,
-            wantOutput: "Error: API returned status code 500\n",
+            wantOutput: "Error: Internal Server Error: Fault injected by devdbfake\n",
         },
     }
 
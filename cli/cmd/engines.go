@@ -0,0 +1,139 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/engine"
+    "github.com/spf13/cobra"
+)
+
+var enginesCmd = &cobra.Command{
+    Use:   "engines",
+    Short: "Show the database engines projects can use",
+    Long:  `Show the database engines, versions and extensions the server supports.`,
+}
+
+var enginesListCmd = &cobra.Command{
+    Use:   "list",
+    Short: "List supported engines and versions",
+    Long: `List the database engines the server runs, the versions a project can be
+created with and the extensions its databases can enable. A project's
+--version is one of the versions listed or a release in one, such as 16.2
+for 16.`,
+    Example: `  devdb engines list
+  devdb engines list -o wide`,
+    Args:         cobra.NoArgs,
+    SilenceUsage: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+        catalog, err := fetchCatalog(context.Background(), client)
+        if err != nil {
+            return err
+        }
+
+        return printResult(cmd, engineTable{obj: catalog.API(), engines: catalog}, func() {
+            cmd.Println("Engines:")
+            for _, e := range catalog {
+                cmd.Printf("- %s (%s)\n", e.Type, e.Name)
+                cmd.Printf("  Versions: %s\n", strings.Join(e.Versions, ", "))
+                cmd.Printf("  Default port: %d\n", e.Port)
+                if len(e.Extensions) > 0 {
+                    cmd.Printf("  Extensions: %s\n", strings.Join(e.Extensions, ", "))
+                }
+            }
+        })
+    },
+}
+
+// fetchCatalog returns the engines the server supports. Servers that do
+// not advertise a catalog yet support the engines the CLI knows.
+func fetchCatalog(ctx context.Context, client api.ClientWithResponsesInterface) (engine.Catalog, error) {
+    resp, err := client.GetCatalogWithResponse(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("getting the engine catalog: %w", err)
+    }
+    switch {
+    case resp.StatusCode() == http.StatusOK && resp.JSON200 != nil:
+        return engine.FromAPI(*resp.JSON200), nil
+    case resp.StatusCode() == http.StatusNotFound:
+        return engine.All(), nil
+    }
+    return nil, api.NewError(resp.HTTPResponse, resp.Body)
+}
+
+// completionCatalog returns the server's catalog for shell completion,
+// or the engines the CLI knows when the server cannot be reached.
+func completionCatalog() engine.Catalog {
+    if client, err := newAPIClient(); err == nil {
+        if catalog, err := fetchCatalog(context.Background(), client); err == nil {
+            return catalog
+        }
+    }
+    return engine.All()
+}
+
+// completeEngineType completes --type flags with the supported engines.
+func completeEngineType(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+    var types []string
+    for _, e := range completionCatalog() {
+        types = append(types, string(e.Type)+"\t"+e.Name)
+    }
+    return types, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEngineVersion completes --version flags with the versions of
+// the engine given with --type.
+func completeEngineVersion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+    dbType, _ := cmd.Flags().GetString("type")
+    e, ok := completionCatalog().Lookup(api.DatabaseType(dbType))
+    if !ok {
+        return nil, cobra.ShellCompDirectiveNoFileComp
+    }
+    return e.Versions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// engineTable renders engines as a table while the structured formats see
+// the catalog as the API serves it.
+type engineTable struct {
+    obj     interface{}
+    engines engine.Catalog
+}
+
+func (t engineTable) Unwrap() interface{} { return t.obj }
+
+func (t engineTable) Columns(wide bool) []string {
+    cols := []string{"TYPE", "NAME", "VERSIONS", "PORT"}
+    if wide {
+        cols = append(cols, "EXTENSIONS")
+    }
+    return cols
+}
+
+func (t engineTable) Rows(wide bool) [][]string {
+    rows := make([][]string, 0, len(t.engines))
+    for _, e := range t.engines {
+        row := []string{string(e.Type), e.Name, strings.Join(e.Versions, ","), strconv.Itoa(e.Port)}
+        if wide {
+            extensions := strings.Join(e.Extensions, ",")
+            if extensions == "" {
+                extensions = none
+            }
+            row = append(row, extensions)
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+func init() {
+    rootCmd.AddCommand(enginesCmd)
+    enginesCmd.AddCommand(enginesListCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestEnginesList(t *testing.T) {
+	fake, _ := newFakeAPI(t)
+
+	tests := []cmdTestCase{
+		{
+			name: "table",
+			cmd:  enginesListCmd,
+			args: []string{"-o", "table"},
+			wantOutput: `TYPE       NAME         VERSIONS               PORT
+postgres   PostgreSQL   17,16,15,14,13,12      5432
+mysql      MySQL        9.1,8.4,8.0            3306
+mariadb    MariaDB      11.4,10.11,10.6,10.5   3306
+redis      Redis        7.4,7.2,6.2            6379
+mongodb    MongoDB      8.0,7.0,6.0            27017
+`,
+		},
+		{
+			name:       "typo in the version",
+			cmd:        projectCreateCmd,
+			args:       []string{"billing", "--type", "postgres", "--version", "v15.4"},
+			wantErr:    true,
+			wantOutput: "Error: invalid PostgreSQL version \"v15.4\": want a version number such as 17; did you mean 15.4?\n",
+		},
+		{
+			name:       "typo in the type",
+			cmd:        projectCreateCmd,
+			args:       []string{"catalog", "--type", "mongo", "--version", "7.0"},
+			wantErr:    true,
+			wantOutput: "Error: unknown database type \"mongo\" (want postgres, mysql, mariadb, redis, mongodb); did you mean mongodb?\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+	if got := len(fake.Projects()) - 1; got != 0 {
+		t.Errorf("created %d projects, want none", got)
+	}
+}
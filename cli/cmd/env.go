@@ -0,0 +1,106 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "github.com/meido-ai/devdb/cli/pkg/envfile"
+    "github.com/spf13/cobra"
+)
+
+var (
+    envFormat    string // Format flag for the env command
+    envWriteFile string // Path of a .env file to merge into
+)
+
+var dbEnvCmd = &cobra.Command{
+    Use:   "env [name]",
+    Short: "Print connection details for a database",
+    Long: `Print the connection details of a database as environment variables:
+DATABASE_URL, PGHOST, PGPORT, PGUSER, PGPASSWORD, PGDATABASE,
+JDBC_DATABASE_URL and DATABASE_DSN (a keyword/value string for pgx).
+For MySQL and MariaDB databases, DATABASE_URL is a mysql:// URL, the PG*
+variables are MYSQL_HOST, MYSQL_TCP_PORT, MYSQL_USER, MYSQL_PWD and
+MYSQL_DATABASE, and DATABASE_DSN is a DSN for the Go MySQL driver.
+Redis databases are described by REDIS_URL, REDIS_HOST, REDIS_PORT and
+REDIS_PASSWORD, and MongoDB databases by MONGODB_URI and MONGODB_DATABASE;
+they have no jdbc or dsn connection string.
+
+Formats:
+  dotenv, shell, fish, powershell, json   all variables
+  url, jdbc, dsn                          a single connection string
+
+Load them into the current shell with:
+  eval "$(devdb db env mydb --project myproject --format shell)"
+
+With --write, the variables are merged into a .env file: existing keys are
+updated in place and unrelated keys, comments and ordering are preserved.`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        ctx := context.Background()
+
+        if !validEnvFormat(envFormat) {
+            return fmt.Errorf("unknown format %q (want one of %s, url, jdbc, dsn)", envFormat, strings.Join(envfile.Formats, ", "))
+        }
+        cmd.SilenceUsage = true
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        info, err := fetchConnInfo(ctx, client, project, name)
+        if err != nil {
+            return err
+        }
+
+        if envWriteFile != "" {
+            vars := info.Vars()
+            if err := envfile.WriteFile(envWriteFile, vars); err != nil {
+                return fmt.Errorf("writing %s: %v", envWriteFile, err)
+            }
+            cmd.Printf("Wrote %d variables to %s\n", len(vars), envWriteFile)
+            return nil
+        }
+
+        switch envFormat {
+        case "url":
+            cmd.Println(info.URL())
+        case "jdbc":
+            if info.JDBCURL() == "" {
+                return fmt.Errorf("%s databases have no JDBC connection string", info.Type)
+            }
+            cmd.Println(info.JDBCURL())
+        case "dsn":
+            if info.DSN() == "" {
+                return fmt.Errorf("%s databases have no DSN; use --format url", info.Type)
+            }
+            cmd.Println(info.DSN())
+        default:
+            return envfile.Render(cmd.OutOrStdout(), envFormat, info.Vars())
+        }
+        return nil
+    },
+}
+
+func validEnvFormat(format string) bool {
+    switch format {
+    case "url", "jdbc", "dsn":
+        return true
+    }
+    for _, f := range envfile.Formats {
+        if f == format {
+            return true
+        }
+    }
+    return false
+}
+
+func init() {
+    dbCmd.AddCommand(dbEnvCmd)
+
+    dbEnvCmd.Flags().StringVar(&envFormat, "format", "dotenv", "Output format: dotenv, shell, fish, powershell, json, url, jdbc or dsn")
+    dbEnvCmd.Flags().StringVar(&envWriteFile, "write", "", "Merge the variables into this .env file instead of printing them")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+    "errors"
+    "net/http"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+// Exit codes, one per class of error, so scripts can tell a missing
+// database from a server failure.
+const (
+    exitError        = 1 // any other error
+    exitInvalid      = 2 // the API rejected the request as invalid
+    exitUnauthorized = 3 // missing, invalid or insufficient credentials
+    exitNotFound     = 4 // the project or database does not exist
+    exitConflict     = 5 // the project or database already exists
+    exitServerError  = 6 // the API failed to handle the request
+)
+
+// exitCode returns the exit code for an error returned by a command.
+func exitCode(err error) int {
+    var loginRequired *loginRequiredError
+    if errors.As(err, &loginRequired) {
+        return exitUnauthorized
+    }
+
+    status := api.StatusCode(err)
+    switch {
+    case status == 0:
+        return exitError
+    case status == http.StatusUnauthorized || status == http.StatusForbidden:
+        return exitUnauthorized
+    case api.IsNotFound(err):
+        return exitNotFound
+    case api.IsConflict(err):
+        return exitConflict
+    case status >= 500:
+        return exitServerError
+    case status >= 400:
+        return exitInvalid
+    }
+    return exitError
+}
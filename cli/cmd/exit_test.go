@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestExitCode(t *testing.T) {
+	apiError := func(status int) error {
+		return fmt.Errorf("getting database: %w", &api.APIError{StatusCode: status})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "plain error", err: errors.New("boom"), want: exitError},
+		{name: "bad request", err: apiError(http.StatusBadRequest), want: exitInvalid},
+		{name: "unprocessable", err: apiError(http.StatusUnprocessableEntity), want: exitInvalid},
+		{name: "unauthorized", err: apiError(http.StatusUnauthorized), want: exitUnauthorized},
+		{name: "forbidden", err: apiError(http.StatusForbidden), want: exitUnauthorized},
+		{name: "login required", err: fmt.Errorf("listing projects: %w", &loginRequiredError{}), want: exitUnauthorized},
+		{name: "not found", err: apiError(http.StatusNotFound), want: exitNotFound},
+		{name: "conflict", err: apiError(http.StatusConflict), want: exitConflict},
+		{name: "server error", err: apiError(http.StatusServiceUnavailable), want: exitServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProblemDetailsShown(t *testing.T) {
+	isolateProjectSelection(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/projects" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(testProjectsResponse))
+			return
+		}
+		w.Header().Set("Content-Type", "application/problem+json")
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"type": "about:blank", "title": "Conflict", "status": 409, "detail": "Database testdb already exists"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type": "about:blank", "title": "Not Found", "status": 404, "detail": "Database testdb not found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	originalURL := apiURL
+	defer func() { apiURL = originalURL }()
+	apiURL = ts.URL
+
+	tests := []cmdTestCase{
+		{
+			name:       "create conflict",
+			cmd:        dbCreateCmd,
+			args:       []string{"testdb", "--project", "testproject"},
+			wantErr:    true,
+			wantOutput: "Error: Conflict: Database testdb already exists\n",
+		},
+		{
+			name:       "show not found",
+			cmd:        dbShowCmd,
+			args:       []string{"testdb", "--project", "testproject"},
+			wantErr:    true,
+			wantOutput: "Error: Not Found: Database testdb not found\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "time"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/spf13/cobra"
+)
+
+var dbExtendTTL time.Duration // TTL flag for the extend command
+
+var dbExtendCmd = &cobra.Command{
+    Use:   "extend [name]",
+    Short: "Give a database more time before it expires",
+    Long: `Push back the expiry of a database created with --ttl. The time is
+added to the current expiry, or to now if that is later. A database
+without an expiry gets one.`,
+    Example: `  devdb db extend feature-x --ttl 24h`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        ttl, err := ttlSeconds(dbExtendTTL)
+        if err != nil {
+            return err
+        }
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        resp, err := client.PostProjectsProjectIdDatabasesNameExtendWithResponse(context.Background(), project, name, api.ExtendDatabaseRequest{TtlSeconds: ttl})
+        if err != nil {
+            return fmt.Errorf("extending database: %w", err)
+        }
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        db := resp.JSON200
+        return printResult(cmd, databaseOutput(*db), func() {
+            if db.ExpiresAt == nil {
+                cmd.Printf("Database %s extended\n", db.Name)
+                return
+            }
+            cmd.Printf("Database %s now expires at %s (in %s)\n", db.Name, db.ExpiresAt.Local().Format(time.RFC3339), expiresIn(*db))
+        })
+    },
+}
+
+// ttlSeconds converts a --ttl value for the API, which counts in whole
+// seconds.
+func ttlSeconds(ttl time.Duration) (int64, error) {
+    if ttl < time.Minute {
+        return 0, fmt.Errorf("invalid --ttl %s: must be at least 1m", ttl)
+    }
+    return int64(ttl / time.Second), nil
+}
+
+func init() {
+    dbCmd.AddCommand(dbExtendCmd)
+
+    dbExtendCmd.Flags().DurationVar(&dbExtendTTL, "ttl", 0, "Time to add, e.g. 24h")
+    dbExtendCmd.MarkFlagRequired("ttl")
+}
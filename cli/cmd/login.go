@@ -0,0 +1,252 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "os/user"
+    "path/filepath"
+    "strings"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/config"
+    "github.com/meido-ai/devdb/cli/pkg/credentials"
+    "github.com/spf13/cobra"
+    "golang.org/x/term"
+)
+
+var (
+    loginTokenStdin bool     // Read the token from stdin instead of prompting
+    loginOIDC       bool     // Log in through an OIDC identity provider
+    loginIssuer     string   // OIDC issuer URL
+    loginClientID   string   // OIDC client ID
+    loginScopes     []string // OIDC scopes
+)
+
+// newCredentialStore returns the store holding API tokens. Tests replace
+// it with an in-memory store.
+var newCredentialStore = func() (credentials.Store, error) {
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return nil, err
+    }
+    return credentials.New(filepath.Join(dir, "devdb")), nil
+}
+
+var errNoTerminal = errors.New("no terminal to prompt on")
+
+// readSecret prompts for a value on the terminal without echoing it.
+var readSecret = func(prompt string) (string, error) {
+    if !isTerminal(os.Stdin) {
+        return "", errNoTerminal
+    }
+    fmt.Fprint(os.Stderr, prompt)
+    secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+    fmt.Fprintln(os.Stderr)
+    return strings.TrimSpace(string(secret)), err
+}
+
+// loadToken returns the token stored for the named context, or an empty
+// string when there is none.
+func loadToken(name string) (string, error) {
+    store, err := newCredentialStore()
+    if err != nil {
+        return "", err
+    }
+    token, err := store.Get(name)
+    if errors.Is(err, credentials.ErrNotFound) {
+        return "", nil
+    }
+    return token, err
+}
+
+// storeToken saves an API token for the current context, creating a
+// "default" context if there is none, and returns the context name. The
+// context stops using OIDC, whose refresh token the stored one replaces.
+func storeToken(token string) (string, error) {
+    name, err := updateCurrentContext(func(ctx *config.Context) {
+        ctx.OIDC = nil
+    })
+    if err != nil {
+        return "", err
+    }
+    store, err := newCredentialStore()
+    if err != nil {
+        return "", err
+    }
+    return name, store.Set(name, token)
+}
+
+var loginCmd = &cobra.Command{
+    Use:   "login",
+    Short: "Log in to the DevDB API",
+    Long: `Log in to the DevDB API of the current context with an API token.
+The token is checked against the server and stored in the OS keyring, or,
+where no keyring is available, in a file only you can read. Set
+DEVDB_CREDENTIALS_PASSPHRASE to encrypt that file with a passphrase.
+
+With --oidc, log in through your organisation's identity provider instead:
+the command shows a code to enter in a browser and waits for the login to
+be approved. The issuer and client ID are saved in the context, and the
+access token is refreshed automatically when it expires.
+
+Set DEVDB_TOKEN to use a token without logging in, e.g. in CI.`,
+    Example: `  # Prompt for the token
+  devdb login
+
+  # Read the token from a secret manager
+  vault read -field=token secret/devdb | devdb login --token-stdin
+
+  # Log in through an identity provider
+  devdb login --oidc --issuer https://login.example.com --client-id devdb-cli`,
+    Args: cobra.NoArgs,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if contextErr != nil {
+            return contextErr
+        }
+        if loginOIDC {
+            return runOIDCLogin(cmd)
+        }
+
+        var token string
+        if loginTokenStdin {
+            data, err := io.ReadAll(cmd.InOrStdin())
+            if err != nil {
+                return fmt.Errorf("reading token: %v", err)
+            }
+            token = strings.TrimSpace(string(data))
+        } else {
+            var err error
+            token, err = readSecret("API token: ")
+            if errors.Is(err, errNoTerminal) {
+                return fmt.Errorf("no terminal to prompt for the token on, use --token-stdin")
+            } else if err != nil {
+                return fmt.Errorf("reading token: %v", err)
+            }
+        }
+        if token == "" {
+            return fmt.Errorf("no token given")
+        }
+        cmd.SilenceUsage = true
+
+        if err := checkToken(token); err != nil {
+            return err
+        }
+        name, err := storeToken(token)
+        if err != nil {
+            return fmt.Errorf("storing token: %v", err)
+        }
+        cmd.Printf("Logged in to %s (context %s)\n", apiURL, name)
+        return nil
+    },
+}
+
+// runOIDCLogin logs in to the current context through the identity
+// provider given by the flags or saved in the context.
+func runOIDCLogin(cmd *cobra.Command) error {
+    if loginTokenStdin {
+        return fmt.Errorf("--token-stdin cannot be used with --oidc")
+    }
+
+    settings := &config.OIDC{}
+    if activeContext != nil && activeContext.OIDC != nil {
+        *settings = *activeContext.OIDC
+    }
+    if loginIssuer != "" {
+        settings.Issuer = loginIssuer
+    }
+    if loginClientID != "" {
+        settings.ClientID = loginClientID
+    }
+    if len(loginScopes) > 0 {
+        settings.Scopes = loginScopes
+    }
+    if settings.Issuer == "" || settings.ClientID == "" {
+        return fmt.Errorf("--issuer and --client-id are required the first time you log in with --oidc")
+    }
+    cmd.SilenceUsage = true
+
+    name, err := updateCurrentContext(func(ctx *config.Context) { ctx.OIDC = settings })
+    if err != nil {
+        return err
+    }
+    token, err := oidcLogin(context.Background(), cmd.ErrOrStderr(), name, settings)
+    if err != nil {
+        return err
+    }
+    if err := checkToken(token.AccessToken); err != nil {
+        return err
+    }
+    cmd.Printf("Logged in to %s (context %s)\n", apiURL, name)
+    return nil
+}
+
+// checkToken makes an authenticated request to verify that the server
+// accepts token.
+func checkToken(token string) error {
+    client, err := newClientWithToken(token, false)
+    if err != nil {
+        return fmt.Errorf("creating client: %v", err)
+    }
+    currentUser, err := user.Current()
+    if err != nil {
+        return fmt.Errorf("error getting current user: %v", err)
+    }
+
+    resp, err := client.GetProjectsWithResponse(context.Background(), &api.GetProjectsParams{
+        Owner: &currentUser.Username,
+    })
+    var loginRequired *loginRequiredError
+    if errors.As(err, &loginRequired) {
+        return fmt.Errorf("the DevDB API at %s rejected the token", apiURL)
+    } else if err != nil {
+        return fmt.Errorf("checking token: %w", err)
+    }
+    if resp.StatusCode() != http.StatusOK {
+        return api.NewError(resp.HTTPResponse, resp.Body)
+    }
+    return nil
+}
+
+var logoutCmd = &cobra.Command{
+    Use:   "logout",
+    Short: "Log out of the DevDB API",
+    Long:  `Remove the stored token of the current context.`,
+    Args:  cobra.NoArgs,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if contextErr != nil {
+            return contextErr
+        }
+        if activeContext == nil {
+            return config.ErrNoContext
+        }
+
+        store, err := newCredentialStore()
+        if err != nil {
+            return err
+        }
+        err = store.Delete(activeContextName)
+        if errors.Is(err, credentials.ErrNotFound) {
+            cmd.Printf("Not logged in to context %s\n", activeContextName)
+            return nil
+        } else if err != nil {
+            return err
+        }
+        cmd.Printf("Logged out of context %s\n", activeContextName)
+        return nil
+    },
+}
+
+func init() {
+    rootCmd.AddCommand(loginCmd)
+    rootCmd.AddCommand(logoutCmd)
+
+    loginCmd.Flags().BoolVar(&loginTokenStdin, "token-stdin", false, "Read the token from standard input")
+    loginCmd.Flags().BoolVar(&loginOIDC, "oidc", false, "Log in through an OIDC identity provider with the device authorization grant")
+    loginCmd.Flags().StringVar(&loginIssuer, "issuer", "", "OIDC issuer URL (saved in the context)")
+    loginCmd.Flags().StringVar(&loginClientID, "client-id", "", "OIDC client ID (saved in the context)")
+    loginCmd.Flags().StringSliceVar(&loginScopes, "scope", nil, "OIDC scopes to request (default openid, profile, email, offline_access)")
+}
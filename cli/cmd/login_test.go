@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/config"
+	"github.com/meido-ai/devdb/cli/pkg/oidc"
+	"github.com/meido-ai/devdb/cli/pkg/oidc/oidctest"
+)
+
+// newAuthTestServer returns a server that only accepts token.
+func newAuthTestServer(token *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+*token {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+}
+
+func TestLoginLogout(t *testing.T) {
+	withConfigFile(t)
+
+	validToken := "good-token"
+	ts := newAuthTestServer(&validToken)
+	defer ts.Close()
+	apiURL = ts.URL
+
+	originalReadSecret := readSecret
+	defer func() { readSecret = originalReadSecret }()
+	readSecret = func(prompt string) (string, error) { return "", errNoTerminal }
+
+	tests := []cmdTestCase{
+		{
+			name:    "login without a terminal",
+			cmd:     loginCmd,
+			wantErr: true,
+		},
+		{
+			name:       "login with a rejected token",
+			cmd:        loginCmd,
+			args:       []string{"--token-stdin"},
+			stdin:      "bad-token\n",
+			wantErr:    true,
+			wantOutput: "Error: the DevDB API at " + ts.URL + " rejected the token\n",
+		},
+		{
+			name:       "login from stdin",
+			cmd:        loginCmd,
+			args:       []string{"--token-stdin"},
+			stdin:      "good-token\n",
+			wantOutput: "Logged in to " + ts.URL + " (context default)\n",
+			teardownMock: func() {
+				if got := storedTokens(t)["default"]; got != "good-token" {
+					t.Errorf("stored token = %q, want good-token", got)
+				}
+			},
+		},
+		{
+			name:       "logout",
+			cmd:        logoutCmd,
+			wantOutput: "Logged out of context default\n",
+		},
+		{
+			name:       "logout again",
+			cmd:        logoutCmd,
+			wantOutput: "Not logged in to context default\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+
+	readSecret = func(prompt string) (string, error) { return "good-token", nil }
+	executeCommand(t, cmdTestCase{
+		name:       "login with prompt",
+		cmd:        loginCmd,
+		wantOutput: "Logged in to " + ts.URL + " (context default)\n",
+	})
+}
+
+func TestUnauthorizedPromptsForLogin(t *testing.T) {
+	withConfigFile(t)
+
+	validToken := "old-token"
+	ts := newAuthTestServer(&validToken)
+	defer ts.Close()
+	apiURL = ts.URL
+
+	originalReadSecret := readSecret
+	defer func() { readSecret = originalReadSecret }()
+
+	executeCommand(t, cmdTestCase{
+		name:   "login",
+		cmd:    loginCmd,
+		args:   []string{"--token-stdin"},
+		stdin:  "old-token",
+	})
+
+	// The server rotates its tokens
+	validToken = "new-token"
+
+	readSecret = func(prompt string) (string, error) { return "", errNoTerminal }
+	executeCommand(t, cmdTestCase{
+		name:       "rejected without a terminal",
+		cmd:        projectListCmd,
+		wantErr:    true,
+		wantOutput: "Error: error listing projects: authentication required: run \"devdb login --context default\"\n",
+	})
+
+	var prompts int
+	readSecret = func(prompt string) (string, error) {
+		prompts++
+		return "new-token", nil
+	}
+	executeCommand(t, cmdTestCase{
+		name:       "rejected with a terminal",
+		cmd:        projectListCmd,
+		wantOutput: "No projects found\n",
+	})
+	if prompts != 1 {
+		t.Errorf("prompted %d times, want 1", prompts)
+	}
+	if got := storedTokens(t)["default"]; got != "new-token" {
+		t.Errorf("stored token = %q, want new-token", got)
+	}
+
+	// The new token is used from now on
+	readSecret = func(prompt string) (string, error) { return "", errors.New("unexpected prompt") }
+	executeCommand(t, cmdTestCase{
+		name:       "new token used",
+		cmd:        projectListCmd,
+		wantOutput: "No projects found\n",
+	})
+}
+
+func TestTokenFromEnvironment(t *testing.T) {
+	withConfigFile(t)
+
+	validToken := "ci-token"
+	ts := newAuthTestServer(&validToken)
+	defer ts.Close()
+	apiURL = ts.URL
+
+	t.Setenv(tokenEnvVar, "ci-token")
+	executeCommand(t, cmdTestCase{
+		name:       "list with DEVDB_TOKEN",
+		cmd:        projectListCmd,
+		wantOutput: "No projects found\n",
+	})
+}
+
+// storedOIDCToken decodes the OIDC token stored for the named context.
+func storedOIDCToken(t *testing.T, name string) *oidc.Token {
+	t.Helper()
+	token := &oidc.Token{}
+	if err := json.Unmarshal([]byte(storedTokens(t)[name]), token); err != nil {
+		t.Fatalf("stored token for %s: %v", name, err)
+	}
+	return token
+}
+
+func TestOIDCLogin(t *testing.T) {
+	path := withConfigFile(t)
+	oidcPollInterval = time.Millisecond
+	defer func() { oidcPollInterval = 0 }()
+
+	idp := oidctest.NewProvider()
+	defer idp.Close()
+	idp.PendingPolls = 2
+
+	validToken := "access-1"
+	ts := newAuthTestServer(&validToken)
+	defer ts.Close()
+	apiURL = ts.URL
+
+	executeCommand(t, cmdTestCase{
+		name:       "login without an issuer",
+		cmd:        loginCmd,
+		args:       []string{"--oidc"},
+		wantErr:    true,
+		wantOutput: "Error: --issuer and --client-id are required the first time you log in with --oidc\n",
+	})
+
+	output := executeCommand(t, cmdTestCase{
+		name: "login with the device flow",
+		cmd:  loginCmd,
+		args: []string{"--oidc", "--issuer", idp.URL, "--client-id", oidctest.ClientID, "--scope", "openid,offline_access"},
+	})
+	for _, want := range []string{
+		"open " + idp.URL + "/activate and enter the code ABCD-EFGH",
+		"Logged in to " + ts.URL + " (context default)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output %q does not contain %q", output, want)
+		}
+	}
+	if idp.Scopes != "openid offline_access" {
+		t.Errorf("requested scopes = %q, want %q", idp.Scopes, "openid offline_access")
+	}
+	if got := storedOIDCToken(t, "default"); got.AccessToken != "access-1" || got.RefreshToken == "" {
+		t.Errorf("stored token = %+v, want access-1 with a refresh token", got)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config.OIDC{Issuer: idp.URL, ClientID: oidctest.ClientID, Scopes: []string{"openid", "offline_access"}}
+	if got := cfg.Contexts["default"].OIDC; got == nil || got.Issuer != want.Issuer || got.ClientID != want.ClientID || len(got.Scopes) != 2 {
+		t.Errorf("saved OIDC settings = %+v, want %+v", got, want)
+	}
+
+	// Logging in again reuses the saved settings
+	validToken = "access-2"
+	executeCommand(t, cmdTestCase{
+		name: "login again",
+		cmd:  loginCmd,
+		args: []string{"--oidc"},
+	})
+	if got := storedOIDCToken(t, "default").AccessToken; got != "access-2" {
+		t.Errorf("stored access token = %q, want access-2", got)
+	}
+
+	// An expired access token is refreshed before it is sent
+	token := storedOIDCToken(t, "default")
+	token.Expiry = time.Now().Add(-time.Minute)
+	data, _ := json.Marshal(token)
+	storedTokens(t)["default"] = string(data)
+
+	validToken = "access-3"
+	executeCommand(t, cmdTestCase{
+		name:       "expired token refreshed",
+		cmd:        projectListCmd,
+		wantOutput: "No projects found\n",
+	})
+	if got := storedOIDCToken(t, "default").AccessToken; got != "access-3" {
+		t.Errorf("stored access token = %q, want access-3", got)
+	}
+	if idp.Issued() != 3 {
+		t.Errorf("provider issued %d tokens, want 3", idp.Issued())
+	}
+
+	// Logging in with an API token replaces the OIDC login
+	validToken = "static-token"
+	executeCommand(t, cmdTestCase{
+		name:       "login with a token after OIDC",
+		cmd:        loginCmd,
+		args:       []string{"--token-stdin"},
+		stdin:      "static-token\n",
+		wantOutput: "Logged in to " + ts.URL + " (context default)\n",
+	})
+	if cfg, err = config.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Contexts["default"].OIDC; got != nil {
+		t.Errorf("saved OIDC settings = %+v after token login, want none", got)
+	}
+	executeCommand(t, cmdTestCase{
+		name:       "token used after OIDC",
+		cmd:        projectListCmd,
+		wantOutput: "No projects found\n",
+	})
+	if idp.Issued() != 3 {
+		t.Errorf("provider issued %d tokens, want 3", idp.Issued())
+	}
+}
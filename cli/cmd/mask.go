@@ -0,0 +1,276 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/backup"
+    "github.com/meido-ai/devdb/cli/pkg/masking"
+    "github.com/meido-ai/devdb/cli/pkg/pgdump"
+    "github.com/spf13/cobra"
+)
+
+var projectMaskCmd = &cobra.Command{
+    Use:   "mask",
+    Short: "Check and apply masking policies",
+    Long: `A masking policy anonymizes the data of plain-format pg_dump backups. It
+is a YAML file mapping columns to a rule:
+
+  seed: a-secret-of-your-own
+  columns:
+    users.email: {faker: email}
+    users.ssn: {hash: true, length: 12}
+    users.notes: null
+    billing.accounts.country: {constant: NL}
+
+Columns are table.column, in the public schema, or schema.table.column.
+The same value is always masked the same way for a seed, so joins on
+masked columns keep working. Keep the seed secret.
+
+project create and project refresh take a policy with --mask-policy and
+mask local backups with it before uploading them.`,
+}
+
+var projectMaskValidateDump string
+
+var projectMaskValidateCmd = &cobra.Command{
+    Use:   "validate [policy]",
+    Short: "Check a masking policy",
+    Long: `Check a masking policy file. With --dump, also check that the plain-format
+pg_dump backup has data for every masked column and can be masked.`,
+    Example: `  devdb project mask validate masking.yaml
+  devdb project mask validate masking.yaml --dump billing.sql`,
+    Args:         cobra.ExactArgs(1),
+    SilenceUsage: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        policy, err := masking.Load(args[0])
+        if err != nil {
+            return err
+        }
+        if projectMaskValidateDump != "" {
+            if err := checkDump(policy, projectMaskValidateDump); err != nil {
+                return err
+            }
+        }
+
+        rules := maskRules(policy)
+        return printResult(cmd, rules, func() {
+            cmd.Printf("Masking policy %s is valid: %d columns are masked\n", args[0], len(rules))
+            for _, rule := range rules {
+                cmd.Printf("  %s: %s\n", rule.Column, rule.Rule)
+            }
+            if projectMaskValidateDump != "" {
+                cmd.Printf("%s has data for all of them\n", projectMaskValidateDump)
+            }
+        })
+    },
+}
+
+var projectMaskApplyCmd = &cobra.Command{
+    Use:   "apply [policy] [dump]",
+    Short: "Mask a plain-format pg_dump backup",
+    Long: `Mask a plain-format pg_dump backup with a masking policy and write the
+result to stdout. The backup is read from stdin when no file is given, so
+dumps can be masked on their way to psql.`,
+    Example: `  devdb project mask apply masking.yaml billing.sql > billing-masked.sql
+  pg_dump -Fp billing | devdb project mask apply masking.yaml | psql -d billing_dev`,
+    Args:         cobra.RangeArgs(1, 2),
+    SilenceUsage: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        policy, err := masking.Load(args[0])
+        if err != nil {
+            return err
+        }
+        in := cmd.InOrStdin()
+        if len(args) == 2 {
+            f, err := os.Open(args[1])
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            in = f
+        }
+        return policy.Apply(in, cmd.OutOrStdout())
+    },
+}
+
+var projectMaskShowCmd = &cobra.Command{
+    Use:          "show [project]",
+    Short:        "Show a project's masking policy",
+    Long:         `Show the masking policy of a project, given by ID or name.`,
+    Args:         cobra.ExactArgs(1),
+    SilenceUsage: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        ctx := context.Background()
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("error creating client: %v", err)
+        }
+
+        projectId, err := resolveProject(ctx, client, args[0])
+        if err != nil {
+            return err
+        }
+        project, err := getProject(ctx, client, projectId)
+        if err != nil {
+            return err
+        }
+        if project.MaskingPolicy == nil {
+            return fmt.Errorf("project %s has no masking policy", project.Name)
+        }
+        cmd.Print(*project.MaskingPolicy)
+        return nil
+    },
+}
+
+// maskRule is one masked column, for the output of mask validate.
+type maskRule struct {
+    Column string `json:"column"`
+    Rule   string `json:"rule"`
+}
+
+type maskRuleList []maskRule
+
+func (l maskRuleList) Columns(wide bool) []string { return []string{"COLUMN", "RULE"} }
+
+func (l maskRuleList) Rows(wide bool) [][]string {
+    rows := make([][]string, len(l))
+    for i, r := range l {
+        rows[i] = []string{r.Column, r.Rule}
+    }
+    return rows
+}
+
+func maskRules(policy *masking.Policy) maskRuleList {
+    rules := maskRuleList{}
+    for column, rule := range policy.Columns {
+        rules = append(rules, maskRule{Column: column, Rule: rule.String()})
+    }
+    sort.Slice(rules, func(i, j int) bool { return rules[i].Column < rules[j].Column })
+    return rules
+}
+
+// checkDump checks that the dump at path has the columns policy masks, and
+// masks it without keeping the result, to find what would stop an upload.
+func checkDump(policy *masking.Policy, path string) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    tables, err := pgdump.Tables(f)
+    if err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    if err := policy.Validate(tables); err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    if _, err := f.Seek(0, io.SeekStart); err != nil {
+        return err
+    }
+    if err := policy.Apply(f, io.Discard); err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    return nil
+}
+
+// loadMaskingPolicy reads and parses a policy file, returning its text for
+// the API along with it.
+func loadMaskingPolicy(path string) (*masking.Policy, string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, "", err
+    }
+    policy, err := masking.Parse(data)
+    if err != nil {
+        return nil, "", fmt.Errorf("%s: %w", path, err)
+    }
+    return policy, string(data), nil
+}
+
+// projectMaskingPolicy returns the masking policy of a project, or nil
+// when it has none.
+func projectMaskingPolicy(project *api.Project) (*masking.Policy, error) {
+    if project.MaskingPolicy == nil {
+        return nil, nil
+    }
+    policy, err := masking.Parse([]byte(*project.MaskingPolicy))
+    if err != nil {
+        return nil, fmt.Errorf("masking policy of project %s: %w", project.Name, err)
+    }
+    return policy, nil
+}
+
+// checkMaskable returns an error for projects of a type masking policies
+// do not apply to.
+func checkMaskable(dbType api.DatabaseType) error {
+    if dbType != api.Postgres {
+        return fmt.Errorf("masking policies apply to pg_dump backups; %s projects cannot have one", dbType)
+    }
+    return nil
+}
+
+func getProject(ctx context.Context, client api.ClientWithResponsesInterface, projectId string) (*api.Project, error) {
+    resp, err := client.GetProjectsProjectIdWithResponse(ctx, projectId)
+    if err != nil {
+        return nil, fmt.Errorf("error getting project: %w", err)
+    }
+    if resp.StatusCode() != 200 {
+        return nil, api.NewError(resp.HTTPResponse, resp.Body)
+    }
+    return resp.JSON200, nil
+}
+
+// maskBackup masks the plain-format pg_dump backup at path into a file of
+// the same name in a temporary directory, and returns its path and a
+// function removing it.
+func maskBackup(cmd *cobra.Command, policy *masking.Policy, path string) (string, func(), error) {
+    f, err := backup.Open(path)
+    if err != nil {
+        return "", nil, err
+    }
+    f.Close()
+    if f.Format != api.BackupFormatPlain {
+        return "", nil, fmt.Errorf("cannot mask %s: %w", path, pgdump.ErrNotPlain)
+    }
+
+    in, err := os.Open(f.Path)
+    if err != nil {
+        return "", nil, err
+    }
+    defer in.Close()
+
+    dir, err := os.MkdirTemp("", "devdb-masked-")
+    if err != nil {
+        return "", nil, err
+    }
+    cleanup := func() { os.RemoveAll(dir) }
+    masked := filepath.Join(dir, f.Name)
+    out, err := os.OpenFile(masked, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+    if err == nil {
+        err = policy.Apply(in, out)
+        if closeErr := out.Close(); err == nil {
+            err = closeErr
+        }
+    }
+    if err != nil {
+        cleanup()
+        return "", nil, fmt.Errorf("masking %s: %w", path, err)
+    }
+    fmt.Fprintf(cmd.ErrOrStderr(), "Masked %s (%d columns)\n", f.Name, len(policy.Columns))
+    return masked, cleanup, nil
+}
+
+func init() {
+    projectCmd.AddCommand(projectMaskCmd)
+    projectMaskCmd.AddCommand(projectMaskValidateCmd, projectMaskApplyCmd, projectMaskShowCmd)
+
+    projectMaskValidateCmd.Flags().StringVar(&projectMaskValidateDump, "dump", "", "Plain-format pg_dump backup to check the policy against")
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+const testMaskPolicy = `seed: test
+columns:
+  users.email: {faker: email}
+  users.notes: null
+`
+
+const testMaskDump = `--
+-- PostgreSQL database dump
+--
+
+COPY public.users (id, email, notes) FROM stdin;
+1	alice@example.org	call after 5
+\.
+`
+
+func writeTestFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProjectMaskValidate(t *testing.T) {
+	dir := t.TempDir()
+	policy := writeTestFile(t, dir, "masking.yaml", testMaskPolicy)
+	badPolicy := writeTestFile(t, dir, "bad.yaml", "columns:\n  users.email: scramble\n")
+	dump := writeTestFile(t, dir, "billing.sql", testMaskDump)
+	otherDump := writeTestFile(t, dir, "orders.sql", "COPY public.orders (id) FROM stdin;\n1\n\\.\n")
+	custom := writeTestFile(t, dir, "billing.dump", "PGDMP\x01\x0e")
+
+	tests := []cmdTestCase{
+		{
+			name: "valid policy",
+			cmd:  projectMaskValidateCmd,
+			args: []string{policy},
+			wantOutput: "Masking policy " + policy + ` is valid: 2 columns are masked
+  public.users.email: faker: email
+  public.users.notes: null
+`,
+		},
+		{
+			name: "checked against a dump",
+			cmd:  projectMaskValidateCmd,
+			args: []string{policy, "--dump", dump, "-o", "table"},
+			wantOutput: `COLUMN               RULE
+public.users.email   faker: email
+public.users.notes   null
+`,
+		},
+		{
+			name:       "invalid policy",
+			cmd:        projectMaskValidateCmd,
+			args:       []string{badPolicy},
+			wantErr:    true,
+			wantOutput: "Error: " + badPolicy + `: column users.email, line 2: unknown rule "scramble": want hash, null, or a mapping with faker, hash, null or constant` + "\n",
+		},
+		{
+			name:       "dump without the columns",
+			cmd:        projectMaskValidateCmd,
+			args:       []string{policy, "--dump", otherDump},
+			wantErr:    true,
+			wantOutput: "Error: " + otherDump + ": the dump has no data for public.users.email, public.users.notes\n",
+		},
+		{
+			name:    "custom-format dump",
+			cmd:     projectMaskValidateCmd,
+			args:    []string{policy, "--dump", custom},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
+
+func TestProjectMaskApply(t *testing.T) {
+	dir := t.TempDir()
+	policy := writeTestFile(t, dir, "masking.yaml", testMaskPolicy)
+
+	output := executeCommand(t, cmdTestCase{
+		name:  "from stdin",
+		cmd:   projectMaskApplyCmd,
+		args:  []string{policy},
+		stdin: testMaskDump,
+	})
+	if strings.Contains(output, "alice@example.org") || strings.Contains(output, "call after 5") {
+		t.Errorf("personal data left in the output:\n%s", output)
+	}
+	if !strings.Contains(output, "@example.com\t\\N\n") {
+		t.Errorf("output does not have the masked row:\n%s", output)
+	}
+
+	fromFile := executeCommand(t, cmdTestCase{
+		name: "from a file",
+		cmd:  projectMaskApplyCmd,
+		args: []string{policy, writeTestFile(t, dir, "billing.sql", testMaskDump)},
+	})
+	if fromFile != output {
+		t.Errorf("masking a file gave\n%s\nwant\n%s", fromFile, output)
+	}
+}
+
+func TestProjectCreateMaskPolicy(t *testing.T) {
+	fake, testProject := newFakeAPI(t)
+
+	dir := t.TempDir()
+	policy := writeTestFile(t, dir, "masking.yaml", testMaskPolicy)
+	dump := writeTestFile(t, dir, "billing.sql", testMaskDump)
+	custom := writeTestFile(t, dir, "orders.dump", "PGDMP\x01\x0e")
+
+	output := executeCommand(t, cmdTestCase{
+		name: "masked upload",
+		cmd:  projectCreateCmd,
+		args: []string{"billing", "--type", "postgres", "--version", "16", "--backup", dump, "--mask-policy", policy},
+	})
+	if !strings.Contains(output, "Masked billing.sql (2 columns)\n") {
+		t.Errorf("output does not report the masking:\n%s", output)
+	}
+	project := fakeProject(t, fake, "billing")
+	if project.MaskingPolicy == nil || *project.MaskingPolicy != testMaskPolicy {
+		t.Errorf("masking policy = %v, want the policy file", project.MaskingPolicy)
+	}
+	uploaded, ok := fake.Upload(project.BackupLocation)
+	if !ok || strings.Contains(string(uploaded), "alice@example.org") || !strings.Contains(string(uploaded), "COPY public.users") {
+		t.Errorf("uploaded backup (found %v):\n%s", ok, uploaded)
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:       "custom-format backup",
+			cmd:        projectCreateCmd,
+			args:       []string{"orders", "--type", "postgres", "--version", "16", "--backup", custom, "--mask-policy", policy},
+			wantErr:    true,
+			wantOutput: "Error: cannot mask " + custom + ": not a plain-format dump: custom and directory archives must be converted with pg_restore -f first\n",
+		},
+		{
+			name:       "backup in S3",
+			cmd:        projectCreateCmd,
+			args:       []string{"orders", "--type", "postgres", "--version", "16", "--backup", "s3://backups/orders.sql", "--mask-policy", policy},
+			wantErr:    true,
+			wantOutput: "Error: cannot mask s3://backups/orders.sql: masking policies are applied to local backups before they are uploaded\n",
+		},
+		{
+			name:       "show",
+			cmd:        projectMaskShowCmd,
+			args:       []string{"billing"},
+			wantOutput: testMaskPolicy,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+
+	// Refreshing from a local backup masks it with the project's policy
+	fake.AddProject(api.Project{Id: "proj-2", Owner: testProject.Owner, Name: "search", BackupLocation: "s3://backups/search.sql"})
+	executeCommand(t, cmdTestCase{
+		name: "refresh",
+		cmd:  projectRefreshCmd,
+		args: []string{"billing", "--backup", dump},
+	})
+	refreshed := fakeProject(t, fake, "billing")
+	uploaded, ok = fake.Upload(refreshed.BackupLocation)
+	if !ok || refreshed.BackupLocation == project.BackupLocation || strings.Contains(string(uploaded), "alice@example.org") {
+		t.Errorf("refreshed backup %s (found %v):\n%s", refreshed.BackupLocation, ok, uploaded)
+	}
+
+	executeCommand(t, cmdTestCase{
+		name:       "refresh without a backup",
+		cmd:        projectRefreshCmd,
+		args:       []string{"search", "--mask-policy", policy},
+		wantErr:    true,
+		wantOutput: "Error: --mask-policy needs --backup: the current backup was uploaded already and cannot be masked again\n",
+	})
+	executeCommand(t, cmdTestCase{
+		name:       "refresh from S3",
+		cmd:        projectRefreshCmd,
+		args:       []string{"billing", "--backup", "s3://backups/billing-unmasked.sql"},
+		wantErr:    true,
+		wantOutput: "Error: project billing has a masking policy: cannot mask s3://backups/billing-unmasked.sql: masking policies are applied to local backups before they are uploaded\n",
+	})
+	if location := fakeProject(t, fake, "billing").BackupLocation; location != refreshed.BackupLocation {
+		t.Errorf("backup location = %s after a refused refresh, want %s", location, refreshed.BackupLocation)
+	}
+	executeCommand(t, cmdTestCase{
+		name:       "no policy",
+		cmd:        projectMaskShowCmd,
+		args:       []string{"search"},
+		wantErr:    true,
+		wantOutput: "Error: project search has no masking policy\n",
+	})
+}
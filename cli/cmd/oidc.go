@@ -0,0 +1,90 @@
+package cmd
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "time"
+
+    "github.com/meido-ai/devdb/cli/pkg/config"
+    "github.com/meido-ai/devdb/cli/pkg/oidc"
+)
+
+// oidcPollInterval overrides the identity provider's polling interval.
+// Tests set it to keep the device flow fast.
+var oidcPollInterval time.Duration
+
+func oidcClient(settings *config.OIDC) *oidc.Client {
+    return &oidc.Client{
+        Issuer:   settings.Issuer,
+        ClientID: settings.ClientID,
+        Scopes:   settings.Scopes,
+        Interval: oidcPollInterval,
+    }
+}
+
+// oidcLogin runs the device authorization grant, telling the user on w
+// where to approve the login, and stores the resulting token for the
+// named context.
+func oidcLogin(ctx context.Context, w io.Writer, name string, settings *config.OIDC) (*oidc.Token, error) {
+    client := oidcClient(settings)
+    da, err := client.StartDeviceAuth(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    fmt.Fprintf(w, "To log in, open %s and enter the code %s\n", da.VerificationURI, da.UserCode)
+    if da.VerificationURIComplete != "" {
+        fmt.Fprintf(w, "or open %s\n", da.VerificationURIComplete)
+    }
+    fmt.Fprintln(w, "Waiting for the login to be approved...")
+
+    token, err := client.PollToken(ctx, da)
+    if err != nil {
+        return nil, err
+    }
+    if err := storeOIDCToken(name, token); err != nil {
+        return nil, fmt.Errorf("storing token: %v", err)
+    }
+    return token, nil
+}
+
+// OIDC tokens are stored as JSON so the refresh token and expiry are kept
+// with the access token.
+func storeOIDCToken(name string, token *oidc.Token) error {
+    data, err := json.Marshal(token)
+    if err != nil {
+        return err
+    }
+    store, err := newCredentialStore()
+    if err != nil {
+        return err
+    }
+    return store.Set(name, string(data))
+}
+
+// oidcAccessToken returns the access token from a stored OIDC token,
+// refreshing it first when it has expired. An empty string is returned
+// when the token cannot be refreshed, so the API's 401 leads to a new
+// login.
+func oidcAccessToken(name string, settings *config.OIDC, stored string) (string, error) {
+    token := &oidc.Token{}
+    if err := json.Unmarshal([]byte(stored), token); err != nil {
+        return "", fmt.Errorf("stored token for context %s is not an OIDC token, run \"devdb login --oidc\"", name)
+    }
+    if token.Valid() {
+        return token.AccessToken, nil
+    }
+
+    refreshed, err := oidcClient(settings).Refresh(context.Background(), token)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+        return "", nil
+    }
+    if err := storeOIDCToken(name, refreshed); err != nil {
+        return "", fmt.Errorf("storing token: %v", err)
+    }
+    return refreshed.AccessToken, nil
+}
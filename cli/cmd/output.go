@@ -0,0 +1,284 @@
+package cmd
+
+import (
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/output"
+    "github.com/spf13/cobra"
+)
+
+var outputFormat string // Value of the global --output flag
+
+// allowMissingKeys is the value of the global --allow-missing-template-keys
+// flag. Like kubectl's, it is on by default.
+var allowMissingKeys = true
+
+// now is the clock used for relative times such as "expires in"; tests
+// replace it.
+var now = time.Now
+
+// printResult writes obj in the format selected with --output. When no
+// format was requested, text is called to print the command's default
+// human-readable output instead.
+func printResult(cmd *cobra.Command, obj interface{}, text func()) error {
+    format, err := output.Parse(outputFormat)
+    if err != nil {
+        return err
+    }
+    if format.Kind == output.Text {
+        text()
+        return nil
+    }
+    format.AllowMissingKeys = allowMissingKeys
+    return output.Print(cmd.OutOrStdout(), format, obj)
+}
+
+const none = "<none>"
+
+func stringOrNone(s *string) string {
+    if s == nil || *s == "" {
+        return none
+    }
+    return *s
+}
+
+func intOrNone(i *int) string {
+    if i == nil {
+        return none
+    }
+    return strconv.Itoa(*i)
+}
+
+// projectTable renders one or more projects as a table while the
+// structured formats see the original value.
+type projectTable struct {
+    obj      interface{}
+    projects []api.Project
+}
+
+func projectOutput(p api.Project) projectTable {
+    return projectTable{obj: p, projects: []api.Project{p}}
+}
+
+func projectsOutput(ps []api.Project) projectTable {
+    if ps == nil {
+        ps = []api.Project{}
+    }
+    return projectTable{obj: ps, projects: ps}
+}
+
+func (t projectTable) Unwrap() interface{} { return t.obj }
+
+func (t projectTable) Columns(wide bool) []string {
+    cols := []string{"NAME", "ID", "OWNER", "TYPE", "VERSION"}
+    if wide {
+        cols = append(cols, "BACKUP", "DATABASES")
+    }
+    return cols
+}
+
+func (t projectTable) Rows(wide bool) [][]string {
+    rows := make([][]string, 0, len(t.projects))
+    for _, p := range t.projects {
+        row := []string{p.Name, p.Id, p.Owner, string(p.DbType), p.DbVersion}
+        if wide {
+            backup := p.BackupLocation
+            if backup == "" {
+                backup = none
+            }
+            count := 0
+            if p.Databases != nil {
+                count = len(*p.Databases)
+            }
+            row = append(row, backup, strconv.Itoa(count))
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+// databaseTable is the database counterpart of projectTable.
+type databaseTable struct {
+    obj       interface{}
+    databases []api.Database
+}
+
+func databaseOutput(db api.Database) databaseTable {
+    return databaseTable{obj: db, databases: []api.Database{db}}
+}
+
+func databasesOutput(dbs []api.Database) databaseTable {
+    if dbs == nil {
+        dbs = []api.Database{}
+    }
+    return databaseTable{obj: dbs, databases: dbs}
+}
+
+func (t databaseTable) Unwrap() interface{} { return t.obj }
+
+func (t databaseTable) Columns(wide bool) []string {
+    cols := []string{"NAME", "STATUS", "HOST", "PORT"}
+    if wide {
+        cols = append(cols, "PROJECT", "USERNAME", "DATABASE", "DATA VERSION", "IDLE", "EXPIRES")
+    }
+    return cols
+}
+
+func (t databaseTable) Rows(wide bool) [][]string {
+    rows := make([][]string, 0, len(t.databases))
+    for _, db := range t.databases {
+        row := []string{db.Name, string(db.Status), stringOrNone(db.Host), intOrNone(db.Port)}
+        if wide {
+            idle, expires := idleFor(db), expiresIn(db)
+            if idle == "" {
+                idle = none
+            }
+            if expires == "" {
+                expires = none
+            }
+            row = append(row, stringOrNone(db.Project), stringOrNone(db.Username), stringOrNone(db.Database), intOrNone(db.DataVersion), idle, expires)
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+// idleFor returns how long a stopped database has been stopped, or "" for
+// databases that are not.
+func idleFor(db api.Database) string {
+    if db.Status != api.Stopped || db.StoppedAt == nil {
+        return ""
+    }
+    return humanDuration(now().Sub(*db.StoppedAt))
+}
+
+// expiresIn returns how long a database has left to live, "expired" once
+// its time is up, or "" for databases that never expire.
+func expiresIn(db api.Database) string {
+    if db.ExpiresAt == nil {
+        return ""
+    }
+    left := db.ExpiresAt.Sub(now())
+    if left <= 0 {
+        return "expired"
+    }
+    return humanDuration(left)
+}
+
+// databaseSummary is the parenthesized part of a database's line in
+// "devdb db list", e.g. "Status: running, expires in 3h".
+func databaseSummary(db api.Database) string {
+    summary := "Status: " + string(db.Status)
+    if idle := idleFor(db); idle != "" {
+        summary += ", idle for " + idle
+    }
+    switch left := expiresIn(db); left {
+    case "":
+    case "expired":
+        summary += ", expired"
+    default:
+        summary += ", expires in " + left
+    }
+    return summary
+}
+
+// humanDuration rounds d to its largest unit, e.g. "45s", "12m", "3h" or
+// "2d".
+func humanDuration(d time.Duration) string {
+    if d < 0 {
+        d = 0
+    }
+    switch {
+    case d < time.Minute:
+        return fmt.Sprintf("%ds", int(d.Seconds()))
+    case d < time.Hour:
+        return fmt.Sprintf("%dm", int(d.Minutes()))
+    case d < 48*time.Hour:
+        return fmt.Sprintf("%dh", int(d.Hours()))
+    }
+    return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// deleteResult is printed by delete commands when a structured output
+// format is requested.
+type deleteResult struct {
+    Name   string `json:"name"`
+    Status string `json:"status"`
+}
+
+func (r deleteResult) Columns(wide bool) []string { return []string{"NAME", "STATUS"} }
+
+func (r deleteResult) Rows(wide bool) [][]string { return [][]string{{r.Name, r.Status}} }
+
+// refreshResult is the outcome of project refresh.
+type refreshResult struct {
+    api.DataRefresh
+}
+
+func (r refreshResult) Columns(wide bool) []string {
+    return []string{"NAME", "PREVIOUS VERSION", "DATA VERSION", "BACKUP"}
+}
+
+func (r refreshResult) Rows(wide bool) [][]string {
+    return [][]string{{r.Project.Name, strconv.Itoa(r.PreviousDataVersion), strconv.Itoa(r.DataVersion), r.Project.BackupLocation}}
+}
+
+// snapshotTable is the snapshot counterpart of projectTable.
+type snapshotTable struct {
+    obj       interface{}
+    snapshots []api.Snapshot
+}
+
+func snapshotOutput(s api.Snapshot) snapshotTable {
+    return snapshotTable{obj: s, snapshots: []api.Snapshot{s}}
+}
+
+func snapshotsOutput(ss []api.Snapshot) snapshotTable {
+    if ss == nil {
+        ss = []api.Snapshot{}
+    }
+    return snapshotTable{obj: ss, snapshots: ss}
+}
+
+func (t snapshotTable) Unwrap() interface{} { return t.obj }
+
+func (t snapshotTable) Columns(wide bool) []string {
+    cols := []string{"NAME", "DATABASE", "STATUS", "CREATED"}
+    if wide {
+        cols = append(cols, "SIZE")
+    }
+    return cols
+}
+
+func (t snapshotTable) Rows(wide bool) [][]string {
+    rows := make([][]string, 0, len(t.snapshots))
+    for _, s := range t.snapshots {
+        row := []string{s.Name, s.Database, string(s.Status), s.CreatedAt.Format(time.RFC3339)}
+        if wide {
+            size := none
+            if s.SizeBytes != nil {
+                size = formatBytes(*s.SizeBytes)
+            }
+            row = append(row, size)
+        }
+        rows = append(rows, row)
+    }
+    return rows
+}
+
+// formatBytes renders a size in bytes with a binary unit, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+    const unit = 1024
+    if n < unit {
+        return fmt.Sprintf("%d B", n)
+    }
+    div, exp := int64(unit), 0
+    for m := n / unit; m >= unit; m /= unit {
+        div *= unit
+        exp++
+    }
+    return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -4,8 +4,12 @@ import (
     "context"
     "fmt"
     "os/user"
+    "strings"
+    "time"
     "github.com/spf13/cobra"
     "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/config"
+    "github.com/meido-ai/devdb/cli/pkg/masking"
 )
 
 var projectCmd = &cobra.Command{
@@ -18,12 +22,37 @@ var (
     projectOwner string
     projectType string
     projectVersion string
+    projectBackup string
+    projectMaskPolicy string
+    projectRefreshBackup string
+    projectRefreshMaskPolicy string
 )
 
 var projectCreateCmd = &cobra.Command{
     Use:   "create [name]",
     Short: "Create a new project",
-    Long:  `Create a new project with the specified name.`,
+    Long:  `Create a new project with the specified name.
+
+--type is postgres, mysql, mariadb, redis or mongodb, and --version one of
+the engine's supported versions, or a release of one, such as 16.2 or 8.4.
+Both are checked against the engines the server supports, which devdb
+engines list shows, with a suggestion when there is a likely typo.
+
+--backup sets the backup new databases are restored from: an S3 URL, or
+a local backup that is uploaded through the API first: a pg_dump backup
+(custom or plain format file, or directory) for postgres, a mysqldump
+SQL file for mysql and mariadb, an RDB file for redis and a mongodump
+archive (mongodump --archive) for mongodb.
+
+--mask-policy attaches a masking policy to the project and masks the
+local backup with it before the upload; the backup must then be a
+plain-format dump. See devdb project mask.`,
+    Example: `  devdb project create billing --type postgres --version 16
+  devdb project create billing --type postgres --version 16 --backup ./billing.dump
+  devdb project create billing --type postgres --version 16 --backup ./billing.sql --mask-policy masking.yaml
+  devdb project create billing --type postgres --version 16 --backup s3://backups/billing.dump
+  devdb project create shop --type mysql --version 8.4 --backup ./shop.sql
+  devdb project create sessions --type redis --version 7.2 --backup ./dump.rdb`,
     Args:  cobra.ExactArgs(1),
     RunE: func(cmd *cobra.Command, args []string) error {
         // We're past flag validation, silence usage for runtime errors
@@ -32,6 +61,27 @@ var projectCreateCmd = &cobra.Command{
         name := args[0]
         ctx := context.Background()
 
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("error creating client: %v", err)
+        }
+
+        // Check the type and version against what the server runs before
+        // anything is uploaded
+        catalog, err := fetchCatalog(ctx, client)
+        if err != nil {
+            return err
+        }
+        dbType := api.DatabaseType(projectType)
+        if err := catalog.Validate(dbType, projectVersion); err != nil {
+            return err
+        }
+        if projectMaskPolicy != "" {
+            if err := checkMaskable(dbType); err != nil {
+                return err
+            }
+        }
+
         // Use current user if owner not specified
         owner := projectOwner
         if owner == "" {
@@ -42,35 +92,51 @@ var projectCreateCmd = &cobra.Command{
             owner = currentUser.Username
         }
 
-        client, err := api.NewClientWithResponses(apiURL)
-        if err != nil {
-            return fmt.Errorf("error creating client: %v", err)
-        }
-
-        resp, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+        request := api.CreateProjectRequest{
             Owner:     owner,
             Name:      name,
-            DbType:    api.DatabaseType(projectType),
+            DbType:    dbType,
             DbVersion: projectVersion,
-        })
+        }
+        var policy *masking.Policy
+        if projectMaskPolicy != "" {
+            var text string
+            if policy, text, err = loadMaskingPolicy(projectMaskPolicy); err != nil {
+                return err
+            }
+            request.MaskingPolicy = &text
+        }
+        if projectBackup != "" {
+            location, err := backupLocation(ctx, cmd, client, projectBackup, request.DbType, policy)
+            if err != nil {
+                return err
+            }
+            request.BackupLocation = &location
+        }
+
+        resp, err := client.PostProjectsWithResponse(ctx, request)
 
         if err != nil {
-            return fmt.Errorf("error creating project: %v", err)
+            return fmt.Errorf("error creating project: %w", err)
         }
 
         if resp.StatusCode() != 201 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
         result := resp.JSON201
-        cmd.Printf("Project created successfully\n")
-        cmd.Printf("Details:\n")
-        cmd.Printf("  ID: %s\n", result.Id)
-        cmd.Printf("  Name: %s\n", result.Name)
-        cmd.Printf("  Owner: %s\n", result.Owner)
-        cmd.Printf("  DbType: %s\n", result.DbType)
-        cmd.Printf("  DbVersion: %s\n", result.DbVersion)
-        return nil
+        return printResult(cmd, projectOutput(*result), func() {
+            cmd.Printf("Project created successfully\n")
+            cmd.Printf("Details:\n")
+            cmd.Printf("  ID: %s\n", result.Id)
+            cmd.Printf("  Name: %s\n", result.Name)
+            cmd.Printf("  Owner: %s\n", result.Owner)
+            cmd.Printf("  DbType: %s\n", result.DbType)
+            cmd.Printf("  DbVersion: %s\n", result.DbVersion)
+            if result.BackupLocation != "" {
+                cmd.Printf("  BackupLocation: %s\n", result.BackupLocation)
+            }
+        })
     },
 }
 
@@ -84,7 +150,7 @@ var projectListCmd = &cobra.Command{
 
         ctx := context.Background()
 
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("error creating client: %v", err)
         }
@@ -99,83 +165,98 @@ var projectListCmd = &cobra.Command{
         })
 
         if err != nil {
-            return fmt.Errorf("error listing projects: %v", err)
+            return fmt.Errorf("error listing projects: %w", err)
         }
 
         if resp.StatusCode() != 200 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
-        projects := resp.JSON200
-        if projects == nil || len(*projects) == 0 {
-            cmd.Println("No projects found")
-            return nil
+        var projects []api.Project
+        if resp.JSON200 != nil {
+            projects = *resp.JSON200
         }
 
-        cmd.Println("Projects:")
-        for _, project := range *projects {
-            cmd.Printf("- %s (ID: %s)\n", project.Name, project.Id)
-            cmd.Printf("  Owner: %s\n", project.Owner)
-            cmd.Printf("  DbType: %s\n", project.DbType)
-            cmd.Printf("  DbVersion: %s\n", project.DbVersion)
-        }
-        return nil
+        return printResult(cmd, projectsOutput(projects), func() {
+            if len(projects) == 0 {
+                cmd.Println("No projects found")
+                return
+            }
+
+            cmd.Println("Projects:")
+            for _, project := range projects {
+                cmd.Printf("- %s (ID: %s)\n", project.Name, project.Id)
+                cmd.Printf("  Owner: %s\n", project.Owner)
+                cmd.Printf("  DbType: %s\n", project.DbType)
+                cmd.Printf("  DbVersion: %s\n", project.DbVersion)
+            }
+        })
     },
 }
 
 var projectDeleteCmd = &cobra.Command{
-    Use:          "delete [name]",
+    Use:          "delete [project]",
     Short:        "Delete a project",
-    Long:         `Delete a project and optionally all its databases.`,
+    Long:         `Delete a project, given by ID or name, and optionally all its databases.`,
     Args:         cobra.ExactArgs(1),
     SilenceUsage: true,
     RunE: func(cmd *cobra.Command, args []string) error {
         name := args[0]
         ctx := context.Background()
 
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("error creating client: %v", err)
         }
 
-        resp, err := client.DeleteProjectWithResponse(ctx, name)
+        projectId, err := resolveProject(ctx, client, name)
+        if err != nil {
+            return err
+        }
+
+        resp, err := client.DeleteProjectWithResponse(ctx, projectId)
         if err != nil {
-            return fmt.Errorf("error deleting project: %v", err)
+            return fmt.Errorf("error deleting project: %w", err)
         }
 
         if resp.StatusCode() != 200 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
-        cmd.Printf("Project %s deleted successfully\n", name)
-        return nil
+        return printResult(cmd, deleteResult{Name: name, Status: "deleted"}, func() {
+            cmd.Printf("Project %s deleted successfully\n", name)
+        })
     },
 }
 
 var projectShowCmd = &cobra.Command{
-    Use:   "show [project-id]",
+    Use:   "show [project]",
     Short: "Show project details",
-    Long:  `Show details of a specific project.`,
+    Long:  `Show details of a specific project, given by ID or name.`,
     Args:  cobra.ExactArgs(1),
     RunE: func(cmd *cobra.Command, args []string) error {
         // Silence usage for runtime errors
         defer func() { cmd.SilenceUsage = true }()
 
         ctx := context.Background()
-        projectId := args[0]
 
-        client, err := api.NewClientWithResponses(apiURL)
+        client, err := newAPIClient()
         if err != nil {
             return fmt.Errorf("error creating client: %v", err)
         }
 
+        projectId, err := resolveProject(ctx, client, args[0])
+        if err != nil {
+            return err
+        }
+
         resp, err := client.GetProjectsProjectIdWithResponse(ctx, projectId)
         if err != nil {
-            return fmt.Errorf("error getting project: %v", err)
+            return fmt.Errorf("error getting project: %w", err)
         }
 
         if resp.StatusCode() != 200 {
-            return fmt.Errorf("API returned status code %d", resp.StatusCode())
+            return api.NewError(resp.HTTPResponse, resp.Body)
         }
 
         project := resp.JSON200
@@ -183,44 +264,175 @@ var projectShowCmd = &cobra.Command{
             return fmt.Errorf("project not found")
         }
 
-        cmd.Printf("Project Details:\n")
-        cmd.Printf("ID: %s\n", project.Id)
-        cmd.Printf("Name: %s\n", project.Name)
-        cmd.Printf("Owner: %s\n", project.Owner)
-        cmd.Printf("DbType: %s\n", project.DbType)
-        cmd.Printf("DbVersion: %s\n", project.DbVersion)
-        if project.BackupLocation != "" {
-            cmd.Printf("BackupLocation: %s\n", project.BackupLocation)
-        }
-        if project.Databases != nil && len(*project.Databases) > 0 {
-            cmd.Printf("\nDatabases:\n")
-            for _, db := range *project.Databases {
-                cmd.Printf("- %s (Status: %s)\n", db.Name, db.Status)
-                if db.Host != nil {
-                    cmd.Printf("  Host: %s\n", *db.Host)
+        return printResult(cmd, projectOutput(*project), func() {
+            cmd.Printf("Project Details:\n")
+            cmd.Printf("ID: %s\n", project.Id)
+            cmd.Printf("Name: %s\n", project.Name)
+            cmd.Printf("Owner: %s\n", project.Owner)
+            cmd.Printf("DbType: %s\n", project.DbType)
+            cmd.Printf("DbVersion: %s\n", project.DbVersion)
+            if project.BackupLocation != "" {
+                cmd.Printf("BackupLocation: %s\n", project.BackupLocation)
+            }
+            if project.DataVersion != nil {
+                cmd.Printf("DataVersion: %d\n", *project.DataVersion)
+            }
+            if project.DataRefreshedAt != nil {
+                cmd.Printf("DataRefreshedAt: %s\n", project.DataRefreshedAt.Local().Format(time.RFC3339))
+            }
+            if project.Databases != nil && len(*project.Databases) > 0 {
+                cmd.Printf("\nDatabases:\n")
+                for _, db := range *project.Databases {
+                    cmd.Printf("- %s (Status: %s)\n", db.Name, db.Status)
+                    if db.Host != nil {
+                        cmd.Printf("  Host: %s\n", *db.Host)
+                    }
+                    if db.Port != nil {
+                        cmd.Printf("  Port: %d\n", *db.Port)
+                    }
+                    if db.Username != nil {
+                        cmd.Printf("  Username: %s\n", *db.Username)
+                    }
                 }
-                if db.Port != nil {
-                    cmd.Printf("  Port: %d\n", *db.Port)
+            }
+        })
+    },
+}
+
+var projectRefreshCmd = &cobra.Command{
+    Use:     "refresh [project]",
+    Aliases: []string{"refresh-data"},
+    Short:   "Refresh a project's data from a new backup",
+    Long: `Refresh a project's data, given by ID or name, and increase its data
+version. Databases created or reset afterwards get the new data; existing
+databases keep theirs.
+
+--backup points the project at a new backup: an S3 URL, or a local pg_dump
+backup that is uploaded first, as with project create. Without it the
+project's current backup is read again.
+
+Local backups are masked with the project's masking policy before the
+upload. --mask-policy replaces the policy and masks the new backup with it.
+Backups in S3 cannot be masked, so projects with a policy only take local
+ones.`,
+    Example: `  devdb project refresh billing --backup ./billing-2024-06.dump
+  devdb project refresh billing --backup ./billing-2024-06.sql --mask-policy masking.yaml
+  devdb project refresh billing --backup s3://backups/billing-2024-06.dump
+  devdb project refresh billing`,
+    Args:         cobra.ExactArgs(1),
+    SilenceUsage: true,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        ctx := context.Background()
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("error creating client: %v", err)
+        }
+
+        projectId, err := resolveProject(ctx, client, args[0])
+        if err != nil {
+            return err
+        }
+
+        var request api.RefreshProjectRequest
+        var policy *masking.Policy
+        if projectRefreshMaskPolicy != "" {
+            if projectRefreshBackup == "" {
+                return fmt.Errorf("--mask-policy needs --backup: the current backup was uploaded already and cannot be masked again")
+            }
+            var text string
+            if policy, text, err = loadMaskingPolicy(projectRefreshMaskPolicy); err != nil {
+                return err
+            }
+            request.MaskingPolicy = &text
+        }
+        if projectRefreshBackup != "" {
+            // Local backups are checked against the project's type and
+            // masked with its policy unless given a new one. Backups in
+            // S3 cannot be masked, so projects with a policy refuse them
+            // like project create does
+            project, err := getProject(ctx, client, projectId)
+            if err != nil {
+                return err
+            }
+            if policy == nil {
+                if policy, err = projectMaskingPolicy(project); err != nil {
+                    return err
                 }
-                if db.Username != nil {
-                    cmd.Printf("  Username: %s\n", *db.Username)
+                if policy != nil && strings.HasPrefix(projectRefreshBackup, "s3://") {
+                    return fmt.Errorf("project %s has a masking policy: cannot mask %s: masking policies are applied to local backups before they are uploaded", project.Name, projectRefreshBackup)
                 }
             }
+            location, err := backupLocation(ctx, cmd, client, projectRefreshBackup, project.DbType, policy)
+            if err != nil {
+                return err
+            }
+            request.BackupLocation = &location
         }
+
+        resp, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, projectId, request)
+        if err != nil {
+            return fmt.Errorf("error refreshing project: %w", err)
+        }
+
+        if resp.StatusCode() != 200 {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        result := resp.JSON200
+        return printResult(cmd, refreshResult{*result}, func() {
+            cmd.Printf("Project %s refreshed from %s\n", result.Project.Name, result.Project.BackupLocation)
+            cmd.Printf("Data version: %d -> %d\n", result.PreviousDataVersion, result.DataVersion)
+            cmd.Println("New databases get the new data; existing databases keep theirs until they are reset.")
+        })
+    },
+}
+
+var projectUseCmd = &cobra.Command{
+    Use:   "use [project]",
+    Short: "Set the default project",
+    Long: `Set the default project of the current context, so database commands
+can be run without --project. The project is given by ID or name. A
+.devdb.yaml file in a checkout or the DEVDB_PROJECT environment variable
+take precedence over this default.`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("error creating client: %v", err)
+        }
+
+        projectId, err := resolveProject(context.Background(), client, args[0])
+        if err != nil {
+            return err
+        }
+
+        name, err := updateCurrentContext(func(ctx *config.Context) { ctx.Project = projectId })
+        if err != nil {
+            return err
+        }
+        cmd.Printf("Default project for context %s set to %s\n", name, projectId)
         return nil
     },
 }
 
 func init() {
     rootCmd.AddCommand(projectCmd)
-    projectCmd.AddCommand(projectCreateCmd, projectListCmd, projectDeleteCmd, projectShowCmd)
+    projectCmd.AddCommand(projectCreateCmd, projectListCmd, projectDeleteCmd, projectShowCmd, projectRefreshCmd, projectUseCmd)
 
     // Add flags for project create command
     projectCreateCmd.Flags().StringVar(&projectOwner, "owner", "", "Owner of the project (defaults to current user)")
-    projectCreateCmd.Flags().StringVar(&projectType, "type", "", "Type of database (postgres or mysql)")
+    projectCreateCmd.Flags().StringVar(&projectType, "type", "", "Type of database (postgres, mysql, mariadb, redis or mongodb)")
     projectCreateCmd.Flags().StringVar(&projectVersion, "version", "", "Version of the database")
+    projectCreateCmd.Flags().StringVar(&projectBackup, "backup", "", "Backup to restore databases from: an s3:// URL or a local backup to upload")
+    projectCreateCmd.Flags().StringVar(&projectMaskPolicy, "mask-policy", "", "Masking policy file to mask the local backup with before the upload")
+
+    projectRefreshCmd.Flags().StringVar(&projectRefreshBackup, "backup", "", "New backup: an s3:// URL or a local backup to upload (default the current backup)")
+    projectRefreshCmd.Flags().StringVar(&projectRefreshMaskPolicy, "mask-policy", "", "New masking policy file to mask the local backup with before the upload (default the project's policy)")
 
     // Mark required flags
     projectCreateCmd.MarkFlagRequired("type")
     projectCreateCmd.MarkFlagRequired("version")
+    projectCreateCmd.RegisterFlagCompletionFunc("type", completeEngineType)
+    projectCreateCmd.RegisterFlagCompletionFunc("version", completeEngineVersion)
 }
@@ -1,117 +1,111 @@
 package cmd
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
 )
 
 func TestProjectCommands(t *testing.T) {
-	// Create a test server that returns mock responses
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method + " " + r.URL.Path {
-		case "POST /projects":
-			// Read and validate request body
-			body, err := io.ReadAll(r.Body)
-			if err != nil {
-				t.Logf("error reading body: %v", err)
-				http.Error(w, "error reading body", http.StatusBadRequest)
-				return
-			}
-
-			var req struct {
-				Owner     string `json:"owner"`
-				Name      string `json:"name"`
-				DbType    string `json:"dbType"`
-				DbVersion string `json:"dbVersion"`
-			}
-			if err := json.Unmarshal(body, &req); err != nil {
-				t.Logf("invalid json: %v, body: %s", err, string(body))
-				http.Error(w, "invalid json", http.StatusBadRequest)
-				return
-			}
-
-			// Validate required fields
-			if req.Owner == "" || req.Name == "" || req.DbType == "" || req.DbVersion == "" {
-				t.Logf("missing required fields, request: %+v", req)
-				http.Error(w, "missing required fields", http.StatusBadRequest)
-				return
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusCreated)
-			w.Write([]byte(`{
-				"id": "proj-123",
-				"owner": "testuser",
-				"name": "testproject",
-				"dbType": "postgres",
-				"dbVersion": "15.3",
-				"backupLocation": "",
-				"defaultCredentials": {
-					"username": "devdb",
-					"password": "generated-password",
-					"database": "testproject"
-				}
-			}`))
-		case "GET /projects":
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(`[
-				{
-					"id": "proj-123",
-					"owner": "testuser",
-					"name": "testproject",
-					"dbType": "postgres",
-					"dbVersion": "15.3",
-					"backupLocation": "",
-					"databases": []
-				}
-			]`))
-		case "DELETE /projects/testproject":
-			w.WriteHeader(http.StatusOK)
-		default:
-			http.Error(w, "not found", http.StatusNotFound)
-		}
-	}))
-	defer ts.Close()
-
-	// Store the original apiURL and restore it after tests
-	originalURL := apiURL
-	defer func() { apiURL = originalURL }()
-	apiURL = ts.URL
+	fake, testProject := newFakeAPI(t)
 
-	tests := []cmdTestCase{
-		{
-			name: "create project",
-			cmd:  projectCreateCmd,
-			args: []string{"testproject", "--type", "postgres", "--version", "15.3"},
-			wantOutput: `Project created successfully
+	output := executeCommand(t, cmdTestCase{
+		name: "create project",
+		cmd:  projectCreateCmd,
+		args: []string{"billing", "--type", "postgres", "--version", "15.3"},
+	})
+	project := fakeProject(t, fake, "billing")
+	owner := testProject.Owner
+	if want := `Project created successfully
 Details:
-  ID: proj-123
-  Name: testproject
-  Owner: testuser
+  ID: ` + project.Id + `
+  Name: billing
+  Owner: ` + owner + `
   DbType: postgres
   DbVersion: 15.3
-`,
-		},
+`; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	// The column widths depend on the owner, so only the cells are compared
+	wide := executeCommand(t, cmdTestCase{
+		name: "list projects as wide table",
+		cmd:  projectListCmd,
+		args: []string{"-o", "wide"},
+	})
+	wantRows := []string{
+		"NAME ID OWNER TYPE VERSION BACKUP DATABASES",
+		"billing " + project.Id + " " + owner + " postgres 15.3 <none> 0",
+		"testproject testproject " + owner + " postgres 16 <none> 0",
+	}
+	rows := strings.Split(strings.TrimSuffix(wide, "\n"), "\n")
+	for i, row := range rows {
+		rows[i] = strings.Join(strings.Fields(row), " ")
+	}
+	if strings.Join(rows, "\n") != strings.Join(wantRows, "\n") {
+		t.Errorf("wide table:\n%s\nwant the rows\n%s", wide, strings.Join(wantRows, "\n"))
+	}
+
+	tests := []cmdTestCase{
 		{
 			name: "list projects",
 			cmd:  projectListCmd,
 			args: []string{},
 			wantOutput: `Projects:
-- testproject (ID: proj-123)
-  Owner: testuser
+- billing (ID: ` + project.Id + `)
+  Owner: ` + owner + `
   DbType: postgres
   DbVersion: 15.3
+- testproject (ID: testproject)
+  Owner: ` + owner + `
+  DbType: postgres
+  DbVersion: 16
+`,
+		},
+		{
+			name: "list projects as yaml",
+			cmd:  projectListCmd,
+			args: []string{"-o", "yaml"},
+			wantOutput: `- backupLocation: ""
+  dataVersion: 1
+  dbType: postgres
+  dbVersion: "15.3"
+  defaultCredentials:
+    database: ""
+    password: ""
+    username: ""
+  id: ` + project.Id + `
+  name: billing
+  owner: ` + owner + `
+- backupLocation: ""
+  dataVersion: 1
+  dbType: postgres
+  dbVersion: "16"
+  defaultCredentials:
+    database: ""
+    password: ""
+    username: ""
+  id: testproject
+  name: testproject
+  owner: ` + owner + `
 `,
 		},
+		{
+			name:    "list projects with unknown output format",
+			cmd:     projectListCmd,
+			args:    []string{"-o", "xml"},
+			wantErr: true,
+		},
 		{
 			name: "delete project",
 			cmd:  projectDeleteCmd,
-			args: []string{"testproject"},
-			wantOutput: "Project testproject deleted successfully\n",
+			args: []string{"billing"},
+			wantOutput: "Project billing deleted successfully\n",
 		},
 	}
 
@@ -150,9 +144,10 @@ func TestProjectClientErrors(t *testing.T) {
 			wantOutput: "Error: API returned status code 500\n",
 		},
 		{
+			// A UUID needs no lookup, so the delete itself fails
 			name: "delete project server error",
 			cmd:  projectDeleteCmd,
-			args: []string{"testproject"},
+			args: []string{"0b8f5a7e-3c1d-4e2f-9a6b-7c8d9e0f1a2b"},
 			wantErr: true,
 			wantOutput: "Error: API returned status code 500\n",
 		},
@@ -164,3 +159,107 @@ func TestProjectClientErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectNameResolution(t *testing.T) {
+	fake, testProject := newFakeAPI(t)
+	fake.AddProject(api.Project{Id: "proj-1", Owner: testProject.Owner, Name: "billing", DbVersion: "15.3"})
+	fake.AddProject(api.Project{Id: "proj-2", Owner: testProject.Owner, Name: "search", DbVersion: "15.3"})
+	fake.AddProject(api.Project{Id: "proj-3", Owner: testProject.Owner, Name: "search", DbVersion: "16.1"})
+
+	tests := []cmdTestCase{
+		{
+			name:       "show project by name",
+			cmd:        projectShowCmd,
+			args:       []string{"billing", "-o", "jsonpath={.id}"},
+			wantOutput: "proj-1\n",
+		},
+		{
+			name:       "create database in project by name",
+			cmd:        dbCreateCmd,
+			args:       []string{"mydb", "--project", "billing", "-o", "jsonpath={.name}"},
+			wantOutput: "mydb\n",
+		},
+		{
+			name:    "ambiguous project name",
+			cmd:     projectShowCmd,
+			args:    []string{"search"},
+			wantErr: true,
+			wantOutput: `Error: project name "search" is ambiguous, use one of these IDs instead:
+  proj-2 (owner ` + testProject.Owner + `)
+  proj-3 (owner ` + testProject.Owner + `)
+`,
+		},
+		{
+			name:       "default project by name",
+			cmd:        projectUseCmd,
+			args:       []string{"billing"},
+			wantOutput: "Default project for context default set to proj-1\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
+
+func TestProjectRefresh(t *testing.T) {
+	fake, testProject := newFakeAPI(t)
+	fake.AddProject(api.Project{Id: "proj-1", Owner: testProject.Owner, Name: "billing", BackupLocation: "s3://backups/billing.dump"})
+	fake.AddProject(api.Project{Id: "proj-2", Owner: testProject.Owner, Name: "search"})
+
+	dump := filepath.Join(t.TempDir(), "billing-2.sql")
+	if err := os.WriteFile(dump, []byte("--\n-- PostgreSQL database dump\n--\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []cmdTestCase{
+		{
+			name: "same backup",
+			cmd:  projectRefreshCmd,
+			args: []string{"billing"},
+			wantOutput: `Project billing refreshed from s3://backups/billing.dump
+Data version: 1 -> 2
+New databases get the new data; existing databases keep theirs until they are reset.
+`,
+		},
+		{
+			name:       "new S3 backup",
+			cmd:        projectRefreshCmd,
+			args:       []string{"billing", "--backup", "s3://backups/billing-2.dump", "-o", "jsonpath={.previousDataVersion} {.dataVersion} {.project.backupLocation}"},
+			wantOutput: "2 3 s3://backups/billing-2.dump\n",
+		},
+		{
+			name:    "no backup to refresh from",
+			cmd:     projectRefreshCmd,
+			args:    []string{"search"},
+			wantErr: true,
+		},
+		{
+			name:    "missing project",
+			cmd:     projectRefreshCmd,
+			args:    []string{"missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+
+	output := executeCommand(t, cmdTestCase{
+		name: "uploaded backup",
+		cmd:  projectRefreshCmd,
+		args: []string{"billing", "--backup", dump},
+	})
+	if !strings.Contains(output, "Uploaded billing-2.sql") || !strings.Contains(output, "Data version: 3 -> 4\n") {
+		t.Errorf("output = %q, want an upload and version 3 -> 4", output)
+	}
+	project := fakeProject(t, fake, "billing")
+	if data, ok := fake.Upload(project.BackupLocation); !ok || !strings.Contains(string(data), "PostgreSQL database dump") {
+		t.Errorf("project backup %s was not uploaded", project.BackupLocation)
+	}
+}
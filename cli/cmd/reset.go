@@ -0,0 +1,80 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/wait"
+    "github.com/spf13/cobra"
+)
+
+var (
+    dbResetYes  bool // Yes flag for the reset command
+    dbResetWait bool // Wait flag for the reset command
+)
+
+var dbResetCmd = &cobra.Command{
+    Use:   "reset [name]",
+    Short: "Reset a database to its project's pristine data",
+    Long: `Recreate a database in place from the project's latest snapshot or
+backup. The database keeps its name and endpoint, so connection strings
+stay valid, but every change made to it is lost. Its snapshots are kept.
+
+The command asks for confirmation unless --yes is given. With --wait, it
+blocks until the database is running again.`,
+    Example: `  # Start over after a migration went wrong
+  devdb db reset mydb
+
+  # In scripts
+  devdb db reset mydb --yes --wait`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        ctx := context.Background()
+
+        if !dbResetYes {
+            ok, err := confirm(cmd, fmt.Sprintf("Reset database %s? All changes made to it will be lost.", name), "--yes")
+            if err != nil {
+                return err
+            }
+            if !ok {
+                return errAborted
+            }
+        }
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        resp, err := client.PostProjectsProjectIdDatabasesNameResetWithResponse(ctx, project, name)
+        if err != nil {
+            return fmt.Errorf("resetting database: %w", err)
+        }
+
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        db := resp.JSON200
+        if dbResetWait {
+            db, err = waitForDatabase(cmd, client, name, api.Running, wait.Options{Probe: waitProbe})
+            if err != nil {
+                return err
+            }
+        }
+        return printResult(cmd, databaseOutput(*db), func() {
+            cmd.Printf("Database %s reset (Status: %s)\n", db.Name, db.Status)
+        })
+    },
+}
+
+func init() {
+    dbCmd.AddCommand(dbResetCmd)
+
+    dbResetCmd.Flags().BoolVarP(&dbResetYes, "yes", "y", false, "Reset without asking for confirmation")
+    dbResetCmd.Flags().BoolVar(&dbResetWait, "wait", false, "Wait until the database is running again")
+    addWaitFlags(dbResetCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestDatabaseReset(t *testing.T) {
+	fake, project := newFakeAPI(t)
+	fake.AddDatabase(project.Id, api.Database{Name: "mydb"})
+
+	originalInterval := waitInterval
+	defer func() { waitInterval = originalInterval }()
+	waitInterval = time.Millisecond
+
+	tests := []cmdTestCase{
+		{
+			name:       "reset with --yes",
+			cmd:        dbResetCmd,
+			args:       []string{"mydb", "--project", "testproject", "--yes"},
+			wantOutput: "Database mydb reset (Status: creating)\n",
+		},
+		{
+			name:       "reset after confirmation",
+			cmd:        dbResetCmd,
+			args:       []string{"mydb", "--project", "testproject"},
+			stdin:      "y\n",
+			wantOutput: "Reset database mydb? All changes made to it will be lost. [y/N]: Database mydb reset (Status: creating)\n",
+		},
+		{
+			name:       "confirmation declined",
+			cmd:        dbResetCmd,
+			args:       []string{"mydb", "--project", "testproject"},
+			stdin:      "n\n",
+			wantErr:    true,
+			wantOutput: "Reset database mydb? All changes made to it will be lost. [y/N]: Error: aborted\n",
+		},
+		{
+			name:    "no answer",
+			cmd:     dbResetCmd,
+			args:    []string{"mydb", "--project", "testproject"},
+			wantErr: true,
+		},
+		{
+			name: "reset and wait",
+			cmd:  dbResetCmd,
+			args: []string{"mydb", "--project", "testproject", "-y", "--wait"},
+			wantOutput: `Waiting for database mydb to be running: running
+Database mydb reset (Status: running)
+`,
+		},
+		{
+			name:    "missing database",
+			cmd:     dbResetCmd,
+			args:    []string{"missing", "--project", "testproject", "--yes"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
@@ -4,14 +4,28 @@ import (
     "os"
     "fmt"
 
+    "github.com/meido-ai/devdb/cli/pkg/config"
+    "github.com/meido-ai/devdb/cli/pkg/output"
     "github.com/spf13/cobra"
     "github.com/spf13/viper"
 )
 
+const defaultAPIURL = "http://localhost:5000"
+
+var (
+    apiURL      string
+    cfgFile     string
+    contextName string // Value of the global --context flag
+    Version     string // This will be set by -ldflags during build
+)
+
+// The context selected by --context or current-context, if any. A
+// configuration or selection error is kept in contextErr and reported by
+// commands that need the API rather than by every command.
 var (
-    apiURL  string
-    cfgFile string
-    Version string // This will be set by -ldflags during build
+    activeContext     *config.Context
+    activeContextName string
+    contextErr        error
 )
 
 var rootCmd = &cobra.Command{
@@ -21,12 +35,23 @@ var rootCmd = &cobra.Command{
 It allows you to create, manage, and share databases from backups,
 without needing to know Kubernetes or infrastructure details.`,
     Version: Version,
+    PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+        return checkOutputFlag()
+    },
+}
+
+// checkOutputFlag rejects a bad --output value before any API call is made.
+// Commands with their own PersistentPreRunE must call it, since cobra only
+// runs the closest one.
+func checkOutputFlag() error {
+    _, err := output.Parse(outputFormat)
+    return err
 }
 
 func Execute() {
     if err := rootCmd.Execute(); err != nil {
         fmt.Println(err)
-        os.Exit(1)
+        os.Exit(exitCode(err))
     }
 }
 
@@ -35,37 +60,48 @@ func init() {
 
     // Global flags
     rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.devdb.yaml)")
-    rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "DevDB API URL")
-    
-    // Bind flags to viper
-    viper.BindPFlag("api.url", rootCmd.PersistentFlags().Lookup("api-url"))
+    rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "DevDB API URL (overrides the context)")
+    rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Context to use instead of the current context")
+    rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Output format: "+output.Formats)
+    rootCmd.PersistentFlags().BoolVar(&allowMissingKeys, "allow-missing-template-keys", true, "Print fields missing from jsonpath and go-template output as empty, as kubectl does; set to false to fail on them instead, e.g. to catch typos")
 }
 
-func initConfig() {
+// configPath returns the configuration file in use.
+func configPath() (string, error) {
     if cfgFile != "" {
-        viper.SetConfigFile(cfgFile)
-    } else {
-        home, err := os.UserHomeDir()
-        if err != nil {
-            fmt.Println(err)
-            os.Exit(1)
-        }
-
-        // Search for config in home directory
-        viper.AddConfigPath(home)
-        viper.SetConfigType("yaml")
-        viper.SetConfigName(".devdb")
+        return cfgFile, nil
+    }
+    return config.DefaultPath()
+}
+
+func initConfig() {
+    path, err := configPath()
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(exitCode(err))
     }
 
-    // Read config
-    if err := viper.ReadInConfig(); err == nil {
-        if apiURL == "" {
-            apiURL = viper.GetString("api.url")
-        }
+    // Settings outside of contexts (e.g. connect.client) are read through viper
+    viper.SetConfigFile(path)
+    viper.SetConfigType("yaml")
+    viper.ReadInConfig()
+
+    activeContext, activeContextName, contextErr = nil, "", nil
+    if cfg, err := config.Load(path); err != nil {
+        contextErr = err
+    } else if name, ctx, err := cfg.Current(contextName); err == nil {
+        activeContext, activeContextName = ctx, name
+    } else if contextName != "" {
+        contextErr = err
+    }
+
+    // --api-url wins over the context
+    if apiURL == "" && activeContext != nil {
+        apiURL = activeContext.APIURL
     }
 
     // Set defaults if not configured
     if apiURL == "" {
-        apiURL = "http://localhost:5000"
+        apiURL = defaultAPIURL
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "os/user"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/config"
+)
+
+// projectEnvVar selects the project when --project is not given.
+const projectEnvVar = "DEVDB_PROJECT"
+
+// getwd is replaced in tests to control where the repo-local file is
+// searched for.
+var getwd = os.Getwd
+
+// projectSource is one place the project can be selected from.
+type projectSource struct {
+    Name  string
+    Value string
+}
+
+// projectSources returns every place the project can be selected from,
+// highest precedence first: the --project flag (given as flag), DEVDB_PROJECT,
+// the repo-local .devdb.yaml and the default project of the active context.
+func projectSources(flag string) ([]projectSource, error) {
+    sources := []projectSource{
+        {Name: "--project flag", Value: flag},
+        {Name: projectEnvVar, Value: os.Getenv(projectEnvVar)},
+    }
+
+    local := projectSource{Name: config.LocalFileName}
+    dir, err := getwd()
+    if err != nil {
+        return nil, err
+    }
+    global, err := configPath()
+    if err != nil {
+        return nil, err
+    }
+    path, file, err := config.FindLocal(dir, global)
+    if err != nil {
+        return nil, err
+    }
+    if path != "" {
+        local = projectSource{Name: path, Value: file.Project}
+    }
+    sources = append(sources, local)
+
+    contextSource := projectSource{Name: "context"}
+    if activeContext != nil {
+        contextSource = projectSource{Name: "context " + activeContextName, Value: activeContext.Project}
+    }
+    return append(sources, contextSource), nil
+}
+
+// selectProject returns the project to use and where it was selected from.
+func selectProject(flag string) (string, string, error) {
+    sources, err := projectSources(flag)
+    if err != nil {
+        return "", "", err
+    }
+    for _, s := range sources {
+        if s.Value != "" {
+            return s.Value, s.Name, nil
+        }
+    }
+    return "", "", fmt.Errorf("no project selected: use --project, set %s, add a project to %s or run \"devdb project use <id>\"",
+        projectEnvVar, config.LocalFileName)
+}
+
+// resolveProject turns a project ID or name into an ID. Names are looked up
+// among the current user's projects.
+func resolveProject(ctx context.Context, client api.ClientWithResponsesInterface, ref string) (string, error) {
+    currentUser, err := user.Current()
+    if err != nil {
+        return "", fmt.Errorf("error getting current user: %v", err)
+    }
+    return api.ResolveProject(ctx, client, currentUser.Username, ref)
+}
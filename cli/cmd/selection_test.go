@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/devdbfake"
+)
+
+// isolateProjectSelection makes sure the environment, a .devdb.yaml above
+// the test directory or the user's configuration cannot select a project.
+// It returns the directory used as the working directory.
+func isolateProjectSelection(t *testing.T) string {
+	t.Helper()
+	withConfigFile(t)
+	t.Setenv(projectEnvVar, "")
+
+	dir := t.TempDir()
+	originalGetwd := getwd
+	t.Cleanup(func() { getwd = originalGetwd })
+	getwd = func() (string, error) { return dir, nil }
+	return dir
+}
+
+// newFakeAPI points the CLI at a fake API holding a project "testproject"
+// of the current user, which only --project selects. Both are cleaned up
+// when the test ends.
+func newFakeAPI(t *testing.T) (*devdbfake.Server, api.Project) {
+	t.Helper()
+	isolateProjectSelection(t)
+
+	currentUser, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := devdbfake.NewServer(devdbfake.Options{})
+	t.Cleanup(fake.Close)
+	project := fake.AddProject(api.Project{Id: "testproject", Owner: currentUser.Username, Name: "testproject"})
+
+	originalURL := apiURL
+	t.Cleanup(func() { apiURL = originalURL })
+	apiURL = fake.URL
+	return fake, project
+}
+
+// fakeProject returns the project of the fake API with the given name.
+func fakeProject(t *testing.T, fake *devdbfake.Server, name string) api.Project {
+	t.Helper()
+	for _, p := range fake.Projects() {
+		if p.Name == name {
+			return p
+		}
+	}
+	t.Fatalf("the fake API has no project %s", name)
+	return api.Project{}
+}
+
+func TestProjectSelection(t *testing.T) {
+	dir := isolateProjectSelection(t)
+
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	originalURL := apiURL
+	defer func() { apiURL = originalURL }()
+	apiURL = ts.URL
+
+	list := func(t *testing.T, wantPath string, args ...string) {
+		t.Helper()
+		gotPath = ""
+		executeCommand(t, cmdTestCase{name: "list", cmd: dbListCmd, args: args, wantOutput: "No databases found\n"})
+		if gotPath != wantPath {
+			t.Errorf("requested %q, want %q", gotPath, wantPath)
+		}
+	}
+
+	executeCommand(t, cmdTestCase{
+		name:       "set default project",
+		cmd:        projectUseCmd,
+		args:       []string{"from-context"},
+		wantOutput: "Default project for context default set to from-context\n",
+	})
+	list(t, "/projects/from-context/databases")
+
+	nested := filepath.Join(dir, "services", "billing")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".devdb.yaml"), []byte("project: from-file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	getwd = func() (string, error) { return nested, nil }
+	list(t, "/projects/from-file/databases")
+
+	t.Setenv(projectEnvVar, "from-env")
+	list(t, "/projects/from-env/databases")
+
+	list(t, "/projects/from-flag/databases", "--project", "from-flag")
+
+	out := executeCommand(t, cmdTestCase{name: "view", cmd: configViewCmd})
+	for _, want := range []string{
+		"Context: default\n",
+		"Project: from-env (from DEVDB_PROJECT)\n",
+		"  1. --project flag: <none>\n",
+		"  2. DEVDB_PROJECT: from-env\n",
+		"  3. " + filepath.Join(dir, ".devdb.yaml") + ": from-file\n",
+		"  4. context default: from-context\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config view output missing %q:\n%s", want, out)
+		}
+	}
+}
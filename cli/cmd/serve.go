@@ -0,0 +1,199 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "os"
+    "os/signal"
+    "path/filepath"
+    "strings"
+    "syscall"
+    "time"
+
+    "github.com/meido-ai/devdb/cli/pkg/s3local"
+    "github.com/meido-ai/devdb/cli/pkg/server"
+    "github.com/spf13/cobra"
+)
+
+var (
+    serveAddr        string        // Address to listen on
+    serveData        string        // BoltDB file holding the server's state
+    serveProvisioner string        // Provisioner running the databases
+    serveTokens      []string      // Accepted API tokens
+    serveDatabases   string        // Data directories of the local provisioner
+    servePGBin       string        // Directory with the PostgreSQL server binaries
+    serveReapEvery   time.Duration // How often expired databases are reaped
+    serveExpired     string        // What happens to expired databases
+    serveUploads     string        // Directory keeping uploaded backups
+    servePublicURL   string        // URL clients reach the server at
+)
+
+// uploadsBucket is the bucket name of the backups uploaded to devdb serve.
+const uploadsBucket = "devdb-backups"
+
+var serveCmd = &cobra.Command{
+    Use:   "serve",
+    Short: "Run a DevDB API server",
+    Long: `Run a DevDB API server implementing the same OpenAPI spec as the
+TypeScript server, without Node, Redis or Kubernetes.
+
+State is kept in memory unless --data names a BoltDB file. Databases are
+run by a provisioner:
+  noop   runs nothing; databases are reported running at localhost, on
+         their engine's default port (5432 for postgres)
+  local  runs each database as a postgres process on this machine, with
+         its own data directory under --databases-dir and a free port.
+         It uses the locally installed PostgreSQL (initdb, postgres,
+         createdb, psql and pg_restore from --pg-bin or PATH), and like
+         postgres itself cannot run as root. It only runs postgres
+         projects of the installed version, and restores their backups
+         if they were uploaded to this server.
+
+Databases created with a time-to-live are deleted once it is up, or
+stopped with --expired-action stop, which leaves databases that are
+already stopped alone. The server checks for them every --reap-interval.
+
+Backups uploaded with devdb project create --backup are stored by an
+S3-compatible endpoint under /s3/, in memory or in --uploads-dir, and
+referred to as s3://devdb-backups/... locations. Upload URLs point at
+--public-url, by default the listen address with localhost for a wildcard
+host.
+
+API tokens come from --token and the comma-separated DEVDB_API_TOKENS
+environment variable. Without any, the server accepts unauthenticated
+requests.`,
+    Example: `  # Throwaway server for trying out the CLI
+  devdb serve
+
+  # Keep projects across restarts and require a token
+  devdb serve --addr :8080 --data ./devdb.db --token s3cret
+
+  # Run real databases with the PostgreSQL installed by Homebrew
+  devdb serve --data ./devdb.db --provisioner local --pg-bin /opt/homebrew/opt/postgresql@16/bin`,
+    Args: cobra.NoArgs,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        provisioner, err := newProvisioner(serveProvisioner)
+        if err != nil {
+            return err
+        }
+        expiredAction, err := server.ParseExpiredAction(serveExpired)
+        if err != nil {
+            return err
+        }
+        cmd.SilenceUsage = true
+        if closer, ok := provisioner.(io.Closer); ok {
+            defer closer.Close()
+        }
+
+        var store server.Store = server.NewMemoryStore()
+        if serveData != "" {
+            if store, err = server.OpenBoltStore(serveData); err != nil {
+                return fmt.Errorf("opening %s: %v", serveData, err)
+            }
+        }
+        defer store.Close()
+
+        tokens := serveTokens
+        for _, token := range strings.Split(os.Getenv("DEVDB_API_TOKENS"), ",") {
+            if token = strings.TrimSpace(token); token != "" {
+                tokens = append(tokens, token)
+            }
+        }
+        if len(tokens) == 0 {
+            fmt.Fprintln(cmd.ErrOrStderr(), "Warning: no API tokens configured, the API accepts unauthenticated requests")
+        }
+
+        listener, err := net.Listen("tcp", serveAddr)
+        if err != nil {
+            return err
+        }
+        // Presigned upload URLs authorize themselves, so the bucket is
+        // served next to the API rather than behind its authentication
+        uploads := s3local.New(uploadsBucket, serveUploads)
+        uploads.BaseURL = strings.TrimSuffix(publicURL(listener.Addr()), "/") + "/s3"
+        if local, ok := provisioner.(*server.LocalProvisioner); ok {
+            local.Backups = uploads
+        }
+        apiServer := server.New(store, provisioner, server.Options{Tokens: tokens, ExpiredAction: expiredAction, Uploader: uploads})
+        mux := http.NewServeMux()
+        mux.Handle("/s3/", http.StripPrefix("/s3", uploads))
+        mux.Handle("/", apiServer.Handler())
+        srv := &http.Server{
+            Handler:           mux,
+            ReadHeaderTimeout: 10 * time.Second,
+        }
+
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+        defer stop()
+        if serveReapEvery > 0 {
+            go apiServer.RunReaper(ctx, serveReapEvery)
+        }
+        go func() {
+            <-ctx.Done()
+            shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+            defer cancel()
+            srv.Shutdown(shutdownCtx)
+        }()
+
+        cmd.Printf("DevDB API listening on http://%s\n", listener.Addr())
+        if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+            return err
+        }
+        return nil
+    },
+}
+
+// publicURL returns the URL clients reach a server listening on addr at:
+// --public-url, or addr, which is not reachable when its host is a
+// wildcard such as [::], so localhost stands in for one.
+func publicURL(addr net.Addr) string {
+    if servePublicURL != "" {
+        return servePublicURL
+    }
+    host, port, err := net.SplitHostPort(addr.String())
+    if err != nil {
+        return "http://" + addr.String()
+    }
+    if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+        host = "localhost"
+    }
+    return "http://" + net.JoinHostPort(host, port)
+}
+
+// newProvisioner returns the provisioner selected with --provisioner.
+func newProvisioner(name string) (server.Provisioner, error) {
+    switch name {
+    case "noop":
+        return server.NoopProvisioner{}, nil
+    case "local":
+        dir := serveDatabases
+        if dir == "" {
+            home, err := os.UserHomeDir()
+            if err != nil {
+                return nil, fmt.Errorf("finding the databases directory: %v", err)
+            }
+            dir = filepath.Join(home, ".devdb", "databases")
+        }
+        return &server.LocalProvisioner{Dir: dir, BinDir: servePGBin}, nil
+    }
+    return nil, fmt.Errorf("unknown provisioner %q (want noop or local)", name)
+}
+
+func init() {
+    rootCmd.AddCommand(serveCmd)
+
+    serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:5000", "Address to listen on")
+    serveCmd.Flags().StringVar(&serveData, "data", "", "BoltDB file to keep projects and databases in (default in memory)")
+    serveCmd.Flags().StringVar(&serveProvisioner, "provisioner", "noop", "Provisioner running the databases: noop or local")
+    serveCmd.Flags().StringSliceVar(&serveTokens, "token", nil, "Accepted API token (repeatable)")
+    serveCmd.Flags().StringVar(&serveDatabases, "databases-dir", "", "Directory for the data of the local provisioner (default ~/.devdb/databases)")
+    serveCmd.Flags().DurationVar(&serveReapEvery, "reap-interval", time.Minute, "How often to look for expired databases (0 disables expiry)")
+    serveCmd.Flags().StringVar(&serveExpired, "expired-action", "delete", "What to do with expired databases: delete or stop")
+    serveCmd.Flags().StringVar(&serveUploads, "uploads-dir", "", "Directory to keep uploaded backups in (default in memory)")
+    serveCmd.Flags().StringVar(&servePublicURL, "public-url", "", "URL clients reach the server at, used in backup upload URLs (default from the listen address)")
+    serveCmd.Flags().StringVar(&servePGBin, "pg-bin", "", "Directory with the PostgreSQL binaries for the local provisioner (default from PATH)")
+}
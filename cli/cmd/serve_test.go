@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/server"
+)
+
+// TestServeEndToEnd runs the CLI against the Go server.
+func TestServeEndToEnd(t *testing.T) {
+	isolateProjectSelection(t)
+
+	ts := httptest.NewServer(server.New(server.NewMemoryStore(), server.NoopProvisioner{}, server.Options{}).Handler())
+	defer ts.Close()
+	apiURL = ts.URL
+
+	output := executeCommand(t, cmdTestCase{
+		name: "create project",
+		cmd:  projectCreateCmd,
+		args: []string{"billing", "--type", "postgres", "--version", "16"},
+	})
+	if !strings.Contains(output, "Project created successfully") {
+		t.Fatalf("unexpected output %q", output)
+	}
+
+	executeCommand(t, cmdTestCase{
+		name:    "create duplicate project",
+		cmd:     projectCreateCmd,
+		args:    []string{"billing", "--type", "postgres", "--version", "16"},
+		wantErr: true,
+	})
+
+	tests := []cmdTestCase{
+		{
+			name:       "create database",
+			cmd:        dbCreateCmd,
+			args:       []string{"dev", "--project", "billing", "--wait"},
+			wantOutput: "Waiting for database dev to be running: running\nDatabase created successfully\nDetails:\n  Name: dev\n  Status: running\n  Host: localhost\n  Port: 5432\n",
+		},
+		{
+			name:       "create database with an invalid name",
+			cmd:        dbCreateCmd,
+			args:       []string{"Dev_DB", "--project", "billing"},
+			wantErr:    true,
+			wantOutput: "Error: Bad Request: Name must consist of lower case letters, digits and '-', and start and end with a letter or digit\n",
+		},
+		{
+			name:       "list databases",
+			cmd:        dbListCmd,
+			args:       []string{"--project", "billing"},
+			wantOutput: "Databases:\n- dev (Status: running)\n  Host: localhost\n  Port: 5432\n",
+		},
+		{
+			name:       "show missing database",
+			cmd:        dbShowCmd,
+			args:       []string{"test", "--project", "billing"},
+			wantErr:    true,
+			wantOutput: "Error: Not Found: Database test not found\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+
+	// An open server hands out the credentials needed to connect
+	output = executeCommand(t, cmdTestCase{
+		name: "env",
+		cmd:  dbEnvCmd,
+		args: []string{"dev", "--project", "billing", "--format", "url"},
+	})
+	if !strings.HasPrefix(output, "postgres://devdb:") || !strings.HasSuffix(output, "@localhost:5432/devdb\n") {
+		t.Errorf("connection URL = %q", output)
+	}
+
+	tests = []cmdTestCase{
+		{
+			name:       "delete database",
+			cmd:        dbDeleteCmd,
+			args:       []string{"dev", "--project", "billing"},
+			wantOutput: "Database dev deleted successfully\n",
+		},
+		{
+			name:       "delete project",
+			cmd:        projectDeleteCmd,
+			args:       []string{"billing"},
+			wantOutput: "Project billing deleted successfully\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
+
+func TestNewProvisioner(t *testing.T) {
+	if _, err := newProvisioner("noop"); err != nil {
+		t.Errorf("newProvisioner(noop) error = %v", err)
+	}
+	p, err := newProvisioner("local")
+	if err != nil {
+		t.Errorf("newProvisioner(local) error = %v", err)
+	} else if local, ok := p.(*server.LocalProvisioner); !ok || !strings.HasSuffix(local.Dir, filepath.Join(".devdb", "databases")) {
+		t.Errorf("newProvisioner(local) = %#v, want a local provisioner under ~/.devdb/databases", p)
+	}
+	if _, err := newProvisioner("kubernetes"); err == nil {
+		t.Error("newProvisioner(kubernetes) should fail")
+	}
+}
+
+func TestServePublicURL(t *testing.T) {
+	tests := map[string]string{
+		"[::]:5000":      "http://localhost:5000",
+		"0.0.0.0:8080":   "http://localhost:8080",
+		"127.0.0.1:5000": "http://127.0.0.1:5000",
+		"[::1]:5000":     "http://[::1]:5000",
+	}
+	for addr, want := range tests {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := publicURL(tcpAddr); got != want {
+			t.Errorf("publicURL(%s) = %q, want %q", addr, got, want)
+		}
+	}
+
+	servePublicURL = "https://devdb.example.com"
+	defer func() { servePublicURL = "" }()
+	if got := publicURL(&net.TCPAddr{Port: 5000}); got != servePublicURL {
+		t.Errorf("publicURL with --public-url = %q", got)
+	}
+}
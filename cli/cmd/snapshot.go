@@ -0,0 +1,126 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/spf13/cobra"
+)
+
+var snapshotName string // Name flag for the snapshot create command
+
+var dbSnapshotCmd = &cobra.Command{
+    Use:   "snapshot",
+    Short: "Manage database snapshots",
+    Long: `Take, list and delete snapshots of a database.
+
+A snapshot is a point-in-time copy of a database's data. Take one before a
+risky migration and create a database from it with
+"devdb db create <name> --from-snapshot <snapshot>". Snapshot names are
+unique within a project. Deleting a database deletes its snapshots.`,
+}
+
+var dbSnapshotCreateCmd = &cobra.Command{
+    Use:   "create [database]",
+    Short: "Take a snapshot of a database",
+    Long: `Take a snapshot of a running database. Without --name, the snapshot is
+named after the database and the current time.`,
+    Example: `  devdb db snapshot create mydb --name before-migration`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        req := api.CreateSnapshotRequest{}
+        if snapshotName != "" {
+            req.Name = &snapshotName
+        }
+        resp, err := client.PostProjectsProjectIdDatabasesNameSnapshotsWithResponse(context.Background(), project, name, req)
+        if err != nil {
+            return fmt.Errorf("creating snapshot: %w", err)
+        }
+        if resp.StatusCode() != http.StatusCreated {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        snap := resp.JSON201
+        return printResult(cmd, snapshotOutput(*snap), func() {
+            cmd.Printf("Snapshot %s of database %s created (Status: %s)\n", snap.Name, snap.Database, snap.Status)
+        })
+    },
+}
+
+var dbSnapshotListCmd = &cobra.Command{
+    Use:   "list [database]",
+    Short: "List the snapshots of a database",
+    Args:  cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        resp, err := client.GetProjectsProjectIdDatabasesNameSnapshotsWithResponse(context.Background(), project, name)
+        if err != nil {
+            return fmt.Errorf("listing snapshots: %w", err)
+        }
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        var snapshots []api.Snapshot
+        if resp.JSON200 != nil {
+            snapshots = *resp.JSON200
+        }
+        return printResult(cmd, snapshotsOutput(snapshots), func() {
+            if len(snapshots) == 0 {
+                cmd.Printf("No snapshots of database %s found\n", name)
+                return
+            }
+            cmd.Printf("Snapshots of database %s:\n", name)
+            for _, snap := range snapshots {
+                cmd.Printf("- %s (Status: %s, created %s)\n", snap.Name, snap.Status, snap.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+            }
+        })
+    },
+}
+
+var dbSnapshotDeleteCmd = &cobra.Command{
+    Use:   "delete [database] [snapshot]",
+    Short: "Delete a snapshot",
+    Args:  cobra.ExactArgs(2),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name, snapshot := args[0], args[1]
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        resp, err := client.DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse(context.Background(), project, name, snapshot)
+        if err != nil {
+            return fmt.Errorf("deleting snapshot: %w", err)
+        }
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        return printResult(cmd, deleteResult{Name: snapshot, Status: "deleted"}, func() {
+            cmd.Printf("Snapshot %s deleted successfully\n", snapshot)
+        })
+    },
+}
+
+func init() {
+    dbCmd.AddCommand(dbSnapshotCmd)
+    dbSnapshotCmd.AddCommand(dbSnapshotCreateCmd)
+    dbSnapshotCmd.AddCommand(dbSnapshotListCmd)
+    dbSnapshotCmd.AddCommand(dbSnapshotDeleteCmd)
+
+    dbSnapshotCreateCmd.Flags().StringVar(&snapshotName, "name", "", "Name of the snapshot (default <database>-<time>)")
+}
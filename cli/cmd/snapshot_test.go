@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestDatabaseSnapshots(t *testing.T) {
+	fake, project := newFakeAPI(t)
+	fake.AddDatabase(project.Id, api.Database{Name: "mydb"})
+
+	tests := []cmdTestCase{
+		{
+			name:       "create snapshot",
+			cmd:        dbSnapshotCreateCmd,
+			args:       []string{"mydb", "--project", "testproject", "--name", "before-migration"},
+			wantOutput: "Snapshot before-migration of database mydb created (Status: ready)\n",
+		},
+		{
+			name:    "duplicate snapshot",
+			cmd:     dbSnapshotCreateCmd,
+			args:    []string{"mydb", "--project", "testproject", "--name", "before-migration"},
+			wantErr: true,
+		},
+		{
+			name:    "snapshot of missing database",
+			cmd:     dbSnapshotCreateCmd,
+			args:    []string{"missing", "--project", "testproject"},
+			wantErr: true,
+		},
+		{
+			name: "create from snapshot",
+			cmd:  dbCreateCmd,
+			args: []string{"restored", "--project", "testproject", "--from-snapshot", "before-migration"},
+		},
+		{
+			name: "create from database",
+			cmd:  dbCreateCmd,
+			args: []string{"forked", "--project", "testproject", "--from-db", "mydb"},
+		},
+		{
+			name:       "no snapshots",
+			cmd:        dbSnapshotListCmd,
+			args:       []string{"forked", "--project", "testproject"},
+			wantOutput: "No snapshots of database forked found\n",
+		},
+		{
+			name:    "create from missing snapshot",
+			cmd:     dbCreateCmd,
+			args:    []string{"nope", "--project", "testproject", "--from-snapshot", "missing"},
+			wantErr: true,
+		},
+		{
+			name:    "from-db and from-snapshot are exclusive",
+			cmd:     dbCreateCmd,
+			args:    []string{"nope", "--project", "testproject", "--from-db", "mydb", "--from-snapshot", "before-migration"},
+			wantErr: true,
+		},
+		{
+			name:       "delete snapshot",
+			cmd:        dbSnapshotDeleteCmd,
+			args:       []string{"mydb", "before-migration", "--project", "testproject"},
+			wantOutput: "Snapshot before-migration deleted successfully\n",
+		},
+		{
+			name:    "delete missing snapshot",
+			cmd:     dbSnapshotDeleteCmd,
+			args:    []string{"mydb", "before-migration", "--project", "testproject"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+
+	for _, name := range []string{"restored", "forked"} {
+		found := false
+		for _, db := range fake.Databases(project.Id) {
+			found = found || db.Name == name
+		}
+		if !found {
+			t.Errorf("database %s was not created", name)
+		}
+	}
+}
+
+func TestDatabaseSnapshotList(t *testing.T) {
+	fake, project := newFakeAPI(t)
+	fake.AddDatabase(project.Id, api.Database{Name: "mydb"})
+
+	executeCommand(t, cmdTestCase{
+		name: "create snapshot",
+		cmd:  dbSnapshotCreateCmd,
+		args: []string{"mydb", "--project", "testproject", "--name", "s1"},
+	})
+
+	out := executeCommand(t, cmdTestCase{
+		name: "list snapshots",
+		cmd:  dbSnapshotListCmd,
+		args: []string{"mydb", "--project", "testproject"},
+	})
+	if !strings.HasPrefix(out, "Snapshots of database mydb:\n- s1 (Status: ready, created ") {
+		t.Errorf("unexpected list output %q", out)
+	}
+
+	out = executeCommand(t, cmdTestCase{
+		name: "list snapshots as table",
+		cmd:  dbSnapshotListCmd,
+		args: []string{"mydb", "--project", "testproject", "-o", "wide"},
+	})
+	if !strings.Contains(out, "SIZE") || !strings.Contains(out, "s1") {
+		t.Errorf("unexpected table output %q", out)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                  "0 B",
+		1023:               "1023 B",
+		1024:               "1.0 KiB",
+		1536 * 1024 * 1024: "1.5 GiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
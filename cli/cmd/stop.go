@@ -0,0 +1,88 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/wait"
+    "github.com/spf13/cobra"
+)
+
+var dbStartWait bool // Wait flag for the start command
+
+var dbStopCmd = &cobra.Command{
+    Use:   "stop [name]",
+    Short: "Stop a database to save resources",
+    Long: `Stop a database while keeping its data. A stopped database uses no
+compute until it is started again with "devdb db start", and keeps its name
+and endpoint. "devdb db list" shows how long databases have been idle.`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        resp, err := client.PostProjectsProjectIdDatabasesNameStopWithResponse(context.Background(), project, name, api.StopDatabaseRequest{})
+        if err != nil {
+            return fmt.Errorf("stopping database: %w", err)
+        }
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        db := resp.JSON200
+        return printResult(cmd, databaseOutput(*db), func() {
+            cmd.Printf("Database %s stopped\n", db.Name)
+        })
+    },
+}
+
+var dbStartCmd = &cobra.Command{
+    Use:   "start [name]",
+    Short: "Start a stopped database",
+    Long: `Start a database stopped with "devdb db stop". It comes back with the
+data it had when it was stopped. With --wait, the command blocks until the
+database is running, and with --probe also until it accepts connections on
+its host and port, which must be reachable from this machine.`,
+    Example: `  devdb db start mydb --wait
+  devdb db start mydb --wait --probe`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        resp, err := client.PostProjectsProjectIdDatabasesNameStartWithResponse(context.Background(), project, name)
+        if err != nil {
+            return fmt.Errorf("starting database: %w", err)
+        }
+        if resp.StatusCode() != http.StatusOK {
+            return api.NewError(resp.HTTPResponse, resp.Body)
+        }
+
+        db := resp.JSON200
+        if dbStartWait {
+            db, err = waitForDatabase(cmd, client, name, api.Running, wait.Options{Probe: waitProbe})
+            if err != nil {
+                return err
+            }
+        }
+        return printResult(cmd, databaseOutput(*db), func() {
+            cmd.Printf("Database %s started (Status: %s)\n", db.Name, db.Status)
+        })
+    },
+}
+
+func init() {
+    dbCmd.AddCommand(dbStopCmd)
+    dbCmd.AddCommand(dbStartCmd)
+
+    dbStartCmd.Flags().BoolVar(&dbStartWait, "wait", false, "Wait until the database is running")
+    addWaitFlags(dbStartCmd)
+}
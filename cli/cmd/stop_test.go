@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestDatabaseStopStart(t *testing.T) {
+	fake, project := newFakeAPI(t)
+
+	// start --wait --probe checks that the database accepts connections,
+	// so answer the probe's SSL request like a postgres without SSL
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			io.ReadFull(conn, make([]byte, 8))
+			conn.Write([]byte("N"))
+			conn.Close()
+		}
+	}()
+	host, port := "127.0.0.1", listener.Addr().(*net.TCPAddr).Port
+
+	fake.AddDatabase(project.Id, api.Database{Name: "mydb", Host: &host, Port: &port})
+	fake.AddDatabase(project.Id, api.Database{Name: "new", Status: api.Creating})
+
+	// A database whose host cannot be reached from here, like the pod
+	// addresses of a Kubernetes server
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+	fake.AddDatabase(project.Id, api.Database{Name: "remote", Status: api.Stopped, Host: &host, Port: &closedPort})
+	fake.StickCreating("new")
+
+	originalInterval := waitInterval
+	defer func() { waitInterval = originalInterval }()
+	waitInterval = time.Millisecond
+
+	tests := []cmdTestCase{
+		{
+			name:       "stop",
+			cmd:        dbStopCmd,
+			args:       []string{"mydb", "--project", "testproject"},
+			wantOutput: "Database mydb stopped\n",
+		},
+		{
+			name:       "stop is idempotent",
+			cmd:        dbStopCmd,
+			args:       []string{"mydb", "--project", "testproject"},
+			wantOutput: "Database mydb stopped\n",
+		},
+		{
+			name:    "stop while creating",
+			cmd:     dbStopCmd,
+			args:    []string{"new", "--project", "testproject"},
+			wantErr: true,
+		},
+		{
+			name: "list shows idle databases",
+			cmd:  dbListCmd,
+			args: []string{"--project", "testproject"},
+			wantOutput: `Databases:
+- mydb (Status: stopped, idle for 0s)
+  Host: 127.0.0.1
+  Port: ` + strconv.Itoa(port) + `
+- new (Status: creating)
+- remote (Status: stopped)
+  Host: 127.0.0.1
+  Port: ` + strconv.Itoa(closedPort) + `
+`,
+		},
+		{
+			name: "start and wait",
+			cmd:  dbStartCmd,
+			args: []string{"mydb", "--project", "testproject", "--wait", "--probe"},
+			wantOutput: `Waiting for database mydb to be running: running
+Database mydb started (Status: running)
+`,
+		},
+		{
+			name: "start and wait without probing",
+			cmd:  dbStartCmd,
+			args: []string{"remote", "--project", "testproject", "--wait", "--timeout", "2s"},
+			wantOutput: `Waiting for database remote to be running: running
+Database remote started (Status: running)
+`,
+		},
+		{
+			name:    "start missing database",
+			cmd:     dbStartCmd,
+			args:    []string{"missing", "--project", "testproject"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+	if waitProbe {
+		t.Error("start --wait left --probe set for the commands run after it")
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		-time.Second:     "0s",
+		45 * time.Second: "45s",
+		12 * time.Minute: "12m",
+		3 * time.Hour:    "3h",
+		47 * time.Hour:   "47h",
+		72 * time.Hour:   "3d",
+	}
+	for d, want := range tests {
+		if got := humanDuration(d); got != want {
+			t.Errorf("humanDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -2,21 +2,62 @@ package cmd
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// testProjectsResponse is the project listing served by mock servers so
+// that --project testproject resolves to itself.
+const testProjectsResponse = `[{"id": "testproject", "owner": "testuser", "name": "testproject", "dbType": "postgres", "dbVersion": "15.3"}]`
+
 type cmdTestCase struct {
 	name        string
 	cmd         *cobra.Command
 	args        []string
+	stdin       string
 	wantErr     bool
+	wantExit    int // exit code the error maps to, checked when set
 	wantOutput  string
 	setupMock   func()
 	teardownMock func()
 }
 
+// freshCommand copies the parts of c that matter for execution into a new
+// command so it can be attached to a test root. Flags are shared with c but
+// reset to their defaults, so each test case starts from a clean state.
+func freshCommand(c *cobra.Command) *cobra.Command {
+	fresh := &cobra.Command{
+		Use:               c.Use,
+		Aliases:           c.Aliases,
+		Short:             c.Short,
+		Long:              c.Long,
+		Args:              c.Args,
+		RunE:              c.RunE,
+		PersistentPreRunE: c.PersistentPreRunE,
+	}
+	c.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		resetFlag(f)
+		fresh.Flags().AddFlag(f)
+	})
+	c.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		resetFlag(f)
+		fresh.PersistentFlags().AddFlag(f)
+	})
+	return fresh
+}
+
+func resetFlag(f *pflag.Flag) {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		sv.Replace(nil)
+	} else {
+		f.Value.Set(f.DefValue)
+	}
+	f.Changed = false
+}
+
 func executeCommand(t *testing.T, tc cmdTestCase) string {
 	t.Helper()
 
@@ -31,124 +72,33 @@ func executeCommand(t *testing.T, tc cmdTestCase) string {
 	// Create a new root command for testing
 	testRoot := &cobra.Command{Use: "devdb"}
 	testRoot.SilenceUsage = true  // Don't show usage on errors
+	testRoot.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Output format")
+	testRoot.PersistentFlags().BoolVar(&allowMissingKeys, "allow-missing-template-keys", true, "Allow missing template keys")
+	testRoot.PersistentFlags().StringVar(&contextName, "context", "", "Context to use")
+
+	// Rebuild the command's ancestry (e.g. "db snapshot create") from fresh
+	// copies so the real command tree is left untouched
+	var chain []*cobra.Command
+	for c := tc.cmd; c != nil && c != rootCmd; c = c.Parent() {
+		chain = append([]*cobra.Command{c}, chain...)
+	}
 
-	// Create fresh command instances for testing
-	if tc.cmd == dbCreateCmd || tc.cmd == dbListCmd || tc.cmd == dbDeleteCmd || tc.cmd == dbShowCmd {
-		// Create fresh db command tree
-		testDbCmd := &cobra.Command{
-			Use:   "db",
-			Short: "Manage databases",
-			Long:  dbCmd.Long,
-		}
-		testRoot.AddCommand(testDbCmd)
-
-		// Create a fresh command instance
-		var testCmd *cobra.Command
-		switch tc.cmd {
-		case dbCreateCmd:
-			testCmd = &cobra.Command{
-				Use:   "create [name]",
-				Short: dbCreateCmd.Short,
-				Long:  dbCreateCmd.Long,
-				Args:  cobra.ExactArgs(1),
-				RunE:  dbCreateCmd.RunE,
-			}
-		case dbListCmd:
-			testCmd = &cobra.Command{
-				Use:   "list",
-				Short: dbListCmd.Short,
-				Long:  dbListCmd.Long,
-				RunE:  dbListCmd.RunE,
-			}
-		case dbShowCmd:
-			testCmd = &cobra.Command{
-				Use:   "show [name]",
-				Short: dbShowCmd.Short,
-				Long:  dbShowCmd.Long,
-				Args:  cobra.ExactArgs(1),
-				RunE:  dbShowCmd.RunE,
-			}
-		case dbDeleteCmd:
-			testCmd = &cobra.Command{
-				Use:   "delete [name]",
-				Short: dbDeleteCmd.Short,
-				Long:  dbDeleteCmd.Long,
-				Args:  cobra.ExactArgs(1),
-				RunE:  dbDeleteCmd.RunE,
-			}
-		}
-
-		// Add the command
-		testDbCmd.AddCommand(testCmd)
-
-		// Add project flag
-		testDbCmd.PersistentFlags().StringVar(&project, "project", "", "Project ID (required)")
-		testDbCmd.MarkPersistentFlagRequired("project")
-	} else if tc.cmd == projectCreateCmd || tc.cmd == projectListCmd || tc.cmd == projectDeleteCmd {
-		// Create fresh project command tree
-		testProjectCmd := &cobra.Command{
-			Use:   "project",
-			Short: "Manage projects",
-			Long:  projectCmd.Long,
-		}
-		testRoot.AddCommand(testProjectCmd)
-
-		// Create a fresh command instance
-		var testCmd *cobra.Command
-		switch tc.cmd {
-		case projectCreateCmd:
-			testCmd = &cobra.Command{
-				Use:   "create",
-				Short: projectCreateCmd.Short,
-				Long:  projectCreateCmd.Long,
-				RunE:  projectCreateCmd.RunE,
-			}
-			// Add flags to the test command instance
-			testCmd.Flags().StringVar(&projectOwner, "owner", "", "Owner of the project (defaults to current user)")
-			testCmd.Flags().StringVar(&projectType, "type", "", "Type of database (postgres or mysql)")
-			testCmd.Flags().StringVar(&projectVersion, "version", "", "Version of the database")
-			testCmd.MarkFlagRequired("type")
-			testCmd.MarkFlagRequired("version")
-		case projectListCmd:
-			testCmd = &cobra.Command{
-				Use:   "list",
-				Short: projectListCmd.Short,
-				Long:  projectListCmd.Long,
-				RunE:  projectListCmd.RunE,
-			}
-		case projectDeleteCmd:
-			testCmd = &cobra.Command{
-				Use:   "delete [id]",
-				Short: projectDeleteCmd.Short,
-				Long:  projectDeleteCmd.Long,
-				Args:  cobra.ExactArgs(1),
-				RunE:  projectDeleteCmd.RunE,
-			}
-		}
-
-		// Add the command
-		testProjectCmd.AddCommand(testCmd)
-	} else {
-		testRoot.AddCommand(tc.cmd)
+	var args []string
+	parent := testRoot
+	for _, c := range chain {
+		fresh := freshCommand(c)
+		parent.AddCommand(fresh)
+		parent = fresh
+		args = append(args, c.Name())
 	}
+	args = append(args, tc.args...)
 
 	// Set up output capture
 	buf := new(bytes.Buffer)
 	testRoot.SetOut(buf)
 	testRoot.SetErr(buf)
-
-	// Set args based on the command type
-	var args []string
-	if tc.cmd == dbCreateCmd || tc.cmd == dbListCmd || tc.cmd == dbDeleteCmd || tc.cmd == dbShowCmd {
-		args = append([]string{"db"}, tc.cmd.Name())
-		args = append(args, tc.args...)
-	} else if tc.cmd == projectCreateCmd || tc.cmd == projectListCmd || tc.cmd == projectDeleteCmd {
-		args = append([]string{"project"}, tc.cmd.Name())
-		args = append(args, tc.args...)
-	} else {
-		args = tc.args
-	}
 	testRoot.SetArgs(args)
+	testRoot.SetIn(strings.NewReader(tc.stdin))
 
 	err := testRoot.Execute()
 	output := buf.String()
@@ -162,6 +112,10 @@ func executeCommand(t *testing.T, tc cmdTestCase) string {
 		t.Errorf("command execution error = %v, wantErr %v", err, tc.wantErr)
 	}
 
+	if tc.wantExit != 0 && exitCode(err) != tc.wantExit {
+		t.Errorf("exit code = %d, want %d", exitCode(err), tc.wantExit)
+	}
+
 	if tc.wantOutput != "" && output != tc.wantOutput {
 		t.Errorf("output = %q, want %q", output, tc.wantOutput)
 	}
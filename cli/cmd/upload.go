@@ -0,0 +1,107 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/backup"
+    "github.com/meido-ai/devdb/cli/pkg/config"
+    "github.com/meido-ai/devdb/cli/pkg/masking"
+    "github.com/spf13/cobra"
+)
+
+// backupLocation returns the S3 URL of the backup given with --backup for
+// a project of type dbType. URLs are used as they are; local backups are
+// masked with policy, when there is one, and uploaded.
+func backupLocation(ctx context.Context, cmd *cobra.Command, client api.ClientWithResponsesInterface, path string, dbType api.DatabaseType, policy *masking.Policy) (string, error) {
+    if strings.HasPrefix(path, "s3://") {
+        if policy != nil {
+            return "", fmt.Errorf("cannot mask %s: masking policies are applied to local backups before they are uploaded", path)
+        }
+        return path, nil
+    }
+    if policy != nil {
+        if err := checkMaskable(dbType); err != nil {
+            return "", err
+        }
+        masked, cleanup, err := maskBackup(cmd, policy, path)
+        if err != nil {
+            return "", err
+        }
+        defer cleanup()
+        path = masked
+    }
+    return uploadBackup(ctx, cmd, client, path, dbType)
+}
+
+// uploadBackup uploads the backup at path through a presigned URL from the
+// API and returns its location. The backup must restore into a dbType
+// database. Progress goes to stderr: a single updating line on a
+// terminal, a line at the start and the end otherwise.
+func uploadBackup(ctx context.Context, cmd *cobra.Command, client api.ClientWithResponsesInterface, path string, dbType api.DatabaseType) (string, error) {
+    f, err := backup.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer f.Close()
+    if err := f.CheckType(dbType); err != nil {
+        return "", err
+    }
+
+    resp, err := client.PostBackupsUploadsWithResponse(ctx, api.CreateBackupUploadRequest{
+        Filename:  f.Name,
+        SizeBytes: f.Size,
+        Format:    f.Format,
+    })
+    if err != nil {
+        return "", fmt.Errorf("error starting upload: %w", err)
+    }
+    if resp.StatusCode() != 201 {
+        return "", api.NewError(resp.HTTPResponse, resp.Body)
+    }
+    upload := resp.JSON201
+
+    httpClient, err := uploadClient(upload.UploadUrl)
+    if err != nil {
+        return "", err
+    }
+
+    stderr := cmd.ErrOrStderr()
+    tty := isTerminal(stderr)
+    total := formatBytes(f.Size)
+    progress := func(sent int64) {
+        fmt.Fprintf(stderr, "\rUploading %s: %d%% (%s of %s)\033[K", f.Name, sent*100/f.Size, formatBytes(sent), total)
+    }
+    if !tty {
+        fmt.Fprintf(stderr, "Uploading %s (%s, %s format)\n", f.Name, total, f.Format)
+        progress = nil
+    }
+    sum, err := backup.Upload(ctx, httpClient, upload.UploadUrl, f, progress)
+    if tty {
+        fmt.Fprintln(stderr)
+    }
+    if err != nil {
+        return "", err
+    }
+    fmt.Fprintf(stderr, "Uploaded %s (%s, sha256 %s)\n", f.Name, total, sum)
+    return upload.Location, nil
+}
+
+// uploadClient returns the HTTP client for an upload URL. Uploads to the
+// API's own host use the context's TLS settings; others, typically S3,
+// use the system's.
+func uploadClient(uploadURL string) (*http.Client, error) {
+    u, err := url.Parse(uploadURL)
+    if err != nil {
+        return nil, fmt.Errorf("invalid upload URL: %v", err)
+    }
+    base, err := url.Parse(apiURL)
+    if err != nil || activeContext == nil || u.Host != base.Host {
+        return httpClientFor(config.TLS{})
+    }
+    return httpClientFor(activeContext.TLS)
+}
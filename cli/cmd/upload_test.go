@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectCreateBackup(t *testing.T) {
+	fake, _ := newFakeAPI(t)
+
+	dir := t.TempDir()
+	dump := append([]byte("PGDMP\x01\x0e\x00"), bytes.Repeat([]byte{0xab}, 4096)...)
+	dumpPath := filepath.Join(dir, "billing.dump")
+	if err := os.WriteFile(dumpPath, dump, 0600); err != nil {
+		t.Fatal(err)
+	}
+	notDump := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notDump, []byte("shopping list\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(dump)
+
+	output := executeCommand(t, cmdTestCase{
+		name: "upload a local backup",
+		cmd:  projectCreateCmd,
+		args: []string{"billing", "--type", "postgres", "--version", "16", "--backup", dumpPath},
+	})
+	for _, want := range []string{
+		"Uploading billing.dump (4.0 KiB, custom format)\n",
+		"Uploaded billing.dump (4.0 KiB, sha256 " + hex.EncodeToString(sum[:]) + ")\n",
+		"  BackupLocation: s3://devdb-backups/uploads/",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+
+	location := fakeProject(t, fake, "billing").BackupLocation
+	if !strings.HasSuffix(location, "/billing.dump") {
+		t.Errorf("backup location = %q, want it to end in /billing.dump", location)
+	}
+	if got, ok := fake.Upload(location); !ok || !bytes.Equal(got, dump) {
+		t.Errorf("uploaded %d bytes (found %v), want the %d bytes of the dump", len(got), ok, len(dump))
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:       "S3 location",
+			cmd:        projectCreateCmd,
+			args:       []string{"orders", "--type", "postgres", "--version", "16", "--backup", "s3://backups/orders.dump", "-o", "jsonpath={.backupLocation}"},
+			wantOutput: "s3://backups/orders.dump\n",
+		},
+		{
+			name:       "not a backup",
+			cmd:        projectCreateCmd,
+			args:       []string{"notes", "--type", "postgres", "--version", "16", "--backup", notDump},
+			wantErr:    true,
+			wantOutput: "Error: " + notDump + " is not a backup DevDB can restore: expected a pg_dump custom (-Fc), directory (-Fd) or plain SQL dump, a Redis RDB file or a mongodump archive\n",
+		},
+		{
+			name:    "missing file",
+			cmd:     projectCreateCmd,
+			args:    []string{"missing", "--type", "postgres", "--version", "16", "--backup", filepath.Join(dir, "missing.dump")},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+	if got := len(fake.Projects()) - 1; got != 2 {
+		t.Errorf("created %d projects, want 2: failed uploads must not create projects", got)
+	}
+}
+
+func TestProjectCreateMySQL(t *testing.T) {
+	fake, _ := newFakeAPI(t)
+
+	dir := t.TempDir()
+	mysqlDump := writeTestFile(t, dir, "shop.sql", "-- MySQL dump 10.13  Distrib 8.4.0, for Linux (x86_64)\n--\n-- Server version\t8.4.0\n\nCREATE TABLE `users` (`id` int);\n")
+	pgDump := writeTestFile(t, dir, "billing.dump", "PGDMP\x01\x0e\x00")
+	policy := writeTestFile(t, dir, "masking.yaml", testMaskPolicy)
+
+	output := executeCommand(t, cmdTestCase{
+		name: "mysqldump backup",
+		cmd:  projectCreateCmd,
+		args: []string{"shop", "--type", "mysql", "--version", "8.4", "--backup", mysqlDump},
+	})
+	if !strings.Contains(output, "Uploading shop.sql (") || !strings.Contains(output, "  DbType: mysql\n") {
+		t.Errorf("output = %q", output)
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:       "unknown type",
+			cmd:        projectCreateCmd,
+			args:       []string{"ledger", "--type", "oracle", "--version", "23"},
+			wantErr:    true,
+			wantOutput: "Error: unknown database type \"oracle\" (want postgres, mysql, mariadb, redis, mongodb)\n",
+		},
+		{
+			name:       "unsupported version",
+			cmd:        projectCreateCmd,
+			args:       []string{"ledger", "--type", "mariadb", "--version", "10.4"},
+			wantErr:    true,
+			wantOutput: "Error: MariaDB 10.4 is not supported (supported: 11.4, 10.11, 10.6, 10.5); did you mean 10.5?\n",
+		},
+		{
+			name:       "pg_dump backup for mysql",
+			cmd:        projectCreateCmd,
+			args:       []string{"ledger", "--type", "mysql", "--version", "8.0", "--backup", pgDump},
+			wantErr:    true,
+			wantOutput: "Error: billing.dump is a pg_dump backup and cannot be restored into a mysql database\n",
+		},
+		{
+			name:       "mysqldump backup for postgres",
+			cmd:        projectCreateCmd,
+			args:       []string{"ledger", "--type", "postgres", "--version", "16", "--backup", mysqlDump},
+			wantErr:    true,
+			wantOutput: "Error: shop.sql is a mysqldump backup and cannot be restored into a postgres database\n",
+		},
+		{
+			name:       "masking policy",
+			cmd:        projectCreateCmd,
+			args:       []string{"ledger", "--type", "mysql", "--version", "8.0", "--backup", mysqlDump, "--mask-policy", policy},
+			wantErr:    true,
+			wantOutput: "Error: masking policies apply to pg_dump backups; mysql projects cannot have one\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+	if got := len(fake.Projects()) - 1; got != 1 {
+		t.Errorf("created %d projects, want 1", got)
+	}
+}
+
+func TestProjectCreateRedis(t *testing.T) {
+	newFakeAPI(t)
+
+	dir := t.TempDir()
+	rdb := writeTestFile(t, dir, "dump.rdb", "REDIS0011\xfa\tredis-ver\x057.2.5")
+	archive := writeTestFile(t, dir, "catalog.archive", "\x6d\xe2\x99\x81\x92\x00\x00\x00")
+
+	output := executeCommand(t, cmdTestCase{
+		name: "rdb backup",
+		cmd:  projectCreateCmd,
+		args: []string{"sessions", "--type", "redis", "--version", "7.2", "--backup", rdb},
+	})
+	if !strings.Contains(output, "Uploading dump.rdb (26 B, rdb format)") || !strings.Contains(output, "  DbType: redis\n") {
+		t.Errorf("output = %q", output)
+	}
+
+	executeCommand(t, cmdTestCase{
+		name:       "mongodump archive for redis",
+		cmd:        projectCreateCmd,
+		args:       []string{"cache", "--type", "redis", "--version", "7.2", "--backup", archive},
+		wantErr:    true,
+		wantOutput: "Error: catalog.archive is a mongodump backup and cannot be restored into a redis database\n",
+	})
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/meido-ai/devdb/cli/pkg/api"
+    "github.com/meido-ai/devdb/cli/pkg/wait"
+    "github.com/spf13/cobra"
+    "golang.org/x/term"
+)
+
+var (
+    waitFor     string        // Condition flag for the wait command
+    waitTimeout time.Duration // Timeout for wait and create --wait
+    waitProbe   bool          // Also probe host:port before reporting running
+
+    // waitInterval is the first poll interval; tests shorten it
+    waitInterval = time.Second
+)
+
+var dbWaitCmd = &cobra.Command{
+    Use:   "wait [name]",
+    Short: "Wait for a database to reach a status",
+    Long: `Block until a database reaches the given status, or is deleted.
+
+  --for status=running   wait until the database is running (default)
+  --for status=stopped   wait until the database is stopped
+  --for delete           wait until the database no longer exists
+
+Exits with an error if the database enters the error state or the timeout
+expires. With --probe, a running database must also accept connections
+on its host and port, speaking the database's own protocol.`,
+    Args: cobra.ExactArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        name := args[0]
+
+        want, err := parseWaitCondition(waitFor)
+        if err != nil {
+            return err
+        }
+        cmd.SilenceUsage = true
+
+        client, err := newAPIClient()
+        if err != nil {
+            return fmt.Errorf("creating client: %v", err)
+        }
+
+        db, err := waitForDatabase(cmd, client, name, want, wait.Options{Probe: waitProbe})
+        if err != nil {
+            return err
+        }
+        if want == wait.Deleted {
+            return printResult(cmd, deleteResult{Name: name, Status: "deleted"}, func() {
+                cmd.Printf("Database %s deleted\n", name)
+            })
+        }
+        return printResult(cmd, databaseOutput(*db), func() {
+            cmd.Printf("Database %s is %s\n", db.Name, db.Status)
+        })
+    },
+}
+
+func parseWaitCondition(s string) (api.DatabaseStatus, error) {
+    if s == "delete" {
+        return wait.Deleted, nil
+    }
+    status, ok := strings.CutPrefix(s, "status=")
+    if !ok {
+        return "", fmt.Errorf("invalid --for %q: use status=<status> or delete", s)
+    }
+    switch api.DatabaseStatus(status) {
+    case api.Creating, api.Running, api.Stopped, api.Error:
+        return api.DatabaseStatus(status), nil
+    }
+    return "", fmt.Errorf("invalid status %q: want creating, running, stopped or error", status)
+}
+
+// waitForDatabase waits for the database in the current project to reach
+// want, honouring --timeout. Of opts, only AllowMissing and Probe are used.
+// Progress goes to stderr: a single updating line on a terminal, one line
+// per status change otherwise.
+func waitForDatabase(cmd *cobra.Command, client api.ClientWithResponsesInterface, name string, want api.DatabaseStatus, opts wait.Options) (*api.Database, error) {
+    ctx := context.Background()
+    if waitTimeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, waitTimeout)
+        defer cancel()
+    }
+
+    stderr := cmd.ErrOrStderr()
+    tty := isTerminal(stderr)
+    lastStatus := ""
+    progress := func(db *api.Database, elapsed time.Duration) {
+        status := "not found"
+        if db != nil {
+            status = string(db.Status)
+        }
+        if tty {
+            fmt.Fprintf(stderr, "\rWaiting for database %s to be %s: %s (%s)\033[K", name, want, status, elapsed.Round(time.Second))
+        } else if status != lastStatus {
+            fmt.Fprintf(stderr, "Waiting for database %s to be %s: %s\n", name, want, status)
+        }
+        lastStatus = status
+    }
+
+    opts.Interval = waitInterval
+    opts.Progress = progress
+    if opts.Probe {
+        // The probe speaks the protocol of the project's engine
+        p, err := getProject(ctx, client, project)
+        if err != nil {
+            return nil, err
+        }
+        opts.Type = p.DbType
+    }
+    db, err := wait.ForStatus(ctx, client, project, name, want, opts)
+    if tty {
+        fmt.Fprintln(stderr)
+    }
+    return db, err
+}
+
+func isTerminal(w io.Writer) bool {
+    f, ok := w.(*os.File)
+    return ok && term.IsTerminal(int(f.Fd()))
+}
+
+func addWaitFlags(cmd *cobra.Command) {
+    cmd.Flags().DurationVar(&waitTimeout, "timeout", 10*time.Minute, "Maximum time to wait (0 waits forever)")
+    cmd.Flags().BoolVar(&waitProbe, "probe", false, "Also check that the database accepts connections on its host and port")
+}
+
+func init() {
+    dbCmd.AddCommand(dbWaitCmd)
+
+    dbWaitCmd.Flags().StringVar(&waitFor, "for", "status=running", "Condition to wait for: status=<status> or delete")
+    addWaitFlags(dbWaitCmd)
+}
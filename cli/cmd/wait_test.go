@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestDatabaseWait(t *testing.T) {
+	fake, project := newFakeAPI(t)
+	fake.AddDatabase(project.Id, api.Database{Name: "broken", Status: api.Error})
+	fake.AddDatabase(project.Id, api.Database{Name: "stuck", Status: api.Creating})
+	fake.StickCreating("stuck")
+	fake.SequenceStatus(project.Id, "testdb", api.Creating, api.Creating)
+
+	originalInterval := waitInterval
+	defer func() { waitInterval = originalInterval }()
+	waitInterval = time.Millisecond
+
+	tests := []cmdTestCase{
+		{
+			name: "create and wait",
+			cmd:  dbCreateCmd,
+			args: []string{"testdb", "--project", "testproject", "--wait"},
+			wantOutput: `Waiting for database testdb to be running: creating
+Waiting for database testdb to be running: running
+Database created successfully
+Details:
+  Name: testdb
+  Status: running
+  Host: localhost
+  Port: 5432
+`,
+		},
+		{
+			name:       "wait for error status",
+			cmd:        dbWaitCmd,
+			args:       []string{"broken", "--project", "testproject", "--for", "status=error"},
+			wantOutput: "Waiting for database broken to be error: error\nDatabase broken is error\n",
+		},
+		{
+			name:    "wait fails on error status",
+			cmd:     dbWaitCmd,
+			args:    []string{"broken", "--project", "testproject"},
+			wantErr: true,
+		},
+		{
+			name:       "wait for a missing database",
+			cmd:        dbWaitCmd,
+			args:       []string{"missing", "--project", "testproject"},
+			wantErr:    true,
+			wantExit:   exitNotFound,
+			wantOutput: "Error: Not Found: Database missing not found\n",
+		},
+		{
+			name:    "wait times out",
+			cmd:     dbWaitCmd,
+			args:    []string{"stuck", "--project", "testproject", "--timeout", "20ms"},
+			wantErr: true,
+		},
+		{
+			name:       "wait for delete",
+			cmd:        dbWaitCmd,
+			args:       []string{"gone", "--project", "testproject", "--for", "delete"},
+			wantOutput: "Waiting for database gone to be deleted: not found\nDatabase gone deleted\n",
+		},
+		{
+			name:       "wait for delete as JSON",
+			cmd:        dbWaitCmd,
+			args:       []string{"gone", "--project", "testproject", "--for", "delete", "-o", "json"},
+			wantOutput: "Waiting for database gone to be deleted: not found\n{\n  \"name\": \"gone\",\n  \"status\": \"deleted\"\n}\n",
+		},
+		{
+			name:    "invalid condition",
+			cmd:     dbWaitCmd,
+			args:    []string{"testdb", "--project", "testproject", "--for", "ready"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executeCommand(t, tc)
+		})
+	}
+}
@@ -12,10 +12,24 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/oapi-codegen/runtime"
 )
 
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for BackupFormat.
+const (
+	BackupFormatArchive   BackupFormat = "archive"
+	BackupFormatCustom    BackupFormat = "custom"
+	BackupFormatDirectory BackupFormat = "directory"
+	BackupFormatPlain     BackupFormat = "plain"
+	BackupFormatRDB       BackupFormat = "rdb"
+)
+
 // Defines values for DatabaseStatus.
 const (
 	Creating DatabaseStatus = "creating"
@@ -26,24 +40,100 @@ const (
 
 // Defines values for DatabaseType.
 const (
+	MariaDB  DatabaseType = "mariadb"
+	MongoDB  DatabaseType = "mongodb"
+	MySQL    DatabaseType = "mysql"
 	Postgres DatabaseType = "postgres"
+	Redis    DatabaseType = "redis"
+)
+
+// Defines values for SnapshotStatus.
+const (
+	SnapshotCreating SnapshotStatus = "creating"
+	SnapshotError    SnapshotStatus = "error"
+	SnapshotReady    SnapshotStatus = "ready"
 )
 
+// BackupFormat Format of a backup; custom and directory are pg_dump's -Fc and -Fd (uploaded as a tar archive), plain is SQL from pg_dump or mysqldump, rdb is a Redis snapshot and archive is mongodump --archive output
+type BackupFormat string
+
+// BackupUpload defines model for BackupUpload.
+type BackupUpload struct {
+	// ExpiresAt When the upload URL stops working
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Location S3 URL of the backup once uploaded, for CreateProjectRequest.backupLocation
+	Location string `json:"location"`
+
+	// UploadUrl Presigned URL to upload the backup to with a PUT request
+	UploadUrl string `json:"uploadUrl"`
+}
+
+// Catalog defines model for Catalog.
+type Catalog struct {
+	// Engines Supported engines, in the order they are listed to users
+	Engines []CatalogEngine `json:"engines"`
+}
+
+// CatalogEngine defines model for CatalogEngine.
+type CatalogEngine struct {
+	// DefaultPort Port its databases listen on
+	DefaultPort int `json:"defaultPort"`
+
+	// Extensions Extensions databases can enable, e.g. with CREATE EXTENSION
+	Extensions []string `json:"extensions"`
+
+	// Name Name of the engine, e.g. PostgreSQL
+	Name string `json:"name"`
+
+	// Type Database engine of a project
+	Type DatabaseType `json:"type"`
+
+	// Versions Supported release series, newest first
+	Versions []string `json:"versions"`
+}
+
+// CreateBackupUploadRequest defines model for CreateBackupUploadRequest.
+type CreateBackupUploadRequest struct {
+	// Filename Name of the backup file, without directories
+	Filename string `json:"filename"`
+
+	// Format Format of a backup; custom and directory are pg_dump's -Fc and -Fd (uploaded as a tar archive), plain is SQL from pg_dump or mysqldump, rdb is a Redis snapshot and archive is mongodump --archive output
+	Format BackupFormat `json:"format"`
+
+	// SizeBytes Exact size of the file that will be uploaded
+	SizeBytes int64 `json:"sizeBytes"`
+}
+
 // CreateDatabaseRequest defines model for CreateDatabaseRequest.
 type CreateDatabaseRequest struct {
+	// FromDatabase Name of a database of the project to copy the data of, instead of restoring the project's backup
+	FromDatabase *string `json:"fromDatabase,omitempty"`
+
+	// FromSnapshot Name of a snapshot of one of the project's databases to restore; cannot be combined with fromDatabase
+	FromSnapshot *string `json:"fromSnapshot,omitempty"`
+
 	// Name Name of the database instance
 	Name string `json:"name"`
+
+	// TtlSeconds Time-to-live of the database in seconds; it never expires when omitted
+	TtlSeconds *int64 `json:"ttlSeconds,omitempty"`
 }
 
 // CreateProjectRequest defines model for CreateProjectRequest.
 type CreateProjectRequest struct {
 	// BackupLocation S3 URL of the backup file (e.g., s3://bucket/path/to/backup.dump)
-	BackupLocation *string      `json:"backupLocation,omitempty"`
-	DbType         DatabaseType `json:"dbType"`
+	BackupLocation *string `json:"backupLocation,omitempty"`
 
-	// DbVersion Version of the database (e.g., '15.3' for PostgreSQL)
+	// DbType Database engine of a project
+	DbType DatabaseType `json:"dbType"`
+
+	// DbVersion Version of the database (e.g., '15.3' for PostgreSQL, '8.4' for MySQL or '7.2' for Redis)
 	DbVersion string `json:"dbVersion"`
 
+	// MaskingPolicy Masking policy (YAML mapping table.column to faker, hash, null or constant) the project's backups are masked with before upload
+	MaskingPolicy *string `json:"maskingPolicy,omitempty"`
+
 	// Name Name of the project
 	Name string `json:"name"`
 
@@ -51,59 +141,182 @@ type CreateProjectRequest struct {
 	Owner string `json:"owner"`
 }
 
+// CreateSnapshotRequest defines model for CreateSnapshotRequest.
+type CreateSnapshotRequest struct {
+	// Name Name of the snapshot; generated from the database name and the time when omitted
+	Name *string `json:"name,omitempty"`
+}
+
+// DataRefresh defines model for DataRefresh.
+type DataRefresh struct {
+	// DataVersion Data version after the refresh
+	DataVersion int `json:"dataVersion"`
+
+	// PreviousDataVersion Data version before the refresh
+	PreviousDataVersion int     `json:"previousDataVersion"`
+	Project             Project `json:"project"`
+}
+
 // Database defines model for Database.
 type Database struct {
-	Database *string        `json:"database,omitempty"`
-	Host     *string        `json:"host,omitempty"`
-	Name     string         `json:"name"`
-	Port     *int           `json:"port,omitempty"`
-	Project  *string        `json:"project,omitempty"`
-	Status   DatabaseStatus `json:"status"`
-	Username *string        `json:"username,omitempty"`
+	// DataVersion Data version of the project the database was created or last reset from; not set for databases copied from a snapshot
+	DataVersion *int    `json:"dataVersion,omitempty"`
+	Database    *string `json:"database,omitempty"`
+
+	// ExpiresAt When the database is deleted or stopped automatically; databases without one never expire
+	ExpiresAt *time.Time     `json:"expiresAt,omitempty"`
+	Host      *string        `json:"host,omitempty"`
+	Name      string         `json:"name"`
+	Port      *int           `json:"port,omitempty"`
+	Project   *string        `json:"project,omitempty"`
+	Status    DatabaseStatus `json:"status"`
+
+	// StoppedAt When the database was stopped; only set while it is stopped
+	StoppedAt *time.Time `json:"stoppedAt,omitempty"`
+	Username  *string    `json:"username,omitempty"`
 }
 
 // DatabaseStatus defines model for Database.Status.
 type DatabaseStatus string
 
-// DatabaseType defines model for DatabaseType.
+// DatabaseType Database engine of a project
 type DatabaseType string
 
 // DefaultDatabaseCredentials defines model for DefaultDatabaseCredentials.
 type DefaultDatabaseCredentials struct {
-	// Database Default database name
+	// Database Default database name; the logical database number for Redis
 	Database string `json:"database"`
 
 	// Password Default password for database access
 	Password string `json:"password"`
 
-	// Username Default username for database access
+	// Username Default username for database access; "default" for Redis
 	Username string `json:"username"`
 }
 
+// ExtendDatabaseRequest defines model for ExtendDatabaseRequest.
+type ExtendDatabaseRequest struct {
+	// TtlSeconds Seconds to add to the database's time-to-live
+	TtlSeconds int64 `json:"ttlSeconds"`
+}
+
+// Problem Error details as defined by RFC 7807
+type Problem struct {
+	// Detail Explanation specific to this occurrence of the problem
+	Detail *string `json:"detail,omitempty"`
+
+	// Instance URI reference identifying this occurrence of the problem
+	Instance *string `json:"instance,omitempty"`
+
+	// Status HTTP status code
+	Status int `json:"status"`
+
+	// Title Short summary of the problem type
+	Title string `json:"title"`
+
+	// Type URI reference identifying the problem type
+	Type *string `json:"type,omitempty"`
+}
+
 // Project defines model for Project.
 type Project struct {
-	// BackupLocation S3 URL where the database backup (pg_dump output) is stored
-	BackupLocation     string                     `json:"backupLocation"`
-	Databases          *[]Database                `json:"databases,omitempty"`
+	// BackupLocation S3 URL where the database backup (pg_dump, mysqldump, RDB or mongodump output) is stored
+	BackupLocation string `json:"backupLocation"`
+
+	// DataRefreshedAt When the project's data was last refreshed
+	DataRefreshedAt *time.Time `json:"dataRefreshedAt,omitempty"`
+
+	// DataVersion Version of the project's data, 1 when created and increased by every refresh
+	DataVersion *int        `json:"dataVersion,omitempty"`
+	Databases   *[]Database `json:"databases,omitempty"`
+
+	// DbType Database engine of a project
 	DbType             DatabaseType               `json:"dbType"`
 	DbVersion          string                     `json:"dbVersion"`
 	DefaultCredentials DefaultDatabaseCredentials `json:"defaultCredentials"`
 	Id                 string                     `json:"id"`
-	Name               string                     `json:"name"`
-	Owner              string                     `json:"owner"`
+
+	// MaskingPolicy Masking policy the project's backups are masked with before upload; like defaultCredentials, only returned to authenticated clients or by servers that do not require authentication
+	MaskingPolicy *string `json:"maskingPolicy,omitempty"`
+	Name          string  `json:"name"`
+	Owner         string  `json:"owner"`
+}
+
+// RefreshProjectRequest defines model for RefreshProjectRequest.
+type RefreshProjectRequest struct {
+	// BackupLocation S3 URL of the new backup; the project's current backup is read again when omitted
+	BackupLocation *string `json:"backupLocation,omitempty"`
+
+	// MaskingPolicy New masking policy of the project; the current one is kept when omitted
+	MaskingPolicy *string `json:"maskingPolicy,omitempty"`
+}
+
+// Snapshot Point-in-time copy of a database's data
+type Snapshot struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Database Name of the database the snapshot was taken of
+	Database string `json:"database"`
+
+	// Name Name of the snapshot, unique within the project
+	Name    string  `json:"name"`
+	Project *string `json:"project,omitempty"`
+
+	// SizeBytes Size of the snapshot's data, when known
+	SizeBytes *int64         `json:"sizeBytes,omitempty"`
+	Status    SnapshotStatus `json:"status"`
 }
 
+// SnapshotStatus defines model for Snapshot.Status.
+type SnapshotStatus string
+
+// StopDatabaseRequest defines model for StopDatabaseRequest.
+type StopDatabaseRequest struct {
+	// ClearExpiry Also remove the database's expiry, as reapers that stop expired databases do, so it is not stopped again once it is started
+	ClearExpiry *bool `json:"clearExpiry,omitempty"`
+}
+
+// BadRequest Error details as defined by RFC 7807
+type BadRequest = Problem
+
+// Conflict Error details as defined by RFC 7807
+type Conflict = Problem
+
+// InternalError Error details as defined by RFC 7807
+type InternalError = Problem
+
+// NotFound Error details as defined by RFC 7807
+type NotFound = Problem
+
+// Unauthorized Error details as defined by RFC 7807
+type Unauthorized = Problem
+
 // GetProjectsParams defines parameters for GetProjects.
 type GetProjectsParams struct {
 	Owner *string `form:"owner,omitempty" json:"owner,omitempty"`
 }
 
+// PostBackupsUploadsJSONRequestBody defines body for PostBackupsUploads for application/json ContentType.
+type PostBackupsUploadsJSONRequestBody = CreateBackupUploadRequest
+
 // PostProjectsJSONRequestBody defines body for PostProjects for application/json ContentType.
 type PostProjectsJSONRequestBody = CreateProjectRequest
 
 // PostProjectsProjectIdDatabasesJSONRequestBody defines body for PostProjectsProjectIdDatabases for application/json ContentType.
 type PostProjectsProjectIdDatabasesJSONRequestBody = CreateDatabaseRequest
 
+// PostProjectsProjectIdDatabasesNameExtendJSONRequestBody defines body for PostProjectsProjectIdDatabasesNameExtend for application/json ContentType.
+type PostProjectsProjectIdDatabasesNameExtendJSONRequestBody = ExtendDatabaseRequest
+
+// PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody defines body for PostProjectsProjectIdDatabasesNameSnapshots for application/json ContentType.
+type PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody = CreateSnapshotRequest
+
+// PostProjectsProjectIdDatabasesNameStopJSONRequestBody defines body for PostProjectsProjectIdDatabasesNameStop for application/json ContentType.
+type PostProjectsProjectIdDatabasesNameStopJSONRequestBody = StopDatabaseRequest
+
+// PostProjectsProjectIdRefreshJSONRequestBody defines body for PostProjectsProjectIdRefresh for application/json ContentType.
+type PostProjectsProjectIdRefreshJSONRequestBody = RefreshProjectRequest
+
 // RequestEditorFn  is the function signature for the RequestEditor callback function
 type RequestEditorFn func(ctx context.Context, req *http.Request) error
 
@@ -177,6 +390,14 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 
 // The interface specification for the client above.
 type ClientInterface interface {
+	// PostBackupsUploadsWithBody request with any body
+	PostBackupsUploadsWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostBackupsUploads(ctx context.Context, body PostBackupsUploadsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetCatalog request
+	GetCatalog(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// GetProjects request
 	GetProjects(ctx context.Context, params *GetProjectsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
 
@@ -204,6 +425,74 @@ type ClientInterface interface {
 
 	// GetProjectsProjectIdDatabasesName request
 	GetProjectsProjectIdDatabasesName(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProjectsProjectIdDatabasesNameExtendWithBody request with any body
+	PostProjectsProjectIdDatabasesNameExtendWithBody(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostProjectsProjectIdDatabasesNameExtend(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameExtendJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProjectsProjectIdDatabasesNameReset request
+	PostProjectsProjectIdDatabasesNameReset(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetProjectsProjectIdDatabasesNameSnapshots request
+	GetProjectsProjectIdDatabasesNameSnapshots(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProjectsProjectIdDatabasesNameSnapshotsWithBody request with any body
+	PostProjectsProjectIdDatabasesNameSnapshotsWithBody(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostProjectsProjectIdDatabasesNameSnapshots(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshot request
+	DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshot(ctx context.Context, projectId string, name string, snapshot string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProjectsProjectIdDatabasesNameStart request
+	PostProjectsProjectIdDatabasesNameStart(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProjectsProjectIdDatabasesNameStopWithBody request with any body
+	PostProjectsProjectIdDatabasesNameStopWithBody(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostProjectsProjectIdDatabasesNameStop(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameStopJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostProjectsProjectIdRefreshWithBody request with any body
+	PostProjectsProjectIdRefreshWithBody(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostProjectsProjectIdRefresh(ctx context.Context, projectId string, body PostProjectsProjectIdRefreshJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) PostBackupsUploadsWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostBackupsUploadsRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostBackupsUploads(ctx context.Context, body PostBackupsUploadsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostBackupsUploadsRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetCatalog(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetCatalogRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
 func (c *Client) GetProjects(ctx context.Context, params *GetProjectsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
@@ -326,68 +615,163 @@ func (c *Client) GetProjectsProjectIdDatabasesName(ctx context.Context, projectI
 	return c.Client.Do(req)
 }
 
-// NewGetProjectsRequest generates requests for GetProjects
-func NewGetProjectsRequest(server string, params *GetProjectsParams) (*http.Request, error) {
-	var err error
+func (c *Client) PostProjectsProjectIdDatabasesNameExtendWithBody(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameExtendRequestWithBody(c.Server, projectId, name, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	serverURL, err := url.Parse(server)
+func (c *Client) PostProjectsProjectIdDatabasesNameExtend(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameExtendJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameExtendRequest(c.Server, projectId, name, body)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	operationPath := fmt.Sprintf("/projects")
-	if operationPath[0] == '/' {
-		operationPath = "." + operationPath
+func (c *Client) PostProjectsProjectIdDatabasesNameReset(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameResetRequest(c.Server, projectId, name)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
+	return c.Client.Do(req)
+}
 
-	queryURL, err := serverURL.Parse(operationPath)
+func (c *Client) GetProjectsProjectIdDatabasesNameSnapshots(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetProjectsProjectIdDatabasesNameSnapshotsRequest(c.Server, projectId, name)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	if params != nil {
-		queryValues := queryURL.Query()
+func (c *Client) PostProjectsProjectIdDatabasesNameSnapshotsWithBody(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameSnapshotsRequestWithBody(c.Server, projectId, name, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-		if params.Owner != nil {
+func (c *Client) PostProjectsProjectIdDatabasesNameSnapshots(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameSnapshotsRequest(c.Server, projectId, name, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "owner", runtime.ParamLocationQuery, *params.Owner); err != nil {
-				return nil, err
-			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
-				return nil, err
-			} else {
-				for k, v := range parsed {
-					for _, v2 := range v {
-						queryValues.Add(k, v2)
-					}
-				}
-			}
+func (c *Client) DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshot(ctx context.Context, projectId string, name string, snapshot string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotRequest(c.Server, projectId, name, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-		}
+func (c *Client) PostProjectsProjectIdDatabasesNameStart(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameStartRequest(c.Server, projectId, name)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-		queryURL.RawQuery = queryValues.Encode()
+func (c *Client) PostProjectsProjectIdDatabasesNameStopWithBody(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameStopRequestWithBody(c.Server, projectId, name, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
+	return c.Client.Do(req)
+}
 
-	req, err := http.NewRequest("GET", queryURL.String(), nil)
+func (c *Client) PostProjectsProjectIdDatabasesNameStop(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameStopJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdDatabasesNameStopRequest(c.Server, projectId, name, body)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	return req, nil
+func (c *Client) PostProjectsProjectIdRefreshWithBody(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdRefreshRequestWithBody(c.Server, projectId, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// NewPostProjectsRequest calls the generic PostProjects builder with application/json body
-func NewPostProjectsRequest(server string, body PostProjectsJSONRequestBody) (*http.Request, error) {
+func (c *Client) PostProjectsProjectIdRefresh(ctx context.Context, projectId string, body PostProjectsProjectIdRefreshJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostProjectsProjectIdRefreshRequest(c.Server, projectId, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewPostBackupsUploadsRequest calls the generic PostBackupsUploads builder with application/json body
+func NewPostBackupsUploadsRequest(server string, body PostBackupsUploadsJSONRequestBody) (*http.Request, error) {
 	var bodyReader io.Reader
 	buf, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 	bodyReader = bytes.NewReader(buf)
-	return NewPostProjectsRequestWithBody(server, "application/json", bodyReader)
+	return NewPostBackupsUploadsRequestWithBody(server, "application/json", bodyReader)
 }
 
-// NewPostProjectsRequestWithBody generates requests for PostProjects with any type of body
-func NewPostProjectsRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+// NewPostBackupsUploadsRequestWithBody generates requests for PostBackupsUploads with any type of body
+func NewPostBackupsUploadsRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
 	var err error
 
 	serverURL, err := url.Parse(server)
@@ -395,7 +779,7 @@ func NewPostProjectsRequestWithBody(server string, contentType string, body io.R
 		return nil, err
 	}
 
-	operationPath := fmt.Sprintf("/projects")
+	operationPath := fmt.Sprintf("/backups/uploads")
 	if operationPath[0] == '/' {
 		operationPath = "." + operationPath
 	}
@@ -415,17 +799,133 @@ func NewPostProjectsRequestWithBody(server string, contentType string, body io.R
 	return req, nil
 }
 
-// NewDeleteProjectRequest generates requests for DeleteProject
-func NewDeleteProjectRequest(server string, projectId string) (*http.Request, error) {
+// NewGetCatalogRequest generates requests for GetCatalog
+func NewGetCatalogRequest(server string) (*http.Request, error) {
 	var err error
 
-	var pathParam0 string
-
-	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
-	if err != nil {
-		return nil, err
-	}
-
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/catalog")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetProjectsRequest generates requests for GetProjects
+func NewGetProjectsRequest(server string, params *GetProjectsParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/projects")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Owner != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "owner", runtime.ParamLocationQuery, *params.Owner); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostProjectsRequest calls the generic PostProjects builder with application/json body
+func NewPostProjectsRequest(server string, body PostProjectsJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostProjectsRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewPostProjectsRequestWithBody generates requests for PostProjects with any type of body
+func NewPostProjectsRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/projects")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDeleteProjectRequest generates requests for DeleteProject
+func NewDeleteProjectRequest(server string, projectId string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
+	}
+
 	serverURL, err := url.Parse(server)
 	if err != nil {
 		return nil, err
@@ -646,538 +1146,2063 @@ func NewGetProjectsProjectIdDatabasesNameRequest(server string, projectId string
 	return req, nil
 }
 
-func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
-	for _, r := range c.RequestEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
-	}
-	for _, r := range additionalEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
+// NewPostProjectsProjectIdDatabasesNameExtendRequest calls the generic PostProjectsProjectIdDatabasesNameExtend builder with application/json body
+func NewPostProjectsProjectIdDatabasesNameExtendRequest(server string, projectId string, name string, body PostProjectsProjectIdDatabasesNameExtendJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	bodyReader = bytes.NewReader(buf)
+	return NewPostProjectsProjectIdDatabasesNameExtendRequestWithBody(server, projectId, name, "application/json", bodyReader)
 }
 
-// ClientWithResponses builds on ClientInterface to offer response payloads
-type ClientWithResponses struct {
-	ClientInterface
-}
+// NewPostProjectsProjectIdDatabasesNameExtendRequestWithBody generates requests for PostProjectsProjectIdDatabasesNameExtend with any type of body
+func NewPostProjectsProjectIdDatabasesNameExtendRequestWithBody(server string, projectId string, name string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
 
-// NewClientWithResponses creates a new ClientWithResponses, which wraps
-// Client with return type handling
-func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
-	client, err := NewClient(server, opts...)
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
 	if err != nil {
 		return nil, err
 	}
-	return &ClientWithResponses{client}, nil
-}
 
-// WithBaseURL overrides the baseURL.
-func WithBaseURL(baseURL string) ClientOption {
-	return func(c *Client) error {
-		newBaseURL, err := url.Parse(baseURL)
-		if err != nil {
-			return err
-		}
-		c.Server = newBaseURL.String()
-		return nil
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// ClientWithResponsesInterface is the interface specification for the client with responses above.
-type ClientWithResponsesInterface interface {
-	// GetProjectsWithResponse request
-	GetProjectsWithResponse(ctx context.Context, params *GetProjectsParams, reqEditors ...RequestEditorFn) (*GetProjectsResponse, error)
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
 
-	// PostProjectsWithBodyWithResponse request with any body
-	PostProjectsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error)
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/extend", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
 
-	PostProjectsWithResponse(ctx context.Context, body PostProjectsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error)
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
 
-	// DeleteProjectWithResponse request
-	DeleteProjectWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*DeleteProjectResponse, error)
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
 
-	// GetProjectsProjectIdWithResponse request
-	GetProjectsProjectIdWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdResponse, error)
+	req.Header.Add("Content-Type", contentType)
 
-	// GetProjectsProjectIdDatabasesWithResponse request
-	GetProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesResponse, error)
+	return req, nil
+}
 
-	// PostProjectsProjectIdDatabasesWithBodyWithResponse request with any body
-	PostProjectsProjectIdDatabasesWithBodyWithResponse(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error)
+// NewPostProjectsProjectIdDatabasesNameResetRequest generates requests for PostProjectsProjectIdDatabasesNameReset
+func NewPostProjectsProjectIdDatabasesNameResetRequest(server string, projectId string, name string) (*http.Request, error) {
+	var err error
 
-	PostProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, body PostProjectsProjectIdDatabasesJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error)
+	var pathParam0 string
 
-	// DeleteProjectsProjectIdDatabasesNameWithResponse request
-	DeleteProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*DeleteProjectsProjectIdDatabasesNameResponse, error)
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
+	}
 
-	// GetProjectsProjectIdDatabasesNameWithResponse request
-	GetProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesNameResponse, error)
-}
+	var pathParam1 string
 
-type GetProjectsResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *[]Project
-}
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
 
-// Status returns HTTPResponse.Status
-func (r GetProjectsResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetProjectsResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/reset", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
 	}
-	return 0
-}
 
-type PostProjectsResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON201      *Project
-}
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
 
-// Status returns HTTPResponse.Status
-func (r PostProjectsResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r PostProjectsResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
-	}
-	return 0
+	return req, nil
 }
 
-type DeleteProjectResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-}
+// NewGetProjectsProjectIdDatabasesNameSnapshotsRequest generates requests for GetProjectsProjectIdDatabasesNameSnapshots
+func NewGetProjectsProjectIdDatabasesNameSnapshotsRequest(server string, projectId string, name string) (*http.Request, error) {
+	var err error
 
-// Status returns HTTPResponse.Status
-func (r DeleteProjectResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r DeleteProjectResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type GetProjectsProjectIdResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *Project
-}
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
 
-// Status returns HTTPResponse.Status
-func (r GetProjectsProjectIdResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/snapshots", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetProjectsProjectIdResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type GetProjectsProjectIdDatabasesResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *[]Database
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r GetProjectsProjectIdDatabasesResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// NewPostProjectsProjectIdDatabasesNameSnapshotsRequest calls the generic PostProjectsProjectIdDatabasesNameSnapshots builder with application/json body
+func NewPostProjectsProjectIdDatabasesNameSnapshotsRequest(server string, projectId string, name string, body PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
+	bodyReader = bytes.NewReader(buf)
+	return NewPostProjectsProjectIdDatabasesNameSnapshotsRequestWithBody(server, projectId, name, "application/json", bodyReader)
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetProjectsProjectIdDatabasesResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// NewPostProjectsProjectIdDatabasesNameSnapshotsRequestWithBody generates requests for PostProjectsProjectIdDatabasesNameSnapshots with any type of body
+func NewPostProjectsProjectIdDatabasesNameSnapshotsRequestWithBody(server string, projectId string, name string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type PostProjectsProjectIdDatabasesResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON201      *Database
-}
+	var pathParam1 string
 
-// Status returns HTTPResponse.Status
-func (r PostProjectsProjectIdDatabasesResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r PostProjectsProjectIdDatabasesResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type DeleteProjectsProjectIdDatabasesNameResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *struct {
-		Message *string `json:"message,omitempty"`
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/snapshots", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
 	}
-}
 
-// Status returns HTTPResponse.Status
-func (r DeleteProjectsProjectIdDatabasesNameResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r DeleteProjectsProjectIdDatabasesNameResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type GetProjectsProjectIdDatabasesNameResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *Database
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r GetProjectsProjectIdDatabasesNameResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// NewDeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotRequest generates requests for DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshot
+func NewDeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotRequest(server string, projectId string, name string, snapshot string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
-}
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetProjectsProjectIdDatabasesNameResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-// GetProjectsWithResponse request returning *GetProjectsResponse
-func (c *ClientWithResponses) GetProjectsWithResponse(ctx context.Context, params *GetProjectsParams, reqEditors ...RequestEditorFn) (*GetProjectsResponse, error) {
-	rsp, err := c.GetProjects(ctx, params, reqEditors...)
+	var pathParam2 string
+
+	pathParam2, err = runtime.StyleParamWithLocation("simple", false, "snapshot", runtime.ParamLocationPath, snapshot)
 	if err != nil {
 		return nil, err
 	}
-	return ParseGetProjectsResponse(rsp)
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/snapshots/%s", pathParam0, pathParam1, pathParam2)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
 }
 
-// PostProjectsWithBodyWithResponse request with arbitrary body returning *PostProjectsResponse
-func (c *ClientWithResponses) PostProjectsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error) {
-	rsp, err := c.PostProjectsWithBody(ctx, contentType, body, reqEditors...)
+// NewPostProjectsProjectIdDatabasesNameStartRequest generates requests for PostProjectsProjectIdDatabasesNameStart
+func NewPostProjectsProjectIdDatabasesNameStartRequest(server string, projectId string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
 	if err != nil {
 		return nil, err
 	}
-	return ParsePostProjectsResponse(rsp)
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/start", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
 }
 
-func (c *ClientWithResponses) PostProjectsWithResponse(ctx context.Context, body PostProjectsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error) {
-	rsp, err := c.PostProjects(ctx, body, reqEditors...)
+// NewPostProjectsProjectIdDatabasesNameStopRequest calls the generic PostProjectsProjectIdDatabasesNameStop builder with application/json body
+func NewPostProjectsProjectIdDatabasesNameStopRequest(server string, projectId string, name string, body PostProjectsProjectIdDatabasesNameStopJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
-	return ParsePostProjectsResponse(rsp)
+	bodyReader = bytes.NewReader(buf)
+	return NewPostProjectsProjectIdDatabasesNameStopRequestWithBody(server, projectId, name, "application/json", bodyReader)
 }
 
-// DeleteProjectWithResponse request returning *DeleteProjectResponse
-func (c *ClientWithResponses) DeleteProjectWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*DeleteProjectResponse, error) {
-	rsp, err := c.DeleteProject(ctx, projectId, reqEditors...)
-	if err != nil {
-		return nil, err
+// NewPostProjectsProjectIdDatabasesNameStopRequestWithBody generates requests for PostProjectsProjectIdDatabasesNameStop with any type of body
+func NewPostProjectsProjectIdDatabasesNameStopRequestWithBody(server string, projectId string, name string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/projects/%s/databases/%s/stop", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewPostProjectsProjectIdRefreshRequest calls the generic PostProjectsProjectIdRefresh builder with application/json body
+func NewPostProjectsProjectIdRefreshRequest(server string, projectId string, body PostProjectsProjectIdRefreshJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostProjectsProjectIdRefreshRequestWithBody(server, projectId, "application/json", bodyReader)
+}
+
+// NewPostProjectsProjectIdRefreshRequestWithBody generates requests for PostProjectsProjectIdRefresh with any type of body
+func NewPostProjectsProjectIdRefreshRequestWithBody(server string, projectId string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "projectId", runtime.ParamLocationPath, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/projects/%s/refresh", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// PostBackupsUploadsWithBodyWithResponse request with any body
+	PostBackupsUploadsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostBackupsUploadsResponse, error)
+
+	PostBackupsUploadsWithResponse(ctx context.Context, body PostBackupsUploadsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostBackupsUploadsResponse, error)
+
+	// GetCatalogWithResponse request
+	GetCatalogWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetCatalogResponse, error)
+
+	// GetProjectsWithResponse request
+	GetProjectsWithResponse(ctx context.Context, params *GetProjectsParams, reqEditors ...RequestEditorFn) (*GetProjectsResponse, error)
+
+	// PostProjectsWithBodyWithResponse request with any body
+	PostProjectsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error)
+
+	PostProjectsWithResponse(ctx context.Context, body PostProjectsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error)
+
+	// DeleteProjectWithResponse request
+	DeleteProjectWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*DeleteProjectResponse, error)
+
+	// GetProjectsProjectIdWithResponse request
+	GetProjectsProjectIdWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdResponse, error)
+
+	// GetProjectsProjectIdDatabasesWithResponse request
+	GetProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesResponse, error)
+
+	// PostProjectsProjectIdDatabasesWithBodyWithResponse request with any body
+	PostProjectsProjectIdDatabasesWithBodyWithResponse(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error)
+
+	PostProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, body PostProjectsProjectIdDatabasesJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error)
+
+	// DeleteProjectsProjectIdDatabasesNameWithResponse request
+	DeleteProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*DeleteProjectsProjectIdDatabasesNameResponse, error)
+
+	// GetProjectsProjectIdDatabasesNameWithResponse request
+	GetProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesNameResponse, error)
+
+	// PostProjectsProjectIdDatabasesNameExtendWithBodyWithResponse request with any body
+	PostProjectsProjectIdDatabasesNameExtendWithBodyWithResponse(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameExtendResponse, error)
+
+	PostProjectsProjectIdDatabasesNameExtendWithResponse(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameExtendJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameExtendResponse, error)
+
+	// PostProjectsProjectIdDatabasesNameResetWithResponse request
+	PostProjectsProjectIdDatabasesNameResetWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameResetResponse, error)
+
+	// GetProjectsProjectIdDatabasesNameSnapshotsWithResponse request
+	GetProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesNameSnapshotsResponse, error)
+
+	// PostProjectsProjectIdDatabasesNameSnapshotsWithBodyWithResponse request with any body
+	PostProjectsProjectIdDatabasesNameSnapshotsWithBodyWithResponse(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameSnapshotsResponse, error)
+
+	PostProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameSnapshotsResponse, error)
+
+	// DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse request
+	DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse(ctx context.Context, projectId string, name string, snapshot string, reqEditors ...RequestEditorFn) (*DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse, error)
+
+	// PostProjectsProjectIdDatabasesNameStartWithResponse request
+	PostProjectsProjectIdDatabasesNameStartWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameStartResponse, error)
+
+	// PostProjectsProjectIdDatabasesNameStopWithBodyWithResponse request with any body
+	PostProjectsProjectIdDatabasesNameStopWithBodyWithResponse(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameStopResponse, error)
+
+	PostProjectsProjectIdDatabasesNameStopWithResponse(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameStopJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameStopResponse, error)
+
+	// PostProjectsProjectIdRefreshWithBodyWithResponse request with any body
+	PostProjectsProjectIdRefreshWithBodyWithResponse(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdRefreshResponse, error)
+
+	PostProjectsProjectIdRefreshWithResponse(ctx context.Context, projectId string, body PostProjectsProjectIdRefreshJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdRefreshResponse, error)
+}
+
+type PostBackupsUploadsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *BackupUpload
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostBackupsUploadsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostBackupsUploadsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetCatalogResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Catalog
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetCatalogResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetCatalogResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProjectsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Project
+	JSON401      *Unauthorized
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProjectsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProjectsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *Project
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON409      *Conflict
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteProjectResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteProjectResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteProjectResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProjectsProjectIdResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Project
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProjectsProjectIdResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProjectsProjectIdResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProjectsProjectIdDatabasesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Database
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProjectsProjectIdDatabasesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProjectsProjectIdDatabasesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdDatabasesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *Database
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON409      *Conflict
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdDatabasesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdDatabasesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteProjectsProjectIdDatabasesNameResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *struct {
+		Message *string `json:"message,omitempty"`
+	}
+	JSON401 *Unauthorized
+	JSON404 *NotFound
+	JSON500 *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteProjectsProjectIdDatabasesNameResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteProjectsProjectIdDatabasesNameResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProjectsProjectIdDatabasesNameResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Database
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProjectsProjectIdDatabasesNameResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProjectsProjectIdDatabasesNameResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdDatabasesNameExtendResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Database
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdDatabasesNameExtendResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdDatabasesNameExtendResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdDatabasesNameResetResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Database
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdDatabasesNameResetResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdDatabasesNameResetResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetProjectsProjectIdDatabasesNameSnapshotsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Snapshot
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetProjectsProjectIdDatabasesNameSnapshotsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetProjectsProjectIdDatabasesNameSnapshotsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdDatabasesNameSnapshotsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *Snapshot
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON409      *Conflict
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdDatabasesNameSnapshotsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdDatabasesNameSnapshotsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *struct {
+		Message *string `json:"message,omitempty"`
+	}
+	JSON401 *Unauthorized
+	JSON404 *NotFound
+	JSON500 *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdDatabasesNameStartResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Database
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON409      *Conflict
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdDatabasesNameStartResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdDatabasesNameStartResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdDatabasesNameStopResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Database
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON409      *Conflict
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdDatabasesNameStopResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdDatabasesNameStopResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostProjectsProjectIdRefreshResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DataRefresh
+	JSON400      *BadRequest
+	JSON401      *Unauthorized
+	JSON404      *NotFound
+	JSON500      *InternalError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostProjectsProjectIdRefreshResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostProjectsProjectIdRefreshResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// PostBackupsUploadsWithBodyWithResponse request with arbitrary body returning *PostBackupsUploadsResponse
+func (c *ClientWithResponses) PostBackupsUploadsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostBackupsUploadsResponse, error) {
+	rsp, err := c.PostBackupsUploadsWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostBackupsUploadsResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostBackupsUploadsWithResponse(ctx context.Context, body PostBackupsUploadsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostBackupsUploadsResponse, error) {
+	rsp, err := c.PostBackupsUploads(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostBackupsUploadsResponse(rsp)
+}
+
+// GetCatalogWithResponse request returning *GetCatalogResponse
+func (c *ClientWithResponses) GetCatalogWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetCatalogResponse, error) {
+	rsp, err := c.GetCatalog(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetCatalogResponse(rsp)
+}
+
+// GetProjectsWithResponse request returning *GetProjectsResponse
+func (c *ClientWithResponses) GetProjectsWithResponse(ctx context.Context, params *GetProjectsParams, reqEditors ...RequestEditorFn) (*GetProjectsResponse, error) {
+	rsp, err := c.GetProjects(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProjectsResponse(rsp)
+}
+
+// PostProjectsWithBodyWithResponse request with arbitrary body returning *PostProjectsResponse
+func (c *ClientWithResponses) PostProjectsWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error) {
+	rsp, err := c.PostProjectsWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProjectsWithResponse(ctx context.Context, body PostProjectsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsResponse, error) {
+	rsp, err := c.PostProjects(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsResponse(rsp)
+}
+
+// DeleteProjectWithResponse request returning *DeleteProjectResponse
+func (c *ClientWithResponses) DeleteProjectWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*DeleteProjectResponse, error) {
+	rsp, err := c.DeleteProject(ctx, projectId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteProjectResponse(rsp)
+}
+
+// GetProjectsProjectIdWithResponse request returning *GetProjectsProjectIdResponse
+func (c *ClientWithResponses) GetProjectsProjectIdWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdResponse, error) {
+	rsp, err := c.GetProjectsProjectId(ctx, projectId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProjectsProjectIdResponse(rsp)
+}
+
+// GetProjectsProjectIdDatabasesWithResponse request returning *GetProjectsProjectIdDatabasesResponse
+func (c *ClientWithResponses) GetProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesResponse, error) {
+	rsp, err := c.GetProjectsProjectIdDatabases(ctx, projectId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProjectsProjectIdDatabasesResponse(rsp)
+}
+
+// PostProjectsProjectIdDatabasesWithBodyWithResponse request with arbitrary body returning *PostProjectsProjectIdDatabasesResponse
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesWithBodyWithResponse(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesWithBody(ctx, projectId, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, body PostProjectsProjectIdDatabasesJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabases(ctx, projectId, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesResponse(rsp)
+}
+
+// DeleteProjectsProjectIdDatabasesNameWithResponse request returning *DeleteProjectsProjectIdDatabasesNameResponse
+func (c *ClientWithResponses) DeleteProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*DeleteProjectsProjectIdDatabasesNameResponse, error) {
+	rsp, err := c.DeleteProjectsProjectIdDatabasesName(ctx, projectId, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteProjectsProjectIdDatabasesNameResponse(rsp)
+}
+
+// GetProjectsProjectIdDatabasesNameWithResponse request returning *GetProjectsProjectIdDatabasesNameResponse
+func (c *ClientWithResponses) GetProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesNameResponse, error) {
+	rsp, err := c.GetProjectsProjectIdDatabasesName(ctx, projectId, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProjectsProjectIdDatabasesNameResponse(rsp)
+}
+
+// PostProjectsProjectIdDatabasesNameExtendWithBodyWithResponse request with arbitrary body returning *PostProjectsProjectIdDatabasesNameExtendResponse
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameExtendWithBodyWithResponse(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameExtendResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameExtendWithBody(ctx, projectId, name, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameExtendResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameExtendWithResponse(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameExtendJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameExtendResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameExtend(ctx, projectId, name, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameExtendResponse(rsp)
+}
+
+// PostProjectsProjectIdDatabasesNameResetWithResponse request returning *PostProjectsProjectIdDatabasesNameResetResponse
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameResetWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameResetResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameReset(ctx, projectId, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameResetResponse(rsp)
+}
+
+// GetProjectsProjectIdDatabasesNameSnapshotsWithResponse request returning *GetProjectsProjectIdDatabasesNameSnapshotsResponse
+func (c *ClientWithResponses) GetProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesNameSnapshotsResponse, error) {
+	rsp, err := c.GetProjectsProjectIdDatabasesNameSnapshots(ctx, projectId, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetProjectsProjectIdDatabasesNameSnapshotsResponse(rsp)
+}
+
+// PostProjectsProjectIdDatabasesNameSnapshotsWithBodyWithResponse request with arbitrary body returning *PostProjectsProjectIdDatabasesNameSnapshotsResponse
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameSnapshotsWithBodyWithResponse(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameSnapshotsResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameSnapshotsWithBody(ctx, projectId, name, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameSnapshotsResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameSnapshotsJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameSnapshotsResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameSnapshots(ctx, projectId, name, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameSnapshotsResponse(rsp)
+}
+
+// DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse request returning *DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse
+func (c *ClientWithResponses) DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse(ctx context.Context, projectId string, name string, snapshot string, reqEditors ...RequestEditorFn) (*DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse, error) {
+	rsp, err := c.DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshot(ctx, projectId, name, snapshot, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse(rsp)
+}
+
+// PostProjectsProjectIdDatabasesNameStartWithResponse request returning *PostProjectsProjectIdDatabasesNameStartResponse
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameStartWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameStartResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameStart(ctx, projectId, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameStartResponse(rsp)
+}
+
+// PostProjectsProjectIdDatabasesNameStopWithBodyWithResponse request with arbitrary body returning *PostProjectsProjectIdDatabasesNameStopResponse
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameStopWithBodyWithResponse(ctx context.Context, projectId string, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameStopResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameStopWithBody(ctx, projectId, name, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameStopResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProjectsProjectIdDatabasesNameStopWithResponse(ctx context.Context, projectId string, name string, body PostProjectsProjectIdDatabasesNameStopJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesNameStopResponse, error) {
+	rsp, err := c.PostProjectsProjectIdDatabasesNameStop(ctx, projectId, name, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdDatabasesNameStopResponse(rsp)
+}
+
+// PostProjectsProjectIdRefreshWithBodyWithResponse request with arbitrary body returning *PostProjectsProjectIdRefreshResponse
+func (c *ClientWithResponses) PostProjectsProjectIdRefreshWithBodyWithResponse(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdRefreshResponse, error) {
+	rsp, err := c.PostProjectsProjectIdRefreshWithBody(ctx, projectId, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdRefreshResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostProjectsProjectIdRefreshWithResponse(ctx context.Context, projectId string, body PostProjectsProjectIdRefreshJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdRefreshResponse, error) {
+	rsp, err := c.PostProjectsProjectIdRefresh(ctx, projectId, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostProjectsProjectIdRefreshResponse(rsp)
+}
+
+// ParsePostBackupsUploadsResponse parses an HTTP response from a PostBackupsUploadsWithResponse call
+func ParsePostBackupsUploadsResponse(rsp *http.Response) (*PostBackupsUploadsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostBackupsUploadsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest BackupUpload
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetCatalogResponse parses an HTTP response from a GetCatalogWithResponse call
+func ParseGetCatalogResponse(rsp *http.Response) (*GetCatalogResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetCatalogResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Catalog
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetProjectsResponse parses an HTTP response from a GetProjectsWithResponse call
+func ParseGetProjectsResponse(rsp *http.Response) (*GetProjectsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProjectsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Project
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostProjectsResponse parses an HTTP response from a PostProjectsWithResponse call
+func ParsePostProjectsResponse(rsp *http.Response) (*PostProjectsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostProjectsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest Project
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Conflict
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteProjectResponse parses an HTTP response from a DeleteProjectWithResponse call
+func ParseDeleteProjectResponse(rsp *http.Response) (*DeleteProjectResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteProjectResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetProjectsProjectIdResponse parses an HTTP response from a GetProjectsProjectIdWithResponse call
+func ParseGetProjectsProjectIdResponse(rsp *http.Response) (*GetProjectsProjectIdResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProjectsProjectIdResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Project
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetProjectsProjectIdDatabasesResponse parses an HTTP response from a GetProjectsProjectIdDatabasesWithResponse call
+func ParseGetProjectsProjectIdDatabasesResponse(rsp *http.Response) (*GetProjectsProjectIdDatabasesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetProjectsProjectIdDatabasesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Database
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostProjectsProjectIdDatabasesResponse parses an HTTP response from a PostProjectsProjectIdDatabasesWithResponse call
+func ParsePostProjectsProjectIdDatabasesResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostProjectsProjectIdDatabasesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest Database
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Conflict
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteProjectsProjectIdDatabasesNameResponse parses an HTTP response from a DeleteProjectsProjectIdDatabasesNameWithResponse call
+func ParseDeleteProjectsProjectIdDatabasesNameResponse(rsp *http.Response) (*DeleteProjectsProjectIdDatabasesNameResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteProjectsProjectIdDatabasesNameResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest struct {
+			Message *string `json:"message,omitempty"`
+		}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
 	}
-	return ParseDeleteProjectResponse(rsp)
-}
 
-// GetProjectsProjectIdWithResponse request returning *GetProjectsProjectIdResponse
-func (c *ClientWithResponses) GetProjectsProjectIdWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdResponse, error) {
-	rsp, err := c.GetProjectsProjectId(ctx, projectId, reqEditors...)
-	if err != nil {
-		return nil, err
-	}
-	return ParseGetProjectsProjectIdResponse(rsp)
+	return response, nil
 }
 
-// GetProjectsProjectIdDatabasesWithResponse request returning *GetProjectsProjectIdDatabasesResponse
-func (c *ClientWithResponses) GetProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesResponse, error) {
-	rsp, err := c.GetProjectsProjectIdDatabases(ctx, projectId, reqEditors...)
+// ParseGetProjectsProjectIdDatabasesNameResponse parses an HTTP response from a GetProjectsProjectIdDatabasesNameWithResponse call
+func ParseGetProjectsProjectIdDatabasesNameResponse(rsp *http.Response) (*GetProjectsProjectIdDatabasesNameResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseGetProjectsProjectIdDatabasesResponse(rsp)
-}
 
-// PostProjectsProjectIdDatabasesWithBodyWithResponse request with arbitrary body returning *PostProjectsProjectIdDatabasesResponse
-func (c *ClientWithResponses) PostProjectsProjectIdDatabasesWithBodyWithResponse(ctx context.Context, projectId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error) {
-	rsp, err := c.PostProjectsProjectIdDatabasesWithBody(ctx, projectId, contentType, body, reqEditors...)
-	if err != nil {
-		return nil, err
+	response := &GetProjectsProjectIdDatabasesNameResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
 	}
-	return ParsePostProjectsProjectIdDatabasesResponse(rsp)
-}
 
-func (c *ClientWithResponses) PostProjectsProjectIdDatabasesWithResponse(ctx context.Context, projectId string, body PostProjectsProjectIdDatabasesJSONRequestBody, reqEditors ...RequestEditorFn) (*PostProjectsProjectIdDatabasesResponse, error) {
-	rsp, err := c.PostProjectsProjectIdDatabases(ctx, projectId, body, reqEditors...)
-	if err != nil {
-		return nil, err
-	}
-	return ParsePostProjectsProjectIdDatabasesResponse(rsp)
-}
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Database
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
 
-// DeleteProjectsProjectIdDatabasesNameWithResponse request returning *DeleteProjectsProjectIdDatabasesNameResponse
-func (c *ClientWithResponses) DeleteProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*DeleteProjectsProjectIdDatabasesNameResponse, error) {
-	rsp, err := c.DeleteProjectsProjectIdDatabasesName(ctx, projectId, name, reqEditors...)
-	if err != nil {
-		return nil, err
-	}
-	return ParseDeleteProjectsProjectIdDatabasesNameResponse(rsp)
-}
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
 
-// GetProjectsProjectIdDatabasesNameWithResponse request returning *GetProjectsProjectIdDatabasesNameResponse
-func (c *ClientWithResponses) GetProjectsProjectIdDatabasesNameWithResponse(ctx context.Context, projectId string, name string, reqEditors ...RequestEditorFn) (*GetProjectsProjectIdDatabasesNameResponse, error) {
-	rsp, err := c.GetProjectsProjectIdDatabasesName(ctx, projectId, name, reqEditors...)
-	if err != nil {
-		return nil, err
 	}
-	return ParseGetProjectsProjectIdDatabasesNameResponse(rsp)
+
+	return response, nil
 }
 
-// ParseGetProjectsResponse parses an HTTP response from a GetProjectsWithResponse call
-func ParseGetProjectsResponse(rsp *http.Response) (*GetProjectsResponse, error) {
+// ParsePostProjectsProjectIdDatabasesNameExtendResponse parses an HTTP response from a PostProjectsProjectIdDatabasesNameExtendWithResponse call
+func ParsePostProjectsProjectIdDatabasesNameExtendResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesNameExtendResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &GetProjectsResponse{
+	response := &PostProjectsProjectIdDatabasesNameExtendResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest []Project
+		var dest Database
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
 		response.JSON200 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
 	}
 
 	return response, nil
 }
 
-// ParsePostProjectsResponse parses an HTTP response from a PostProjectsWithResponse call
-func ParsePostProjectsResponse(rsp *http.Response) (*PostProjectsResponse, error) {
+// ParsePostProjectsProjectIdDatabasesNameResetResponse parses an HTTP response from a PostProjectsProjectIdDatabasesNameResetWithResponse call
+func ParsePostProjectsProjectIdDatabasesNameResetResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesNameResetResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &PostProjectsResponse{
+	response := &PostProjectsProjectIdDatabasesNameResetResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
-		var dest Project
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Database
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
-		response.JSON201 = &dest
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
 
 	}
 
 	return response, nil
 }
 
-// ParseDeleteProjectResponse parses an HTTP response from a DeleteProjectWithResponse call
-func ParseDeleteProjectResponse(rsp *http.Response) (*DeleteProjectResponse, error) {
+// ParseGetProjectsProjectIdDatabasesNameSnapshotsResponse parses an HTTP response from a GetProjectsProjectIdDatabasesNameSnapshotsWithResponse call
+func ParseGetProjectsProjectIdDatabasesNameSnapshotsResponse(rsp *http.Response) (*GetProjectsProjectIdDatabasesNameSnapshotsResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &DeleteProjectResponse{
+	response := &GetProjectsProjectIdDatabasesNameSnapshotsResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Snapshot
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
 	return response, nil
 }
 
-// ParseGetProjectsProjectIdResponse parses an HTTP response from a GetProjectsProjectIdWithResponse call
-func ParseGetProjectsProjectIdResponse(rsp *http.Response) (*GetProjectsProjectIdResponse, error) {
+// ParsePostProjectsProjectIdDatabasesNameSnapshotsResponse parses an HTTP response from a PostProjectsProjectIdDatabasesNameSnapshotsWithResponse call
+func ParsePostProjectsProjectIdDatabasesNameSnapshotsResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesNameSnapshotsResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &GetProjectsProjectIdResponse{
+	response := &PostProjectsProjectIdDatabasesNameSnapshotsResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest Project
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest Snapshot
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
-		response.JSON200 = &dest
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Conflict
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
 
 	}
 
 	return response, nil
 }
 
-// ParseGetProjectsProjectIdDatabasesResponse parses an HTTP response from a GetProjectsProjectIdDatabasesWithResponse call
-func ParseGetProjectsProjectIdDatabasesResponse(rsp *http.Response) (*GetProjectsProjectIdDatabasesResponse, error) {
+// ParseDeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse parses an HTTP response from a DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse call
+func ParseDeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse(rsp *http.Response) (*DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &GetProjectsProjectIdDatabasesResponse{
+	response := &DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest []Database
+		var dest struct {
+			Message *string `json:"message,omitempty"`
+		}
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
 		response.JSON200 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
 	}
 
 	return response, nil
 }
 
-// ParsePostProjectsProjectIdDatabasesResponse parses an HTTP response from a PostProjectsProjectIdDatabasesWithResponse call
-func ParsePostProjectsProjectIdDatabasesResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesResponse, error) {
+// ParsePostProjectsProjectIdDatabasesNameStartResponse parses an HTTP response from a PostProjectsProjectIdDatabasesNameStartWithResponse call
+func ParsePostProjectsProjectIdDatabasesNameStartResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesNameStartResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &PostProjectsProjectIdDatabasesResponse{
+	response := &PostProjectsProjectIdDatabasesNameStartResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
-	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
 		var dest Database
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
-		response.JSON201 = &dest
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Conflict
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
 
 	}
 
 	return response, nil
 }
 
-// ParseDeleteProjectsProjectIdDatabasesNameResponse parses an HTTP response from a DeleteProjectsProjectIdDatabasesNameWithResponse call
-func ParseDeleteProjectsProjectIdDatabasesNameResponse(rsp *http.Response) (*DeleteProjectsProjectIdDatabasesNameResponse, error) {
+// ParsePostProjectsProjectIdDatabasesNameStopResponse parses an HTTP response from a PostProjectsProjectIdDatabasesNameStopWithResponse call
+func ParsePostProjectsProjectIdDatabasesNameStopResponse(rsp *http.Response) (*PostProjectsProjectIdDatabasesNameStopResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &DeleteProjectsProjectIdDatabasesNameResponse{
+	response := &PostProjectsProjectIdDatabasesNameStopResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest struct {
-			Message *string `json:"message,omitempty"`
-		}
+		var dest Database
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
 		response.JSON200 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest Conflict
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
 	}
 
 	return response, nil
 }
 
-// ParseGetProjectsProjectIdDatabasesNameResponse parses an HTTP response from a GetProjectsProjectIdDatabasesNameWithResponse call
-func ParseGetProjectsProjectIdDatabasesNameResponse(rsp *http.Response) (*GetProjectsProjectIdDatabasesNameResponse, error) {
+// ParsePostProjectsProjectIdRefreshResponse parses an HTTP response from a PostProjectsProjectIdRefreshWithResponse call
+func ParsePostProjectsProjectIdRefreshResponse(rsp *http.Response) (*PostProjectsProjectIdRefreshResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &GetProjectsProjectIdDatabasesNameResponse{
+	response := &PostProjectsProjectIdRefreshResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest Database
+		var dest DataRefresh
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
 		response.JSON200 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest BadRequest
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest Unauthorized
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest NotFound
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest InternalError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
 	}
 
 	return response, nil
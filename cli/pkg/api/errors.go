@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the API answers with an unexpected status code.
+// Problem holds the RFC 7807 problem details from the response body, or
+// the body of a plain text response, when there are any.
+type APIError struct {
+	StatusCode int
+	Problem    *Problem
+}
+
+// NewError builds an APIError from a response and its body, which the
+// generated client has already read.
+func NewError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode}
+
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "json"):
+		p := &Problem{}
+		if json.Unmarshal(body, p) == nil && (p.Title != "" || p.Detail != nil) {
+			e.Problem = p
+		}
+	case strings.HasPrefix(contentType, "text/plain"):
+		// Servers predating problem details send the reason as text
+		if text := strings.TrimSpace(string(body)); text != "" {
+			e.Problem = &Problem{Status: resp.StatusCode, Title: http.StatusText(resp.StatusCode), Detail: &text}
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.Problem == nil {
+		return fmt.Sprintf("API returned status code %d", e.StatusCode)
+	}
+	title, detail := e.Problem.Title, ""
+	if e.Problem.Detail != nil {
+		detail = *e.Problem.Detail
+	}
+	switch {
+	case detail == "" || detail == title:
+		return fmt.Sprintf("%s (status code %d)", title, e.StatusCode)
+	case title == "":
+		return fmt.Sprintf("%s (status code %d)", detail, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", title, detail)
+}
+
+// StatusCode returns the status code of the API error in err's chain, or 0
+// if there is none.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is an API error for a missing resource.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err is an API error for a resource that
+// already exists.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        string
+		notFound    bool
+		conflict    bool
+	}{
+		{
+			name:        "problem details",
+			status:      http.StatusBadRequest,
+			contentType: "application/problem+json",
+			body:        `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "Name is required"}`,
+			want:        "Bad Request: Name is required",
+		},
+		{
+			name:        "problem without detail",
+			status:      http.StatusNotFound,
+			contentType: "application/problem+json",
+			body:        `{"title": "Not Found", "status": 404}`,
+			want:        "Not Found (status code 404)",
+			notFound:    true,
+		},
+		{
+			name:        "plain text",
+			status:      http.StatusConflict,
+			contentType: "text/plain; charset=utf-8",
+			body:        "Database already exists\n",
+			want:        "Conflict: Database already exists",
+			conflict:    true,
+		},
+		{
+			name:        "json that is not a problem",
+			status:      http.StatusInternalServerError,
+			contentType: "application/json",
+			body:        `{"error": "boom"}`,
+			want:        "API returned status code 500",
+		},
+		{
+			name:   "empty body",
+			status: http.StatusBadGateway,
+			want:   "API returned status code 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			client, err := NewClientWithResponses(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.GetProjectsProjectIdWithResponse(context.Background(), "p-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = fmt.Errorf("getting project: %w", NewError(resp.HTTPResponse, resp.Body))
+			if got := err.Error(); got != "getting project: "+tt.want {
+				t.Errorf("error = %q, want %q", got, "getting project: "+tt.want)
+			}
+			if StatusCode(err) != tt.status {
+				t.Errorf("StatusCode() = %d, want %d", StatusCode(err), tt.status)
+			}
+			if IsNotFound(err) != tt.notFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.notFound)
+			}
+			if IsConflict(err) != tt.conflict {
+				t.Errorf("IsConflict() = %v, want %v", IsConflict(err), tt.conflict)
+			}
+		})
+	}
+
+	if StatusCode(fmt.Errorf("not an API error")) != 0 {
+		t.Error("StatusCode() of a plain error should be 0")
+	}
+}
+
+func TestProblemResponsesParsed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"title": "Not Found", "status": 404, "detail": "Project not found"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithResponses(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.GetProjectsProjectIdWithResponse(context.Background(), "p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.JSON404 == nil || resp.JSON404.Detail == nil || *resp.JSON404.Detail != "Project not found" {
+		t.Errorf("JSON404 = %+v, want the problem details", resp.JSON404)
+	}
+}
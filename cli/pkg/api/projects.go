@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AmbiguousProjectError is returned by ResolveProject when a name matches
+// more than one project.
+type AmbiguousProjectError struct {
+	Name       string
+	Candidates []Project
+}
+
+func (e *AmbiguousProjectError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "project name %q is ambiguous, use one of these IDs instead:", e.Name)
+	for _, p := range e.Candidates {
+		fmt.Fprintf(&b, "\n  %s (owner %s)", p.Id, p.Owner)
+	}
+	return b.String()
+}
+
+// ResolveProject returns the ID of the project ref refers to. ref is either
+// a project ID or the name of one of owner's projects; an empty owner
+// searches all projects. A ref that matches no project is returned
+// unchanged, since it may be the ID of a project owned by someone else.
+func ResolveProject(ctx context.Context, c ClientWithResponsesInterface, owner, ref string) (string, error) {
+	// Generated IDs are UUIDs and need no lookup
+	if uuidPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	params := &GetProjectsParams{}
+	if owner != "" {
+		params.Owner = &owner
+	}
+	resp, err := c.GetProjectsWithResponse(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("listing projects: %w", err)
+	}
+	if resp.StatusCode() != 200 {
+		return "", fmt.Errorf("listing projects: %w", NewError(resp.HTTPResponse, resp.Body))
+	}
+	if resp.JSON200 == nil {
+		return ref, nil
+	}
+
+	var named []Project
+	for _, p := range *resp.JSON200 {
+		if p.Id == ref {
+			return p.Id, nil
+		}
+		if p.Name == ref {
+			named = append(named, p)
+		}
+	}
+	switch len(named) {
+	case 0:
+		return ref, nil
+	case 1:
+		return named[0].Id, nil
+	default:
+		return "", &AmbiguousProjectError{Name: ref, Candidates: named}
+	}
+}
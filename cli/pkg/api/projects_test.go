@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveProject(t *testing.T) {
+	var lookups int
+	var gotOwner string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		gotOwner = r.URL.Query().Get("owner")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": "p-1", "name": "billing", "owner": "alice", "dbType": "postgres", "dbVersion": "15"},
+			{"id": "p-2", "name": "search", "owner": "alice", "dbType": "postgres", "dbVersion": "15"},
+			{"id": "p-3", "name": "search", "owner": "alice", "dbType": "postgres", "dbVersion": "16"},
+			{"id": "billing-old", "name": "legacy", "owner": "alice", "dbType": "postgres", "dbVersion": "13"}
+		]`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClientWithResponses(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		ref           string
+		want          string
+		wantAmbiguous bool
+	}{
+		{name: "by name", ref: "billing", want: "p-1"},
+		{name: "by id", ref: "p-2", want: "p-2"},
+		{name: "id wins over name", ref: "billing-old", want: "billing-old"},
+		{name: "unknown is passed through", ref: "p-9", want: "p-9"},
+		{name: "ambiguous name", ref: "search", wantAmbiguous: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveProject(context.Background(), client, "alice", tc.ref)
+			var ambiguous *AmbiguousProjectError
+			if tc.wantAmbiguous {
+				if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+					t.Fatalf("ResolveProject() error = %v, want ambiguity between 2 projects", err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("ResolveProject() = %q, %v; want %q", got, err, tc.want)
+			}
+			if gotOwner != "alice" {
+				t.Errorf("owner = %q, want alice", gotOwner)
+			}
+		})
+	}
+
+	lookups = 0
+	id := "0b8f5a7e-3c1d-4e2f-9a6b-7c8d9e0f1a2b"
+	if got, err := ResolveProject(context.Background(), client, "alice", id); err != nil || got != id {
+		t.Errorf("ResolveProject(uuid) = %q, %v", got, err)
+	}
+	if lookups != 0 {
+		t.Errorf("UUID triggered %d lookups, want none", lookups)
+	}
+}
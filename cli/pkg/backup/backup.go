@@ -0,0 +1,375 @@
+// Package backup prepares local backups (pg_dump, mysqldump, Redis RDB and
+// mongodump archives) for upload and uploads them to presigned URLs.
+//
+//	f, err := backup.Open("billing.dump")
+//	defer f.Close()
+//	sum, err := backup.Upload(ctx, http.DefaultClient, uploadURL, f, nil)
+//
+// Custom (-Fc) and plain SQL dumps are uploaded as they are. Directory
+// dumps (-Fd) are archived with tar first. That is not pg_dump's own tar
+// format (-Ft), so the server unpacks the archive and restores the
+// directory with pg_restore --format=directory.
+// mysqldump output is plain SQL. Redis RDB files and mongodump archives
+// (--archive) are single files and uploaded as they are.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+)
+
+// customMagic starts every pg_dump archive in the custom format.
+const customMagic = "PGDMP"
+
+// rdbMagic starts every Redis RDB file, followed by its version.
+const rdbMagic = "REDIS"
+
+// mongoArchiveMagic starts every mongodump archive: 0x8199e26d, little
+// endian.
+const mongoArchiveMagic = "\x6d\xe2\x99\x81"
+
+// File is a backup ready to be uploaded. Close it to remove the archive
+// of a directory dump.
+type File struct {
+	// Path is the file to upload.
+	Path string
+	// Name is the file name to upload it as.
+	Name string
+	// Size is the size of the file in bytes.
+	Size int64
+	// Format is the format of the backup.
+	Format api.BackupFormat
+	// DumpedFrom is the type of database the backup was dumped from, when
+	// it can be told; pg_dump archives are postgres, RDB files redis and
+	// mongodump archives mongodb, and plain dumps say in their header
+	// comment whether pg_dump or mysqldump wrote them.
+	DumpedFrom api.DatabaseType
+
+	temp bool
+}
+
+// Open validates the backup at path, a file or a directory dump, and
+// returns it ready for upload.
+func Open(path string) (*File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return openDirectory(path)
+	}
+
+	if info.Size() == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	format, header, err := detect(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Path: path, Name: filepath.Base(path), Size: info.Size(), Format: format}
+	switch format {
+	case api.BackupFormatPlain:
+		f.DumpedFrom = sqlDumpedFrom(header)
+	case api.BackupFormatRDB:
+		f.DumpedFrom = api.Redis
+	case api.BackupFormatArchive:
+		f.DumpedFrom = api.MongoDB
+	default:
+		f.DumpedFrom = api.Postgres
+	}
+	return f, nil
+}
+
+// CheckType returns an error if the backup cannot be restored into a
+// database of type t. Backups of unknown origin pass; MySQL and MariaDB
+// restore each other's dumps.
+func (f *File) CheckType(t api.DatabaseType) error {
+	if f.DumpedFrom == "" || f.DumpedFrom == t || engine.MySQLFamily(f.DumpedFrom) && engine.MySQLFamily(t) {
+		return nil
+	}
+	tool := "pg_dump"
+	switch {
+	case engine.MySQLFamily(f.DumpedFrom):
+		tool = "mysqldump"
+	case f.DumpedFrom == api.Redis:
+		tool = "Redis RDB"
+	case f.DumpedFrom == api.MongoDB:
+		tool = "mongodump"
+	}
+	return fmt.Errorf("%s is a %s backup and cannot be restored into a %s database", f.Name, tool, t)
+}
+
+// Close removes the temporary archive of a directory dump.
+func (f *File) Close() error {
+	if !f.temp {
+		return nil
+	}
+	return os.Remove(f.Path)
+}
+
+// Detect returns the format of the backup file at path from its header.
+// A tar archive of a directory dump is reported as api.BackupFormatDirectory.
+func Detect(path string) (api.BackupFormat, error) {
+	format, _, err := detect(path)
+	return format, err
+}
+
+// detect is Detect, also returning the start of the file.
+func detect(path string) (api.BackupFormat, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 4096)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte(customMagic)):
+		return api.BackupFormatCustom, header, nil
+	case bytes.HasPrefix(header, []byte(rdbMagic)):
+		return api.BackupFormatRDB, header, nil
+	case bytes.HasPrefix(header, []byte(mongoArchiveMagic)):
+		return api.BackupFormatArchive, header, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return "", nil, fmt.Errorf("%s is gzip-compressed: decompress it first, or dump with pg_dump -Fc, which compresses by itself", path)
+	case len(header) > 262 && string(header[257:262]) == "ustar":
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", nil, err
+		}
+		if hasTOC(file) {
+			return api.BackupFormatDirectory, header, nil
+		}
+		return "", nil, fmt.Errorf("%s is a tar archive without a toc.dat, not a pg_dump directory dump", path)
+	case looksLikeSQL(header):
+		return api.BackupFormatPlain, header, nil
+	}
+	return "", nil, fmt.Errorf("%s is not a backup DevDB can restore: expected a pg_dump custom (-Fc), directory (-Fd) or plain SQL dump, a Redis RDB file or a mongodump archive", path)
+}
+
+// sqlDumpedFrom tells from the header comment of a plain SQL dump whether
+// pg_dump or mysqldump wrote it. mysqldump and mariadb-dump start with
+// "-- MySQL dump" or "-- MariaDB dump", followed by the server version.
+func sqlDumpedFrom(header []byte) api.DatabaseType {
+	scanner := bufio.NewScanner(bytes.NewReader(header))
+	for i := 0; i < 10 && scanner.Scan(); i++ {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "-- PostgreSQL database dump"):
+			return api.Postgres
+		case strings.HasPrefix(line, "-- MariaDB dump"):
+			return api.MariaDB
+		case strings.HasPrefix(line, "-- MySQL dump"):
+			// mysqldump from a MySQL client dumping a MariaDB server
+			// says so on the server version line
+			if bytes.Contains(header, []byte("MariaDB")) {
+				return api.MariaDB
+			}
+			return api.MySQL
+		}
+	}
+	return ""
+}
+
+// hasTOC reports whether a tar archive has a top-level toc.dat.
+func hasTOC(r io.Reader) bool {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			return false
+		}
+		if path.Clean(hdr.Name) == "toc.dat" {
+			return true
+		}
+	}
+}
+
+// looksLikeSQL reports whether the start of a file is text whose first
+// statement is a comment or a statement pg_dump writes.
+func looksLikeSQL(header []byte) bool {
+	if bytes.IndexByte(header, 0) >= 0 {
+		return false
+	}
+	// The header may end in the middle of a multi-byte character
+	for i := 0; i < utf8.UTFMax && len(header) > 0 && !utf8.Valid(header); i++ {
+		header = header[:len(header)-1]
+	}
+	if !utf8.Valid(header) {
+		return false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(header))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		upper := strings.ToUpper(line)
+		for _, prefix := range []string{"--", "/*", "\\", "SET ", "SELECT ", "CREATE ", "BEGIN", "COPY ", "INSERT ", "ALTER ", "DROP "} {
+			if strings.HasPrefix(upper, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// openDirectory archives a directory dump into a temporary tar file.
+func openDirectory(dir string) (*File, error) {
+	if _, err := os.Stat(filepath.Join(dir, "toc.dat")); err != nil {
+		if bson, _ := filepath.Glob(filepath.Join(dir, "*", "*.bson")); len(bson) > 0 {
+			return nil, fmt.Errorf("%s is a mongodump directory: dump to a single file with mongodump --archive=FILE instead", dir)
+		}
+		return nil, fmt.Errorf("%s is not a pg_dump directory dump: it has no toc.dat", dir)
+	}
+
+	tmp, err := os.CreateTemp("", "devdb-backup-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTar(tmp, dir); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("archiving %s: %v", dir, err)
+	}
+	info, err := tmp.Stat()
+	if err == nil {
+		err = tmp.Close()
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	name := filepath.Base(filepath.Clean(dir)) + ".tar"
+	return &File{Path: tmp.Name(), Name: name, Size: info.Size(), Format: api.BackupFormatDirectory, DumpedFrom: api.Postgres, temp: true}, nil
+}
+
+// writeTar writes the regular files of dir to w, with names relative to
+// dir, so unpacking the archive gives back the directory pg_restore reads.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Doer sends HTTP requests; *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Upload PUTs f to a presigned URL and returns the hex SHA-256 checksum of
+// what was sent. progress, when not nil, is called with the number of
+// bytes sent so far as the upload goes.
+func Upload(ctx context.Context, client Doer, url string, f *File, progress func(sent int64)) (string, error) {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	body := &progressReader{r: io.TeeReader(file, hash), progress: progress}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = f.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("uploading %s: %w", f.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("uploading %s: %s", f.Name, storageError(resp))
+	}
+	if body.sent != f.Size {
+		return "", fmt.Errorf("uploading %s: sent %d of %d bytes; did the file change?", f.Name, body.sent, f.Size)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// storageError describes a failed upload, using the message of an S3
+// error document when there is one.
+func storageError(resp *http.Response) string {
+	var doc struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &doc) == nil && doc.Code != "" {
+		return fmt.Sprintf("%s (%s: %s)", resp.Status, doc.Code, doc.Message)
+	}
+	return resp.Status
+}
+
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	progress func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.sent += int64(n)
+	if n > 0 && p.progress != nil {
+		p.progress(p.sent)
+	}
+	return n, err
+}
@@ -0,0 +1,264 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/s3local"
+)
+
+func writeFile(t *testing.T, path string, data string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	dumpDir := filepath.Join(dir, "billing")
+	writeFile(t, filepath.Join(dumpDir, "toc.dat"), "PGDMP toc")
+	writeFile(t, filepath.Join(dumpDir, "3456.dat.gz"), "\x1f\x8bdata")
+
+	tests := []struct {
+		name    string
+		path    string
+		want    api.BackupFormat
+		wantErr string
+	}{
+		{name: "custom", path: writeFile(t, filepath.Join(dir, "a.dump"), "PGDMP\x01\x0e\x00rest"), want: api.BackupFormatCustom},
+		{name: "plain", path: writeFile(t, filepath.Join(dir, "a.sql"), "--\n-- PostgreSQL database dump\n--\n\nSET statement_timeout = 0;\n"), want: api.BackupFormatPlain},
+		{name: "plain without comments", path: writeFile(t, filepath.Join(dir, "b.sql"), "\ncreate table t (id int);\n"), want: api.BackupFormatPlain},
+		{name: "directory", path: dumpDir, want: api.BackupFormatDirectory},
+		{name: "gzip", path: writeFile(t, filepath.Join(dir, "a.sql.gz"), "\x1f\x8b\x08\x00"), wantErr: "gzip-compressed"},
+		{name: "text", path: writeFile(t, filepath.Join(dir, "notes.txt"), "shopping list\n"), wantErr: "not a backup DevDB can restore"},
+		{name: "binary", path: writeFile(t, filepath.Join(dir, "a.bin"), "\x00\x01\x02"), wantErr: "not a backup DevDB can restore"},
+		{name: "empty", path: writeFile(t, filepath.Join(dir, "empty.dump"), ""), wantErr: "is empty"},
+		{name: "directory without toc", path: filepath.Dir(writeFile(t, filepath.Join(dir, "other", "x"), "x")), wantErr: "no toc.dat"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Open(tc.path)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Open = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if f.Format != tc.want {
+				t.Errorf("format = %q, want %q", f.Format, tc.want)
+			}
+		})
+	}
+}
+
+func TestDumpedFrom(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		data string
+		want api.DatabaseType
+	}{
+		{name: "custom", data: "PGDMP\x01\x0e\x00rest", want: api.Postgres},
+		{name: "pg_dump", data: "--\n-- PostgreSQL database dump\n--\n", want: api.Postgres},
+		{name: "mysqldump", data: "-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)\n--\n-- Server version\t8.0.36\n", want: api.MySQL},
+		{name: "mysqldump of mariadb", data: "-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)\n--\n-- Server version\t10.11.6-MariaDB-1\n", want: api.MariaDB},
+		{name: "mariadb-dump", data: "-- MariaDB dump 10.19  Distrib 10.11.6-MariaDB, for debian-linux-gnu (x86_64)\n", want: api.MariaDB},
+		{name: "rdb", data: "REDIS0011\xfa\tredis-ver\x057.2.5", want: api.Redis},
+		{name: "mongodump", data: "\x6d\xe2\x99\x81\x92\x00\x00\x00\x02concurrent_collections", want: api.MongoDB},
+		{name: "unknown", data: "create table t (id int);\n", want: ""},
+	}
+	for _, tc := range tests {
+		f, err := Open(writeFile(t, filepath.Join(dir, tc.name), tc.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.DumpedFrom != tc.want {
+			t.Errorf("%s: DumpedFrom = %q, want %q", tc.name, f.DumpedFrom, tc.want)
+		}
+	}
+
+	mysql := &File{Name: "billing.sql", DumpedFrom: api.MySQL}
+	if err := mysql.CheckType(api.MariaDB); err != nil {
+		t.Errorf("CheckType(mariadb) = %v", err)
+	}
+	if err := mysql.CheckType(api.Postgres); err == nil || err.Error() != "billing.sql is a mysqldump backup and cannot be restored into a postgres database" {
+		t.Errorf("CheckType(postgres) = %v", err)
+	}
+	custom := &File{Name: "billing.dump", DumpedFrom: api.Postgres}
+	if err := custom.CheckType(api.MySQL); err == nil || err.Error() != "billing.dump is a pg_dump backup and cannot be restored into a mysql database" {
+		t.Errorf("CheckType(mysql) = %v", err)
+	}
+	rdb := &File{Name: "dump.rdb", DumpedFrom: api.Redis}
+	if err := rdb.CheckType(api.MongoDB); err == nil || err.Error() != "dump.rdb is a Redis RDB backup and cannot be restored into a mongodb database" {
+		t.Errorf("CheckType(mongodb) = %v", err)
+	}
+	if err := (&File{Name: "any.sql"}).CheckType(api.MySQL); err != nil {
+		t.Errorf("CheckType of an unknown dump = %v", err)
+	}
+}
+
+func TestOpenDirectory(t *testing.T) {
+	dumpDir := filepath.Join(t.TempDir(), "billing")
+	writeFile(t, filepath.Join(dumpDir, "toc.dat"), "PGDMP toc")
+	writeFile(t, filepath.Join(dumpDir, "3456.dat.gz"), "rows")
+
+	f, err := Open(dumpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "billing.tar" {
+		t.Errorf("name = %q, want billing.tar", f.Name)
+	}
+	if format, err := Detect(f.Path); err != nil || format != api.BackupFormatDirectory {
+		t.Errorf("Detect(archive) = %q, %v, want directory", format, err)
+	}
+
+	archive, err := os.Open(f.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(archive)
+	for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+		names = append(names, hdr.Name)
+	}
+	archive.Close()
+	if got := strings.Join(names, ","); got != "3456.dat.gz,toc.dat" {
+		t.Errorf("archive has %s, want 3456.dat.gz,toc.dat", got)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(f.Path); !os.IsNotExist(err) {
+		t.Errorf("archive still exists after Close: %v", err)
+	}
+
+	mongoDir := filepath.Join(t.TempDir(), "dump")
+	writeFile(t, filepath.Join(mongoDir, "shop", "orders.bson"), "")
+	if _, err := Open(mongoDir); err == nil || !strings.Contains(err.Error(), "mongodump --archive") {
+		t.Errorf("Open(mongodump directory) = %v", err)
+	}
+}
+
+// TestDirectoryDumpRoundTrip dumps a database with pg_dump -Fd, archives
+// the dump like Open and restores it the way the API server does: by
+// unpacking the archive and running pg_restore on the directory. It needs
+// the PostgreSQL binaries on PATH.
+func TestDirectoryDumpRoundTrip(t *testing.T) {
+	for _, name := range []string{"initdb", "pg_ctl", "psql", "pg_dump", "pg_restore", "tar"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s is not installed", name)
+		}
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("initdb does not run as root")
+	}
+
+	// Unix socket paths are short, so the socket gets a directory of its own
+	dir := t.TempDir()
+	sockets, err := os.MkdirTemp("", "pg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sockets)
+	run := func(name string, args ...string) string {
+		t.Helper()
+		out, err := exec.Command(name, args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, out)
+		}
+		return string(out)
+	}
+
+	data := filepath.Join(dir, "data")
+	run("initdb", "-D", data, "-U", "postgres", "-A", "trust")
+	run("pg_ctl", "-D", data, "-w", "-l", filepath.Join(dir, "postgres.log"), "-o", "-k "+sockets+" -c listen_addresses=", "start")
+	defer exec.Command("pg_ctl", "-D", data, "-m", "fast", "stop").Run()
+	run("psql", "-h", sockets, "-U", "postgres", "-v", "ON_ERROR_STOP=1", "-c",
+		"CREATE TABLE users (id int PRIMARY KEY, email text); INSERT INTO users VALUES (1, 'alice@example.org');")
+	dumpDir := filepath.Join(dir, "billing")
+	run("pg_dump", "-h", sockets, "-U", "postgres", "-Fd", "-f", dumpDir, "postgres")
+
+	f, err := Open(dumpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if f.Format != api.BackupFormatDirectory {
+		t.Fatalf("format = %s, want directory", f.Format)
+	}
+
+	unpacked := filepath.Join(dir, "unpacked")
+	if err := os.Mkdir(unpacked, 0700); err != nil {
+		t.Fatal(err)
+	}
+	run("tar", "-xf", f.Path, "-C", unpacked)
+	restored := run("pg_restore", "--format=directory", "-f", "-", unpacked)
+	if !strings.Contains(restored, "COPY public.users (id, email) FROM stdin;\n1\talice@example.org\n") {
+		t.Errorf("pg_restore output does not have the table's rows:\n%s", restored)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	store := s3local.New("backups", "")
+	ts := httptest.NewServer(store)
+	defer ts.Close()
+	store.BaseURL = ts.URL
+
+	data := "PGDMP" + strings.Repeat("x", 100000)
+	f, err := Open(writeFile(t, filepath.Join(t.TempDir(), "billing.dump"), data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, location, err := store.PresignPut(context.Background(), "uploads/billing.dump", f.Size, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last int64
+	sum, err := Upload(context.Background(), http.DefaultClient, url, f, func(sent int64) { last = sent })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sha256.Sum256([]byte(data))
+	if sum != hex.EncodeToString(want[:]) {
+		t.Errorf("checksum = %s, want %x", sum, want)
+	}
+	if last != f.Size {
+		t.Errorf("last progress = %d, want %d", last, f.Size)
+	}
+	if got, err := store.ObjectAt(location); err != nil || !bytes.Equal(got, []byte(data)) {
+		t.Errorf("uploaded object: %d bytes, %v", len(got), err)
+	}
+
+	// A URL presigned for another size is refused, with S3's reason
+	url, _, err = store.PresignPut(context.Background(), "uploads/other.dump", 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Upload(context.Background(), http.DefaultClient, url, f, nil); err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Upload with the wrong size = %v, want SignatureDoesNotMatch", err)
+	}
+}
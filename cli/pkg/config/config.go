@@ -0,0 +1,212 @@
+// Package config reads and writes the CLI configuration file, which holds
+// named contexts: one per DevDB server the user works with.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultContextName is used when migrating a configuration written before
+// contexts existed.
+const DefaultContextName = "default"
+
+// TLS holds the TLS settings for talking to a DevDB API.
+type TLS struct {
+	CAFile             string `yaml:"ca-file,omitempty" json:"caFile,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty" json:"insecureSkipVerify,omitempty"`
+}
+
+// OIDC holds the identity provider used to log in with the device
+// authorization grant.
+type OIDC struct {
+	Issuer   string   `yaml:"issuer" json:"issuer"`
+	ClientID string   `yaml:"client-id" json:"clientId"`
+	Scopes   []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// Context describes one DevDB server and the settings used with it. The
+// API token is not part of the file; it lives in the credential store
+// under the context's name.
+type Context struct {
+	APIURL  string `yaml:"api-url" json:"apiUrl"`
+	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+	TLS     TLS    `yaml:"tls,omitempty" json:"tls,omitempty"`
+	OIDC    *OIDC  `yaml:"oidc,omitempty" json:"oidc,omitempty"`
+}
+
+// Config is the content of the configuration file. Settings this package
+// does not manage (e.g. connect.client) are kept in Other and written back
+// unchanged.
+type Config struct {
+	CurrentContext string                 `yaml:"current-context,omitempty"`
+	Contexts       map[string]*Context    `yaml:"contexts,omitempty"`
+	Other          map[string]interface{} `yaml:",inline"`
+}
+
+// ErrNoContext is returned when no context is selected.
+var ErrNoContext = errors.New("no context selected")
+
+// DefaultPath returns the default configuration file, ~/.devdb.yaml.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".devdb.yaml"), nil
+}
+
+// Load reads the configuration file at path. A missing file yields an
+// empty configuration. A legacy top-level api.url is migrated into a
+// context named "default".
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	if c.Contexts == nil {
+		c.Contexts = map[string]*Context{}
+	}
+	c.migrateLegacyURL()
+	return c, nil
+}
+
+func (c *Config) migrateLegacyURL() {
+	legacy, ok := c.Other["api"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	url, _ := legacy["url"].(string)
+	if url == "" {
+		return
+	}
+	if len(c.Contexts) == 0 {
+		c.Contexts[DefaultContextName] = &Context{APIURL: url}
+		if c.CurrentContext == "" {
+			c.CurrentContext = DefaultContextName
+		}
+	}
+	delete(legacy, "url")
+	if len(legacy) == 0 {
+		delete(c.Other, "api")
+	}
+}
+
+// Save writes the configuration to path with owner-only permissions.
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Names returns the context names in sorted order.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named context.
+func (c *Config) Get(name string) (*Context, error) {
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	return ctx, nil
+}
+
+// Current returns the context named override, or the current context when
+// override is empty.
+func (c *Config) Current(override string) (string, *Context, error) {
+	name := override
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return "", nil, ErrNoContext
+	}
+	ctx, err := c.Get(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, ctx, nil
+}
+
+// Add stores a new context. It fails if the name is taken.
+func (c *Config) Add(name string, ctx *Context) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if _, ok := c.Contexts[name]; ok {
+		return fmt.Errorf("context %q already exists", name)
+	}
+	c.Contexts[name] = ctx
+	return nil
+}
+
+// Use makes the named context current.
+func (c *Config) Use(name string) error {
+	if _, err := c.Get(name); err != nil {
+		return err
+	}
+	c.CurrentContext = name
+	return nil
+}
+
+// Delete removes the named context, clearing the current context if it
+// was the one removed.
+func (c *Config) Delete(name string) error {
+	if _, err := c.Get(name); err != nil {
+		return err
+	}
+	delete(c.Contexts, name)
+	if c.CurrentContext == name {
+		c.CurrentContext = ""
+	}
+	return nil
+}
+
+// Rename renames a context, following it with the current context.
+func (c *Config) Rename(from, to string) error {
+	ctx, err := c.Get(from)
+	if err != nil {
+		return err
+	}
+	if err := validateName(to); err != nil {
+		return err
+	}
+	if _, ok := c.Contexts[to]; ok {
+		return fmt.Errorf("context %q already exists", to)
+	}
+	delete(c.Contexts, from)
+	c.Contexts[to] = ctx
+	if c.CurrentContext == from {
+		c.CurrentContext = to
+	}
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\n/") {
+		return fmt.Errorf("invalid context name %q: must be non-empty without spaces or slashes", name)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(c.Contexts) != 0 || c.CurrentContext != "" {
+		t.Errorf("Load() = %+v, want empty config", c)
+	}
+	if _, _, err := c.Current(""); err != ErrNoContext {
+		t.Errorf("Current() error = %v, want ErrNoContext", err)
+	}
+}
+
+func TestLoadMigratesLegacyURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	legacy := "api:\n  url: http://devdb.example.com\nconnect:\n  client: pgcli\n"
+	if err := os.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	name, ctx, err := c.Current("")
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+	if name != DefaultContextName || ctx.APIURL != "http://devdb.example.com" {
+		t.Errorf("Current() = %s, %+v", name, ctx)
+	}
+	if _, ok := c.Other["api"]; ok {
+		t.Errorf("legacy api key kept: %v", c.Other)
+	}
+
+	// Unrelated settings survive a round trip
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	c, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]interface{}{"client": "pgcli"}
+	if got := c.Other["connect"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("connect = %v, want %v", got, want)
+	}
+}
+
+func TestContextOperations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Add("local", &Context{APIURL: "http://localhost:5000"}); err != nil {
+		t.Fatalf("Add(local) error = %v", err)
+	}
+	staging := &Context{
+		APIURL:  "https://staging.example.com",
+		Project: "billing",
+		TLS:     TLS{CAFile: "/etc/ca.pem"},
+	}
+	if err := c.Add("staging", staging); err != nil {
+		t.Fatalf("Add(staging) error = %v", err)
+	}
+	if err := c.Add("local", &Context{}); err == nil {
+		t.Error("Add() of an existing name succeeded")
+	}
+	if err := c.Add("bad name", &Context{}); err == nil {
+		t.Error("Add() of an invalid name succeeded")
+	}
+	if err := c.Use("missing"); err == nil {
+		t.Error("Use() of a missing context succeeded")
+	}
+	if err := c.Use("staging"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	if err := c.Rename("staging", "stage"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if c.CurrentContext != "stage" {
+		t.Errorf("CurrentContext = %q after rename, want stage", c.CurrentContext)
+	}
+	if err := c.Rename("stage", "local"); err == nil {
+		t.Error("Rename() onto an existing name succeeded")
+	}
+
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("file mode = %v, want 0600", perm)
+	}
+
+	c, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Names(); !reflect.DeepEqual(got, []string{"local", "stage"}) {
+		t.Errorf("Names() = %v", got)
+	}
+	name, ctx, err := c.Current("")
+	if err != nil || name != "stage" || !reflect.DeepEqual(ctx, staging) {
+		t.Errorf("Current() = %s, %+v, %v; want stage, %+v", name, ctx, err, staging)
+	}
+	if name, _, err := c.Current("local"); err != nil || name != "local" {
+		t.Errorf("Current(local) = %s, %v", name, err)
+	}
+
+	if err := c.Delete("stage"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if c.CurrentContext != "" {
+		t.Errorf("CurrentContext = %q after deleting it, want empty", c.CurrentContext)
+	}
+	if err := c.Delete("stage"); err == nil {
+		t.Error("Delete() of a missing context succeeded")
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalFileName is the name of the repo-local configuration file that pins
+// settings for a checkout.
+const LocalFileName = ".devdb.yaml"
+
+// Local is the content of a repo-local configuration file.
+type Local struct {
+	Project string `yaml:"project,omitempty"`
+}
+
+// FindLocal walks up from dir looking for a repo-local configuration file
+// and returns its path and content. The file at global is skipped, since
+// the global configuration in the home directory has the same name. An
+// empty path is returned when no file is found.
+func FindLocal(dir, global string) (string, *Local, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	globalInfo, _ := os.Stat(global)
+
+	for {
+		path := filepath.Join(dir, LocalFileName)
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() && (globalInfo == nil || !os.SameFile(info, globalInfo)) {
+			local, err := loadLocal(path)
+			if err != nil {
+				return "", nil, err
+			}
+			return path, local, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, nil
+		}
+		dir = parent
+	}
+}
+
+func loadLocal(path string) (*Local, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	local := &Local{}
+	if err := yaml.Unmarshal(data, local); err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return local, nil
+}
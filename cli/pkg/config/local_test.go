@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindLocal(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "repo", "services", "billing")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	global := filepath.Join(root, LocalFileName)
+	if err := os.WriteFile(global, []byte("current-context: local\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the global file exists above nested
+	path, local, err := FindLocal(nested, global)
+	if err != nil || path != "" || local != nil {
+		t.Errorf("FindLocal() = %q, %v, %v; want nothing found", path, local, err)
+	}
+
+	repoFile := filepath.Join(root, "repo", LocalFileName)
+	if err := os.WriteFile(repoFile, []byte("project: billing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path, local, err = FindLocal(nested, global)
+	if err != nil {
+		t.Fatalf("FindLocal() error = %v", err)
+	}
+	if path != repoFile || local.Project != "billing" {
+		t.Errorf("FindLocal() = %q, %+v; want %q with project billing", path, local, repoFile)
+	}
+
+	if err := os.WriteFile(repoFile, []byte("project: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := FindLocal(nested, global); err == nil {
+		t.Error("FindLocal() with an invalid file succeeded")
+	}
+}
@@ -0,0 +1,96 @@
+// Package credentials stores API tokens per context. Tokens are kept in
+// the OS keyring when one is available and in a file otherwise.
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned when no token is stored for a context.
+var ErrNotFound = errors.New("no credentials stored")
+
+// PassphraseEnv names the environment variable holding the passphrase the
+// file is encrypted with. Without it, the key is kept next to the file, so
+// the tokens are only protected by the files' permissions.
+const PassphraseEnv = "DEVDB_CREDENTIALS_PASSPHRASE"
+
+// Store holds one token per context name.
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, token string) error
+	Delete(name string) error
+}
+
+// New returns the default store: the OS keyring, falling back to a file
+// in dir.
+func New(dir string) Store {
+	return &Fallback{
+		Primary: Keyring{Service: "devdb"},
+		Secondary: &File{
+			Path:       filepath.Join(dir, "credentials"),
+			Passphrase: os.Getenv(PassphraseEnv),
+		},
+	}
+}
+
+// Fallback uses Primary and switches to Secondary whenever Primary fails
+// for a reason other than a missing token, e.g. because no keyring
+// service is running.
+type Fallback struct {
+	Primary   Store
+	Secondary Store
+}
+
+func (f *Fallback) Get(name string) (string, error) {
+	token, err := f.Primary.Get(name)
+	if err == nil {
+		return token, nil
+	}
+	return f.Secondary.Get(name)
+}
+
+func (f *Fallback) Set(name, token string) error {
+	if err := f.Primary.Set(name, token); err != nil {
+		return f.Secondary.Set(name, token)
+	}
+	// Don't leave an older token behind in the file
+	if err := f.Secondary.Delete(name); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (f *Fallback) Delete(name string) error {
+	primaryErr := f.Primary.Delete(name)
+	secondaryErr := f.Secondary.Delete(name)
+	if primaryErr == nil || secondaryErr == nil {
+		return nil
+	}
+	return secondaryErr
+}
+
+// Memory keeps tokens in memory. It is meant for tests.
+type Memory map[string]string
+
+func (m Memory) Get(name string) (string, error) {
+	token, ok := m[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (m Memory) Set(name, token string) error {
+	m[name] = token
+	return nil
+}
+
+func (m Memory) Delete(name string) error {
+	if _, ok := m[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m, name)
+	return nil
+}
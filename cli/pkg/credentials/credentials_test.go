@@ -0,0 +1,110 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+
+	if _, err := s.Get("local"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() of a missing token error = %v, want ErrNotFound", err)
+	}
+	if err := s.Set("local", "token-1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := s.Set("staging", "token-2"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := s.Set("local", "token-3"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	for name, want := range map[string]string{"local": "token-3", "staging": "token-2"} {
+		if got, err := s.Get(name); err != nil || got != want {
+			t.Errorf("Get(%s) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if err := s.Delete("local"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get("local"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("local"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, Memory{})
+}
+
+func TestKeyring(t *testing.T) {
+	keyring.MockInit()
+	testStore(t, Keyring{Service: "devdb-test"})
+}
+
+func TestFileWithKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	testStore(t, &File{Path: path})
+
+	for _, p := range []string{path, path + ".key"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s mode = %v, want 0600", p, perm)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "token-2") {
+		t.Errorf("token stored in plain text: %s", data)
+	}
+}
+
+func TestFileWithPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	testStore(t, &File{Path: path, Passphrase: "correct horse"})
+
+	if _, err := os.Stat(path + ".key"); !os.IsNotExist(err) {
+		t.Errorf("key file created despite passphrase: %v", err)
+	}
+	if _, err := (&File{Path: path, Passphrase: "wrong"}).Get("staging"); err == nil {
+		t.Error("Get() with the wrong passphrase succeeded")
+	}
+}
+
+// unavailable is a keyring that cannot be reached.
+type unavailable struct{}
+
+func (unavailable) Get(string) (string, error) { return "", errors.New("no keyring") }
+func (unavailable) Set(string, string) error   { return errors.New("no keyring") }
+func (unavailable) Delete(string) error        { return errors.New("no keyring") }
+
+func TestFallback(t *testing.T) {
+	t.Run("primary unavailable", func(t *testing.T) {
+		secondary := Memory{}
+		testStore(t, &Fallback{Primary: unavailable{}, Secondary: secondary})
+		if secondary["staging"] != "token-2" {
+			t.Errorf("secondary = %v, want token stored there", secondary)
+		}
+	})
+
+	t.Run("primary available", func(t *testing.T) {
+		primary, secondary := Memory{}, Memory{"staging": "stale"}
+		testStore(t, &Fallback{Primary: primary, Secondary: secondary})
+		if len(secondary) != 0 {
+			t.Errorf("secondary = %v, want stale token removed", secondary)
+		}
+	})
+}
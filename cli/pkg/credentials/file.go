@@ -0,0 +1,169 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// File stores tokens in a file readable by the owner only. With a
+// Passphrase, each token is encrypted with AES-256-GCM under a key derived
+// from it. Without one, the random key is kept in Path + ".key", with the
+// same permissions, so anyone who can read the file can read the key: the
+// tokens are then only protected by the file's 0600 mode.
+type File struct {
+	Path       string
+	Passphrase string
+}
+
+type fileContent struct {
+	// Salt for deriving the key from a passphrase
+	Salt   []byte            `json:"salt,omitempty"`
+	Tokens map[string][]byte `json:"tokens"`
+}
+
+func (f *File) Get(name string) (string, error) {
+	content, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := content.Tokens[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	gcm, err := f.cipher(content, false)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("%s: corrupt entry for %q", f.Path, name)
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	token, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("%s: cannot decrypt token for %q (wrong passphrase?)", f.Path, name)
+	}
+	return string(token), nil
+}
+
+func (f *File) Set(name, token string) error {
+	content, err := f.load()
+	if err != nil {
+		return err
+	}
+	gcm, err := f.cipher(content, true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	content.Tokens[name] = gcm.Seal(nonce, nonce, []byte(token), []byte(name))
+	return f.save(content)
+}
+
+func (f *File) Delete(name string) error {
+	content, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := content.Tokens[name]; !ok {
+		return ErrNotFound
+	}
+	delete(content.Tokens, name)
+	return f.save(content)
+}
+
+func (f *File) load() (*fileContent, error) {
+	content := &fileContent{}
+	data, err := os.ReadFile(f.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, content); err != nil {
+			return nil, fmt.Errorf("parsing %s: %v", f.Path, err)
+		}
+	}
+	if content.Tokens == nil {
+		content.Tokens = map[string][]byte{}
+	}
+	return content, nil
+}
+
+func (f *File) save(content *fileContent) error {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, data, 0600)
+}
+
+// cipher returns the AEAD for content, creating the salt or key file
+// when create is set.
+func (f *File) cipher(content *fileContent, create bool) (cipher.AEAD, error) {
+	var key []byte
+	var err error
+	if f.Passphrase != "" {
+		if content.Salt == nil {
+			if !create {
+				return nil, fmt.Errorf("%s was not written with a passphrase", f.Path)
+			}
+			content.Salt = make([]byte, 16)
+			if _, err := io.ReadFull(rand.Reader, content.Salt); err != nil {
+				return nil, err
+			}
+		}
+		key, err = scrypt.Key([]byte(f.Passphrase), content.Salt, 1<<15, 8, 1, 32)
+	} else {
+		key, err = f.keyFile(create)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (f *File) keyFile(create bool) ([]byte, error) {
+	path := f.Path + ".key"
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%s: invalid key", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !create {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
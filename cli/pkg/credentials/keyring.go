@@ -0,0 +1,33 @@
+package credentials
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Keyring stores tokens in the OS keyring: the macOS Keychain, the Windows
+// Credential Manager or the Secret Service on Linux.
+type Keyring struct {
+	Service string
+}
+
+func (k Keyring) Get(name string) (string, error) {
+	token, err := keyring.Get(k.Service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	return token, err
+}
+
+func (k Keyring) Set(name, token string) error {
+	return keyring.Set(k.Service, name, token)
+}
+
+func (k Keyring) Delete(name string) error {
+	err := keyring.Delete(k.Service, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
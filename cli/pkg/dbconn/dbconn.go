@@ -0,0 +1,279 @@
+// Package dbconn turns DevDB API resources into the connection details that
+// database clients understand.
+package dbconn
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+	"github.com/meido-ai/devdb/cli/pkg/envfile"
+)
+
+// Info holds everything needed to connect to a single database.
+type Info struct {
+	// Type is the database's engine; an empty Type is postgres.
+	Type     api.DatabaseType
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+}
+
+// FromAPI combines a database with the default credentials of its project.
+// Values reported on the database itself take precedence over the project
+// defaults.
+func FromAPI(db api.Database, project api.Project) (Info, error) {
+	if db.Host == nil || *db.Host == "" || db.Port == nil {
+		return Info{}, fmt.Errorf("database %s has no endpoint yet (status: %s)", db.Name, db.Status)
+	}
+
+	info := Info{
+		Type:     project.DbType,
+		Host:     *db.Host,
+		Port:     *db.Port,
+		User:     project.DefaultCredentials.Username,
+		Password: project.DefaultCredentials.Password,
+		Database: project.DefaultCredentials.Database,
+	}
+	if db.Username != nil && *db.Username != "" {
+		info.User = *db.Username
+	}
+	if db.Database != nil && *db.Database != "" {
+		info.Database = *db.Database
+	}
+	return info, nil
+}
+
+// dbType returns i's engine, postgres when none is set.
+func (i Info) dbType() api.DatabaseType {
+	if i.Type == "" {
+		return api.Postgres
+	}
+	return i.Type
+}
+
+// mysql reports whether i is a MySQL or MariaDB database.
+func (i Info) mysql() bool {
+	return engine.MySQLFamily(i.Type)
+}
+
+// Env returns the environment variables of i's client in KEY=value form:
+// libpq's PG* variables, MYSQL_* for MySQL and MariaDB, and redis-cli's
+// REDISCLI_AUTH for Redis. mongosh reads none, so MongoDB has none. The
+// password is omitted when none is known so clients can prompt.
+func (i Info) Env() []string {
+	switch {
+	case i.Type == api.Redis:
+		if i.Password == "" {
+			return nil
+		}
+		return []string{"REDISCLI_AUTH=" + i.Password}
+	case i.Type == api.MongoDB:
+		return nil
+	case i.mysql():
+		// The mysql client reads MYSQL_HOST, MYSQL_TCP_PORT and MYSQL_PWD;
+		// the user and database are passed on its command line
+		env := []string{
+			"MYSQL_HOST=" + i.Host,
+			"MYSQL_TCP_PORT=" + strconv.Itoa(i.Port),
+			"MYSQL_USER=" + i.User,
+			"MYSQL_DATABASE=" + i.Database,
+		}
+		if i.Password != "" {
+			env = append(env, "MYSQL_PWD="+i.Password)
+		}
+		return env
+	}
+
+	env := []string{
+		"PGHOST=" + i.Host,
+		"PGPORT=" + strconv.Itoa(i.Port),
+		"PGUSER=" + i.User,
+		"PGDATABASE=" + i.Database,
+	}
+	if i.Password != "" {
+		env = append(env, "PGPASSWORD="+i.Password)
+	}
+	return env
+}
+
+// clientTypes are the database types the clients ClientArgs knows connect
+// to. Other clients are launched as they are.
+var clientTypes = map[string][]api.DatabaseType{
+	"psql":      {api.Postgres},
+	"pgcli":     {api.Postgres},
+	"mysql":     {api.MySQL, api.MariaDB},
+	"mariadb":   {api.MySQL, api.MariaDB},
+	"usql":      {api.Postgres, api.MySQL, api.MariaDB},
+	"redis-cli": {api.Redis},
+	"mongosh":   {api.MongoDB},
+}
+
+// ClientArgs returns the command line used to launch client against i.
+// client may carry its own arguments (e.g. "pgcli --less-chatty"); extra is
+// appended last. The password is never placed on the command line, since
+// argv is visible to other users of the machine; it travels in Env instead
+// or is asked for by the client.
+func ClientArgs(client string, i Info, extra []string) ([]string, error) {
+	args := strings.Fields(client)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no database client configured")
+	}
+
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	if types, ok := clientTypes[name]; ok && !slices.Contains(types, i.dbType()) {
+		e, _ := engine.Lookup(i.dbType())
+		return nil, fmt.Errorf("%s cannot connect to a %s database; use %s", name, i.dbType(), e.Client)
+	}
+	switch name {
+	case "psql", "pgcli":
+		// Both read PGHOST, PGPORT, PGUSER, PGDATABASE and PGPASSWORD
+	case "mysql", "mariadb":
+		// Both read the host, port and password from MYSQL_*. TCP is
+		// forced, as they would use a socket for localhost otherwise
+		args = append(args, "--protocol=TCP", "--user="+i.User, "--database="+i.Database)
+	case "redis-cli":
+		// The password comes from REDISCLI_AUTH. Servers without ACL
+		// users have no user, and only numbered databases can be selected
+		args = append(args, "-h", i.Host, "-p", strconv.Itoa(i.Port))
+		if i.User != "" {
+			args = append(args, "--user", i.User)
+		}
+		if _, err := strconv.Atoi(i.Database); err == nil {
+			args = append(args, "-n", i.Database)
+		}
+	case "usql", "mongosh":
+		// Both need a URL; usql's password still comes from PGPASSWORD,
+		// or is asked for by MySQL, and mongosh asks for it
+		args = append(args, i.url(false))
+	}
+	return append(args, extra...), nil
+}
+
+// URL returns a postgres:// connection URL including the password, or a
+// mysql://, redis:// or mongodb:// one for the other engines.
+func (i Info) URL() string {
+	return i.url(true)
+}
+
+// JDBCURL returns a JDBC URL for the database's JDBC driver, or "" for
+// Redis and MongoDB, which have none.
+func (i Info) JDBCURL() string {
+	if !engine.SQL(i.Type) {
+		return ""
+	}
+	q := url.Values{}
+	q.Set("user", i.User)
+	if i.Password != "" {
+		q.Set("password", i.Password)
+	}
+	driver := "postgresql"
+	switch i.Type {
+	case api.MySQL:
+		driver = "mysql"
+	case api.MariaDB:
+		driver = "mariadb"
+	}
+	return fmt.Sprintf("jdbc:%s://%s/%s?%s", driver, net.JoinHostPort(i.Host, strconv.Itoa(i.Port)), url.PathEscape(i.Database), q.Encode())
+}
+
+// DSN returns a keyword/value connection string as accepted by pgx and
+// libpq, e.g. "host=h port=5432 user=u password=p dbname=d". For MySQL
+// and MariaDB it is a Go MySQL driver DSN, e.g. "u:p@tcp(h:3306)/d".
+// Redis and MongoDB drivers take URLs, so DSN is "" for them.
+func (i Info) DSN() string {
+	if !engine.SQL(i.Type) {
+		return ""
+	}
+	if i.mysql() {
+		user := i.User
+		if i.Password != "" {
+			user += ":" + i.Password
+		}
+		return fmt.Sprintf("%s@tcp(%s)/%s", user, net.JoinHostPort(i.Host, strconv.Itoa(i.Port)), i.Database)
+	}
+
+	parts := []string{
+		"host=" + dsnQuote(i.Host),
+		"port=" + strconv.Itoa(i.Port),
+		"user=" + dsnQuote(i.User),
+	}
+	if i.Password != "" {
+		parts = append(parts, "password="+dsnQuote(i.Password))
+	}
+	parts = append(parts, "dbname="+dsnQuote(i.Database))
+	return strings.Join(parts, " ")
+}
+
+// Vars returns the environment variables describing i, in a stable order.
+// Redis and MongoDB databases are described the way their drivers expect,
+// with REDIS_URL and MONGODB_URI.
+func (i Info) Vars() []envfile.Var {
+	switch i.Type {
+	case api.Redis:
+		return []envfile.Var{
+			{Key: "REDIS_URL", Value: i.URL()},
+			{Key: "REDIS_HOST", Value: i.Host},
+			{Key: "REDIS_PORT", Value: strconv.Itoa(i.Port)},
+			{Key: "REDIS_PASSWORD", Value: i.Password},
+		}
+	case api.MongoDB:
+		return []envfile.Var{
+			{Key: "MONGODB_URI", Value: i.URL()},
+			{Key: "MONGODB_DATABASE", Value: i.Database},
+		}
+	}
+
+	vars := []envfile.Var{{Key: "DATABASE_URL", Value: i.URL()}}
+	for _, kv := range i.Env() {
+		k, v, _ := strings.Cut(kv, "=")
+		vars = append(vars, envfile.Var{Key: k, Value: v})
+	}
+	return append(vars,
+		envfile.Var{Key: "JDBC_DATABASE_URL", Value: i.JDBCURL()},
+		envfile.Var{Key: "DATABASE_DSN", Value: i.DSN()},
+	)
+}
+
+// dsnQuote quotes a keyword/value DSN value when it is empty or contains
+// spaces, quotes or backslashes.
+func dsnQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, ` '\`) {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+func (i Info) url(withPassword bool) string {
+	scheme := "postgres"
+	switch {
+	case i.mysql():
+		scheme = "mysql"
+	case i.Type == api.Redis, i.Type == api.MongoDB:
+		scheme = string(i.Type)
+	}
+	u := url.URL{
+		Scheme: scheme,
+		User:   url.User(i.User),
+		Host:   net.JoinHostPort(i.Host, strconv.Itoa(i.Port)),
+		Path:   "/" + i.Database,
+	}
+	if withPassword && i.Password != "" {
+		u.User = url.UserPassword(i.User, i.Password)
+	}
+	if i.Type == api.MongoDB {
+		// The root user DevDB creates lives in the admin database
+		u.RawQuery = "authSource=admin"
+	}
+	return u.String()
+}
@@ -0,0 +1,372 @@
+// Package devdbfake runs an in-process fake of the DevDB API for tests.
+//
+// The fake is the server of pkg/server with in-memory state. Every request
+// is validated against the OpenAPI spec before it is handled, so clients
+// that drift from the spec fail their tests. Faults can be injected to
+// exercise error handling: latency, error responses, databases that never
+// leave the creating status and scripted sequences of statuses. Backup
+// uploads are presigned for an S3-compatible stand-in served under /s3/,
+// and can be read back with Upload.
+//
+//	fake := devdbfake.NewServer(devdbfake.Options{})
+//	defer fake.Close()
+//	project := fake.AddProject(api.Project{Owner: "alice", Name: "billing"})
+//	fake.Fail("POST /projects/{projectId}/databases", http.StatusInternalServerError, 1)
+//	client, _ := api.NewClientWithResponses(fake.URL)
+package devdbfake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/google/uuid"
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+	"github.com/meido-ai/devdb/cli/pkg/s3local"
+	"github.com/meido-ai/devdb/cli/pkg/server"
+)
+
+// Options configure a Server.
+type Options struct {
+	// Tokens are the accepted bearer tokens. Without any, the fake accepts
+	// unauthenticated requests.
+	Tokens []string
+
+	// Logger receives the errors logged by the server; they are discarded
+	// when nil.
+	Logger *log.Logger
+}
+
+// Server is a running fake. URL is the base URL to point clients at.
+type Server struct {
+	*httptest.Server
+
+	store   *server.MemoryStore
+	uploads *s3local.Store
+	spec    *openapi3.T
+	router  routers.Router
+
+	mu       sync.Mutex
+	latency  time.Duration
+	faults   map[string]*fault
+	stuck    map[string]bool
+	status   map[string]api.DatabaseStatus
+	sequence map[string][]api.DatabaseStatus
+}
+
+// fault makes the requests to one operation fail.
+type fault struct {
+	status    int
+	remaining int // 0 fails every request
+}
+
+// NewServer starts a fake. Close it when done.
+func NewServer(opts Options) *Server {
+	doc, err := api.GetSwagger()
+	if err != nil {
+		panic(fmt.Sprintf("devdbfake: loading the OpenAPI spec: %v", err))
+	}
+	// Match requests on any host
+	doc.Servers = nil
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic(fmt.Sprintf("devdbfake: building the router: %v", err))
+	}
+
+	s := &Server{
+		store:    server.NewMemoryStore(),
+		uploads:  s3local.New("devdb-backups", ""),
+		spec:     doc,
+		router:   router,
+		faults:   map[string]*fault{},
+		stuck:    map[string]bool{},
+		status:   map[string]api.DatabaseStatus{},
+		sequence: map[string][]api.DatabaseStatus{},
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	handler := server.New(s.store, provisioner{s}, server.Options{Tokens: opts.Tokens, Logger: logger, Uploader: s.uploads}).Handler()
+	mux := http.NewServeMux()
+	mux.Handle("/s3/", http.StripPrefix("/s3", s.uploads))
+	mux.Handle("/", s.middleware(handler))
+	s.Server = httptest.NewServer(mux)
+	s.uploads.BaseURL = s.URL + "/s3"
+	return s
+}
+
+// middleware delays, validates and fails requests before handing them to
+// the server.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		latency := s.latency
+		s.mu.Unlock()
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		// Requests for paths the spec does not have get the server's 404
+		route, pathParams, err := s.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		})
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Request does not match the OpenAPI spec: %v", err))
+			return
+		}
+
+		if status := s.takeFault(route.Method + " " + route.Path); status != 0 {
+			writeProblem(w, r, status, "Fault injected by devdbfake")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetLatency delays every request by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Fail makes the next times requests to an operation fail with status,
+// or every request when times is zero. The operation is given as a method
+// and path template of the spec, e.g. "GET /projects/{projectId}". Fail
+// panics if the spec has no such operation.
+func (s *Server) Fail(operation string, status, times int) {
+	method, path, _ := strings.Cut(operation, " ")
+	if item := s.spec.Paths.Value(path); item == nil || item.GetOperation(method) == nil {
+		panic(fmt.Sprintf("devdbfake: the OpenAPI spec has no operation %q", operation))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[operation] = &fault{status: status, remaining: times}
+}
+
+// takeFault returns the status an operation should fail with, or 0.
+func (s *Server) takeFault(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.faults[operation]
+	if !ok {
+		return 0
+	}
+	if f.remaining > 0 {
+		if f.remaining--; f.remaining == 0 {
+			delete(s.faults, operation)
+		}
+	}
+	return f.status
+}
+
+// StickCreating keeps databases with the given name in the creating
+// status, in every project, until Reset is called.
+func (s *Server) StickCreating(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stuck[name] = true
+}
+
+// SetStatus overrides the status reported for a database, e.g. to make it
+// fail with api.Error.
+func (s *Server) SetStatus(projectID, name string, status api.DatabaseStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[projectID+"/"+name] = status
+}
+
+// SequenceStatus makes the next lookups of a database report statuses in
+// turn, e.g. creating twice before it is running. The database gets its
+// usual status again once the sequence is used up.
+func (s *Server) SequenceStatus(projectID, name string, statuses ...api.DatabaseStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sequence[projectID+"/"+name] = statuses
+}
+
+// Reset removes all latency, faults, status overrides and sequences.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = 0
+	s.faults = map[string]*fault{}
+	s.stuck = map[string]bool{}
+	s.status = map[string]api.DatabaseStatus{}
+	s.sequence = map[string][]api.DatabaseStatus{}
+}
+
+// AddProject stores a project as if it had been created through the API
+// and returns it. The ID, database type and version, data version, and
+// credentials are filled in when empty.
+func (s *Server) AddProject(project api.Project) api.Project {
+	if project.Id == "" {
+		project.Id = uuid.NewString()
+	}
+	if project.DbType == "" {
+		project.DbType = api.Postgres
+	}
+	if project.DbVersion == "" {
+		project.DbVersion = "16"
+	}
+	if project.DataVersion == nil {
+		version := 1
+		project.DataVersion = &version
+	}
+	if project.DefaultCredentials == (api.DefaultDatabaseCredentials{}) {
+		project.DefaultCredentials = engine.Credentials(project.DbType, "devdb")
+	}
+	if err := s.store.CreateProject(context.Background(), project); err != nil {
+		panic(fmt.Sprintf("devdbfake: adding project: %v", err))
+	}
+	return project
+}
+
+// AddDatabase stores a database of a project as if it had been created
+// through the API, with its status and connection details as given.
+func (s *Server) AddDatabase(projectID string, db api.Database) api.Database {
+	db.Project = &projectID
+	if db.Status == "" {
+		db.Status = api.Running
+	}
+	if err := s.store.PutDatabase(context.Background(), db); err != nil {
+		panic(fmt.Sprintf("devdbfake: adding database: %v", err))
+	}
+	return db
+}
+
+// Projects returns the stored projects.
+func (s *Server) Projects() []api.Project {
+	projects, _ := s.store.ListProjects(context.Background())
+	return projects
+}
+
+// Databases returns the stored databases of a project, as last reported
+// by the API.
+func (s *Server) Databases(projectID string) []api.Database {
+	databases, _ := s.store.ListDatabases(context.Background(), projectID)
+	return databases
+}
+
+// Upload returns the content of a backup uploaded to the presigned URL of
+// POST /backups/uploads, by its location.
+func (s *Server) Upload(location string) ([]byte, bool) {
+	data, err := s.uploads.ObjectAt(location)
+	return data, err == nil
+}
+
+// provisioner reports databases as creating when they are created and as
+// running from the next time they are looked at, unless a fault says
+// otherwise.
+type provisioner struct {
+	s *Server
+}
+
+func (p provisioner) Create(ctx context.Context, project api.Project, db *api.Database) error {
+	host, port := "localhost", 5432
+	if e, ok := engine.Lookup(project.DbType); ok {
+		port = e.Port
+	}
+	db.Status = api.Creating
+	db.Host = &host
+	db.Port = &port
+	db.Username = &project.DefaultCredentials.Username
+	db.Database = &project.DefaultCredentials.Database
+	return nil
+}
+
+func (p provisioner) Refresh(ctx context.Context, project api.Project, db *api.Database) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	key := project.Id + "/" + db.Name
+	if sequence := p.s.sequence[key]; len(sequence) > 0 {
+		db.Status = sequence[0]
+		p.s.sequence[key] = sequence[1:]
+	} else if status, ok := p.s.status[key]; ok {
+		db.Status = status
+	} else if db.Status == api.Creating && !p.s.stuck[db.Name] {
+		db.Status = api.Running
+	}
+	return nil
+}
+
+func (p provisioner) Delete(ctx context.Context, project api.Project, db api.Database) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	delete(p.s.status, project.Id+"/"+db.Name)
+	delete(p.s.sequence, project.Id+"/"+db.Name)
+	return nil
+}
+
+func (p provisioner) Snapshot(ctx context.Context, project api.Project, db api.Database, snap *api.Snapshot) error {
+	snap.Status = api.SnapshotReady
+	return nil
+}
+
+func (p provisioner) DeleteSnapshot(ctx context.Context, project api.Project, snap api.Snapshot) error {
+	return nil
+}
+
+func (p provisioner) Clone(ctx context.Context, project api.Project, db *api.Database, from api.Snapshot) error {
+	return p.Create(ctx, project, db)
+}
+
+func (p provisioner) Reset(ctx context.Context, project api.Project, db *api.Database) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	delete(p.s.status, project.Id+"/"+db.Name)
+	db.Status = api.Creating
+	return nil
+}
+
+func (p provisioner) Stop(ctx context.Context, project api.Project, db *api.Database) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	delete(p.s.status, project.Id+"/"+db.Name)
+	db.Status = api.Stopped
+	return nil
+}
+
+func (p provisioner) Start(ctx context.Context, project api.Project, db *api.Database) error {
+	db.Status = api.Creating
+	return nil
+}
+
+// writeProblem answers with an RFC 7807 problem document, like the server.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problemType, instance := "about:blank", r.URL.Path
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.Problem{
+		Type:     &problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   &detail,
+		Instance: &instance,
+	})
+}
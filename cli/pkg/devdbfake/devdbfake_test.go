@@ -0,0 +1,157 @@
+package devdbfake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func newClient(t *testing.T, fake *Server) *api.ClientWithResponses {
+	t.Helper()
+	client, err := api.NewClientWithResponses(fake.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	fake := NewServer(Options{})
+	defer fake.Close()
+	client := newClient(t, fake)
+
+	project := fake.AddProject(api.Project{Owner: "alice", Name: "billing"})
+
+	created, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.JSON201 == nil || created.JSON201.Status != api.Creating {
+		t.Fatalf("create database: status %d, want 201 creating", created.StatusCode())
+	}
+
+	got, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, project.Id, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.JSON200 == nil || got.JSON200.Status != api.Running {
+		t.Fatalf("get database: status %d, want 200 running", got.StatusCode())
+	}
+	if dbs := fake.Databases(project.Id); len(dbs) != 1 || dbs[0].Status != api.Running {
+		t.Errorf("stored databases = %+v, want dev running", dbs)
+	}
+
+	projects, err := client.GetProjectsWithResponse(ctx, &api.GetProjectsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projects.JSON200 == nil || len(*projects.JSON200) != 1 {
+		t.Errorf("list projects: status %d, want the added project", projects.StatusCode())
+	}
+}
+
+func TestValidation(t *testing.T) {
+	fake := NewServer(Options{})
+	defer fake.Close()
+	project := fake.AddProject(api.Project{Owner: "alice", Name: "billing"})
+
+	// name is required by the spec
+	resp, err := http.Post(fake.URL+"/projects/"+project.Id+"/databases", "application/json", bytes.NewBufferString(`{"nmae": "dev"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var problem api.Problem
+	json.NewDecoder(resp.Body).Decode(&problem)
+	if resp.StatusCode != http.StatusBadRequest || problem.Detail == nil || !strings.Contains(*problem.Detail, "OpenAPI spec") {
+		t.Errorf("invalid request: status %d, %+v, want a 400 problem from validation", resp.StatusCode, problem)
+	}
+
+	resp, err = http.Get(fake.URL + "/nowhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown path: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	ctx := context.Background()
+	fake := NewServer(Options{})
+	defer fake.Close()
+	client := newClient(t, fake)
+	project := fake.AddProject(api.Project{Owner: "alice", Name: "billing"})
+
+	fake.Fail("POST /projects/{projectId}/databases", http.StatusInternalServerError, 1)
+	failed, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed.JSON500 == nil {
+		t.Errorf("injected fault: status %d, want 500", failed.StatusCode())
+	}
+	retried, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried.StatusCode() != http.StatusCreated {
+		t.Errorf("after the fault: status %d, want 201", retried.StatusCode())
+	}
+
+	fake.StickCreating("stuck")
+	client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "stuck"})
+	for i := 0; i < 3; i++ {
+		got, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, project.Id, "stuck")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.JSON200 == nil || got.JSON200.Status != api.Creating {
+			t.Fatalf("stuck database: status %d, want 200 creating", got.StatusCode())
+		}
+	}
+
+	fake.SetStatus(project.Id, "dev", api.Error)
+	got, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, project.Id, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.JSON200 == nil || got.JSON200.Status != api.Error {
+		t.Errorf("database with status override: status %d, want 200 error", got.StatusCode())
+	}
+
+	fake.SequenceStatus(project.Id, "slow", api.Creating, api.Creating)
+	client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "slow"})
+	for _, want := range []api.DatabaseStatus{api.Creating, api.Creating, api.Running} {
+		got, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, project.Id, "slow")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.JSON200 == nil || got.JSON200.Status != want {
+			t.Fatalf("sequenced database: status %d, want 200 %s", got.StatusCode(), want)
+		}
+	}
+
+	fake.Reset()
+	fake.SetLatency(50 * time.Millisecond)
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetProjectsWithResponse(timeout, &api.GetProjectsParams{}); err == nil {
+		t.Error("request should time out with latency")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Fail should panic for an operation not in the spec")
+		}
+	}()
+	fake.Fail("GET /databases", http.StatusInternalServerError, 0)
+}
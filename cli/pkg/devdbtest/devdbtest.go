@@ -0,0 +1,268 @@
+// Package devdbtest gives Go tests a throwaway DevDB database each,
+// restored from the project's backup.
+//
+//	func TestInvoices(t *testing.T) {
+//		db := devdbtest.New(t, devdbtest.Project("billing"))
+//		rows, err := db.Query("SELECT ...")
+//	}
+//
+// The database gets a unique name, is created through the DevDB API and
+// deleted again when the test ends. Tests are skipped when DEVDB_API_URL is
+// not set, so they can live next to unit tests. DEVDB_TOKEN holds the API
+// token and DEVDB_PROJECT the default project.
+//
+// New opens the database with database/sql and the "pgx" driver unless
+// Driver says otherwise; the driver must be registered by the test, e.g.
+// with a blank import of github.com/jackc/pgx/v5/stdlib. The databases of
+// MySQL and MariaDB projects get a DSN for github.com/go-sql-driver/mysql;
+// open them with Driver("mysql"). Redis and MongoDB databases have no
+// database/sql driver: use NewDSN, which returns their redis:// or
+// mongodb:// URL, and connect with the engine's own client library.
+package devdbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/dbconn"
+	"github.com/meido-ai/devdb/cli/pkg/wait"
+)
+
+// Environment variables read by the package.
+const (
+	EnvAPIURL  = "DEVDB_API_URL"
+	EnvToken   = "DEVDB_TOKEN"
+	EnvProject = "DEVDB_PROJECT"
+)
+
+// Option configures New, NewDSN and NewPool.
+type Option func(*options)
+
+type options struct {
+	apiURL  string
+	token   string
+	project string
+	owner   string
+	driver  string
+	timeout time.Duration
+	probe   bool
+}
+
+func defaultOptions() options {
+	return options{
+		apiURL:  os.Getenv(EnvAPIURL),
+		token:   os.Getenv(EnvToken),
+		project: os.Getenv(EnvProject),
+		driver:  "pgx",
+		timeout: 5 * time.Minute,
+		probe:   true,
+	}
+}
+
+// Project selects the project, by ID or name, whose backup the database is
+// restored from. It defaults to DEVDB_PROJECT.
+func Project(ref string) Option {
+	return func(o *options) { o.project = ref }
+}
+
+// Owner limits the lookup of a project name to one owner's projects.
+func Owner(owner string) Option {
+	return func(o *options) { o.owner = owner }
+}
+
+// Driver names the database/sql driver New opens the database with.
+func Driver(name string) Option {
+	return func(o *options) { o.driver = name }
+}
+
+// Timeout bounds how long to wait for the database to be running; five
+// minutes by default.
+func Timeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// APIURL sets the DevDB API URL instead of DEVDB_API_URL.
+func APIURL(url string) Option {
+	return func(o *options) { o.apiURL = url }
+}
+
+// Token sets the API token instead of DEVDB_TOKEN.
+func Token(token string) Option {
+	return func(o *options) { o.token = token }
+}
+
+// WithoutProbe counts a database as ready as soon as the API reports it
+// running, without checking that it accepts connections. It is meant for
+// testing against a fake API.
+func WithoutProbe() Option {
+	return func(o *options) { o.probe = false }
+}
+
+// New creates a database for the test and returns it opened with the
+// configured driver. The database is closed and deleted when the test
+// ends.
+func New(t testing.TB, opts ...Option) *sql.DB {
+	t.Helper()
+	o := newOptions(t, opts)
+	dsn := create(t, o, databaseName(t.Name()))
+	return open(t, o, dsn)
+}
+
+// NewDSN creates a database for the test like New and returns its
+// postgres:// connection URL, Go MySQL driver DSN, or redis:// or
+// mongodb:// URL, instead of opening it.
+func NewDSN(t testing.TB, opts ...Option) string {
+	t.Helper()
+	return create(t, newOptions(t, opts), databaseName(t.Name()))
+}
+
+func newOptions(t testing.TB, opts []Option) options {
+	t.Helper()
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.apiURL == "" {
+		t.Skipf("%s is not set, skipping test that needs a DevDB database", EnvAPIURL)
+	}
+	if o.project == "" {
+		t.Fatalf("devdbtest: no project selected: use devdbtest.Project or set %s", EnvProject)
+	}
+	return o
+}
+
+// create creates a database named name and registers its deletion with
+// t.Cleanup.
+func create(t testing.TB, o options, name string) string {
+	t.Helper()
+	d, err := createDatabase(context.Background(), o, name)
+	if d != nil {
+		t.Cleanup(func() {
+			if err := d.delete(context.Background()); err != nil {
+				t.Errorf("devdbtest: %v", err)
+			}
+		})
+	}
+	if err != nil {
+		t.Fatalf("devdbtest: %v", err)
+	}
+	return d.dsn
+}
+
+func open(t testing.TB, o options, dsn string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open(o.driver, dsn)
+	if err != nil {
+		t.Fatalf("devdbtest: opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// database is a database created through the API.
+type database struct {
+	client    *api.ClientWithResponses
+	projectID string
+	name      string
+	dsn       string
+}
+
+// createDatabase creates a database and waits for it to be running. The
+// database is returned along with any error once it exists, so the caller
+// can delete it.
+func createDatabase(ctx context.Context, o options, name string) (*database, error) {
+	client, err := newClient(o)
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := api.ResolveProject(ctx, client, o.owner, o.project)
+	if err != nil {
+		return nil, err
+	}
+	projectResp, err := client.GetProjectsProjectIdWithResponse(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("getting project %s: %w", o.project, err)
+	}
+	if projectResp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("getting project %s: %w", o.project, api.NewError(projectResp.HTTPResponse, projectResp.Body))
+	}
+
+	resp, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("creating database %s: %w", name, err)
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return nil, fmt.Errorf("creating database %s: %w", name, api.NewError(resp.HTTPResponse, resp.Body))
+	}
+	d := &database{client: client, projectID: projectID, name: name}
+
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	db, err := wait.ForStatus(ctx, client, projectID, name, api.Running, wait.Options{
+		Interval:     time.Second,
+		AllowMissing: true,
+		Probe:        o.probe,
+		Type:         projectResp.JSON200.DbType,
+	})
+	if err != nil {
+		return d, fmt.Errorf("waiting for database %s: %w", name, err)
+	}
+	info, err := dbconn.FromAPI(*db, *projectResp.JSON200)
+	if err != nil {
+		return d, err
+	}
+	d.dsn = info.URL()
+	if info.Type == api.MySQL || info.Type == api.MariaDB {
+		d.dsn = info.DSN()
+	}
+	return d, nil
+}
+
+func (d *database) delete(ctx context.Context) error {
+	resp, err := d.client.DeleteProjectsProjectIdDatabasesNameWithResponse(ctx, d.projectID, d.name)
+	if err != nil {
+		return fmt.Errorf("deleting database %s: %w", d.name, err)
+	}
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNotFound {
+		return fmt.Errorf("deleting database %s: %w", d.name, api.NewError(resp.HTTPResponse, resp.Body))
+	}
+	return nil
+}
+
+func newClient(o options) (*api.ClientWithResponses, error) {
+	return api.NewClientWithResponses(o.apiURL, api.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		if o.token != "" {
+			req.Header.Set("Authorization", "Bearer "+o.token)
+		}
+		return nil
+	}))
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// databaseName derives a unique database name from a test name, keeping it
+// a valid DNS label.
+func databaseName(testName string) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		panic(err)
+	}
+	base := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(testName), "-"), "-")
+	if len(base) > 40 {
+		base = strings.TrimRight(base[:40], "-")
+	}
+	if base == "" {
+		base = "test"
+	}
+	return base + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,105 @@
+package devdbtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// Pool shares databases between the tests of a package, so that only as
+// many are created as tests run in parallel. A database goes back to the
+// pool when the test that got it ends, with whatever the test left in it;
+// tests sharing a pool must not depend on each other's changes.
+//
+//	var pool *devdbtest.Pool
+//
+//	func TestMain(m *testing.M) {
+//		pool = devdbtest.NewPool(devdbtest.Project("billing"))
+//		code := m.Run()
+//		pool.Close()
+//		os.Exit(code)
+//	}
+//
+//	func TestInvoices(t *testing.T) {
+//		db := pool.Get(t)
+//		...
+//	}
+type Pool struct {
+	opts []Option
+
+	mu   sync.Mutex
+	idle []*database
+	all  []*database
+}
+
+// NewPool returns an empty pool creating databases with opts. Nothing is
+// created until a test asks for a database.
+func NewPool(opts ...Option) *Pool {
+	return &Pool{opts: opts}
+}
+
+// Get returns an idle database of the pool opened with the configured
+// driver, creating one if none is idle. The test is skipped when
+// DEVDB_API_URL is not set.
+func (p *Pool) Get(t testing.TB) *sql.DB {
+	t.Helper()
+	o := newOptions(t, p.opts)
+	return open(t, o, p.take(t, o))
+}
+
+// GetDSN returns the connection URL of an idle database like Get.
+func (p *Pool) GetDSN(t testing.TB) string {
+	t.Helper()
+	return p.take(t, newOptions(t, p.opts))
+}
+
+func (p *Pool) take(t testing.TB, o options) string {
+	t.Helper()
+	p.mu.Lock()
+	var d *database
+	if n := len(p.idle); n > 0 {
+		d, p.idle = p.idle[n-1], p.idle[:n-1]
+	}
+	p.mu.Unlock()
+
+	if d == nil {
+		var err error
+		d, err = createDatabase(context.Background(), o, databaseName("pool"))
+		if d != nil && err != nil {
+			// Never handed out, so not worth keeping
+			d.delete(context.Background())
+		}
+		if err != nil {
+			t.Fatalf("devdbtest: %v", err)
+		}
+		p.mu.Lock()
+		p.all = append(p.all, d)
+		p.mu.Unlock()
+	}
+
+	t.Cleanup(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.idle = append(p.idle, d)
+	})
+	return d.dsn
+}
+
+// Close deletes all databases of the pool. Call it after m.Run in
+// TestMain.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	all := p.all
+	p.all, p.idle = nil, nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, d := range all {
+		if err := d.delete(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
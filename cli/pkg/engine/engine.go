@@ -0,0 +1,213 @@
+// Package engine describes the database engines DevDB runs: the versions
+// that are supported, the port they listen on, the client that connects
+// to them and what their default credentials mean. The engines a server
+// supports are its Catalog, which it advertises through the API.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+// Engine is a database engine projects can be created with.
+type Engine struct {
+	// Type is the engine's name in the API.
+	Type api.DatabaseType
+	// Name is the name people know the engine by.
+	Name string
+	// Port is the port its databases listen on by default.
+	Port int
+	// Client is the command line client db connect opens by default.
+	Client string
+	// User and Database are the default credentials of new projects,
+	// next to a generated password. Redis authenticates its "default"
+	// user and numbers its databases.
+	User     string
+	Database string
+	// Versions are the supported release series, newest first. A
+	// version of a project must be one of them or a release in one,
+	// e.g. 16 or 16.2 for 16, and 8.4.1 for 8.4.
+	Versions []string
+	// Extensions are the extensions its databases can enable.
+	Extensions []string
+}
+
+// Catalog is a list of engines, such as those a server supports.
+type Catalog []Engine
+
+var engines = Catalog{
+	{
+		Type:     api.Postgres,
+		Name:     "PostgreSQL",
+		Port:     5432,
+		Client:   "psql",
+		User:     "devdb",
+		Database: "devdb",
+		Versions: []string{"17", "16", "15", "14", "13", "12"},
+		// The contrib modules of the official images
+		Extensions: []string{"btree_gin", "btree_gist", "citext", "cube", "hstore", "intarray", "pg_stat_statements", "pg_trgm", "pgcrypto", "tablefunc", "unaccent", "uuid-ossp"},
+	},
+	{
+		Type:     api.MySQL,
+		Name:     "MySQL",
+		Port:     3306,
+		Client:   "mysql",
+		User:     "devdb",
+		Database: "devdb",
+		Versions: []string{"9.1", "8.4", "8.0"},
+	},
+	{
+		Type:     api.MariaDB,
+		Name:     "MariaDB",
+		Port:     3306,
+		Client:   "mariadb",
+		User:     "devdb",
+		Database: "devdb",
+		Versions: []string{"11.4", "10.11", "10.6", "10.5"},
+	},
+	{
+		Type:     api.Redis,
+		Name:     "Redis",
+		Port:     6379,
+		Client:   "redis-cli",
+		User:     "default",
+		Database: "0",
+		Versions: []string{"7.4", "7.2", "6.2"},
+	},
+	{
+		Type:     api.MongoDB,
+		Name:     "MongoDB",
+		Port:     27017,
+		Client:   "mongosh",
+		User:     "devdb",
+		Database: "devdb",
+		Versions: []string{"8.0", "7.0", "6.0"},
+	},
+}
+
+// All returns every engine, in the order they are listed to users.
+func All() Catalog {
+	return append(Catalog(nil), engines...)
+}
+
+// FromAPI returns the catalog a server advertises.
+func FromAPI(c api.Catalog) Catalog {
+	catalog := make(Catalog, len(c.Engines))
+	for i, e := range c.Engines {
+		catalog[i] = Engine{Type: e.Type, Name: e.Name, Port: e.DefaultPort, Versions: e.Versions, Extensions: e.Extensions}
+		if known, ok := Lookup(e.Type); ok {
+			catalog[i].Client = known.Client
+			catalog[i].User = known.User
+			catalog[i].Database = known.Database
+		}
+	}
+	return catalog
+}
+
+// API returns c as servers advertise it.
+func (c Catalog) API() api.Catalog {
+	catalog := api.Catalog{Engines: make([]api.CatalogEngine, len(c))}
+	for i, e := range c {
+		catalog.Engines[i] = api.CatalogEngine{
+			Type:        e.Type,
+			Name:        e.Name,
+			DefaultPort: e.Port,
+			Versions:    append([]string{}, e.Versions...),
+			Extensions:  append([]string{}, e.Extensions...),
+		}
+	}
+	return catalog
+}
+
+// Lookup returns the engine of a database type.
+func Lookup(t api.DatabaseType) (Engine, bool) {
+	return engines.Lookup(t)
+}
+
+// Lookup returns the engine of a database type in c.
+func (c Catalog) Lookup(t api.DatabaseType) (Engine, bool) {
+	for _, e := range c {
+		if e.Type == t {
+			return e, true
+		}
+	}
+	return Engine{}, false
+}
+
+// MySQLFamily reports whether t speaks the MySQL protocol and restores
+// mysqldump backups.
+func MySQLFamily(t api.DatabaseType) bool {
+	return t == api.MySQL || t == api.MariaDB
+}
+
+// SQL reports whether t is a relational database, which JDBC drivers and
+// database/sql connect to.
+func SQL(t api.DatabaseType) bool {
+	return t != api.Redis && t != api.MongoDB
+}
+
+// Credentials returns the default credentials of a new project of type t
+// with password.
+func Credentials(t api.DatabaseType, password string) api.DefaultDatabaseCredentials {
+	e, ok := Lookup(t)
+	if !ok {
+		e = engines[0]
+	}
+	return api.DefaultDatabaseCredentials{Username: e.User, Password: password, Database: e.Database}
+}
+
+var versionPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+){0,2}$`)
+
+// Validate checks that t is a known engine and version one of its
+// supported versions.
+func Validate(t api.DatabaseType, version string) error {
+	return engines.Validate(t, version)
+}
+
+// Validate checks that t is an engine in c and version one of its
+// supported versions. The error suggests what was likely meant, e.g.
+// "did you mean 15.4?" for v15.4.
+func (c Catalog) Validate(t api.DatabaseType, version string) error {
+	e, ok := c.Lookup(t)
+	if !ok {
+		names := make([]string, len(c))
+		for i, e := range c {
+			names[i] = string(e.Type)
+		}
+		return didYouMean(fmt.Sprintf("unknown database type %q (want %s)", t, strings.Join(names, ", ")), c.SuggestType(string(t)))
+	}
+	if len(e.Versions) == 0 {
+		return fmt.Errorf("no %s versions available", e.Name)
+	}
+	if !versionPattern.MatchString(version) {
+		return didYouMean(fmt.Sprintf("invalid %s version %q: want a version number such as %s", e.Name, version, e.Versions[0]), e.SuggestVersion(version))
+	}
+	if !e.Supports(version) {
+		return didYouMean(fmt.Sprintf("%s %s is not supported (supported: %s)", e.Name, version, strings.Join(e.Versions, ", ")), e.SuggestVersion(version))
+	}
+	return nil
+}
+
+// didYouMean returns an error with msg, followed by suggestion when there
+// is one.
+func didYouMean(msg, suggestion string) error {
+	if suggestion == "" {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s; did you mean %s?", msg, suggestion)
+}
+
+// Supports reports whether version is one of e's supported versions or a
+// release in one.
+func (e Engine) Supports(version string) bool {
+	for _, v := range e.Versions {
+		if version == v || strings.HasPrefix(version, v+".") {
+			return true
+		}
+	}
+	return false
+}
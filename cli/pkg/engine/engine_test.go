@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		dbType  api.DatabaseType
+		version string
+		wantErr string
+	}{
+		{dbType: api.Postgres, version: "16"},
+		{dbType: api.Postgres, version: "15.3"},
+		{dbType: api.MySQL, version: "8.0.36"},
+		{dbType: api.MariaDB, version: "10.11"},
+		{dbType: api.Redis, version: "7.2.5"},
+		{dbType: api.MongoDB, version: "7.0"},
+		{dbType: "oracle", version: "23", wantErr: `unknown database type "oracle" (want postgres, mysql, mariadb, redis, mongodb)`},
+		{dbType: "postgresql", version: "16", wantErr: `unknown database type "postgresql" (want postgres, mysql, mariadb, redis, mongodb); did you mean postgres?`},
+		{dbType: api.Postgres, version: "v15.4", wantErr: `invalid PostgreSQL version "v15.4": want a version number such as 17; did you mean 15.4?`},
+		{dbType: api.Postgres, version: "latest", wantErr: `invalid PostgreSQL version "latest": want a version number such as 17`},
+		{dbType: api.Postgres, version: "9.6", wantErr: "PostgreSQL 9.6 is not supported (supported: 17, 16, 15, 14, 13, 12); did you mean 12?"},
+		{dbType: api.MySQL, version: "8", wantErr: "MySQL 8 is not supported"},
+		{dbType: api.MySQL, version: "8.40", wantErr: "MySQL 8.40 is not supported"},
+	}
+	for _, tc := range tests {
+		err := Validate(tc.dbType, tc.version)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%s, %s) = %v", tc.dbType, tc.version, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("Validate(%s, %s) = %v, want an error containing %q", tc.dbType, tc.version, err, tc.wantErr)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	e, ok := Lookup(api.MariaDB)
+	if !ok || e.Port != 3306 || e.Client != "mariadb" {
+		t.Errorf("Lookup(mariadb) = %+v, %v", e, ok)
+	}
+	if _, ok := Lookup("oracle"); ok {
+		t.Error("Lookup(oracle) found an engine")
+	}
+	if !MySQLFamily(api.MariaDB) || MySQLFamily(api.Postgres) {
+		t.Error("MySQLFamily is wrong")
+	}
+	if SQL(api.Redis) || SQL(api.MongoDB) || !SQL(api.MySQL) {
+		t.Error("SQL is wrong")
+	}
+
+	want := api.DefaultDatabaseCredentials{Username: "default", Password: "pw", Database: "0"}
+	if got := Credentials(api.Redis, "pw"); got != want {
+		t.Errorf("Credentials(redis) = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SuggestType returns the type in c that t most likely means, such as
+// postgres for "postgresql" or mongodb for "mongo", or "" when none is
+// close.
+func (c Catalog) SuggestType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" {
+		return ""
+	}
+	for _, e := range c {
+		typ := string(e.Type)
+		switch {
+		case t == strings.ToLower(e.Name):
+			return typ
+		case len(t) >= 3 && (strings.HasPrefix(typ, t) || strings.HasPrefix(t, typ)):
+			return typ
+		case len(t) >= 4 && editDistance(t, typ) <= 2:
+			return typ
+		}
+	}
+	return ""
+}
+
+// SuggestVersion returns the supported version that version most likely
+// means, or "" when it is supported or not a version at all. Spellings
+// such as v15.4, 15,4 or 16-alpine are read as 15.4 and 16; other
+// versions get the closest supported one, in the same major version when
+// there is one, e.g. 10.5 for MariaDB 10.4 and 12 for PostgreSQL 9.6.
+func (e Engine) SuggestVersion(version string) string {
+	v := normalizeVersion(version)
+	if !versionPattern.MatchString(v) {
+		return ""
+	}
+	if e.Supports(v) {
+		if v == version {
+			return ""
+		}
+		return v
+	}
+
+	major, minor := splitVersion(v)
+	best, bestDistance := "", 0
+	for _, s := range e.Versions {
+		m, n := splitVersion(s)
+		distance := abs(m-major) * 1000
+		if m == major && minor >= 0 && n >= 0 {
+			distance += abs(n - minor)
+		}
+		// Versions are newest first, so ties go to the newer one
+		if best == "" || distance < bestDistance {
+			best, bestDistance = s, distance
+		}
+	}
+	return best
+}
+
+// normalizeVersion undoes the usual ways of writing a version that
+// Validate does not take: a v prefix, an image tag suffix, commas and a
+// trailing .x.
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	if i := strings.IndexAny(v, "-_+ "); i >= 0 {
+		v = v[:i]
+	}
+	v = strings.ReplaceAll(v, ",", ".")
+	return strings.TrimSuffix(strings.TrimSuffix(v, ".x"), ".")
+}
+
+// splitVersion returns the major and minor number of a valid version; the
+// minor number is -1 when there is none.
+func splitVersion(v string) (major, minor int) {
+	parts := strings.Split(v, ".")
+	major, _ = strconv.Atoi(parts[0])
+	minor = -1
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	return major, minor
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestSuggestType(t *testing.T) {
+	tests := map[string]string{
+		"postgresql": "postgres",
+		"PostgreSQL": "postgres",
+		"Postgres":   "postgres",
+		"postgress":  "postgres",
+		"mongo":      "mongodb",
+		"maria":      "mariadb",
+		"mysq":       "mysql",
+		"oracle":     "",
+		"pg":         "",
+		"":           "",
+	}
+	for in, want := range tests {
+		if got := All().SuggestType(in); got != want {
+			t.Errorf("SuggestType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSuggestVersion(t *testing.T) {
+	tests := []struct {
+		dbType  api.DatabaseType
+		version string
+		want    string
+	}{
+		{api.Postgres, "v15.4", "15.4"},
+		{api.Postgres, "15,4", "15.4"},
+		{api.Postgres, "16-alpine", "16"},
+		{api.Postgres, "16.x", "16"},
+		{api.Postgres, "15.4", ""},
+		{api.Postgres, "9.6", "12"},
+		{api.Postgres, "v18", "17"},
+		{api.Postgres, "latest", ""},
+		{api.MySQL, "8", "8.4"},
+		{api.MySQL, "8.1", "8.0"},
+		{api.MariaDB, "10.4", "10.5"},
+		{api.MariaDB, "10.9", "10.11"},
+	}
+	for _, tc := range tests {
+		e, _ := Lookup(tc.dbType)
+		if got := e.SuggestVersion(tc.version); got != tc.want {
+			t.Errorf("SuggestVersion(%s %q) = %q, want %q", tc.dbType, tc.version, got, tc.want)
+		}
+	}
+}
+
+func TestCatalogAPI(t *testing.T) {
+	catalog := FromAPI(All().API())
+	e, ok := catalog.Lookup(api.Postgres)
+	if !ok || e.Port != 5432 || e.Client != "psql" || len(e.Extensions) == 0 {
+		t.Errorf("Lookup(postgres) = %+v, %v", e, ok)
+	}
+
+	// A server may support fewer engines than the CLI knows
+	catalog = FromAPI(api.Catalog{Engines: []api.CatalogEngine{{Type: api.Postgres, Name: "PostgreSQL", Versions: []string{"16"}}}})
+	if err := catalog.Validate(api.Postgres, "15"); err == nil || err.Error() != "PostgreSQL 15 is not supported (supported: 16); did you mean 16?" {
+		t.Errorf("Validate(postgres 15) = %v", err)
+	}
+	if err := catalog.Validate(api.MySQL, "8.4"); err == nil || err.Error() != `unknown database type "mysql" (want postgres)` {
+		t.Errorf("Validate(mysql 8.4) = %v", err)
+	}
+
+	// Nor should a server without versions make validation panic
+	catalog = FromAPI(api.Catalog{Engines: []api.CatalogEngine{{Type: api.Postgres, Name: "PostgreSQL"}}})
+	if err := catalog.Validate(api.Postgres, "x"); err == nil || err.Error() != "no PostgreSQL versions available" {
+		t.Errorf("Validate(postgres x) without versions = %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package envfile renders environment variables for shells and .env files
+// and merges them into existing .env files.
+package envfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Var is a single environment variable.
+type Var struct {
+	Key   string
+	Value string
+}
+
+// Formats lists the formats accepted by Render.
+var Formats = []string{"dotenv", "shell", "fish", "powershell", "json"}
+
+// Render writes vars to w in the given format.
+func Render(w io.Writer, format string, vars []Var) error {
+	switch format {
+	case "dotenv":
+		for _, v := range vars {
+			fmt.Fprintf(w, "%s=%s\n", v.Key, dotenvQuote(v.Value))
+		}
+	case "shell":
+		for _, v := range vars {
+			fmt.Fprintf(w, "export %s=%s\n", v.Key, shellQuote(v.Value))
+		}
+	case "fish":
+		for _, v := range vars {
+			fmt.Fprintf(w, "set -gx %s %s;\n", v.Key, fishQuote(v.Value))
+		}
+	case "powershell":
+		for _, v := range vars {
+			fmt.Fprintf(w, "$Env:%s = %s\n", v.Key, powershellQuote(v.Value))
+		}
+	case "json":
+		m := make(map[string]string, len(vars))
+		for _, v := range vars {
+			m[v.Key] = v.Value
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	default:
+		return fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+var assignment = regexp.MustCompile(`^(\s*(?:export\s+)?)([A-Za-z_][A-Za-z0-9_]*)\s*=`)
+
+// Merge returns the contents of an existing .env file with vars applied.
+// Keys already present are updated in place, keeping any "export" prefix;
+// new keys are appended. Comments, blank lines and unrelated keys are left
+// untouched.
+func Merge(existing []byte, vars []Var) []byte {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		values[v.Key] = v.Value
+	}
+	seen := make(map[string]bool, len(vars))
+
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(existing))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := assignment.FindStringSubmatch(line); m != nil {
+			if value, ok := values[m[2]]; ok {
+				line = m[1] + m[2] + "=" + dotenvQuote(value)
+				seen[m[2]] = true
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	for _, v := range vars {
+		if !seen[v.Key] {
+			fmt.Fprintf(&out, "%s=%s\n", v.Key, dotenvQuote(v.Value))
+			seen[v.Key] = true
+		}
+	}
+	return out.Bytes()
+}
+
+// WriteFile merges vars into the .env file at path. A new file is created
+// with owner-only permissions since it holds secrets.
+func WriteFile(path string, vars []Var) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, Merge(existing, vars), 0600)
+}
+
+var dotenvSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+func dotenvQuote(s string) string {
+	if dotenvSafe.MatchString(s) {
+		return s
+	}
+	if !strings.ContainsAny(s, "'\n") {
+		return "'" + s + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func fishQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,87 @@
+package envfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	vars := []Var{{Key: "A", Value: "plain"}, {Key: "B", Value: "it's $x"}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "dotenv", want: "A=plain\nB=\"it's \\$x\"\n"},
+		{format: "shell", want: "export A='plain'\nexport B='it'\\''s $x'\n"},
+		{format: "fish", want: "set -gx A 'plain';\nset -gx B 'it\\'s $x';\n"},
+		{format: "powershell", want: "$Env:A = 'plain'\n$Env:B = 'it''s $x'\n"},
+		{format: "json", want: "{\n  \"A\": \"plain\",\n  \"B\": \"it's $x\"\n}\n"},
+	}
+
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, tc.format, vars); err != nil {
+			t.Errorf("Render(%s): %v", tc.format, err)
+			continue
+		}
+		if buf.String() != tc.want {
+			t.Errorf("Render(%s) = %q, want %q", tc.format, buf.String(), tc.want)
+		}
+	}
+
+	if err := Render(&bytes.Buffer{}, "toml", vars); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestDotenvQuote(t *testing.T) {
+	tests := map[string]string{
+		"simple":           "simple",
+		"":                 "",
+		"a b#c":            "'a b#c'",
+		"p@ss:w/rd":        "p@ss:w/rd",
+		"multi\nline":      `"multi\nline"`,
+		`say "hi" 'there'`: `"say \"hi\" 'there'"`,
+	}
+	for in, want := range tests {
+		if got := dotenvQuote(in); got != want {
+			t.Errorf("dotenvQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	existing := "# comment\nOTHER=1\n\nexport URL=old\nURL = older\n"
+	got := Merge([]byte(existing), []Var{{Key: "URL", Value: "new"}, {Key: "ADDED", Value: "x y"}})
+	want := "# comment\nOTHER=1\n\nexport URL=new\nURL=new\nADDED='x y'\n"
+	if string(got) != want {
+		t.Errorf("Merge() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := WriteFile(path, []Var{{Key: "A", Value: "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []Var{{Key: "B", Value: "2"}, {Key: "A", Value: "3"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "A=3\nB=2\n" {
+		t.Errorf("file = %q", got)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", fi.Mode().Perm())
+	}
+}
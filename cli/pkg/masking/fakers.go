@@ -0,0 +1,113 @@
+package masking
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// source is the randomness of one masked value: derived from the value,
+// so the same value always gets the same fake.
+type source struct {
+	*rand.Rand
+	sum []byte
+}
+
+func newSource(sum []byte) *source {
+	return &source{Rand: rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(sum)))), sum: sum}
+}
+
+// tag encodes the bytes of the value's sum that don't seed its randomness.
+// Fakers for values that are likely to be unique, like emails, include it,
+// so two values only get the same fake when their HMACs collide and masked
+// values still fit UNIQUE columns.
+func (s *source) tag() string {
+	return strings.ToLower(tagEncoding.EncodeToString(s.sum[8:]))
+}
+
+var tagEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func (s *source) pick(words []string) string {
+	return words[s.Intn(len(words))]
+}
+
+// fakers makes up a value of a kind from a source and the original value.
+var fakers = map[string]func(s *source, original string) string{
+	"first_name": func(s *source, _ string) string { return s.pick(firstNames) },
+	"last_name":  func(s *source, _ string) string { return s.pick(lastNames) },
+	"name": func(s *source, _ string) string {
+		return s.pick(firstNames) + " " + s.pick(lastNames)
+	},
+	"email": func(s *source, _ string) string {
+		return fmt.Sprintf("%s.%s.%s@example.com", strings.ToLower(s.pick(firstNames)), strings.ToLower(s.pick(lastNames)), s.tag())
+	},
+	"username": func(s *source, _ string) string {
+		return fmt.Sprintf("%s%s_%s", strings.ToLower(s.pick(firstNames)[:1]), strings.ToLower(s.pick(lastNames)), s.tag())
+	},
+	"phone": func(s *source, _ string) string {
+		return fmt.Sprintf("+1-555-%03d-%04d", s.Intn(1000), s.Intn(10000))
+	},
+	"address": func(s *source, _ string) string {
+		return fmt.Sprintf("%d %s %s", 1+s.Intn(9999), s.pick(lastNames), s.pick(streetSuffixes))
+	},
+	"city":    func(s *source, _ string) string { return s.pick(cities) },
+	"company": func(s *source, _ string) string { return s.pick(lastNames) + " " + s.pick(companySuffixes) },
+	"uuid": func(s *source, _ string) string {
+		b := append([]byte(nil), s.sum[:16]...)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		h := hex.EncodeToString(b)
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	},
+	"ipv4": func(s *source, _ string) string {
+		return fmt.Sprintf("10.%d.%d.%d", s.sum[0], s.sum[1], s.sum[2])
+	},
+	"date": func(s *source, _ string) string {
+		return fmt.Sprintf("%04d-%02d-%02d", 1950+s.Intn(55), 1+s.Intn(12), 1+s.Intn(28))
+	},
+	// text keeps about the length of the original
+	"text": func(s *source, original string) string {
+		var b strings.Builder
+		for b.Len() == 0 || b.Len() < len(original) {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(s.pick(loremWords))
+		}
+		return b.String()
+	},
+}
+
+// Fakers returns the kinds of values faker rules can make up.
+func Fakers() []string {
+	kinds := make([]string, 0, len(fakers))
+	for kind := range fakers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+var (
+	firstNames = []string{
+		"Alex", "Ana", "Ben", "Carla", "Chen", "Dana", "Elif", "Emma", "Farid", "Grace",
+		"Hugo", "Ines", "Jonas", "Kai", "Lena", "Liam", "Maya", "Noah", "Olga", "Priya",
+		"Quinn", "Rosa", "Sam", "Tariq", "Uma", "Victor", "Wen", "Yara", "Zoe", "Mateo",
+	}
+	lastNames = []string{
+		"Adams", "Berg", "Costa", "Dubois", "Eriksen", "Fischer", "Garcia", "Haddad", "Ito", "Jansen",
+		"Kowalski", "Lopez", "Moreau", "Nakamura", "Okafor", "Petrov", "Quinn", "Rossi", "Silva", "Tanaka",
+		"Usman", "Varga", "Weber", "Xu", "Yilmaz", "Zimmer", "Novak", "Murphy", "Kim", "Singh",
+	}
+	streetSuffixes  = []string{"Street", "Avenue", "Road", "Lane", "Way", "Drive", "Court", "Place"}
+	cities          = []string{"Springfield", "Riverton", "Lakeside", "Fairview", "Greenville", "Milton", "Ashford", "Bridgeport", "Clayton", "Dover"}
+	companySuffixes = []string{"Ltd", "Inc", "Group", "Labs", "Partners", "Systems", "Holdings", "& Co"}
+	loremWords      = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+	}
+)
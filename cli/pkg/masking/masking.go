@@ -0,0 +1,319 @@
+// Package masking anonymizes plain-format pg_dump output according to a
+// declarative policy, so backups with personal data can seed projects.
+//
+// A policy maps columns to a masking rule:
+//
+//	seed: billing-2024
+//	columns:
+//	  users.email: {faker: email}
+//	  users.full_name: {faker: name}
+//	  users.ssn: hash
+//	  users.api_token: {hash: true, length: 16}
+//	  users.notes: null
+//	  billing.accounts.country: {constant: NL}
+//
+// Columns are table.column, in the public schema, or schema.table.column.
+// The rules are:
+//
+//	faker     a made-up value of a kind, e.g. email or name; see Fakers
+//	hash      the hex HMAC-SHA256 of the value
+//	null      NULL
+//	constant  the given value
+//
+// length truncates what faker and hash produce. NULL values stay NULL,
+// except that null makes every value NULL.
+//
+// Masking is deterministic: with the same seed, a value is always masked
+// the same way, in any column, so joins on masked columns keep working.
+// The seed should be kept secret, or hashes of guessable values can be
+// reversed by trying them.
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/meido-ai/devdb/cli/pkg/pgdump"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a parsed masking policy.
+type Policy struct {
+	// Seed keys the hashes and fakers.
+	Seed string
+	// Columns maps schema.table.column to its rule.
+	Columns map[string]Rule
+}
+
+// Rule says how to mask a column. Exactly one of Faker, Hash, Null and
+// Constant is set.
+type Rule struct {
+	Faker    string
+	Hash     bool
+	Null     bool
+	Constant *string
+	// Length, when positive, truncates the values of Faker and Hash to
+	// that many characters.
+	Length int
+}
+
+// String describes r the way it is written in a policy.
+func (r Rule) String() string {
+	var s string
+	switch {
+	case r.Faker != "":
+		s = "faker: " + r.Faker
+	case r.Hash:
+		s = "hash"
+	case r.Null:
+		return "null"
+	case r.Constant != nil:
+		return fmt.Sprintf("constant: %q", *r.Constant)
+	}
+	if r.Length > 0 {
+		s += fmt.Sprintf(", length: %d", r.Length)
+	}
+	return s
+}
+
+// Load reads and parses the policy in a file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Parse parses and checks a policy.
+func Parse(data []byte) (*Policy, error) {
+	var doc struct {
+		Seed    string               `yaml:"seed"`
+		Columns map[string]yaml.Node `yaml:"columns"`
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+	if len(doc.Columns) == 0 {
+		return nil, fmt.Errorf("invalid policy: no columns to mask")
+	}
+
+	p := &Policy{Seed: doc.Seed, Columns: map[string]Rule{}}
+	for name, node := range doc.Columns {
+		column, err := qualify(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.Columns[column]; ok {
+			return nil, fmt.Errorf("column %s is listed twice", column)
+		}
+		rule, err := parseRule(&node)
+		if err != nil {
+			return nil, fmt.Errorf("column %s, line %d: %v", name, node.Line, err)
+		}
+		p.Columns[column] = rule
+	}
+	return p, nil
+}
+
+// qualify turns table.column and schema.table.column into the latter.
+func qualify(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return "public." + name, nil
+	case 3:
+		return name, nil
+	}
+	return "", fmt.Errorf("invalid column %q: want table.column or schema.table.column", name)
+}
+
+func parseRule(node *yaml.Node) (Rule, error) {
+	var r Rule
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch {
+		case node.Tag == "!!null" || node.Value == "null":
+			r.Null = true
+		case node.Value == "hash":
+			r.Hash = true
+		default:
+			return r, fmt.Errorf("unknown rule %q: want hash, null, or a mapping with faker, hash, null or constant", node.Value)
+		}
+		return r, nil
+	case yaml.MappingNode:
+	default:
+		return r, fmt.Errorf("a rule must be hash, null, or a mapping")
+	}
+
+	strategies := 0
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		var err error
+		switch key {
+		case "faker":
+			strategies++
+			err = value.Decode(&r.Faker)
+			if err == nil && fakers[r.Faker] == nil {
+				err = fmt.Errorf("unknown faker %q (known: %s)", r.Faker, strings.Join(Fakers(), ", "))
+			}
+		case "hash":
+			strategies++
+			err = value.Decode(&r.Hash)
+			if err == nil && !r.Hash {
+				err = fmt.Errorf("hash: false masks nothing")
+			}
+		case "null":
+			strategies++
+			err = value.Decode(&r.Null)
+			if err == nil && !r.Null {
+				err = fmt.Errorf("null: false masks nothing")
+			}
+		case "constant":
+			strategies++
+			if value.Tag == "!!null" {
+				err = fmt.Errorf("constant: null; use the null rule instead")
+				break
+			}
+			r.Constant = new(string)
+			err = value.Decode(r.Constant)
+		case "length":
+			err = value.Decode(&r.Length)
+			if err == nil && r.Length < 1 {
+				err = fmt.Errorf("length must be at least 1")
+			}
+		default:
+			err = fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+	if strategies != 1 {
+		return r, fmt.Errorf("a rule needs exactly one of faker, hash, null and constant")
+	}
+	if r.Length > 0 && r.Faker == "" && !r.Hash {
+		return r, fmt.Errorf("length only applies to faker and hash")
+	}
+	return r, nil
+}
+
+// Validate checks the policy against the tables of a dump and returns an
+// error listing the columns it masks that the dump has no data for.
+func (p *Policy) Validate(tables []pgdump.Table) error {
+	known := map[string]bool{}
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			known[qualifiedColumn(t, c)] = true
+		}
+	}
+	var missing []string
+	for column := range p.Columns {
+		if !known[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("the dump has no data for %s", strings.Join(missing, ", "))
+}
+
+func qualifiedColumn(t pgdump.Table, column string) string {
+	schema := t.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	return schema + "." + t.Name + "." + column
+}
+
+// Apply copies the plain-format dump in r to w with the policy's columns
+// masked.
+func (p *Policy) Apply(r io.Reader, w io.Writer) error {
+	return pgdump.Rewrite(r, w, p.rows)
+}
+
+// rows returns the function masking the rows of t, or nil when none of
+// its columns are masked.
+func (p *Policy) rows(t pgdump.Table) pgdump.RowFunc {
+	rules := make([]*Rule, len(t.Columns))
+	masked := false
+	for i, c := range t.Columns {
+		if rule, ok := p.Columns[qualifiedColumn(t, c)]; ok {
+			rules[i] = &rule
+			masked = true
+		}
+	}
+	if t.Columns == nil {
+		// The columns of INSERT statements are not known; any rule for
+		// the table means it cannot be masked
+		prefix := qualifiedColumn(t, "")
+		for column := range p.Columns {
+			if strings.HasPrefix(column, prefix) {
+				masked = true
+			}
+		}
+	}
+	if !masked {
+		return nil
+	}
+	return func(row []pgdump.Value) error {
+		for i, rule := range rules {
+			if rule != nil {
+				row[i] = p.mask(*rule, row[i])
+			}
+		}
+		return nil
+	}
+}
+
+func (p *Policy) mask(r Rule, v pgdump.Value) pgdump.Value {
+	switch {
+	case r.Null:
+		return pgdump.Value{Null: true}
+	case v.Null:
+		return v
+	case r.Constant != nil:
+		return pgdump.Value{Text: *r.Constant}
+	case r.Hash:
+		return pgdump.Value{Text: truncate(hex.EncodeToString(p.sum("hash", v.Text)), r.Length)}
+	}
+	return pgdump.Value{Text: truncate(fakers[r.Faker](newSource(p.sum(r.Faker, v.Text)), v.Text), r.Length)}
+}
+
+// sum keys a value with the seed; the kind keeps the fakers of a value
+// independent of each other and of its hash.
+func (p *Policy) sum(kind, value string) []byte {
+	mac := hmac.New(sha256.New, []byte(p.Seed))
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
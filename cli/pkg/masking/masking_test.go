@@ -0,0 +1,217 @@
+package masking
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/pgdump"
+)
+
+const samplePolicy = `seed: test-seed
+columns:
+  users.email: {faker: email}
+  users.ssn: {hash: true, length: 12}
+  users.notes: null
+  billing.accounts.country: {constant: NL}
+  billing.accounts.owner_email: {faker: email}
+`
+
+const sampleDump = `SET statement_timeout = 0;
+
+COPY public.users (id, email, ssn, notes) FROM stdin;
+1	alice@example.org	123-45-6789	call after 5
+2	bob@example.org	\N	\N
+\.
+
+COPY billing.accounts (id, owner_email, country) FROM stdin;
+7	alice@example.org	DE
+\.
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(samplePolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Seed != "test-seed" {
+		t.Errorf("seed = %q", p.Seed)
+	}
+	want := map[string]string{
+		"public.users.email":           "faker: email",
+		"public.users.ssn":             "hash, length: 12",
+		"public.users.notes":           "null",
+		"billing.accounts.country":     `constant: "NL"`,
+		"billing.accounts.owner_email": "faker: email",
+	}
+	if len(p.Columns) != len(want) {
+		t.Errorf("columns = %v, want %v", p.Columns, want)
+	}
+	for column, rule := range want {
+		if got := p.Columns[column].String(); got != rule {
+			t.Errorf("%s = %s, want %s", column, got, rule)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{name: "empty", policy: "", want: "no columns to mask"},
+		{name: "unknown field", policy: "seeds: x\ncolumns:\n  users.email: hash\n", want: "field seeds not found"},
+		{name: "bad column", policy: "columns:\n  email: hash\n", want: `invalid column "email"`},
+		{name: "duplicate", policy: "columns:\n  users.email: hash\n  public.users.email: null\n", want: "public.users.email is listed twice"},
+		{name: "unknown rule", policy: "columns:\n  users.email: scramble\n", want: `unknown rule "scramble"`},
+		{name: "unknown faker", policy: "columns:\n  users.email: {faker: iban}\n", want: `unknown faker "iban"`},
+		{name: "two strategies", policy: "columns:\n  users.email: {faker: email, hash: true}\n", want: "exactly one of"},
+		{name: "no strategy", policy: "columns:\n  users.email: {length: 3}\n", want: "exactly one of"},
+		{name: "length of constant", policy: "columns:\n  users.email: {constant: x, length: 3}\n", want: "length only applies"},
+		{name: "constant null", policy: "columns:\n  users.email: {constant: null}\n", want: "use the null rule"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.policy))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Parse() = %v, want an error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	p, err := Parse([]byte(samplePolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := p.Apply(strings.NewReader(sampleDump), &out); err != nil {
+		t.Fatal(err)
+	}
+	masked := out.String()
+	if strings.Contains(masked, "alice@example.org") || strings.Contains(masked, "123-45-6789") || strings.Contains(masked, "call after 5") {
+		t.Fatalf("personal data left in the masked dump:\n%s", masked)
+	}
+
+	tables := map[string][][]pgdump.Value{}
+	err = pgdump.Rewrite(strings.NewReader(masked), &strings.Builder{}, func(table pgdump.Table) pgdump.RowFunc {
+		return func(row []pgdump.Value) error {
+			tables[table.Name] = append(tables[table.Name], append([]pgdump.Value(nil), row...))
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, accounts := tables["users"], tables["accounts"]
+	if len(users) != 2 || len(accounts) != 1 {
+		t.Fatalf("masked dump has %d users and %d accounts", len(users), len(accounts))
+	}
+
+	alice, bob := users[0], users[1]
+	if alice[0].Text != "1" || !strings.HasSuffix(alice[1].Text, "@example.com") {
+		t.Errorf("alice = %+v", alice)
+	}
+	if len(alice[2].Text) != 12 {
+		t.Errorf("hashed ssn = %q, want 12 characters", alice[2].Text)
+	}
+	if !alice[3].Null {
+		t.Errorf("notes = %+v, want NULL", alice[3])
+	}
+	if !bob[2].Null || !bob[3].Null {
+		t.Errorf("NULL values of bob were masked: %+v", bob)
+	}
+	// The same email is masked the same way in every column
+	if accounts[0][1].Text != alice[1].Text {
+		t.Errorf("owner_email = %q, want %q like users.email", accounts[0][1].Text, alice[1].Text)
+	}
+	if accounts[0][2].Text != "NL" {
+		t.Errorf("country = %q, want NL", accounts[0][2].Text)
+	}
+
+	// Masking is deterministic for a seed, and differs across seeds
+	var again strings.Builder
+	if err := p.Apply(strings.NewReader(sampleDump), &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != masked {
+		t.Error("masking the same dump twice gave different results")
+	}
+	p.Seed = "another-seed"
+	var reseeded strings.Builder
+	if err := p.Apply(strings.NewReader(sampleDump), &reseeded); err != nil {
+		t.Fatal(err)
+	}
+	if reseeded.String() == masked {
+		t.Error("masking with another seed gave the same result")
+	}
+}
+
+func TestApplyInserts(t *testing.T) {
+	p, err := Parse([]byte("columns:\n  users.email: hash\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := "INSERT INTO public.orders VALUES (1);\nINSERT INTO public.users VALUES (1, 'alice@example.org');\n"
+	err = p.Apply(strings.NewReader(dump), &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "line 2: the data of public.users is dumped as INSERT statements") {
+		t.Errorf("Apply() = %v, want an INSERT error for public.users", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p, err := Parse([]byte(samplePolicy + "  users.phone: {faker: phone}\n  audit.log.ip: {faker: ipv4}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := pgdump.Tables(strings.NewReader(sampleDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Validate(tables)
+	if err == nil || err.Error() != "the dump has no data for audit.log.ip, public.users.phone" {
+		t.Errorf("Validate() = %v", err)
+	}
+	delete(p.Columns, "public.users.phone")
+	delete(p.Columns, "audit.log.ip")
+	if err := p.Validate(tables); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestFakers(t *testing.T) {
+	p := &Policy{Seed: "s"}
+	for _, kind := range Fakers() {
+		v := p.mask(Rule{Faker: kind}, pgdump.Value{Text: "original value"})
+		if v.Null || v.Text == "" || v.Text == "original value" {
+			t.Errorf("faker %s made %+v", kind, v)
+		}
+	}
+}
+
+func TestFakersUnique(t *testing.T) {
+	p := &Policy{Seed: "s"}
+
+	// The words of an email come from the first 8 bytes of its sum and its
+	// tag carries the rest
+	email := p.mask(Rule{Faker: "email"}, pgdump.Value{Text: "alice@example.org"}).Text
+	local := strings.TrimSuffix(email, "@example.com")
+	tag := local[strings.LastIndex(local, ".")+1:]
+	if want := strings.ToLower(tagEncoding.EncodeToString(p.sum("email", "alice@example.org")[8:])); tag != want {
+		t.Errorf("email %s has tag %q, want %q", email, tag, want)
+	}
+
+	for _, kind := range []string{"email", "username"} {
+		seen := make(map[string]string)
+		for i := 0; i < 20000; i++ {
+			original := fmt.Sprintf("user%d@example.org", i)
+			v := p.mask(Rule{Faker: kind}, pgdump.Value{Text: original})
+			if other, ok := seen[v.Text]; ok {
+				t.Fatalf("faker %s made %s for both %s and %s", kind, v.Text, other, original)
+			}
+			seen[v.Text] = original
+		}
+	}
+}
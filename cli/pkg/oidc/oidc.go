@@ -0,0 +1,299 @@
+// Package oidc implements the parts of OpenID Connect the CLI needs to
+// sign users in through their identity provider: discovery, the OAuth 2.0
+// device authorization grant (RFC 8628) and refresh tokens.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultScopes are requested when no scopes are configured. offline_access
+// asks for a refresh token.
+var DefaultScopes = []string{"openid", "profile", "email", "offline_access"}
+
+// Errors returned while polling for a token.
+var (
+	ErrAccessDenied = errors.New("the login request was denied")
+	ErrExpired      = errors.New("the login request expired, start again")
+)
+
+// expirySkew renews tokens shortly before they expire, so a token does not
+// run out while a request is in flight.
+const expirySkew = 30 * time.Second
+
+// Client talks to one identity provider.
+type Client struct {
+	Issuer   string
+	ClientID string
+	Scopes   []string
+
+	// HTTPClient is used for all requests; http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	// Interval overrides the polling interval given by the provider and
+	// the increment asked for with slow_down.
+	Interval time.Duration
+
+	endpoints *endpoints
+}
+
+type endpoints struct {
+	DeviceAuthorization string `json:"device_authorization_endpoint"`
+	Token               string `json:"token_endpoint"`
+}
+
+// DeviceAuth is the provider's answer to a device authorization request.
+// The user visits VerificationURI and enters UserCode.
+type DeviceAuth struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// Token is an access token with the refresh token used to renew it.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	IDToken      string    `json:"id_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Valid reports whether the access token can still be used.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" &&
+		(t.Expiry.IsZero() || time.Now().Add(expirySkew).Before(t.Expiry))
+}
+
+// tokenResponse is the token endpoint's response, successful or not.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	IDToken          string `json:"id_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// discover fetches the provider's endpoints from its discovery document.
+func (c *Client) discover(ctx context.Context) (*endpoints, error) {
+	if c.endpoints != nil {
+		return c.endpoints, nil
+	}
+
+	u := strings.TrimSuffix(c.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching OIDC discovery document: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching OIDC discovery document: %s returned status code %d", u, resp.StatusCode)
+	}
+
+	e := &endpoints{}
+	if err := json.NewDecoder(resp.Body).Decode(e); err != nil {
+		return nil, fmt.Errorf("parsing OIDC discovery document: %v", err)
+	}
+	if e.DeviceAuthorization == "" {
+		return nil, fmt.Errorf("%s does not support the device authorization grant", c.Issuer)
+	}
+	if e.Token == "" {
+		return nil, fmt.Errorf("%s has no token endpoint", c.Issuer)
+	}
+	c.endpoints = e
+	return e, nil
+}
+
+// StartDeviceAuth asks the provider for a device code and the user code
+// to show to the user.
+func (c *Client) StartDeviceAuth(ctx context.Context) (*DeviceAuth, error) {
+	e, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	resp, err := c.postForm(ctx, e.DeviceAuthorization, url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {strings.Join(scopes, " ")},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("requesting device code: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("requesting device code: %s", describeError(resp.StatusCode, body))
+	}
+	da := &DeviceAuth{}
+	if err := json.Unmarshal(body, da); err != nil {
+		return nil, fmt.Errorf("parsing device authorization response: %v", err)
+	}
+	return da, nil
+}
+
+// PollToken polls the token endpoint until the user has approved the
+// request, the request expired or ctx is done.
+func (c *Client) PollToken(ctx context.Context, da *DeviceAuth) (*Token, error) {
+	e, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Defaults from RFC 8628 section 3.5
+	interval, slowDown := time.Duration(da.Interval)*time.Second, 5*time.Second
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	if c.Interval != 0 {
+		interval, slowDown = c.Interval, c.Interval
+	}
+	if da.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(da.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrExpired
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		token, errCode, err := c.requestToken(ctx, e.Token, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {da.DeviceCode},
+			"client_id":   {c.ClientID},
+		})
+		switch {
+		case errCode == "authorization_pending":
+		case errCode == "slow_down":
+			interval += slowDown
+		case errCode == "access_denied":
+			return nil, ErrAccessDenied
+		case errCode == "expired_token":
+			return nil, ErrExpired
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return nil, ErrExpired
+		case err != nil:
+			return nil, err
+		default:
+			return token, nil
+		}
+	}
+}
+
+// Refresh exchanges t's refresh token for a new token. The refresh token
+// is kept if the provider does not issue a new one.
+func (c *Client) Refresh(ctx context.Context, t *Token) (*Token, error) {
+	if t.RefreshToken == "" {
+		return nil, errors.New("no refresh token")
+	}
+	e, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, _, err := c.requestToken(ctx, e.Token, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {t.RefreshToken},
+		"client_id":     {c.ClientID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("refreshing token: %v", err)
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = t.RefreshToken
+	}
+	return token, nil
+}
+
+// requestToken posts to the token endpoint. OAuth errors are returned
+// with their error code.
+func (c *Client) requestToken(ctx context.Context, endpoint string, form url.Values) (*Token, string, error) {
+	resp, err := c.postForm(ctx, endpoint, form)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	tr := &tokenResponse{}
+	if err := json.Unmarshal(body, tr); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, "", fmt.Errorf("parsing token response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		return nil, tr.Error, errors.New(describeError(resp.StatusCode, body))
+	}
+	if tr.AccessToken == "" {
+		return nil, "", errors.New("token response has no access token")
+	}
+
+	token := &Token{
+		AccessToken:  tr.AccessToken,
+		RefreshToken: tr.RefreshToken,
+		IDToken:      tr.IDToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, "", nil
+}
+
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return c.httpClient().Do(req)
+}
+
+// describeError turns an OAuth error response into a message.
+func describeError(status int, body []byte) string {
+	var e tokenResponse
+	if json.Unmarshal(body, &e) == nil && e.Error != "" {
+		if e.ErrorDescription != "" {
+			return fmt.Sprintf("%s: %s", e.Error, e.ErrorDescription)
+		}
+		return e.Error
+	}
+	return fmt.Sprintf("identity provider returned status code %d", status)
+}
@@ -0,0 +1,126 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/oidc"
+	"github.com/meido-ai/devdb/cli/pkg/oidc/oidctest"
+)
+
+func newClient(p *oidctest.Provider) *oidc.Client {
+	return &oidc.Client{
+		Issuer:   p.URL,
+		ClientID: oidctest.ClientID,
+		Interval: time.Millisecond,
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	p := oidctest.NewProvider()
+	defer p.Close()
+	p.PendingPolls = 2
+	p.SlowDown = true
+
+	c := newClient(p)
+	ctx := context.Background()
+
+	da, err := c.StartDeviceAuth(ctx)
+	if err != nil {
+		t.Fatalf("StartDeviceAuth() error = %v", err)
+	}
+	if da.UserCode != "ABCD-EFGH" || da.VerificationURI != p.URL+"/activate" {
+		t.Errorf("StartDeviceAuth() = %+v", da)
+	}
+	if p.Scopes != "openid profile email offline_access" {
+		t.Errorf("scopes = %q, want the default scopes", p.Scopes)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	token, err := c.PollToken(ctx, da)
+	if err != nil {
+		t.Fatalf("PollToken() error = %v", err)
+	}
+	if token.AccessToken != "access-1" || token.RefreshToken != "refresh-1" || !token.Valid() {
+		t.Errorf("PollToken() = %+v", token)
+	}
+
+	refreshed, err := c.Refresh(ctx, token)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if refreshed.AccessToken != "access-2" || refreshed.RefreshToken != "refresh-2" {
+		t.Errorf("Refresh() = %+v", refreshed)
+	}
+
+	// The old refresh token was rotated away
+	if _, err := c.Refresh(ctx, token); err == nil {
+		t.Error("Refresh() with a used refresh token succeeded")
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	p := oidctest.NewProvider()
+	defer p.Close()
+	p.Deny = true
+
+	c := newClient(p)
+	da, err := c.StartDeviceAuth(context.Background())
+	if err != nil {
+		t.Fatalf("StartDeviceAuth() error = %v", err)
+	}
+	if _, err := c.PollToken(context.Background(), da); !errors.Is(err, oidc.ErrAccessDenied) {
+		t.Errorf("PollToken() error = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestDeviceFlowExpires(t *testing.T) {
+	p := oidctest.NewProvider()
+	defer p.Close()
+	p.PendingPolls = 1 << 30
+
+	c := newClient(p)
+	da, err := c.StartDeviceAuth(context.Background())
+	if err != nil {
+		t.Fatalf("StartDeviceAuth() error = %v", err)
+	}
+	da.ExpiresIn = 1
+	if _, err := c.PollToken(context.Background(), da); !errors.Is(err, oidc.ErrExpired) {
+		t.Errorf("PollToken() error = %v, want ErrExpired", err)
+	}
+}
+
+func TestUnknownClient(t *testing.T) {
+	p := oidctest.NewProvider()
+	defer p.Close()
+
+	c := newClient(p)
+	c.ClientID = "someone-else"
+	if _, err := c.StartDeviceAuth(context.Background()); err == nil {
+		t.Error("StartDeviceAuth() with an unknown client succeeded")
+	}
+}
+
+func TestTokenValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *oidc.Token
+		want  bool
+	}{
+		{name: "nil", token: nil, want: false},
+		{name: "no expiry", token: &oidc.Token{AccessToken: "a"}, want: true},
+		{name: "fresh", token: &oidc.Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, want: true},
+		{name: "about to expire", token: &oidc.Token{AccessToken: "a", Expiry: time.Now().Add(10 * time.Second)}, want: false},
+		{name: "expired", token: &oidc.Token{AccessToken: "a", Expiry: time.Now().Add(-time.Minute)}, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.token.Valid(); got != tc.want {
+				t.Errorf("Valid() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
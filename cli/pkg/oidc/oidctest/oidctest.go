@@ -0,0 +1,131 @@
+// Package oidctest provides a stand-in identity provider for testing the
+// device authorization grant.
+package oidctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// ClientID is the only client the provider accepts.
+const ClientID = "devdb-cli"
+
+// Provider is an OIDC provider serving discovery, device authorization and
+// token endpoints. Every device code is approved after PendingPolls polls
+// unless Deny is set. Access tokens are numbered: "access-1", "access-2"...
+type Provider struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	PendingPolls int
+	SlowDown     bool
+	Deny         bool
+	ExpiresIn    int
+	polls        int
+	issued       int
+	refreshToken string
+	// Scopes holds the scope parameter of the last device authorization
+	Scopes string
+}
+
+// NewProvider starts a provider. Tokens are valid for an hour unless
+// ExpiresIn is changed.
+func NewProvider() *Provider {
+	p := &Provider{ExpiresIn: 3600}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/device", p.device)
+	mux.HandleFunc("/token", p.token)
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+// Issued returns the number of access tokens issued so far.
+func (p *Provider) Issued() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.issued
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                        p.URL,
+		"device_authorization_endpoint": p.URL + "/device",
+		"token_endpoint":                p.URL + "/token",
+	})
+}
+
+func (p *Provider) device(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") != ClientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	p.mu.Lock()
+	p.polls = 0
+	p.Scopes = r.PostFormValue("scope")
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"device_code":               "device-code",
+		"user_code":                 "ABCD-EFGH",
+		"verification_uri":          p.URL + "/activate",
+		"verification_uri_complete": p.URL + "/activate?user_code=ABCD-EFGH",
+		"expires_in":                600,
+		"interval":                  5,
+	})
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch r.PostFormValue("grant_type") {
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		if r.PostFormValue("device_code") != "device-code" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		if p.Deny {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "access_denied"})
+			return
+		}
+		p.polls++
+		if p.SlowDown && p.polls == 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "slow_down"})
+			return
+		}
+		if p.polls <= p.PendingPolls {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+			return
+		}
+	case "refresh_token":
+		if p.refreshToken == "" || r.PostFormValue("refresh_token") != p.refreshToken {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error":             "invalid_grant",
+				"error_description": "refresh token is invalid or expired",
+			})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	p.issued++
+	p.refreshToken = fmt.Sprintf("refresh-%d", p.issued)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  fmt.Sprintf("access-%d", p.issued),
+		"refresh_token": p.refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    p.ExpiresIn,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
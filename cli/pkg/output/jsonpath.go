@@ -0,0 +1,329 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EvalJSONPath evaluates a kubectl-style JSONPath template against data,
+// which must be the generic form produced by encoding/json. Text outside
+// braces is copied verbatim; inside braces the following are understood:
+//
+//	{.name}  {.databases[0].host}  {[*].name}  {..port}  {.*}
+//	{range [*]}{.name}{"\n"}{end}
+//
+// Multiple results of a single expression are separated by spaces. A field
+// that is not present, or an index past the end of an array, is an error
+// unless allowMissing is set, in which case it produces no output, like
+// kubectl's --allow-missing-template-keys.
+func EvalJSONPath(expr string, data interface{}, allowMissing bool) (string, error) {
+	nodes, err := parseTemplate(expr)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := execNodes(&b, nodes, data, allowMissing); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+type jpNode struct {
+	text     string // literal text, used when path and children are nil
+	path     []jpStep
+	isPath   bool
+	children []jpNode // set for range nodes
+	isRange  bool
+}
+
+type jpStepKind int
+
+const (
+	stepField jpStepKind = iota
+	stepIndex
+	stepWildcard
+	stepRecursive
+)
+
+type jpStep struct {
+	kind  jpStepKind
+	name  string
+	index int
+}
+
+func parseTemplate(expr string) ([]jpNode, error) {
+	var stack [][]jpNode
+	var cur []jpNode
+
+	for len(expr) > 0 {
+		open := strings.IndexByte(expr, '{')
+		if open < 0 {
+			cur = append(cur, jpNode{text: expr})
+			break
+		}
+		if open > 0 {
+			cur = append(cur, jpNode{text: expr[:open]})
+		}
+		end, err := closingBrace(expr, open)
+		if err != nil {
+			return nil, err
+		}
+		inner := strings.TrimSpace(expr[open+1 : end])
+		expr = expr[end+1:]
+
+		switch {
+		case inner == "end":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("jsonpath: {end} without matching {range}")
+			}
+			body := cur
+			cur = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			cur[len(cur)-1].children = body
+		case strings.HasPrefix(inner, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(inner, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			cur = append(cur, jpNode{path: path, isPath: true, isRange: true})
+			stack = append(stack, cur)
+			cur = nil
+		case strings.HasPrefix(inner, `"`):
+			s, err := strconv.Unquote(inner)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid string literal %s", inner)
+			}
+			cur = append(cur, jpNode{text: s})
+		default:
+			path, err := parsePath(inner)
+			if err != nil {
+				return nil, err
+			}
+			cur = append(cur, jpNode{path: path, isPath: true})
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("jsonpath: {range} without matching {end}")
+	}
+	return cur, nil
+}
+
+// closingBrace returns the index of the brace closing the one at open,
+// skipping over quoted string literals.
+func closingBrace(s string, open int) (int, error) {
+	inQuote := false
+	for i := open + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '"':
+			inQuote = !inQuote
+		case '}':
+			if !inQuote {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("jsonpath: unclosed '{' in %q", s)
+}
+
+func parsePath(p string) ([]jpStep, error) {
+	orig := p
+	p = strings.TrimPrefix(p, "$")
+	var steps []jpStep
+	for len(p) > 0 {
+		switch {
+		case strings.HasPrefix(p, ".."):
+			name, rest := splitName(p[2:])
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath: expected field name after '..' in %q", orig)
+			}
+			steps = append(steps, jpStep{kind: stepRecursive, name: name})
+			p = rest
+		case p[0] == '.':
+			name, rest := splitName(p[1:])
+			switch name {
+			case "":
+				// A bare "." refers to the current element.
+			case "*":
+				steps = append(steps, jpStep{kind: stepWildcard})
+			default:
+				steps = append(steps, jpStep{kind: stepField, name: name})
+			}
+			p = rest
+		case p[0] == '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath: unclosed '[' in %q", orig)
+			}
+			sub := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			switch {
+			case sub == "*":
+				steps = append(steps, jpStep{kind: stepWildcard})
+			case len(sub) >= 2 && (sub[0] == '\'' || sub[0] == '"') && sub[len(sub)-1] == sub[0]:
+				steps = append(steps, jpStep{kind: stepField, name: sub[1 : len(sub)-1]})
+			default:
+				n, err := strconv.Atoi(sub)
+				if err != nil {
+					return nil, fmt.Errorf("jsonpath: invalid index [%s] in %q", sub, orig)
+				}
+				steps = append(steps, jpStep{kind: stepIndex, index: n})
+			}
+		default:
+			return nil, fmt.Errorf("jsonpath: unexpected %q in %q", p, orig)
+		}
+	}
+	return steps, nil
+}
+
+func splitName(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func execNodes(b *strings.Builder, nodes []jpNode, cur interface{}, allowMissing bool) error {
+	for _, n := range nodes {
+		if !n.isPath {
+			b.WriteString(n.text)
+			continue
+		}
+		values, err := evalPath(n.path, cur, allowMissing)
+		if err != nil {
+			return err
+		}
+		if n.isRange {
+			for _, v := range values {
+				items := []interface{}{v}
+				if arr, ok := v.([]interface{}); ok && expandsRange(n.path) {
+					items = arr
+				}
+				for _, item := range items {
+					if err := execNodes(b, n.children, item, allowMissing); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		for i, v := range values {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			s, err := formatValue(v)
+			if err != nil {
+				return err
+			}
+			b.WriteString(s)
+		}
+	}
+	return nil
+}
+
+// expandsRange reports whether a range over path iterates the elements of
+// the array it selects, as in {range .databases}, rather than the values
+// already produced by a wildcard or index.
+func expandsRange(path []jpStep) bool {
+	if len(path) == 0 {
+		return true
+	}
+	k := path[len(path)-1].kind
+	return k == stepField || k == stepRecursive
+}
+
+// evalPath returns the values steps select in data. Wildcards and
+// recursive descent may select nothing; a named field or an index must
+// exist unless allowMissing is set.
+func evalPath(steps []jpStep, data interface{}, allowMissing bool) ([]interface{}, error) {
+	cur := []interface{}{data}
+	for _, st := range steps {
+		var next []interface{}
+		for _, v := range cur {
+			switch st.kind {
+			case stepField:
+				m, _ := v.(map[string]interface{})
+				fv, ok := m[st.name]
+				if ok {
+					next = append(next, fv)
+				} else if !allowMissing {
+					return nil, fmt.Errorf("jsonpath: %s is not found", st.name)
+				}
+			case stepIndex:
+				arr, _ := v.([]interface{})
+				i := st.index
+				if i < 0 {
+					i += len(arr)
+				}
+				if i >= 0 && i < len(arr) {
+					next = append(next, arr[i])
+				} else if !allowMissing {
+					return nil, fmt.Errorf("jsonpath: index [%d] is out of range", st.index)
+				}
+			case stepWildcard:
+				next = append(next, children(v)...)
+			case stepRecursive:
+				next = append(next, recursiveFind(v, st.name)...)
+			}
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func children(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, t[k])
+		}
+		return out
+	}
+	return nil
+}
+
+func recursiveFind(v interface{}, name string) []interface{} {
+	var out []interface{}
+	if m, ok := v.(map[string]interface{}); ok {
+		if fv, ok := m[name]; ok {
+			out = append(out, fv)
+		}
+	}
+	for _, c := range children(v) {
+		out = append(out, recursiveFind(c, name)...)
+	}
+	return out
+}
+
+func formatValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
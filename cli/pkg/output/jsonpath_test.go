@@ -0,0 +1,85 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEvalJSONPath(t *testing.T) {
+	var data interface{}
+	err := json.Unmarshal([]byte(`{
+		"name": "billing",
+		"databases": [
+			{"name": "db1", "port": 5432, "status": "running"},
+			{"name": "db2", "port": 5433, "status": "stopped"}
+		],
+		"defaultCredentials": {"username": "devdb"}
+	}`), &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{expr: "{.name}", want: "billing"},
+		{expr: "$.name", want: "$.name"},
+		{expr: "{$.name}", want: "billing"},
+		{expr: "{.databases[1].name}", want: "db2"},
+		{expr: "{.databases[-1].port}", want: "5433"},
+		{expr: "{.databases[*].name}", want: "db1 db2"},
+		{expr: "{..username}", want: "devdb"},
+		{expr: "{.defaultCredentials}", want: `{"username":"devdb"}`},
+		{expr: "{.missing}", wantErr: true},
+		{expr: "{.databases[2].name}", wantErr: true},
+		{expr: "{.databases[*].missing}", wantErr: true},
+		{expr: "{..missing}", want: ""},
+		{expr: "name={.name}", want: "name=billing"},
+		{expr: `{range .databases}{.name}:{.status}{"\n"}{end}`, want: "db1:running\ndb2:stopped\n"},
+		{expr: `{range .databases[*]}[{.port}]{end}`, want: "[5432][5433]"},
+		{expr: "{.databases[x]}", wantErr: true},
+		{expr: "{.name", wantErr: true},
+		{expr: "{range .databases}{.name}", wantErr: true},
+		{expr: "{end}", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		got, err := EvalJSONPath(tc.expr, data, false)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("EvalJSONPath(%q) error = %v, wantErr %v", tc.expr, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("EvalJSONPath(%q) = %q, want %q", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestEvalJSONPathAllowMissing(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(`{"databases": [{"name": "db1", "expiresAt": "tomorrow"}, {"name": "db2"}]}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "{.missing}", want: ""},
+		{expr: "{.databases[5].name}", want: ""},
+		{expr: `{range .databases}{.name}={.expiresAt};{end}`, want: "db1=tomorrow;db2=;"},
+	}
+
+	for _, tc := range tests {
+		got, err := EvalJSONPath(tc.expr, data, true)
+		if err != nil {
+			t.Errorf("EvalJSONPath(%q) error = %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("EvalJSONPath(%q) = %q, want %q", tc.expr, got, tc.want)
+		}
+	}
+}
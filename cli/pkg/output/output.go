@@ -0,0 +1,207 @@
+// Package output renders API resources in the formats accepted by the CLI's
+// --output flag: JSON, YAML, aligned tables, JSONPath expressions and Go
+// templates.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind identifies an output format.
+type Kind string
+
+const (
+	// Text is the default, human-oriented output written by each command.
+	Text     Kind = ""
+	JSON     Kind = "json"
+	YAML     Kind = "yaml"
+	Table    Kind = "table"
+	Wide     Kind = "wide"
+	JSONPath Kind = "jsonpath"
+	Template Kind = "go-template"
+)
+
+// Format is a parsed --output value. Arg holds the expression for the
+// jsonpath and go-template kinds. AllowMissingKeys makes fields that are
+// not present print as empty instead of failing, for optional fields such
+// as a database's expiresAt.
+type Format struct {
+	Kind             Kind
+	Arg              string
+	AllowMissingKeys bool
+}
+
+// Formats lists the accepted --output values for help text.
+const Formats = "json|yaml|table|wide|jsonpath=<expr>|go-template=<tmpl>"
+
+// Parse parses an --output value such as "json" or "jsonpath={.name}".
+func Parse(s string) (Format, error) {
+	name, arg, hasArg := strings.Cut(s, "=")
+	switch Kind(name) {
+	case Text, JSON, YAML, Table, Wide:
+		if hasArg {
+			return Format{}, fmt.Errorf("output format %q does not take an argument", name)
+		}
+		return Format{Kind: Kind(name)}, nil
+	case JSONPath, Template, "template":
+		if arg == "" {
+			return Format{}, fmt.Errorf("output format %q requires an expression, e.g. %s=...", name, name)
+		}
+		if name == "template" {
+			name = string(Template)
+		}
+		return Format{Kind: Kind(name), Arg: arg}, nil
+	}
+	return Format{}, fmt.Errorf("unknown output format %q (want %s)", s, Formats)
+}
+
+// Tabular is implemented by values that can be shown as a table. Wide
+// tables include every field of the resource.
+type Tabular interface {
+	Columns(wide bool) []string
+	Rows(wide bool) [][]string
+}
+
+// Print writes obj to w in format f. Table and wide formats require obj to
+// implement Tabular; all other formats work on the JSON form of obj, so
+// field names match the API.
+func Print(w io.Writer, f Format, obj interface{}) error {
+	switch f.Kind {
+	case Table, Wide:
+		t, ok := obj.(Tabular)
+		if !ok {
+			return fmt.Errorf("output format %q is not supported for this command", f.Kind)
+		}
+		return printTable(w, t, f.Kind == Wide)
+	}
+
+	data, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+
+	switch f.Kind {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(data); err != nil {
+			return err
+		}
+		return enc.Close()
+	case JSONPath:
+		out, err := EvalJSONPath(f.Arg, data, f.AllowMissingKeys)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, out)
+		return err
+	case Template:
+		missingKey := "missingkey=error"
+		if f.AllowMissingKeys {
+			missingKey = "missingkey=default"
+		}
+		tmpl, err := template.New("output").Option(missingKey).Funcs(template.FuncMap{emptyFunc: empty}).Parse(f.Arg)
+		if err != nil {
+			return fmt.Errorf("parsing template: %v", err)
+		}
+		if f.AllowMissingKeys {
+			printEmpty(tmpl)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("executing template: %v", err)
+		}
+		_, err = fmt.Fprintln(w, buf.String())
+		return err
+	}
+	return fmt.Errorf("output format %q cannot be printed", f.Kind)
+}
+
+// emptyFunc names the template function printEmpty pipes values through.
+const emptyFunc = "devdbEmpty"
+
+// empty returns "" for a missing field, which reaches it as nil, and v
+// otherwise.
+func empty(v interface{}) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+// printEmpty makes the actions of tmpl that print a value print missing
+// fields as empty, as kubectl does, rather than as "<no value>".
+func printEmpty(tmpl *template.Template) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			printEmptyNode(t.Tree, t.Tree.Root)
+		}
+	}
+}
+
+func printEmptyNode(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			printEmptyNode(tree, child)
+		}
+	case *parse.ActionNode:
+		// Actions declaring variables print nothing
+		if len(n.Pipe.Decl) == 0 {
+			ident := parse.NewIdentifier(emptyFunc).SetTree(tree).SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{ident}})
+		}
+	case *parse.IfNode:
+		printEmptyNode(tree, n.List)
+		printEmptyNode(tree, n.ElseList)
+	case *parse.RangeNode:
+		printEmptyNode(tree, n.List)
+		printEmptyNode(tree, n.ElseList)
+	case *parse.WithNode:
+		printEmptyNode(tree, n.List)
+		printEmptyNode(tree, n.ElseList)
+	}
+}
+
+// toGeneric round-trips obj through JSON so that the JSON tags of the
+// generated API types decide the field names for every format. Tabular
+// wrappers expose the underlying resource through Unwrap.
+func toGeneric(obj interface{}) (interface{}, error) {
+	if u, ok := obj.(interface{ Unwrap() interface{} }); ok {
+		obj = u.Unwrap()
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("encoding output: %v", err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("encoding output: %v", err)
+	}
+	return data, nil
+}
+
+func printTable(w io.Writer, t Tabular, wide bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.Columns(wide), "\t"))
+	for _, row := range t.Rows(wide) {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,151 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type item struct {
+	Name string `json:"name"`
+	Port *int   `json:"port,omitempty"`
+}
+
+type itemTable []item
+
+func (t itemTable) Unwrap() interface{} { return []item(t) }
+
+func (t itemTable) Columns(wide bool) []string {
+	if wide {
+		return []string{"NAME", "PORT"}
+	}
+	return []string{"NAME"}
+}
+
+func (t itemTable) Rows(wide bool) [][]string {
+	var rows [][]string
+	for _, it := range t {
+		row := []string{it.Name}
+		if wide {
+			row = append(row, "5432")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "", want: Format{Kind: Text}},
+		{in: "json", want: Format{Kind: JSON}},
+		{in: "wide", want: Format{Kind: Wide}},
+		{in: "jsonpath={.name}", want: Format{Kind: JSONPath, Arg: "{.name}"}},
+		{in: "template={{.name}}", want: Format{Kind: Template, Arg: "{{.name}}"}},
+		{in: "go-template={{.name}}", want: Format{Kind: Template, Arg: "{{.name}}"}},
+		{in: "jsonpath", wantErr: true},
+		{in: "json=x", wantErr: true},
+		{in: "xml", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		got, err := Parse(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	port := 5432
+	items := itemTable{{Name: "a", Port: &port}, {Name: "bb"}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "json", want: "[\n  {\n    \"name\": \"a\",\n    \"port\": 5432\n  },\n  {\n    \"name\": \"bb\"\n  }\n]\n"},
+		{format: "yaml", want: "- name: a\n  port: 5432\n- name: bb\n"},
+		{format: "table", want: "NAME\na\nbb\n"},
+		{format: "wide", want: "NAME   PORT\na      5432\nbb     5432\n"},
+		{format: "jsonpath={[*].name}", want: "a bb\n"},
+		{format: "go-template={{range .}}{{.name}};{{end}}", want: "a;bb;\n"},
+	}
+
+	for _, tc := range tests {
+		f, err := Parse(tc.format)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.format, err)
+		}
+		var buf bytes.Buffer
+		if err := Print(&buf, f, items); err != nil {
+			t.Errorf("Print(%q): %v", tc.format, err)
+			continue
+		}
+		if buf.String() != tc.want {
+			t.Errorf("Print(%q) = %q, want %q", tc.format, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestPrintMissingKeys(t *testing.T) {
+	items := itemTable{{Name: "a"}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "jsonpath={[*].port}", want: "\n"},
+		{format: "go-template={{range .}}{{.name}}:{{.port}};{{end}}", want: "a:;\n"},
+		{format: `go-template={{range .}}{{if .name}}{{.port}}{{else}}-{{end}}{{with .name}}{{.}}{{end}}{{end}}`, want: "a\n"},
+	}
+	for _, tc := range tests {
+		f, err := Parse(tc.format)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.format, err)
+		}
+		var buf bytes.Buffer
+		if err := Print(&buf, f, items); err == nil {
+			t.Errorf("Print(%q) without AllowMissingKeys: expected error", tc.format)
+		}
+
+		f.AllowMissingKeys = true
+		buf.Reset()
+		if err := Print(&buf, f, items); err != nil {
+			t.Errorf("Print(%q): %v", tc.format, err)
+			continue
+		}
+		if buf.String() != tc.want {
+			t.Errorf("Print(%q) = %q, want %q", tc.format, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestPrintTemplateValues(t *testing.T) {
+	// Only missing fields print as empty, not values that read like them
+	items := itemTable{{Name: "<no value>"}}
+	f, err := Parse("go-template={{range .}}{{.name}}|{{.port}}|{{printf \"%s!\" .name}}{{end}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AllowMissingKeys = true
+	var buf bytes.Buffer
+	if err := Print(&buf, f, items); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<no value>||<no value>!\n"; buf.String() != want {
+		t.Errorf("Print = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintTableUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Print(&buf, Format{Kind: Table}, map[string]string{"a": "b"}); err == nil {
+		t.Error("expected error printing a non-tabular value as a table")
+	}
+}
@@ -0,0 +1,292 @@
+package pgdump
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Archive formats, as pg_dump stores them in an archive's header.
+const (
+	formatCustom    = 1
+	formatDirectory = 5
+)
+
+// Archive versions, as pg_dump's K_VERS_* constants.
+const (
+	archiveVersion1_10 = 1<<16 | 10<<8
+	archiveVersion1_11 = 1<<16 | 11<<8
+	archiveVersion1_14 = 1<<16 | 14<<8
+	archiveVersion1_15 = 1<<16 | 15<<8
+	archiveVersion1_16 = 1<<16 | 16<<8
+)
+
+// TOC is the table of contents of a custom or directory-format archive:
+// every object in the dump, in the order pg_restore restores them.
+type TOC struct {
+	// ServerVersion and DumpVersion are the versions of the dumped
+	// server and of pg_dump.
+	ServerVersion string
+	DumpVersion   string
+	Entries       []TOCEntry
+}
+
+// TOCEntry is an object in an archive.
+type TOCEntry struct {
+	DumpID int
+	// Tag is the name of the object, e.g. users.
+	Tag string
+	// Desc is the kind of object, e.g. TABLE, TABLE DATA or FK CONSTRAINT.
+	Desc      string
+	Namespace string
+	Owner     string
+	// Defn is the SQL creating the object.
+	Defn string
+	// CopyStmt is the COPY statement of TABLE DATA entries.
+	CopyStmt string
+	// Filename is the file with the entry's data in a directory-format
+	// archive.
+	Filename string
+}
+
+// ReadTOC reads the table of contents of a custom-format archive, or of a
+// directory-format one from its toc.dat. Archive versions 1.10 and later
+// are supported, which covers every maintained pg_dump.
+func ReadTOC(r io.Reader) (*TOC, error) {
+	ar := &archiveReader{r: bufio.NewReader(r)}
+	magic := make([]byte, 5)
+	if _, err := io.ReadFull(ar.r, magic); err != nil || string(magic) != "PGDMP" {
+		return nil, errors.New("not a pg_dump archive")
+	}
+	major, minor := ar.byte(), ar.byte()
+	ar.version = int(major)<<16 | int(minor)<<8 | int(ar.byte())
+	if ar.err == nil && ar.version < archiveVersion1_10 {
+		return nil, fmt.Errorf("archive version %d.%d is not supported", major, minor)
+	}
+	ar.intSize = int(ar.byte())
+	ar.offSize = int(ar.byte())
+	format := ar.byte()
+	if ar.err == nil && format != formatCustom && format != formatDirectory {
+		return nil, fmt.Errorf("archive format %d is not supported: expected a custom or directory-format archive", format)
+	}
+	if ar.version >= archiveVersion1_15 {
+		ar.byte() // compression algorithm
+	} else {
+		ar.int() // compression level
+	}
+	for i := 0; i < 7; i++ {
+		ar.int() // creation time
+	}
+	ar.str() // database name
+
+	toc := &TOC{ServerVersion: ar.str(), DumpVersion: ar.str()}
+	n := ar.int()
+	for i := 0; i < n && ar.err == nil; i++ {
+		var e TOCEntry
+		e.DumpID = ar.int()
+		ar.int() // has a data dumper
+		ar.str() // catalog table OID
+		ar.str() // OID
+		e.Tag = ar.str()
+		e.Desc = ar.str()
+		if ar.version >= archiveVersion1_11 {
+			ar.int() // section
+		}
+		e.Defn = ar.str()
+		ar.str() // DROP statement
+		e.CopyStmt = ar.str()
+		e.Namespace = ar.str()
+		ar.str() // tablespace
+		if ar.version >= archiveVersion1_14 {
+			ar.str() // table access method
+		}
+		if ar.version >= archiveVersion1_16 {
+			ar.int() // relkind
+		}
+		e.Owner = ar.str()
+		ar.str() // WITH OIDS
+		// Dependencies, up to a NULL
+		for _, null := ar.strOrNull(); !null && ar.err == nil; _, null = ar.strOrNull() {
+		}
+		if format == formatDirectory {
+			e.Filename = ar.str()
+		} else {
+			ar.byte() // data state
+			ar.skip(ar.offSize)
+		}
+		toc.Entries = append(toc.Entries, e)
+	}
+	if ar.err != nil {
+		if errors.Is(ar.err, io.EOF) || errors.Is(ar.err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("the archive's table of contents is truncated")
+		}
+		return nil, ar.err
+	}
+	return toc, nil
+}
+
+// archiveReader reads the integers and strings of pg_dump's archive
+// format. The first error sticks; reads after it return zero values.
+type archiveReader struct {
+	r       *bufio.Reader
+	version int
+	intSize int
+	offSize int
+	err     error
+}
+
+func (ar *archiveReader) byte() byte {
+	if ar.err != nil {
+		return 0
+	}
+	b, err := ar.r.ReadByte()
+	ar.err = err
+	return b
+}
+
+func (ar *archiveReader) skip(n int) {
+	if ar.err == nil {
+		_, ar.err = ar.r.Discard(n)
+	}
+}
+
+// int reads a sign byte and a little-endian magnitude of intSize bytes.
+func (ar *archiveReader) int() int {
+	negative := ar.byte() != 0
+	n := 0
+	for i := 0; i < ar.intSize; i++ {
+		n |= int(ar.byte()) << (8 * i)
+	}
+	if negative {
+		return -n
+	}
+	return n
+}
+
+func (ar *archiveReader) str() string {
+	s, _ := ar.strOrNull()
+	return s
+}
+
+// strOrNull reads a string, which is NULL when its length is negative.
+func (ar *archiveReader) strOrNull() (string, bool) {
+	n := ar.int()
+	if ar.err != nil || n < 0 {
+		return "", ar.err == nil
+	}
+	b := make([]byte, n)
+	_, ar.err = io.ReadFull(ar.r, b)
+	return string(b), false
+}
+
+// Open opens a dump to read as plain SQL: a plain-format file as it is, or
+// a directory-format dump (pg_dump -Fd) converted the way pg_restore -f
+// would, but without ownership statements.
+func Open(path string) (io.ReadCloser, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return os.Open(path)
+	}
+
+	f, err := os.Open(filepath.Join(path, "toc.dat"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s has no toc.dat: not a pg_dump directory dump", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	toc, err := ReadTOC(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(toc.writeSQL(pw, path)) }()
+	return pr, nil
+}
+
+// writeSQL writes the archive as a plain-format dump, reading the data of
+// its tables from dir.
+func (toc *TOC) writeSQL(w io.Writer, dir string) error {
+	bw := bufio.NewWriterSize(w, 64<<10)
+	bw.WriteString("--\n-- PostgreSQL database dump\n--\n\n")
+	if toc.ServerVersion != "" {
+		fmt.Fprintf(bw, "-- Dumped from database version %s\n", toc.ServerVersion)
+	}
+	if toc.DumpVersion != "" {
+		fmt.Fprintf(bw, "-- Dumped by pg_dump version %s\n", toc.DumpVersion)
+	}
+	bw.WriteString("\n")
+
+	for _, e := range toc.Entries {
+		switch {
+		case e.Desc == "BLOBS":
+			return errors.New("large objects are not supported: dump without them (pg_dump -B)")
+		case e.Desc == "TABLE DATA":
+			if e.Filename == "" || e.CopyStmt == "" {
+				continue
+			}
+			fmt.Fprintf(bw, "--\n-- Data for Name: %s; Type: %s; Schema: %s; Owner: %s\n--\n\n", e.Tag, e.Desc, orDash(e.Namespace), orDash(e.Owner))
+			bw.WriteString(e.CopyStmt)
+			if err := copyData(bw, filepath.Join(dir, e.Filename)); err != nil {
+				return err
+			}
+			bw.WriteString("\\.\n\n\n")
+		case e.Defn != "":
+			fmt.Fprintf(bw, "--\n-- Name: %s; Type: %s; Schema: %s; Owner: %s\n--\n\n", e.Tag, e.Desc, orDash(e.Namespace), orDash(e.Owner))
+			bw.WriteString(e.Defn)
+			bw.WriteString("\n\n")
+		}
+	}
+	bw.WriteString("--\n-- PostgreSQL database dump complete\n--\n\n")
+	return bw.Flush()
+}
+
+// copyData copies a table's data file, which pg_dump may have compressed
+// and given an extension to.
+func copyData(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		f, err = os.Open(path + ".gz")
+		if os.IsNotExist(err) {
+			for _, ext := range []string{".lz4", ".zst"} {
+				if _, statErr := os.Stat(path + ext); statErr == nil {
+					return fmt.Errorf("%s%s: only gzip-compressed dumps are supported (pg_dump -Z gzip)", path, ext)
+				}
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if filepath.Ext(f.Name()) == ".gz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,182 @@
+package pgdump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tocWriter writes the parts of pg_dump's archive format with 4-byte
+// integers.
+type tocWriter struct{ bytes.Buffer }
+
+func (w *tocWriter) int(n int) {
+	sign := byte(0)
+	if n < 0 {
+		sign, n = 1, -n
+	}
+	w.WriteByte(sign)
+	for i := 0; i < 4; i++ {
+		w.WriteByte(byte(n >> (8 * i)))
+	}
+}
+
+func (w *tocWriter) str(s string) {
+	w.int(len(s))
+	w.WriteString(s)
+}
+
+func (w *tocWriter) null() { w.int(-1) }
+
+// writeDirectoryDump writes a directory-format dump, as pg_dump 16 would,
+// with the entries and the data files.
+func writeDirectoryDump(t *testing.T, entries []TOCEntry, data map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	var w tocWriter
+	w.WriteString("PGDMP")
+	w.Write([]byte{1, 15, 0, 4, 8, formatDirectory, 1})
+	for i := 0; i < 7; i++ {
+		w.int(0)
+	}
+	w.str("shop")
+	w.str("16.2")
+	w.str("16.2")
+	w.int(len(entries))
+	for _, e := range entries {
+		w.int(e.DumpID)
+		w.int(1)
+		w.str("0")
+		w.str("0")
+		w.str(e.Tag)
+		w.str(e.Desc)
+		w.int(2)
+		w.str(e.Defn)
+		w.str("")
+		w.str(e.CopyStmt)
+		w.str(e.Namespace)
+		w.str("")
+		w.str("heap")
+		w.str(e.Owner)
+		w.str("false")
+		w.str("1")
+		w.null()
+		if e.Filename == "" {
+			w.null()
+		} else {
+			w.str(e.Filename)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "toc.dat"), w.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, rows := range data {
+		var buf bytes.Buffer
+		if strings.HasSuffix(name, ".gz") {
+			gz := gzip.NewWriter(&buf)
+			gz.Write([]byte(rows))
+			gz.Close()
+		} else {
+			buf.WriteString(rows)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var sampleEntries = []TOCEntry{
+	{DumpID: 1, Tag: "ENCODING", Desc: "ENCODING", Defn: "SET client_encoding = 'UTF8';\n"},
+	{DumpID: 10, Tag: "users", Desc: "TABLE", Namespace: "public", Owner: "alice", Defn: "CREATE TABLE public.users (\n    id integer NOT NULL\n);\n"},
+	{DumpID: 11, Tag: "orders", Desc: "TABLE", Namespace: "public", Owner: "alice", Defn: "CREATE TABLE public.orders (\n    id integer NOT NULL,\n    user_id integer\n);\n"},
+	{DumpID: 20, Tag: "users", Desc: "TABLE DATA", Namespace: "public", Owner: "alice", CopyStmt: "COPY public.users (id) FROM stdin;\n", Filename: "20.dat"},
+	{DumpID: 21, Tag: "orders", Desc: "TABLE DATA", Namespace: "public", Owner: "alice", CopyStmt: "COPY public.orders (id, user_id) FROM stdin;\n", Filename: "21.dat"},
+	{DumpID: 30, Tag: "orders orders_user_id_fkey", Desc: "FK CONSTRAINT", Namespace: "public", Owner: "alice", Defn: "ALTER TABLE ONLY public.orders\n    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);\n"},
+}
+
+func TestReadTOC(t *testing.T) {
+	dir := writeDirectoryDump(t, sampleEntries, nil)
+	f, err := os.Open(filepath.Join(dir, "toc.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	toc, err := ReadTOC(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if toc.ServerVersion != "16.2" || len(toc.Entries) != len(sampleEntries) {
+		t.Fatalf("toc = %+v", toc)
+	}
+	for i, e := range toc.Entries {
+		if e != sampleEntries[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, e, sampleEntries[i])
+		}
+	}
+
+	if _, err := ReadTOC(strings.NewReader("PGDMP\x01\x0f\x00\x04\x08\x05")); err == nil || err.Error() != "the archive's table of contents is truncated" {
+		t.Errorf("ReadTOC(truncated) = %v", err)
+	}
+	if _, err := ReadTOC(strings.NewReader("-- SQL\n")); err == nil {
+		t.Error("ReadTOC(plain dump) succeeded")
+	}
+}
+
+func TestOpenDirectory(t *testing.T) {
+	dir := writeDirectoryDump(t, sampleEntries, map[string]string{
+		"20.dat.gz": "1\n2\n",
+		"21.dat":    "7\t1\n8\t\\N\n",
+	})
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	sql, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"-- Dumped from database version 16.2\n",
+		"SET client_encoding = 'UTF8';\n",
+		"-- Name: users; Type: TABLE; Schema: public; Owner: alice\n--\n\nCREATE TABLE public.users (\n",
+		"-- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: alice\n--\n\nCOPY public.users (id) FROM stdin;\n1\n2\n\\.\n",
+		"COPY public.orders (id, user_id) FROM stdin;\n7\t1\n8\t\\N\n\\.\n",
+	} {
+		if !strings.Contains(string(sql), want) {
+			t.Errorf("SQL does not contain %q:\n%s", want, sql)
+		}
+	}
+
+	schema, err := ReadSchema(bytes.NewReader(sql))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Tables) != 2 || len(schema.ForeignKeys) != 1 {
+		t.Errorf("schema = %+v, want 2 tables and a foreign key", schema)
+	}
+
+	// Missing data files surface when reading
+	os.Remove(filepath.Join(dir, "21.dat"))
+	r, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := io.ReadAll(r); err == nil || !strings.Contains(err.Error(), "21.dat") {
+		t.Errorf("reading without a data file = %v", err)
+	}
+
+	if _, err := Open(t.TempDir()); err == nil || !strings.Contains(err.Error(), "has no toc.dat") {
+		t.Errorf("Open(empty directory) = %v", err)
+	}
+}
@@ -0,0 +1,380 @@
+// Package pgdump reads and rewrites plain-format pg_dump output (pg_dump
+// -Fp, the SQL script) without a database.
+//
+// Table data is dumped as COPY ... FROM stdin blocks in PostgreSQL's text
+// format; Rewrite hands their rows to a function that may change them and
+// copies everything else through unchanged:
+//
+//	err := pgdump.Rewrite(in, out, func(t pgdump.Table) pgdump.RowFunc {
+//		if t.Name != "users" {
+//			return nil
+//		}
+//		return func(row []pgdump.Value) error { ... }
+//	})
+package pgdump
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Table is a table whose data is in a dump, with its columns in the order
+// of the values in each row.
+type Table struct {
+	Schema  string
+	Name    string
+	Columns []string
+}
+
+// String returns the qualified table name, e.g. public.users.
+func (t Table) String() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// Value is one column of a row. Text is the decoded value; it is empty
+// when Null is set.
+type Value struct {
+	Text string
+	Null bool
+}
+
+// RowFunc changes the values of a row in place. It must not change the
+// number of values. Returning SkipRow leaves the row out.
+type RowFunc func(row []Value) error
+
+// SkipRow is returned by a RowFunc to leave a row out of the rewritten
+// dump. It is not returned as an error by any function.
+var SkipRow = errors.New("skip this row")
+
+// ErrNotPlain is returned for archives pg_restore reads, which have to be
+// converted to SQL first (pg_restore -f dump.sql archive.dump).
+var ErrNotPlain = errors.New("not a plain-format dump: custom and directory archives must be converted with pg_restore -f first")
+
+// Rewrite copies the dump in r to w. For every table, rows is asked for a
+// RowFunc; the rows of tables it returns one for are passed through it.
+//
+// Data dumped with INSERT statements (pg_dump --inserts) cannot be
+// rewritten: Rewrite fails when rows returns a RowFunc for such a table.
+func Rewrite(r io.Reader, w io.Writer, rows func(Table) RowFunc) error {
+	br := bufio.NewReaderSize(r, 64<<10)
+	bw := bufio.NewWriterSize(w, 64<<10)
+
+	if magic, _ := br.Peek(5); string(magic) == "PGDMP" {
+		return ErrNotPlain
+	}
+
+	var (
+		lineNo int
+		fn     RowFunc
+		table  Table
+		inCopy bool
+		values []Value
+	)
+	for {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			if err != io.EOF {
+				return err
+			}
+			break
+		}
+		lineNo++
+
+		switch {
+		case inCopy && strings.TrimRight(line, "\r\n") == `\.`:
+			inCopy = false
+		case inCopy && fn != nil:
+			body, eol := splitEOL(line)
+			values = DecodeRow(body, values[:0])
+			if len(values) != len(table.Columns) {
+				return fmt.Errorf("line %d: row of %s has %d values, want %d", lineNo, table, len(values), len(table.Columns))
+			}
+			switch err := fn(values); {
+			case err == SkipRow:
+				line = ""
+			case err != nil:
+				return fmt.Errorf("line %d: %s: %w", lineNo, table, err)
+			case len(values) != len(table.Columns):
+				return fmt.Errorf("line %d: %s: the number of values was changed", lineNo, table)
+			default:
+				line = EncodeRow(values) + eol
+			}
+		case inCopy:
+		default:
+			if t, ok := ParseCopy(line); ok {
+				table, fn, inCopy = t, rows(t), true
+			} else if t, ok := parseInsert(line); ok && rows(t) != nil {
+				return fmt.Errorf("line %d: the data of %s is dumped as INSERT statements, which cannot be rewritten; dump it without --inserts", lineNo, t)
+			}
+		}
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if inCopy {
+		return fmt.Errorf("line %d: the dump ends inside the data of %s", lineNo, table)
+	}
+	return bw.Flush()
+}
+
+// Tables returns the tables whose data is in the dump in r, in order.
+func Tables(r io.Reader) ([]Table, error) {
+	var tables []Table
+	err := Rewrite(r, io.Discard, func(t Table) RowFunc {
+		if t.Columns != nil {
+			tables = append(tables, t)
+		}
+		return nil
+	})
+	return tables, err
+}
+
+func splitEOL(line string) (string, string) {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2], "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
+
+// ParseCopy parses the COPY statement pg_dump starts a table's data with,
+// e.g. COPY public.users (id, email) FROM stdin;
+func ParseCopy(line string) (Table, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "COPY ")
+	if !ok || !strings.HasSuffix(rest, " FROM stdin;") {
+		return Table{}, false
+	}
+	rest = strings.TrimSuffix(rest, " FROM stdin;")
+
+	t, rest, ok := parseTableName(rest)
+	if !ok {
+		return Table{}, false
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		// COPY without a column list has the table's columns, which only
+		// CREATE TABLE knows; pg_dump always writes the list
+		return Table{}, false
+	}
+	t.Columns, rest, ok = parseIdentList(rest)
+	if !ok || rest != "" {
+		return Table{}, false
+	}
+	return t, true
+}
+
+// parseIdentList parses a parenthesized, comma-separated list of
+// identifiers at the start of s.
+func parseIdentList(s string) ([]string, string, bool) {
+	rest, ok := strings.CutPrefix(s, "(")
+	if !ok {
+		return nil, s, false
+	}
+	idents := []string{}
+	for {
+		ident, after, ok := parseIdent(strings.TrimLeft(rest, " "))
+		if !ok {
+			return nil, s, false
+		}
+		idents = append(idents, ident)
+		after = strings.TrimLeft(after, " ")
+		switch {
+		case strings.HasPrefix(after, ","):
+			rest = after[1:]
+		case strings.HasPrefix(after, ")"):
+			return idents, after[1:], true
+		default:
+			return nil, s, false
+		}
+	}
+}
+
+// parseInsert returns the table of an INSERT statement as pg_dump --inserts
+// writes them. The columns are left out.
+func parseInsert(line string) (Table, bool) {
+	rest, ok := strings.CutPrefix(line, "INSERT INTO ")
+	if !ok {
+		return Table{}, false
+	}
+	t, _, ok := parseTableName(rest)
+	return t, ok
+}
+
+// parseTableName parses a possibly schema-qualified, possibly quoted
+// table name at the start of s.
+func parseTableName(s string) (Table, string, bool) {
+	first, rest, ok := parseIdent(s)
+	if !ok {
+		return Table{}, s, false
+	}
+	if !strings.HasPrefix(rest, ".") {
+		return Table{Name: first}, rest, true
+	}
+	second, rest, ok := parseIdent(rest[1:])
+	if !ok {
+		return Table{}, s, false
+	}
+	return Table{Schema: first, Name: second}, rest, true
+}
+
+// parseIdent parses an SQL identifier at the start of s, unquoting it if
+// needed, and returns it with what follows it.
+func parseIdent(s string) (string, string, bool) {
+	if strings.HasPrefix(s, `"`) {
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '"' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '"' {
+				b.WriteByte('"')
+				i++
+				continue
+			}
+			return b.String(), s[i+1:], true
+		}
+		return "", s, false
+	}
+	end := strings.IndexAny(s, " .,()\t;")
+	if end == 0 {
+		return "", s, false
+	}
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:], true
+}
+
+// DecodeRow splits a row of COPY text format into its values, appending
+// them to values.
+func DecodeRow(line string, values []Value) []Value {
+	for _, field := range strings.Split(line, "\t") {
+		if field == `\N` {
+			values = append(values, Value{Null: true})
+			continue
+		}
+		values = append(values, Value{Text: unescape(field)})
+	}
+	return values
+}
+
+// EncodeRow renders values as a row of COPY text format, without the
+// line ending.
+func EncodeRow(values []Value) string {
+	var b strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte('\t')
+		}
+		if v.Null {
+			b.WriteString(`\N`)
+			continue
+		}
+		escape(&b, v.Text)
+	}
+	return b.String()
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := 0
+			for j := 0; j < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7'; j++ {
+				n = n*8 + int(s[i]-'0')
+				i++
+			}
+			i--
+			b.WriteByte(byte(n))
+		case 'x':
+			n, digits := 0, 0
+			for digits < 2 && i+1 < len(s) && isHex(s[i+1]) {
+				i++
+				n = n*16 + hexValue(s[i])
+				digits++
+			}
+			if digits == 0 {
+				b.WriteByte('x')
+			} else {
+				b.WriteByte(byte(n))
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// escape writes s the way COPY TO does: backslashes and the characters
+// that would end the field or the row are escaped.
+func escape(b *strings.Builder, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\v':
+			b.WriteString(`\v`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	}
+	return int(c-'A') + 10
+}
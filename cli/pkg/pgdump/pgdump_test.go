@@ -0,0 +1,172 @@
+package pgdump
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleDump = `--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+
+CREATE TABLE public.users (
+    id integer NOT NULL,
+    email text,
+    notes text
+);
+
+COPY public.users (id, email, notes) FROM stdin;
+1	alice@example.org	likes\ttabs\\and backslashes
+2	bob@example.org	\N
+\.
+
+COPY "Sales"."Order Lines" (id, "user", "Unit ""Price""") FROM stdin;
+10	1	9.99
+\.
+
+INSERT INTO public.audit VALUES (1, 'created');
+
+--
+-- PostgreSQL database dump complete
+--
+`
+
+func TestRewrite(t *testing.T) {
+	var out strings.Builder
+	err := Rewrite(strings.NewReader(sampleDump), &out, func(table Table) RowFunc {
+		if table.String() != "public.users" {
+			return nil
+		}
+		return func(row []Value) error {
+			row[1] = Value{Text: strings.ToUpper(row[1].Text)}
+			if !row[2].Null {
+				row[2].Text += "\nmore"
+			}
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.NewReplacer(
+		"1\talice@example.org\tlikes\\ttabs\\\\and backslashes\n", "1\tALICE@EXAMPLE.ORG\tlikes\\ttabs\\\\and backslashes\\nmore\n",
+		"2\tbob@example.org\t\\N\n", "2\tBOB@EXAMPLE.ORG\t\\N\n",
+	).Replace(sampleDump)
+	if out.String() != want {
+		t.Errorf("Rewrite() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRewriteUnchanged(t *testing.T) {
+	var out strings.Builder
+	identity := func(Table) RowFunc { return func([]Value) error { return nil } }
+	dump := strings.ReplaceAll(sampleDump, "INSERT INTO public.audit", "-- INSERT INTO public.audit")
+	if err := Rewrite(strings.NewReader(dump), &out, identity); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != dump {
+		t.Errorf("Rewrite() changed the dump:\n%s", out.String())
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	all := func(Table) RowFunc { return func([]Value) error { return nil } }
+	tests := []struct {
+		name string
+		dump string
+		want string
+	}{
+		{name: "inserts", dump: sampleDump, want: "public.audit is dumped as INSERT statements"},
+		{name: "truncated", dump: "COPY users (id) FROM stdin;\n1\n", want: "ends inside the data of users"},
+		{name: "short row", dump: "COPY users (id, email) FROM stdin;\n1\n\\.\n", want: "line 2: row of users has 1 values, want 2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Rewrite(strings.NewReader(tc.dump), &strings.Builder{}, all)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Rewrite() = %v, want an error containing %q", err, tc.want)
+			}
+		})
+	}
+
+	if err := Rewrite(strings.NewReader("PGDMP\x01\x0e"), &strings.Builder{}, all); !errors.Is(err, ErrNotPlain) {
+		t.Errorf("Rewrite(custom archive) = %v, want ErrNotPlain", err)
+	}
+}
+
+func TestTables(t *testing.T) {
+	tables, err := Tables(strings.NewReader(sampleDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Table{
+		{Schema: "public", Name: "users", Columns: []string{"id", "email", "notes"}},
+		{Schema: "Sales", Name: "Order Lines", Columns: []string{"id", "user", `Unit "Price"`}},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("Tables() = %+v, want %+v", tables, want)
+	}
+}
+
+func TestDecodeRow(t *testing.T) {
+	row := DecodeRow(`a\tb	\N	\101\x42\7	\\N	`, nil)
+	want := []Value{{Text: "a\tb"}, {Null: true}, {Text: "AB\a"}, {Text: `\N`}, {Text: ""}}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("DecodeRow() = %+v, want %+v", row, want)
+	}
+	if got := EncodeRow(want); got != "a\\tb\t\\N\tAB\a\t\\\\N\t" {
+		t.Errorf("EncodeRow() = %q", got)
+	}
+}
+
+func TestRewriteSkipRow(t *testing.T) {
+	var out strings.Builder
+	err := Rewrite(strings.NewReader(sampleDump), &out, func(table Table) RowFunc {
+		if table.Name != "users" {
+			return nil
+		}
+		return func(row []Value) error {
+			if row[0].Text == "1" {
+				return SkipRow
+			}
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "alice") || !strings.Contains(out.String(), "\n2\tbob@example.org\t\\N\n\\.\n") {
+		t.Errorf("Rewrite() =\n%s", out.String())
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	dump := sampleDump + `
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY "Sales"."Order Lines"
+    ADD CONSTRAINT "Order Lines_user_fkey" FOREIGN KEY ("user", id) REFERENCES public.users(id, email) ON DELETE CASCADE;
+`
+	schema, err := ReadSchema(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Tables) != 2 {
+		t.Errorf("tables = %+v", schema.Tables)
+	}
+	want := []ForeignKey{{
+		Name:       "Order Lines_user_fkey",
+		Table:      Table{Schema: "Sales", Name: "Order Lines"},
+		Columns:    []string{"user", "id"},
+		RefTable:   Table{Schema: "public", Name: "users"},
+		RefColumns: []string{"id", "email"},
+	}}
+	if !reflect.DeepEqual(schema.ForeignKeys, want) {
+		t.Errorf("foreign keys = %+v, want %+v", schema.ForeignKeys, want)
+	}
+}
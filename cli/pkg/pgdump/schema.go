@@ -0,0 +1,133 @@
+package pgdump
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ForeignKey is a foreign key constraint from a dump's schema: the values
+// of Columns in Table match those of RefColumns in RefTable.
+type ForeignKey struct {
+	Name       string
+	Table      Table
+	Columns    []string
+	RefTable   Table
+	RefColumns []string
+}
+
+// Schema is what a dump says about the shape of its data.
+type Schema struct {
+	// Tables are the tables with data in the dump, as in Tables.
+	Tables []Table
+	// ForeignKeys are the foreign key constraints between tables.
+	ForeignKeys []ForeignKey
+}
+
+// ReadSchema reads the tables and foreign keys of the dump in r.
+func ReadSchema(r io.Reader) (*Schema, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	if magic, _ := br.Peek(5); string(magic) == "PGDMP" {
+		return nil, ErrNotPlain
+	}
+
+	schema := &Schema{}
+	var (
+		inCopy    bool
+		statement strings.Builder
+	)
+	for {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+			break
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case inCopy:
+			inCopy = trimmed != `\.`
+		case statement.Len() > 0 || strings.HasPrefix(trimmed, "ALTER TABLE "):
+			// pg_dump writes constraints as ALTER TABLE statements over
+			// two lines; they end at the first line ending in a semicolon
+			statement.WriteString(trimmed)
+			statement.WriteByte(' ')
+			if strings.HasSuffix(trimmed, ";") {
+				if fk, ok := ParseForeignKey(statement.String()); ok {
+					schema.ForeignKeys = append(schema.ForeignKeys, fk)
+				}
+				statement.Reset()
+			}
+		default:
+			if t, ok := ParseCopy(line); ok {
+				schema.Tables = append(schema.Tables, t)
+				inCopy = true
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return schema, nil
+}
+
+// ParseForeignKey parses the statement pg_dump adds a foreign key with,
+// e.g.
+//
+//	ALTER TABLE ONLY public.orders
+//	    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);
+func ParseForeignKey(statement string) (ForeignKey, bool) {
+	var fk ForeignKey
+	s := strings.Join(strings.Fields(statement), " ")
+
+	s, ok := cutKeywords(s, "ALTER", "TABLE")
+	if !ok {
+		return fk, false
+	}
+	if rest, ok := cutKeywords(s, "ONLY"); ok {
+		s = rest
+	}
+	if fk.Table, s, ok = parseTableName(s); !ok {
+		return fk, false
+	}
+	if s, ok = cutKeywords(s, "ADD", "CONSTRAINT"); !ok {
+		return fk, false
+	}
+	if fk.Name, s, ok = parseIdent(s); !ok {
+		return fk, false
+	}
+	if s, ok = cutKeywords(s, "FOREIGN", "KEY"); !ok {
+		return fk, false
+	}
+	if fk.Columns, s, ok = parseIdentList(s); !ok {
+		return fk, false
+	}
+	if s, ok = cutKeywords(s, "REFERENCES"); !ok {
+		return fk, false
+	}
+	if fk.RefTable, s, ok = parseTableName(s); !ok {
+		return fk, false
+	}
+	if fk.RefColumns, _, ok = parseIdentList(strings.TrimLeft(s, " ")); !ok || len(fk.RefColumns) != len(fk.Columns) {
+		return fk, false
+	}
+	return fk, true
+}
+
+// cutKeywords removes the keywords, in any case and separated by spaces,
+// from the start of s.
+func cutKeywords(s string, keywords ...string) (string, bool) {
+	for _, kw := range keywords {
+		s = strings.TrimLeft(s, " ")
+		if len(s) < len(kw) || !strings.EqualFold(s[:len(kw)], kw) {
+			return s, false
+		}
+		if len(s) > len(kw) && s[len(kw)] != ' ' {
+			return s, false
+		}
+		s = s[len(kw):]
+	}
+	return strings.TrimLeft(s, " "), true
+}
@@ -0,0 +1,263 @@
+// Package s3local is a minimal S3-compatible object store for local API
+// servers and tests. It keeps the objects of a single bucket in memory or
+// in a directory, and accepts uploads through presigned PUT URLs the way
+// S3 does, so clients can be exercised without AWS.
+//
+// Only presigned uploads are served over HTTP. The signature is an HMAC
+// with a key that lives as long as the Store; its query parameters are
+// named after SigV4's but it is not SigV4.
+package s3local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoSuchKey is returned by Object for keys that were never uploaded.
+var ErrNoSuchKey = errors.New("s3local: no such key")
+
+// Store serves one bucket. Its zero value is not usable; call New.
+type Store struct {
+	// BaseURL is where the Store's handler is reachable by clients, e.g.
+	// http://localhost:5000/s3. Presigned URLs are relative to it, so it
+	// may be set after New, as long as it is before the first presign.
+	BaseURL string
+
+	bucket string
+	dir    string
+	key    []byte
+	now    func() time.Time
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// New returns a Store for bucket. Objects are kept in dir, below a
+// directory named after the bucket, or in memory when dir is empty.
+func New(bucket, dir string) *Store {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("s3local: generating the signing key: %v", err))
+	}
+	return &Store{bucket: bucket, dir: dir, key: key, now: time.Now, objects: map[string][]byte{}}
+}
+
+// Bucket returns the name of the bucket served by s.
+func (s *Store) Bucket() string { return s.bucket }
+
+// PresignPut returns a URL that accepts one PUT of exactly size bytes to
+// key until expires has passed, and the object's s3:// location.
+func (s *Store) PresignPut(ctx context.Context, key string, size int64, expires time.Duration) (string, string, error) {
+	if !validKey(key) {
+		return "", "", fmt.Errorf("s3local: invalid key %q", key)
+	}
+	if s.BaseURL == "" {
+		return "", "", errors.New("s3local: BaseURL is not set")
+	}
+	date := s.now().UTC().Format("20060102T150405Z")
+	seconds := strconv.Itoa(int(expires / time.Second))
+	q := url.Values{}
+	q.Set("X-Amz-Date", date)
+	q.Set("X-Amz-Expires", seconds)
+	q.Set("X-Amz-Content-Length", strconv.FormatInt(size, 10))
+	q.Set("X-Amz-Signature", s.sign(http.MethodPut, key, date, seconds, size))
+
+	u := strings.TrimSuffix(s.BaseURL, "/") + "/" + s.bucket + "/" + escapeKey(key) + "?" + q.Encode()
+	return u, "s3://" + s.bucket + "/" + key, nil
+}
+
+// Object returns the content of an uploaded object.
+func (s *Store) Object(key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrNoSuchKey
+	}
+	if s.dir != "" {
+		data, err := os.ReadFile(s.path(key))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoSuchKey
+		}
+		return data, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	return data, nil
+}
+
+// ObjectAt is Object for an s3://bucket/key location.
+func (s *Store) ObjectAt(location string) ([]byte, error) {
+	key, ok := strings.CutPrefix(location, "s3://"+s.bucket+"/")
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	return s.Object(key)
+}
+
+// ServeHTTP handles presigned uploads to /<bucket>/<key>. Mount it with
+// http.StripPrefix when BaseURL has a path.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "Only presigned PUT requests are supported")
+		return
+	}
+	if !validKey(key) {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", "Invalid object key")
+		return
+	}
+
+	q := r.URL.Query()
+	date, seconds := q.Get("X-Amz-Date"), q.Get("X-Amz-Expires")
+	size, err := strconv.ParseInt(q.Get("X-Amz-Content-Length"), 10, 64)
+	if err != nil || !hmac.Equal([]byte(q.Get("X-Amz-Signature")), []byte(s.sign(http.MethodPut, key, date, seconds, size))) {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided")
+		return
+	}
+	signedAt, err := time.Parse("20060102T150405Z", date)
+	expires, err2 := strconv.Atoi(seconds)
+	if err != nil || err2 != nil || s.now().After(signedAt.Add(time.Duration(expires)*time.Second)) {
+		writeError(w, http.StatusForbidden, "AccessDenied", "Request has expired")
+		return
+	}
+	if r.ContentLength != size {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch", fmt.Sprintf("Content-Length %d does not match the signed length %d", r.ContentLength, size))
+		return
+	}
+
+	sum := md5.New()
+	body := &bodyReader{r: io.TeeReader(io.LimitReader(r.Body, size+1), sum)}
+	err = s.put(key, body, size)
+	switch {
+	case body.err != nil:
+		writeError(w, http.StatusBadRequest, "IncompleteBody", body.err.Error())
+		return
+	case errors.Is(err, errIncompleteBody):
+		writeError(w, http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum.Sum(nil))+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// errIncompleteBody is returned by put when the body is not of the signed
+// length.
+var errIncompleteBody = errors.New("s3local: body does not have the signed length")
+
+// bodyReader keeps the first error reading a request body, to tell it
+// apart from errors writing the object.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// put stores the object read from r, which must be size bytes long.
+// Objects in a directory are streamed to disk, never held in memory.
+func (s *Store) put(key string, r io.Reader, size int64) error {
+	if s.dir == "" {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) != size {
+			return errIncompleteBody
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.objects[key] = data
+		return nil
+	}
+	p := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	// Write next to the object and rename, so a failed upload never
+	// leaves a truncated object behind
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(tmp, r)
+	if err == nil && n != size {
+		err = errIncompleteBody
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, s.bucket, filepath.FromSlash(key))
+}
+
+func (s *Store) sign(method, key, date, expires string, size int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s\n%d", method, s.bucket, key, date, expires, size)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validKey rejects keys that would escape the bucket's directory.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && !strings.HasPrefix(key, "../") && key != ".."
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// writeError answers like S3 does, with an XML error document.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: message})
+}
@@ -0,0 +1,115 @@
+package s3local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func put(t *testing.T, url string, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestStore(t *testing.T) {
+	for _, dir := range []string{"", t.TempDir()} {
+		store := New("backups", dir)
+		ts := httptest.NewServer(http.StripPrefix("/s3", store))
+		defer ts.Close()
+		store.BaseURL = ts.URL + "/s3"
+		ctx := context.Background()
+
+		data := []byte("PGDMP backup")
+		url, location, err := store.PresignPut(ctx, "uploads/1/billing dump", int64(len(data)), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if location != "s3://backups/uploads/1/billing dump" {
+			t.Errorf("location = %q", location)
+		}
+
+		if resp := put(t, url, data[:5]); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("short upload: status %d, want 403", resp.StatusCode)
+		}
+		if resp := put(t, strings.Replace(url, "billing", "other", 1), data); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("upload to another key: status %d, want 403", resp.StatusCode)
+		}
+		if _, err := store.ObjectAt(location); err != ErrNoSuchKey {
+			t.Errorf("object before upload: %v, want ErrNoSuchKey", err)
+		}
+
+		resp := put(t, url, data)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+			t.Fatalf("upload: status %d, ETag %q", resp.StatusCode, resp.Header.Get("ETag"))
+		}
+		got, err := store.ObjectAt(location)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("object = %q, %v, want %q", got, err, data)
+		}
+
+		store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+		expired, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(expired.Body)
+		expired.Body.Close()
+		if expired.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "<Code>AccessDenied</Code>") {
+			t.Errorf("expired upload: status %d, body %s", expired.StatusCode, body)
+		}
+	}
+}
+
+func TestStoreRejectsEscapingKeys(t *testing.T) {
+	store := New("backups", t.TempDir())
+	store.BaseURL = "http://localhost/s3"
+	for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", `a\b`} {
+		if _, _, err := store.PresignPut(context.Background(), key, 1, time.Minute); err == nil {
+			t.Errorf("PresignPut accepted key %q", key)
+		}
+	}
+}
+
+func TestStoreIncompleteUpload(t *testing.T) {
+	dir := t.TempDir()
+	store := New("backups", dir)
+	store.BaseURL = "http://localhost/s3"
+	data := []byte("PGDMP backup")
+	u, location, err := store.PresignPut(context.Background(), "uploads/1/billing.dump", int64(len(data)), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The body ends before the signed length it claims to have
+	req := httptest.NewRequest(http.MethodPut, strings.TrimPrefix(u, store.BaseURL), bytes.NewReader(data[:5]))
+	req.ContentLength = int64(len(data))
+	rec := httptest.NewRecorder()
+	store.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "<Code>IncompleteBody</Code>") {
+		t.Errorf("status %d, body %s", rec.Code, rec.Body)
+	}
+	if _, err := store.ObjectAt(location); err != ErrNoSuchKey {
+		t.Errorf("object after a failed upload: %v, want ErrNoSuchKey", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "backups", "uploads", "1"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("files left behind: %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	projectsBucket  = []byte("projects")
+	databasesBucket = []byte("databases")
+	snapshotsBucket = []byte("snapshots")
+)
+
+// BoltStore is a Store kept in a BoltDB file, so a server keeps its
+// projects, databases and snapshots across restarts.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens or creates the BoltDB file at path. The file is
+// locked while the store is open.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{projectsBucket, databasesBucket, snapshotsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Databases and snapshots are keyed by project ID and name, separated by a
+// NUL byte so a project's databases can be found with a prefix scan.
+func databaseKey(projectID, name string) []byte {
+	return []byte(projectID + "\x00" + name)
+}
+
+func (s *BoltStore) put(bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, data)
+	})
+}
+
+func (s *BoltStore) get(bucket, key []byte, v interface{}) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, v)
+	})
+}
+
+func (s *BoltStore) delete(bucket, key []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b.Get(key) == nil {
+			return ErrNotFound
+		}
+		return b.Delete(key)
+	})
+}
+
+func (s *BoltStore) CreateProject(ctx context.Context, project api.Project) error {
+	return s.put(projectsBucket, []byte(project.Id), project)
+}
+
+func (s *BoltStore) GetProject(ctx context.Context, id string) (api.Project, error) {
+	var project api.Project
+	err := s.get(projectsBucket, []byte(id), &project)
+	return project, err
+}
+
+func (s *BoltStore) ListProjects(ctx context.Context) ([]api.Project, error) {
+	var projects []api.Project
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(projectsBucket).ForEach(func(k, v []byte) error {
+			var project api.Project
+			if err := json.Unmarshal(v, &project); err != nil {
+				return err
+			}
+			projects = append(projects, project)
+			return nil
+		})
+	})
+	sortProjects(projects)
+	return projects, err
+}
+
+func (s *BoltStore) UpdateProject(ctx context.Context, project api.Project) error {
+	data, err := json.Marshal(project)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		projects := tx.Bucket(projectsBucket)
+		if projects.Get([]byte(project.Id)) == nil {
+			return ErrNotFound
+		}
+		return projects.Put([]byte(project.Id), data)
+	})
+}
+
+func (s *BoltStore) DeleteProject(ctx context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		projects := tx.Bucket(projectsBucket)
+		if projects.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		if err := projects.Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		prefix := databaseKey(id, "")
+		for _, bucket := range [][]byte{databasesBucket, snapshotsBucket} {
+			c := tx.Bucket(bucket).Cursor()
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) PutDatabase(ctx context.Context, db api.Database) error {
+	return s.put(databasesBucket, databaseKey(projectOf(db), db.Name), db)
+}
+
+func (s *BoltStore) GetDatabase(ctx context.Context, projectID, name string) (api.Database, error) {
+	var db api.Database
+	err := s.get(databasesBucket, databaseKey(projectID, name), &db)
+	return db, err
+}
+
+func (s *BoltStore) ListDatabases(ctx context.Context, projectID string) ([]api.Database, error) {
+	databases := []api.Database{}
+	prefix := databaseKey(projectID, "")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(databasesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var db api.Database
+			if err := json.Unmarshal(v, &db); err != nil {
+				return err
+			}
+			databases = append(databases, db)
+		}
+		return nil
+	})
+	sortDatabases(databases)
+	return databases, err
+}
+
+func (s *BoltStore) DeleteDatabase(ctx context.Context, projectID, name string) error {
+	return s.delete(databasesBucket, databaseKey(projectID, name))
+}
+
+func (s *BoltStore) PutSnapshot(ctx context.Context, snap api.Snapshot) error {
+	return s.put(snapshotsBucket, databaseKey(snapshotProjectOf(snap), snap.Name), snap)
+}
+
+func (s *BoltStore) GetSnapshot(ctx context.Context, projectID, name string) (api.Snapshot, error) {
+	var snap api.Snapshot
+	err := s.get(snapshotsBucket, databaseKey(projectID, name), &snap)
+	return snap, err
+}
+
+func (s *BoltStore) ListSnapshots(ctx context.Context, projectID string) ([]api.Snapshot, error) {
+	snapshots := []api.Snapshot{}
+	prefix := databaseKey(projectID, "")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(snapshotsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var snap api.Snapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snap)
+		}
+		return nil
+	})
+	sortSnapshots(snapshots)
+	return snapshots, err
+}
+
+func (s *BoltStore) DeleteSnapshot(ctx context.Context, projectID, name string) error {
+	return s.delete(snapshotsBucket, databaseKey(projectID, name))
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,733 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/backup"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+	"github.com/meido-ai/devdb/cli/pkg/s3local"
+)
+
+// LocalProvisioner runs each database as a postgres process on this
+// machine, using the PostgreSQL server binaries installed locally. Every
+// database gets its own data directory under Dir and a free TCP port on
+// Host. Databases whose data directory survives a server restart are
+// started again when they are next looked at. Snapshots are copies of a
+// data directory, taken while the database is briefly stopped.
+//
+// Only projects of the installed PostgreSQL version can be run. New and
+// reset databases of a project with a backup restore it from Backups, with
+// psql or pg_restore.
+type LocalProvisioner struct {
+	// Dir holds a directory per database.
+	Dir string
+
+	// BinDir is the directory with initdb, postgres, createdb, psql and
+	// pg_restore. They are looked up on PATH when it is empty.
+	BinDir string
+
+	// Backups holds the backups projects are restored from. Databases of
+	// projects with a backup cannot be created without it.
+	Backups BackupStore
+
+	// Host is the address postgres listens on; 127.0.0.1 when empty.
+	Host string
+
+	// StartTimeout bounds how long a database may take to accept
+	// connections; 30 seconds when zero.
+	StartTimeout time.Duration
+
+	mu        sync.Mutex
+	instances map[string]*localInstance
+
+	versionOnce sync.Once
+	version     string
+	versionErr  error
+}
+
+// BackupStore returns backups by their s3:// location, like the store of
+// the backups uploaded to devdb serve.
+type BackupStore interface {
+	ObjectAt(location string) ([]byte, error)
+}
+
+// postgresVersionPattern finds the release in the output of
+// postgres --version, e.g. "postgres (PostgreSQL) 16.4".
+var postgresVersionPattern = regexp.MustCompile(`\(PostgreSQL\) ([0-9]+)(\.[0-9]+)?`)
+
+// Version returns the release series of the installed PostgreSQL, e.g. 16,
+// as engine versions name it.
+func (p *LocalProvisioner) Version() (string, error) {
+	p.versionOnce.Do(func() {
+		out, err := exec.Command(p.binary("postgres"), "--version").Output()
+		if err != nil {
+			p.versionErr = fmt.Errorf("running postgres --version: %v", err)
+			return
+		}
+		m := postgresVersionPattern.FindSubmatch(out)
+		if m == nil {
+			p.versionErr = fmt.Errorf("postgres --version printed %q, not a PostgreSQL version", bytes.TrimSpace(out))
+			return
+		}
+		p.version = string(m[1])
+	})
+	return p.version, p.versionErr
+}
+
+// localInstance is a running, starting or failed postgres process.
+type localInstance struct {
+	cmd      *exec.Cmd
+	port     int
+	status   api.DatabaseStatus
+	stopping bool
+	exited   chan struct{}
+}
+
+func (p *LocalProvisioner) host() string {
+	if p.Host == "" {
+		return "127.0.0.1"
+	}
+	return p.Host
+}
+
+func (p *LocalProvisioner) binary(name string) string {
+	if p.BinDir == "" {
+		return name
+	}
+	return filepath.Join(p.BinDir, name)
+}
+
+// dir returns the directory of a database. The data directory and the
+// server log live below it.
+func (p *LocalProvisioner) dir(projectID, name string) string {
+	return filepath.Join(p.Dir, projectID, name)
+}
+
+// snapshotDir returns the directory of a snapshot. Database names cannot
+// start with a dot, so it never clashes with a database directory.
+func (p *LocalProvisioner) snapshotDir(projectID, name string) string {
+	return filepath.Join(p.Dir, projectID, ".snapshots", name)
+}
+
+func (p *LocalProvisioner) Create(ctx context.Context, project api.Project, db *api.Database) error {
+	return p.create(project, db, "")
+}
+
+func (p *LocalProvisioner) Clone(ctx context.Context, project api.Project, db *api.Database, from api.Snapshot) error {
+	return p.create(project, db, filepath.Join(p.snapshotDir(project.Id, from.Name), "data"))
+}
+
+// Engines returns PostgreSQL, the only engine the local provisioner runs,
+// in the installed version. Every version is listed when postgres cannot
+// be run; databases then fail to start.
+func (p *LocalProvisioner) Engines() engine.Catalog {
+	e, _ := engine.Lookup(api.Postgres)
+	if version, err := p.Version(); err == nil {
+		e.Versions = []string{version}
+	}
+	return engine.Catalog{e}
+}
+
+// check returns an UnsupportedError for projects the local provisioner
+// cannot run.
+func (p *LocalProvisioner) check(project api.Project) error {
+	if project.DbType != "" && project.DbType != api.Postgres {
+		return &UnsupportedError{Reason: fmt.Sprintf("the local provisioner only runs postgres databases, not %s", project.DbType)}
+	}
+	if project.DbVersion == "" {
+		return nil
+	}
+	version, err := p.Version()
+	if err != nil {
+		return err
+	}
+	if installed := (engine.Engine{Versions: []string{version}}); !installed.Supports(project.DbVersion) {
+		return &UnsupportedError{Reason: fmt.Sprintf("project %s is on PostgreSQL %s, but the local provisioner runs the installed PostgreSQL %s", project.Name, project.DbVersion, version)}
+	}
+	return nil
+}
+
+// fetchBackup writes the project's backup, if it has one, to the
+// directory of a database, where run restores it from.
+func (p *LocalProvisioner) fetchBackup(project api.Project, dir string) error {
+	path := filepath.Join(dir, "backup")
+	if project.BackupLocation == "" {
+		// Don't restore what an earlier create or reset left behind
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if p.Backups == nil {
+		return &UnsupportedError{Reason: fmt.Sprintf("project %s has the backup %s, but this server has no backups to restore from", project.Name, project.BackupLocation)}
+	}
+	data, err := p.Backups.ObjectAt(project.BackupLocation)
+	if errors.Is(err, s3local.ErrNoSuchKey) {
+		return &UnsupportedError{Reason: fmt.Sprintf("backup %s of project %s is not one uploaded to this server", project.BackupLocation, project.Name)}
+	}
+	if err != nil {
+		return fmt.Errorf("reading backup %s: %v", project.BackupLocation, err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// create sets up the directory of a new database and starts it. The data
+// directory is copied from source if set, and initialized and restored
+// from the project's backup otherwise.
+func (p *LocalProvisioner) create(project api.Project, db *api.Database, source string) error {
+	if err := p.check(project); err != nil {
+		return err
+	}
+	dir := p.dir(project.Id, db.Name)
+
+	// Leftovers of a database that was not deleted cleanly
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if source != "" {
+		if _, err := copyDir(source, filepath.Join(dir, "data")); err != nil {
+			os.RemoveAll(dir)
+			return fmt.Errorf("copying snapshot: %v", err)
+		}
+	} else if err := p.fetchBackup(project, dir); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	port, err := freePort(p.host())
+	if err != nil {
+		return err
+	}
+
+	host := p.host()
+	db.Status = api.Creating
+	db.Host = &host
+	db.Port = &port
+	db.Username = &project.DefaultCredentials.Username
+	db.Database = &project.DefaultCredentials.Database
+
+	p.start(project, db.Name, port, source == "")
+	return nil
+}
+
+func (p *LocalProvisioner) Refresh(ctx context.Context, project api.Project, db *api.Database) error {
+	p.mu.Lock()
+	inst, ok := p.instances[project.Id+"/"+db.Name]
+	p.mu.Unlock()
+
+	if !ok {
+		// The server was restarted; bring the database back if its data
+		// is still there
+		if _, err := os.Stat(filepath.Join(p.dir(project.Id, db.Name), "data", "PG_VERSION")); err != nil {
+			db.Status = api.Error
+			return nil
+		}
+		port := 0
+		if db.Port != nil {
+			port = *db.Port
+		}
+		if port == 0 || !portFree(p.host(), port) {
+			var err error
+			if port, err = freePort(p.host()); err != nil {
+				return err
+			}
+		}
+		db.Port = &port
+		db.Status = api.Creating
+		p.start(project, db.Name, port, false)
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	db.Status = inst.status
+	db.Port = &inst.port
+	return nil
+}
+
+func (p *LocalProvisioner) Delete(ctx context.Context, project api.Project, db api.Database) error {
+	key := project.Id + "/" + db.Name
+	p.mu.Lock()
+	inst, ok := p.instances[key]
+	delete(p.instances, key)
+	p.mu.Unlock()
+
+	if ok {
+		p.stop(inst)
+	}
+	return os.RemoveAll(p.dir(project.Id, db.Name))
+}
+
+func (p *LocalProvisioner) Snapshot(ctx context.Context, project api.Project, db api.Database, snap *api.Snapshot) error {
+	// Copying the data directory of a running postgres would give an
+	// inconsistent copy, so stop it for the time of the copy
+	key := project.Id + "/" + db.Name
+	p.mu.Lock()
+	inst, ok := p.instances[key]
+	delete(p.instances, key)
+	p.mu.Unlock()
+	if ok {
+		p.stop(inst)
+		defer p.start(project, db.Name, inst.port, false)
+	}
+
+	dir := p.snapshotDir(project.Id, snap.Name)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	size, err := copyDir(filepath.Join(p.dir(project.Id, db.Name), "data"), filepath.Join(dir, "data"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("copying data directory: %v", err)
+	}
+	snap.Status = api.SnapshotReady
+	snap.SizeBytes = &size
+	return nil
+}
+
+func (p *LocalProvisioner) DeleteSnapshot(ctx context.Context, project api.Project, snap api.Snapshot) error {
+	return os.RemoveAll(p.snapshotDir(project.Id, snap.Name))
+}
+
+func (p *LocalProvisioner) Reset(ctx context.Context, project api.Project, db *api.Database) error {
+	// The backup is fetched first, so a failure leaves the database as it
+	// was
+	if err := p.check(project); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.dir(project.Id, db.Name), 0700); err != nil {
+		return err
+	}
+	if err := p.fetchBackup(project, p.dir(project.Id, db.Name)); err != nil {
+		return err
+	}
+
+	key := project.Id + "/" + db.Name
+	p.mu.Lock()
+	inst, ok := p.instances[key]
+	delete(p.instances, key)
+	p.mu.Unlock()
+	if ok {
+		p.stop(inst)
+	}
+
+	if err := os.RemoveAll(filepath.Join(p.dir(project.Id, db.Name), "data")); err != nil {
+		return err
+	}
+
+	// Keep the port so that clients can reconnect without looking it up
+	port := 0
+	if db.Port != nil {
+		port = *db.Port
+	}
+	if port == 0 || !portFree(p.host(), port) {
+		var err error
+		if port, err = freePort(p.host()); err != nil {
+			return err
+		}
+	}
+	db.Port = &port
+	db.Status = api.Creating
+	p.start(project, db.Name, port, true)
+	return nil
+}
+
+func (p *LocalProvisioner) Stop(ctx context.Context, project api.Project, db *api.Database) error {
+	key := project.Id + "/" + db.Name
+	p.mu.Lock()
+	inst, ok := p.instances[key]
+	delete(p.instances, key)
+	p.mu.Unlock()
+	if ok {
+		p.stop(inst)
+	}
+	db.Status = api.Stopped
+	return nil
+}
+
+func (p *LocalProvisioner) Start(ctx context.Context, project api.Project, db *api.Database) error {
+	// Refresh starts databases it doesn't know about, keeping the port
+	// when it is still free
+	return p.Refresh(ctx, project, db)
+}
+
+// Close stops all databases. Their data is kept, so they start again
+// when the server is restarted with the same store.
+func (p *LocalProvisioner) Close() error {
+	p.mu.Lock()
+	instances := p.instances
+	p.instances = nil
+	p.mu.Unlock()
+
+	for _, inst := range instances {
+		p.stop(inst)
+	}
+	return nil
+}
+
+// start brings up a database in the background, running initdb and
+// createdb first for a new one and restoring its backup.
+func (p *LocalProvisioner) start(project api.Project, name string, port int, initialize bool) {
+	key := project.Id + "/" + name
+	inst := &localInstance{port: port, status: api.Creating, exited: make(chan struct{})}
+	p.mu.Lock()
+	if p.instances == nil {
+		p.instances = map[string]*localInstance{}
+	}
+	if _, ok := p.instances[key]; ok && !initialize {
+		// Another request is already restarting it
+		p.mu.Unlock()
+		return
+	}
+	p.instances[key] = inst
+	p.mu.Unlock()
+
+	go func() {
+		err := p.run(project, name, inst, initialize)
+		if errors.Is(err, errStopped) {
+			return
+		}
+		if err != nil {
+			// Keep the reason next to the server log
+			appendLog(filepath.Join(p.dir(project.Id, name), "postgres.log"), "devdb: "+err.Error()+"\n")
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err != nil {
+			inst.status = api.Error
+			return
+		}
+		inst.status = api.Running
+	}()
+}
+
+// errStopped is returned by run when the database was stopped before
+// postgres was started.
+var errStopped = errors.New("database stopped while starting")
+
+func (p *LocalProvisioner) run(project api.Project, name string, inst *localInstance, initialize bool) error {
+	dir := p.dir(project.Id, name)
+	dataDir := filepath.Join(dir, "data")
+	creds := project.DefaultCredentials
+
+	if initialize {
+		pwfile := filepath.Join(dir, "pwfile")
+		if err := os.WriteFile(pwfile, []byte(creds.Password+"\n"), 0600); err != nil {
+			return err
+		}
+		err := runQuiet(exec.Command(p.binary("initdb"),
+			"-D", dataDir,
+			"-U", creds.Username,
+			"--pwfile", pwfile,
+			"-A", "scram-sha-256",
+			"-E", "UTF8"))
+		os.Remove(pwfile)
+		if err != nil {
+			return fmt.Errorf("initdb: %v", err)
+		}
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(dir, "postgres.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(p.binary("postgres"),
+		"-D", dataDir,
+		"-p", strconv.Itoa(inst.port),
+		"-c", "listen_addresses="+p.host(),
+		"-c", "unix_socket_directories=")
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+
+	// The database may have been stopped or deleted while initdb ran;
+	// postgres must not be started for it then, as nothing would stop it
+	p.mu.Lock()
+	stopping := inst.stopping
+	p.mu.Unlock()
+	if stopping {
+		logFile.Close()
+		return errStopped
+	}
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("starting postgres: %v", err)
+	}
+	p.mu.Lock()
+	stopping = inst.stopping
+	if !stopping {
+		inst.cmd = cmd
+	}
+	p.mu.Unlock()
+	if stopping {
+		cmd.Process.Kill()
+		cmd.Wait()
+		logFile.Close()
+		return errStopped
+	}
+	go func() {
+		cmd.Wait()
+		logFile.Close()
+		p.mu.Lock()
+		if !inst.stopping {
+			inst.status = api.Error
+		}
+		p.mu.Unlock()
+		close(inst.exited)
+	}()
+
+	if err := p.waitReady(inst); err != nil {
+		p.stop(inst)
+		return err
+	}
+
+	if initialize && creds.Database != "postgres" {
+		createdb := exec.Command(p.binary("createdb"),
+			"-h", p.host(),
+			"-p", strconv.Itoa(inst.port),
+			"-U", creds.Username,
+			"-O", creds.Username,
+			creds.Database)
+		createdb.Env = append(os.Environ(), "PGPASSWORD="+creds.Password)
+		if err := runQuiet(createdb); err != nil {
+			p.stop(inst)
+			return fmt.Errorf("createdb: %v", err)
+		}
+	}
+	if initialize {
+		if err := p.restore(dir, inst.port, creds); err != nil {
+			// Without its data, the database must not come back after a
+			// server restart
+			p.stop(inst)
+			os.RemoveAll(dataDir)
+			return fmt.Errorf("restoring backup: %v", err)
+		}
+	}
+	return nil
+}
+
+// restore restores the backup fetchBackup left in dir into the database
+// listening on port, and removes it. Backups are told apart like the
+// CLI does when it uploads them: plain SQL dumps are run with psql, custom
+// archives restored with pg_restore, and directory dumps, which the CLI
+// archives with tar, unpacked and restored with pg_restore.
+func (p *LocalProvisioner) restore(dir string, port int, creds api.DefaultDatabaseCredentials) error {
+	path := filepath.Join(dir, "backup")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	defer os.Remove(path)
+	format, err := backup.Detect(path)
+	if err != nil {
+		return err
+	}
+
+	connect := []string{"-h", p.host(), "-p", strconv.Itoa(port), "-U", creds.Username, "-d", creds.Database}
+	var cmd *exec.Cmd
+	switch format {
+	case api.BackupFormatPlain:
+		cmd = exec.Command(p.binary("psql"), append(connect, "-v", "ON_ERROR_STOP=1", "-q", "-f", path)...)
+	case api.BackupFormatCustom:
+		cmd = exec.Command(p.binary("pg_restore"), append(connect, "--no-owner", "--no-privileges", path)...)
+	case api.BackupFormatDirectory:
+		unpacked := filepath.Join(dir, "backup.d")
+		defer os.RemoveAll(unpacked)
+		if err := untar(path, unpacked); err != nil {
+			return fmt.Errorf("unpacking directory dump: %v", err)
+		}
+		cmd = exec.Command(p.binary("pg_restore"), append(connect, "--format=directory", "--no-owner", "--no-privileges", unpacked)...)
+	default:
+		return fmt.Errorf("cannot restore a %s backup into postgres", format)
+	}
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+creds.Password)
+	return runQuiet(cmd)
+}
+
+// untar unpacks the regular files of the tar archive at path into dir.
+func untar(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive member %s is outside the archive", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// waitReady waits until postgres accepts TCP connections.
+func (p *LocalProvisioner) waitReady(inst *localInstance) error {
+	timeout := p.StartTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	addr := net.JoinHostPort(p.host(), strconv.Itoa(inst.port))
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-inst.exited:
+			return errors.New("postgres exited during startup")
+		case <-time.After(100 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("postgres did not accept connections on %s within %s", addr, timeout)
+		}
+	}
+}
+
+// stop shuts postgres down with a fast shutdown, killing it if it does
+// not exit in time.
+func (p *LocalProvisioner) stop(inst *localInstance) {
+	p.mu.Lock()
+	cmd := inst.cmd
+	inst.stopping = true
+	p.mu.Unlock()
+	if cmd == nil {
+		return
+	}
+
+	cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-inst.exited:
+	case <-time.After(10 * time.Second):
+		cmd.Process.Kill()
+		<-inst.exited
+	}
+}
+
+// runQuiet runs cmd, returning its output as part of the error if it
+// fails.
+func runQuiet(cmd *exec.Cmd) error {
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(out.Bytes()); len(msg) > 0 {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// copyDir copies the directory tree src to dst, keeping permissions, and
+// returns the number of bytes copied.
+func copyDir(src, dst string) (int64, error) {
+	var size int64
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			// Sockets and the like are recreated by postgres
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(out, in)
+		size += n
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+	return size, err
+}
+
+func appendLog(path, line string) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(line)
+}
+
+func freePort(host string) (int, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func portFree(host string, port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
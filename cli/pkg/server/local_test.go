@@ -0,0 +1,446 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/s3local"
+)
+
+// The test binary doubles as fake initdb, postgres, createdb, psql and
+// pg_restore commands when it is run under one of those names.
+func TestMain(m *testing.M) {
+	switch filepath.Base(os.Args[0]) {
+	case "initdb":
+		fakeInitdb()
+	case "postgres":
+		fakePostgres()
+	case "createdb":
+		os.Exit(0)
+	case "psql", "pg_restore":
+		fakeRestore()
+	}
+	os.Exit(m.Run())
+}
+
+// restoreLogEnv names the file fake psql and pg_restore append to.
+const restoreLogEnv = "DEVDB_TEST_RESTORE_LOG"
+
+// fakeRestore logs how psql or pg_restore was called, followed by the
+// content of the file, or the names in the directory, it restores.
+func fakeRestore() {
+	var out bytes.Buffer
+	fmt.Fprintln(&out, filepath.Base(os.Args[0]), strings.Join(os.Args[1:], " "))
+	last := os.Args[len(os.Args)-1]
+	if entries, err := os.ReadDir(last); err == nil {
+		for _, e := range entries {
+			fmt.Fprintln(&out, e.Name())
+		}
+	} else if data, err := os.ReadFile(last); err == nil {
+		out.Write(data)
+	}
+	f, err := os.OpenFile(os.Getenv(restoreLogEnv), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	f.Write(out.Bytes())
+	f.Close()
+	os.Exit(0)
+}
+
+// initdbDelayEnv makes fake initdb take the given duration.
+const initdbDelayEnv = "DEVDB_TEST_INITDB_DELAY"
+
+func fakeInitdb() {
+	if d, err := time.ParseDuration(os.Getenv(initdbDelayEnv)); err == nil {
+		time.Sleep(d)
+	}
+	fs := flag.NewFlagSet("initdb", flag.ExitOnError)
+	dir := fs.String("D", "", "")
+	fs.String("U", "", "")
+	fs.String("pwfile", "", "")
+	fs.String("A", "", "")
+	fs.String("E", "", "")
+	fs.Parse(os.Args[1:])
+	if err := os.MkdirAll(*dir, 0700); err == nil {
+		err = os.WriteFile(filepath.Join(*dir, "PG_VERSION"), []byte("16\n"), 0600)
+	}
+	os.Exit(0)
+}
+
+func fakePostgres() {
+	if len(os.Args) == 2 && os.Args[1] == "--version" {
+		fmt.Println("postgres (PostgreSQL) 16.4")
+		os.Exit(0)
+	}
+	fs := flag.NewFlagSet("postgres", flag.ExitOnError)
+	fs.String("D", "", "")
+	port := fs.Int("p", 0, "")
+	fs.Var(new(stringsFlag), "c", "")
+	fs.Parse(os.Args[1:])
+
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", *port))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+	os.Exit(0)
+}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string     { return fmt.Sprint(*f) }
+func (f *stringsFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+// fakeBinDir returns a directory where initdb, postgres, createdb, psql
+// and pg_restore run the test binary.
+func fakeBinDir(t *testing.T) string {
+	t.Helper()
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"initdb", "postgres", "createdb", "psql", "pg_restore"} {
+		if err := os.Symlink(self, filepath.Join(dir, name)); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+	}
+	return dir
+}
+
+func waitForStatus(t *testing.T, p Provisioner, project api.Project, db *api.Database, want api.DatabaseStatus) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if err := p.Refresh(context.Background(), project, db); err != nil {
+			t.Fatal(err)
+		}
+		if db.Status == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("database status = %s, want %s", db.Status, want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestLocalProvisioner(t *testing.T) {
+	ctx := context.Background()
+	p := &LocalProvisioner{Dir: t.TempDir(), BinDir: fakeBinDir(t)}
+	defer p.Close()
+
+	project := api.Project{
+		Id: "p-1",
+		DefaultCredentials: api.DefaultDatabaseCredentials{
+			Username: "devdb", Password: "secret", Database: "devdb",
+		},
+	}
+	db := &api.Database{Name: "dev"}
+	if err := p.Create(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+	if db.Status != api.Creating || db.Host == nil || *db.Host != "127.0.0.1" || db.Port == nil {
+		t.Fatalf("created database = %+v, want creating on 127.0.0.1", db)
+	}
+	waitForStatus(t, p, project, db, api.Running)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", *db.Port))
+	if err != nil {
+		t.Fatalf("database does not accept connections: %v", err)
+	}
+	conn.Close()
+
+	// Snapshots copy the data directory, and clones start from the copy
+	marker := filepath.Join(p.Dir, "p-1", "dev", "data", "marker")
+	if err := os.WriteFile(marker, []byte("before migration"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	snap := api.Snapshot{Name: "s1", Database: "dev"}
+	if err := p.Snapshot(ctx, project, *db, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Status != api.SnapshotReady || snap.SizeBytes == nil || *snap.SizeBytes == 0 {
+		t.Errorf("snapshot = %+v, want ready with a size", snap)
+	}
+	waitForStatus(t, p, project, db, api.Running)
+
+	clone := &api.Database{Name: "feature-x"}
+	if err := p.Clone(ctx, project, clone, snap); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, p, project, clone, api.Running)
+	if data, err := os.ReadFile(filepath.Join(p.Dir, "p-1", "feature-x", "data", "marker")); err != nil || string(data) != "before migration" {
+		t.Errorf("clone data = %q, %v, want the snapshot's", data, err)
+	}
+	if err := p.DeleteSnapshot(ctx, project, snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(p.Dir, "p-1", ".snapshots", "s1")); !os.IsNotExist(err) {
+		t.Errorf("snapshot left after delete: %v", err)
+	}
+
+	// A reset database starts over from an empty data directory on the
+	// same port
+	port := *db.Port
+	if err := p.Reset(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, p, project, db, api.Running)
+	if *db.Port != port {
+		t.Errorf("reset database port = %d, want %d", *db.Port, port)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("data left after reset: %v", err)
+	}
+
+	// Stopped databases keep their data and come back on start
+	if err := os.WriteFile(marker, []byte("kept"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx, project, db); err != nil || db.Status != api.Stopped {
+		t.Fatalf("stop = %s, %v, want stopped", db.Status, err)
+	}
+	if conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", *db.Port)); err == nil {
+		conn.Close()
+		t.Error("stopped database still accepts connections")
+	}
+	if err := p.Start(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, p, project, db, api.Running)
+	if data, err := os.ReadFile(marker); err != nil || string(data) != "kept" {
+		t.Errorf("data after start = %q, %v, want kept", data, err)
+	}
+
+	// A new provisioner, as after a server restart, starts it again
+	p.Close()
+	restarted := &LocalProvisioner{Dir: p.Dir, BinDir: p.BinDir}
+	defer restarted.Close()
+	waitForStatus(t, restarted, project, db, api.Running)
+
+	if err := restarted.Delete(ctx, project, *db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(p.Dir, "p-1", "dev")); !os.IsNotExist(err) {
+		t.Errorf("data directory left after delete: %v", err)
+	}
+	if err := restarted.Refresh(ctx, project, db); err != nil || db.Status != api.Error {
+		t.Errorf("deleted database status = %s, %v, want error", db.Status, err)
+	}
+}
+
+func TestLocalProvisionerFailure(t *testing.T) {
+	// No binaries in the bin directory
+	p := &LocalProvisioner{Dir: t.TempDir(), BinDir: t.TempDir()}
+	defer p.Close()
+
+	project := api.Project{Id: "p-1"}
+	db := &api.Database{Name: "dev"}
+	if err := p.Create(context.Background(), project, db); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, p, project, db, api.Error)
+
+	log, err := os.ReadFile(filepath.Join(p.Dir, "p-1", "dev", "postgres.log"))
+	if err != nil || len(log) == 0 {
+		t.Errorf("expected the failure in postgres.log, got %q, %v", log, err)
+	}
+}
+
+func TestLocalProvisionerBackup(t *testing.T) {
+	ctx := context.Background()
+	restoreLog := filepath.Join(t.TempDir(), "restore.log")
+	t.Setenv(restoreLogEnv, restoreLog)
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, name := range []string{"toc.dat", "3001.dat.gz"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: 5})
+		tw.Write([]byte("PGDMP"))
+	}
+	tw.Close()
+	backups := fakeBackups{
+		"s3://devdb-backups/uploads/app.sql":  []byte("CREATE TABLE users (id int);\n"),
+		"s3://devdb-backups/uploads/app.dump": []byte("PGDMP\x01\x0e"),
+		"s3://devdb-backups/uploads/app.tar":  archive.Bytes(),
+	}
+	p := &LocalProvisioner{Dir: t.TempDir(), BinDir: fakeBinDir(t), Backups: backups}
+	defer p.Close()
+
+	for _, tc := range []struct {
+		location string
+		want     string
+	}{
+		{"s3://devdb-backups/uploads/app.sql", "psql %s -v ON_ERROR_STOP=1 -q -f %s/backup\nCREATE TABLE users (id int);\n"},
+		{"s3://devdb-backups/uploads/app.dump", "pg_restore %s --no-owner --no-privileges %s/backup\nPGDMP\x01\x0e"},
+		{"s3://devdb-backups/uploads/app.tar", "pg_restore %s --format=directory --no-owner --no-privileges %s/backup.d\n3001.dat.gz\ntoc.dat\n"},
+	} {
+		os.Remove(restoreLog)
+		project := api.Project{
+			Id:             "p-1",
+			Name:           "billing",
+			DbType:         api.Postgres,
+			DbVersion:      "16",
+			BackupLocation: tc.location,
+			DefaultCredentials: api.DefaultDatabaseCredentials{
+				Username: "devdb", Password: "secret", Database: "app",
+			},
+		}
+		db := &api.Database{Name: strings.TrimPrefix(filepath.Ext(tc.location), ".")}
+		if err := p.Create(ctx, project, db); err != nil {
+			t.Fatal(err)
+		}
+		waitForStatus(t, p, project, db, api.Running)
+
+		dir := filepath.Join(p.Dir, "p-1", db.Name)
+		want := fmt.Sprintf(tc.want, fmt.Sprintf("-h 127.0.0.1 -p %d -U devdb -d app", *db.Port), dir)
+		if got, err := os.ReadFile(restoreLog); err != nil || string(got) != want {
+			t.Errorf("restore of %s = %q, %v, want %q", tc.location, got, err, want)
+		}
+		for _, name := range []string{"backup", "backup.d"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+				t.Errorf("%s left after the restore of %s: %v", name, tc.location, err)
+			}
+		}
+	}
+
+	// A reset database is restored from the backup again
+	project := api.Project{
+		Id:                 "p-1",
+		BackupLocation:     "s3://devdb-backups/uploads/app.sql",
+		DefaultCredentials: api.DefaultDatabaseCredentials{Username: "devdb", Password: "secret", Database: "app"},
+	}
+	db := &api.Database{Name: "sql"}
+	os.Remove(restoreLog)
+	if err := p.Reset(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, p, project, db, api.Running)
+	if got, err := os.ReadFile(restoreLog); err != nil || !strings.HasPrefix(string(got), "psql ") {
+		t.Errorf("restore after reset = %q, %v, want psql", got, err)
+	}
+
+	// Without a backup, a reset doesn't restore one left behind earlier
+	project.BackupLocation = ""
+	if err := os.WriteFile(filepath.Join(p.Dir, "p-1", "sql", "backup"), []byte("DROP TABLE users;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(restoreLog)
+	if err := p.Reset(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, p, project, db, api.Running)
+	if got, err := os.ReadFile(restoreLog); !os.IsNotExist(err) {
+		t.Errorf("restore after reset without a backup = %q, %v, want none", got, err)
+	}
+}
+
+func TestLocalProvisionerStopWhileInitializing(t *testing.T) {
+	t.Setenv(initdbDelayEnv, "300ms")
+	ctx := context.Background()
+	p := &LocalProvisioner{Dir: t.TempDir(), BinDir: fakeBinDir(t)}
+	defer p.Close()
+
+	project := api.Project{Id: "p-1", DefaultCredentials: api.DefaultDatabaseCredentials{Username: "devdb", Password: "secret", Database: "postgres"}}
+	db := &api.Database{Name: "dev"}
+	if err := p.Create(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx, project, db); err != nil {
+		t.Fatal(err)
+	}
+
+	// postgres would be listening soon after initdb is done, with nothing
+	// left to stop it
+	time.Sleep(time.Second)
+	if conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", *db.Port)); err == nil {
+		conn.Close()
+		t.Error("postgres was started for a database stopped during initdb")
+	}
+}
+
+func TestLocalProvisionerUnsupported(t *testing.T) {
+	ctx := context.Background()
+	p := &LocalProvisioner{Dir: t.TempDir(), BinDir: fakeBinDir(t), Backups: fakeBackups{}}
+	defer p.Close()
+
+	if version, err := p.Version(); err != nil || version != "16" {
+		t.Errorf("Version() = %q, %v, want 16", version, err)
+	}
+	if catalog := p.Engines(); len(catalog) != 1 || catalog[0].Type != api.Postgres || len(catalog[0].Versions) != 1 || catalog[0].Versions[0] != "16" {
+		t.Errorf("Engines() = %+v, want PostgreSQL 16", catalog)
+	}
+
+	for _, tc := range []struct {
+		project api.Project
+		want    string
+	}{
+		{
+			api.Project{Id: "p-1", Name: "billing", DbType: api.Postgres, DbVersion: "15"},
+			"project billing is on PostgreSQL 15, but the local provisioner runs the installed PostgreSQL 16",
+		},
+		{
+			api.Project{Id: "p-1", Name: "billing", DbType: api.Postgres, DbVersion: "16", BackupLocation: "s3://company-backups/billing.dump"},
+			"backup s3://company-backups/billing.dump of project billing is not one uploaded to this server",
+		},
+	} {
+		db := &api.Database{Name: "dev"}
+		err := p.Create(ctx, tc.project, db)
+		var unsupported *UnsupportedError
+		if !errors.As(err, &unsupported) || err.Error() != tc.want {
+			t.Errorf("create = %v, want %q", err, tc.want)
+		}
+		if err := p.Reset(ctx, tc.project, db); err == nil || err.Error() != tc.want {
+			t.Errorf("reset = %v, want %q", err, tc.want)
+		}
+		if _, err := os.Stat(filepath.Join(p.Dir, "p-1", "dev", "data")); !os.IsNotExist(err) {
+			t.Errorf("data directory left after a refused create: %v", err)
+		}
+	}
+
+	// Without a backup store, no backup can be restored
+	p.Backups = nil
+	project := api.Project{Id: "p-1", Name: "billing", BackupLocation: "s3://devdb-backups/uploads/app.sql"}
+	want := "project billing has the backup s3://devdb-backups/uploads/app.sql, but this server has no backups to restore from"
+	if err := p.Create(ctx, project, &api.Database{Name: "dev"}); err == nil || err.Error() != want {
+		t.Errorf("create without backups = %v, want %q", err, want)
+	}
+}
+
+// fakeBackups is a BackupStore holding backups by location.
+type fakeBackups map[string][]byte
+
+func (b fakeBackups) ObjectAt(location string) ([]byte, error) {
+	data, ok := b[location]
+	if !ok {
+		return nil, s3local.ErrNoSuchKey
+	}
+	return data, nil
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+)
+
+// Provisioner runs the database instances behind the API.
+type Provisioner interface {
+	// Create starts a database for project. It sets db's status and
+	// connection details; a database that is still starting is reported
+	// as creating and brought up to date by Refresh.
+	Create(ctx context.Context, project api.Project, db *api.Database) error
+
+	// Refresh updates db's status.
+	Refresh(ctx context.Context, project api.Project, db *api.Database) error
+
+	// Delete removes a database and its data.
+	Delete(ctx context.Context, project api.Project, db api.Database) error
+
+	// Snapshot copies the data of a running database into snap. It sets
+	// snap's status, and its size when known.
+	Snapshot(ctx context.Context, project api.Project, db api.Database, snap *api.Snapshot) error
+
+	// DeleteSnapshot removes a snapshot's data.
+	DeleteSnapshot(ctx context.Context, project api.Project, snap api.Snapshot) error
+
+	// Clone creates a database like Create, starting from the data of a
+	// snapshot instead of the project's backup.
+	Clone(ctx context.Context, project api.Project, db *api.Database, from api.Snapshot) error
+
+	// Reset replaces a database's data with the project's pristine data,
+	// keeping its name and, where possible, its endpoint. It sets db's
+	// status like Create.
+	Reset(ctx context.Context, project api.Project, db *api.Database) error
+
+	// Stop shuts a database down, keeping its data, and sets db's status
+	// to stopped. Refresh is not called for stopped databases.
+	Stop(ctx context.Context, project api.Project, db *api.Database) error
+
+	// Start brings a stopped database back with its data. It sets db's
+	// status like Create.
+	Start(ctx context.Context, project api.Project, db *api.Database) error
+}
+
+// UnsupportedError is returned by provisioners for projects they cannot
+// run as asked, such as one of a version that is not installed. The server
+// answers with its reason instead of an internal error.
+type UnsupportedError struct {
+	Reason string
+}
+
+func (e *UnsupportedError) Error() string { return e.Reason }
+
+// EngineLister is implemented by provisioners that run only some of the
+// engines DevDB knows. The server advertises and accepts only those.
+type EngineLister interface {
+	Engines() engine.Catalog
+}
+
+// NoopProvisioner runs nothing. Databases are running as soon as they are
+// created and point at Host, or at localhost when Host is empty, and Port,
+// or the default port of their engine when it is zero. It is meant for
+// trying out the API and for tests.
+type NoopProvisioner struct {
+	Host string
+	Port int
+}
+
+func (p NoopProvisioner) Create(ctx context.Context, project api.Project, db *api.Database) error {
+	host, port := p.Host, p.Port
+	if host == "" {
+		host = "localhost"
+	}
+	if port == 0 {
+		port = 5432
+		if e, ok := engine.Lookup(project.DbType); ok {
+			port = e.Port
+		}
+	}
+	db.Status = api.Running
+	db.Host = &host
+	db.Port = &port
+	db.Username = &project.DefaultCredentials.Username
+	db.Database = &project.DefaultCredentials.Database
+	return nil
+}
+
+func (p NoopProvisioner) Refresh(ctx context.Context, project api.Project, db *api.Database) error {
+	return nil
+}
+
+func (p NoopProvisioner) Delete(ctx context.Context, project api.Project, db api.Database) error {
+	return nil
+}
+
+func (p NoopProvisioner) Snapshot(ctx context.Context, project api.Project, db api.Database, snap *api.Snapshot) error {
+	snap.Status = api.SnapshotReady
+	return nil
+}
+
+func (p NoopProvisioner) DeleteSnapshot(ctx context.Context, project api.Project, snap api.Snapshot) error {
+	return nil
+}
+
+func (p NoopProvisioner) Clone(ctx context.Context, project api.Project, db *api.Database, from api.Snapshot) error {
+	return p.Create(ctx, project, db)
+}
+
+func (p NoopProvisioner) Reset(ctx context.Context, project api.Project, db *api.Database) error {
+	db.Status = api.Running
+	return nil
+}
+
+func (p NoopProvisioner) Stop(ctx context.Context, project api.Project, db *api.Database) error {
+	db.Status = api.Stopped
+	return nil
+}
+
+func (p NoopProvisioner) Start(ctx context.Context, project api.Project, db *api.Database) error {
+	db.Status = api.Running
+	return nil
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+// minTTLSeconds is the shortest time-to-live a database can be given.
+const minTTLSeconds = 60
+
+// ExpiredAction is what happens to a database once its time-to-live is up.
+type ExpiredAction string
+
+const (
+	// DeleteExpired deletes expired databases with their snapshots.
+	DeleteExpired ExpiredAction = "delete"
+
+	// StopExpired stops expired databases, keeping their data. Their
+	// expiry is cleared so they are not stopped again once started.
+	// Databases that are already stopped are left alone, as by devdb admin
+	// reap --action stop.
+	StopExpired ExpiredAction = "stop"
+)
+
+// ParseExpiredAction accepts "delete" and "stop".
+func ParseExpiredAction(s string) (ExpiredAction, error) {
+	switch a := ExpiredAction(s); a {
+	case DeleteExpired, StopExpired:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown action %q for expired databases (want delete or stop)", s)
+}
+
+// Reap deletes or stops, depending on Options.ExpiredAction, the databases
+// whose expiry has passed, and returns them. It carries on past databases
+// it fails to reap and returns the first error.
+func (s *Server) Reap(ctx context.Context) ([]api.Database, error) {
+	projects, err := s.store.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var reaped []api.Database
+	var firstErr error
+	for _, project := range projects {
+		databases, err := s.store.ListDatabases(ctx, project.Id)
+		if err != nil {
+			return reaped, err
+		}
+		for _, listed := range databases {
+			if !s.expiredAt(listed, now) {
+				continue
+			}
+			db, ok, err := s.reap(ctx, project, listed.Name, now)
+			if err != nil {
+				s.logger.Printf("reaping database %s of project %s: %v", listed.Name, project.Id, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if ok {
+				db.Project = &project.Id
+				reaped = append(reaped, db)
+			}
+		}
+	}
+	return reaped, firstErr
+}
+
+// expiredAt reports whether db is to be reaped at now.
+func (s *Server) expiredAt(db api.Database, now time.Time) bool {
+	if db.ExpiresAt == nil || db.ExpiresAt.After(now) {
+		return false
+	}
+	return s.expired != StopExpired || db.Status != api.Stopped
+}
+
+// reap deletes or stops the database name if it is still expired, and
+// reports whether it did.
+func (s *Server) reap(ctx context.Context, project api.Project, name string, now time.Time) (api.Database, bool, error) {
+	// Requests for the same database must not interleave with the reaper,
+	// so it is read again under the lock: it may have been extended,
+	// stopped or deleted since it was listed
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := s.store.GetDatabase(ctx, project.Id, name)
+	if errors.Is(err, ErrNotFound) {
+		return db, false, nil
+	}
+	if err != nil {
+		return db, false, err
+	}
+	if !s.expiredAt(db, now) {
+		return db, false, nil
+	}
+	if s.expired == StopExpired {
+		db.ExpiresAt = nil
+		return db, true, s.stopDatabase(ctx, project, &db)
+	}
+	return db, true, s.deleteDatabase(ctx, project, db)
+}
+
+// RunReaper calls Reap every interval until ctx is done.
+func (s *Server) RunReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reaped, _ := s.Reap(ctx)
+			for _, db := range reaped {
+				s.logger.Printf("%s expired database %s of project %s", pastTense(s.expired), db.Name, *db.Project)
+			}
+		}
+	}
+}
+
+func pastTense(a ExpiredAction) string {
+	if a == StopExpired {
+		return "stopped"
+	}
+	return "deleted"
+}
@@ -0,0 +1,1001 @@
+// Package server implements the DevDB API described by
+// api/openapi/openapi.yaml on top of the handlers generated into pkg/api.
+// Where state is kept and how databases are run is left to a Store and a
+// Provisioner.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+	"github.com/meido-ai/devdb/cli/pkg/masking"
+)
+
+// Database names become host names, so they follow the rules for DNS labels.
+var databaseNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// Options configure a Server.
+type Options struct {
+	// Tokens are the accepted bearer tokens. Without any, the API accepts
+	// unauthenticated requests.
+	Tokens []string
+
+	// Logger receives errors; log.Default() when nil.
+	Logger *log.Logger
+
+	// Now returns the current time; time.Now when nil. Tests set it to
+	// control when databases expire.
+	Now func() time.Time
+
+	// ExpiredAction is what Reap does with expired databases; they are
+	// deleted when empty.
+	ExpiredAction ExpiredAction
+
+	// Uploader presigns backup uploads. Without one, POST /backups/uploads
+	// answers that uploads are not enabled.
+	Uploader Uploader
+}
+
+// Uploader hands out URLs that accept a single PUT of a backup file.
+type Uploader interface {
+	// PresignPut returns a URL accepting exactly size bytes for key until
+	// expires has passed, and the s3:// location the object will have.
+	PresignPut(ctx context.Context, key string, size int64, expires time.Duration) (url, location string, err error)
+}
+
+// uploadExpiry is how long a presigned backup upload URL works.
+const uploadExpiry = time.Hour
+
+// Server serves the DevDB API.
+type Server struct {
+	store       Store
+	provisioner Provisioner
+	tokens      [][sha256.Size]byte
+	logger      *log.Logger
+	now         func() time.Time
+	expired     ExpiredAction
+	uploader    Uploader
+
+	// mu serializes creations, so two requests cannot both pass the
+	// check for an existing name, and changes to databases, so requests
+	// and the reaper do not write over each other
+	mu sync.Mutex
+}
+
+var _ api.ServerInterface = (*Server)(nil)
+
+// New returns a Server keeping state in store and running databases with
+// provisioner.
+func New(store Store, provisioner Provisioner, opts Options) *Server {
+	s := &Server{store: store, provisioner: provisioner, logger: opts.Logger, now: opts.Now, expired: opts.ExpiredAction, uploader: opts.Uploader}
+	if s.logger == nil {
+		s.logger = log.Default()
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	if s.expired == "" {
+		s.expired = DeleteExpired
+	}
+	for _, token := range opts.Tokens {
+		s.tokens = append(s.tokens, sha256.Sum256([]byte(token)))
+	}
+	return s
+}
+
+// Handler returns the HTTP handler for the API.
+func (s *Server) Handler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(s.authenticate)
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("No route for %s %s", r.Method, r.URL.Path))
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "")
+	})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+	})
+
+	return api.HandlerWithOptions(s, api.ChiServerOptions{
+		BaseRouter: r,
+		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			writeProblem(w, r, http.StatusBadRequest, err.Error())
+		},
+	})
+}
+
+type authenticatedKey struct{}
+
+// authenticate implements the bearerAuth security scheme. Requests with a
+// valid token are marked in their context.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.tokens) == 0 || r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok {
+			presented := sha256.Sum256([]byte(strings.TrimSpace(token)))
+			for _, accepted := range s.tokens {
+				if subtle.ConstantTimeCompare(accepted[:], presented[:]) == 1 {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authenticatedKey{}, true)))
+					return
+				}
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="devdb"`)
+		writeProblem(w, r, http.StatusUnauthorized, "Missing or invalid credentials")
+	})
+}
+
+// showCredentials reports whether a project's default credentials may be
+// returned. Without tokens the server is open and returns them to anyone,
+// so databases can be connected to.
+func (s *Server) showCredentials(r *http.Request) bool {
+	return len(s.tokens) == 0 || r.Context().Value(authenticatedKey{}) == true
+}
+
+// catalog returns the engines projects can be created with.
+func (s *Server) catalog() engine.Catalog {
+	if lister, ok := s.provisioner.(EngineLister); ok {
+		return lister.Engines()
+	}
+	return engine.All()
+}
+
+func (s *Server) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.catalog().API())
+}
+
+func (s *Server) PostBackupsUploads(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateBackupUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if req.Filename == "" || req.Filename != path.Base(req.Filename) || strings.Contains(req.Filename, "\\") || strings.Trim(req.Filename, ".") == "" {
+		writeProblem(w, r, http.StatusBadRequest, "Filename must be a file name without directories")
+		return
+	}
+	if req.SizeBytes < 1 {
+		writeProblem(w, r, http.StatusBadRequest, "Size must be at least 1 byte")
+		return
+	}
+	switch req.Format {
+	case api.BackupFormatCustom, api.BackupFormatPlain, api.BackupFormatDirectory, api.BackupFormatRDB, api.BackupFormatArchive:
+	default:
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Unsupported backup format %q", req.Format))
+		return
+	}
+	if s.uploader == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Backup uploads are not enabled on this server")
+		return
+	}
+
+	key := "uploads/" + uuid.NewString() + "/" + req.Filename
+	uploadURL, location, err := s.uploader.PresignPut(r.Context(), key, req.SizeBytes, uploadExpiry)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, api.BackupUpload{
+		UploadUrl: uploadURL,
+		Location:  location,
+		ExpiresAt: s.now().Add(uploadExpiry).UTC(),
+	})
+}
+
+func (s *Server) GetProjects(w http.ResponseWriter, r *http.Request, params api.GetProjectsParams) {
+	projects, err := s.store.ListProjects(r.Context())
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+
+	result := []map[string]interface{}{}
+	for _, project := range projects {
+		if params.Owner == nil || project.Owner == *params.Owner {
+			result = append(result, withoutSecrets(project))
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) PostProjects(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if req.Owner == "" || req.Name == "" || req.DbType == "" || req.DbVersion == "" {
+		writeProblem(w, r, http.StatusBadRequest, "Missing required fields: owner, name, dbType, and dbVersion are required")
+		return
+	}
+	if err := s.catalog().Validate(req.DbType, req.DbVersion); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid database: %v", err))
+		return
+	}
+	if req.BackupLocation != nil && *req.BackupLocation != "" && !strings.HasPrefix(*req.BackupLocation, "s3://") {
+		writeProblem(w, r, http.StatusBadRequest, "Backup location must be an S3 URL (e.g., s3://bucket-name/path/to/backup.dump)")
+		return
+	}
+	if !validMaskingPolicy(w, r, req.MaskingPolicy) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects, err := s.store.ListProjects(r.Context())
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	for _, p := range projects {
+		if p.Owner == req.Owner && p.Name == req.Name {
+			writeProblem(w, r, http.StatusConflict, fmt.Sprintf("Project %s already exists for owner %s", req.Name, req.Owner))
+			return
+		}
+	}
+
+	project := api.Project{
+		Id:                 uuid.NewString(),
+		Owner:              req.Owner,
+		Name:               req.Name,
+		DbType:             req.DbType,
+		DbVersion:          req.DbVersion,
+		DataVersion:        intPtr(1),
+		DefaultCredentials: engine.Credentials(req.DbType, generatePassword()),
+	}
+	if req.BackupLocation != nil {
+		project.BackupLocation = *req.BackupLocation
+	}
+	if req.MaskingPolicy != nil && *req.MaskingPolicy != "" {
+		project.MaskingPolicy = req.MaskingPolicy
+	}
+	if err := s.store.CreateProject(r.Context(), project); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, withoutSecrets(project))
+}
+
+func (s *Server) DeleteProject(w http.ResponseWriter, r *http.Request, projectId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.project(w, r, projectId)
+	if !ok {
+		return
+	}
+	databases, err := s.store.ListDatabases(r.Context(), projectId)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	for _, db := range databases {
+		if err := s.provisioner.Delete(r.Context(), project, db); err != nil {
+			s.internalError(w, r, fmt.Errorf("deleting database %s: %v", db.Name, err))
+			return
+		}
+	}
+	snapshots, err := s.store.ListSnapshots(r.Context(), projectId)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	for _, snap := range snapshots {
+		if err := s.provisioner.DeleteSnapshot(r.Context(), project, snap); err != nil {
+			s.internalError(w, r, fmt.Errorf("deleting snapshot %s: %v", snap.Name, err))
+			return
+		}
+	}
+	if err := s.store.DeleteProject(r.Context(), projectId); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "Project deleted successfully")
+}
+
+func (s *Server) GetProjectsProjectId(w http.ResponseWriter, r *http.Request, projectId string) {
+	project, ok := s.project(w, r, projectId)
+	if !ok {
+		return
+	}
+	databases, err := s.databases(r.Context(), project)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	project.Databases = &databases
+	if !s.showCredentials(r) {
+		writeJSON(w, http.StatusOK, withoutSecrets(project))
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) PostProjectsProjectIdRefresh(w http.ResponseWriter, r *http.Request, projectId string) {
+	var req api.RefreshProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if req.BackupLocation != nil && !strings.HasPrefix(*req.BackupLocation, "s3://") {
+		writeProblem(w, r, http.StatusBadRequest, "Backup location must be an S3 URL (e.g., s3://bucket-name/path/to/backup.dump)")
+		return
+	}
+	if !validMaskingPolicy(w, r, req.MaskingPolicy) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.project(w, r, projectId)
+	if !ok {
+		return
+	}
+	if req.BackupLocation != nil {
+		project.BackupLocation = *req.BackupLocation
+	}
+	if req.MaskingPolicy != nil {
+		project.MaskingPolicy = req.MaskingPolicy
+		if *req.MaskingPolicy == "" {
+			project.MaskingPolicy = nil
+		}
+	}
+	if project.BackupLocation == "" {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Project %s has no backup to refresh from; give a backupLocation", project.Name))
+		return
+	}
+
+	// Databases keep the data they were created with; the ones created
+	// or reset from now on are at the new version
+	previous := dataVersion(project)
+	refreshedAt := s.now().UTC()
+	project.DataVersion = intPtr(previous + 1)
+	project.DataRefreshedAt = &refreshedAt
+	if err := s.store.UpdateProject(r.Context(), project); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	if !s.showCredentials(r) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"previousDataVersion": previous,
+			"dataVersion":         previous + 1,
+			"project":             withoutSecrets(project),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, api.DataRefresh{
+		PreviousDataVersion: previous,
+		DataVersion:         previous + 1,
+		Project:             project,
+	})
+}
+
+func (s *Server) GetProjectsProjectIdDatabases(w http.ResponseWriter, r *http.Request, projectId string) {
+	project, ok := s.project(w, r, projectId)
+	if !ok {
+		return
+	}
+	databases, err := s.databases(r.Context(), project)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, databases)
+}
+
+func (s *Server) PostProjectsProjectIdDatabases(w http.ResponseWriter, r *http.Request, projectId string) {
+	var req api.CreateDatabaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if req.Name == "" {
+		writeProblem(w, r, http.StatusBadRequest, "Name is required")
+		return
+	}
+	if !databaseNamePattern.MatchString(req.Name) {
+		writeProblem(w, r, http.StatusBadRequest, "Name must consist of lower case letters, digits and '-', and start and end with a letter or digit")
+		return
+	}
+
+	if req.FromDatabase != nil && req.FromSnapshot != nil {
+		writeProblem(w, r, http.StatusBadRequest, "fromDatabase and fromSnapshot cannot be combined")
+		return
+	}
+	if req.TtlSeconds != nil && *req.TtlSeconds < minTTLSeconds {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("ttlSeconds must be at least %d", minTTLSeconds))
+		return
+	}
+
+	project, ok := s.project(w, r, projectId)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.store.GetDatabase(r.Context(), projectId, req.Name); err == nil {
+		writeProblem(w, r, http.StatusConflict, fmt.Sprintf("Database %s already exists", req.Name))
+		return
+	} else if !errors.Is(err, ErrNotFound) {
+		s.internalError(w, r, err)
+		return
+	}
+
+	db := api.Database{Name: req.Name, Status: api.Creating, Project: &project.Id}
+	if req.TtlSeconds != nil {
+		expiresAt := s.now().UTC().Add(time.Duration(*req.TtlSeconds) * time.Second)
+		db.ExpiresAt = &expiresAt
+	}
+	var err error
+	switch {
+	case req.FromSnapshot != nil:
+		snap, ok := s.snapshot(w, r, projectId, *req.FromSnapshot)
+		if !ok {
+			return
+		}
+		if snap.Status != api.SnapshotReady {
+			writeProblem(w, r, http.StatusConflict, fmt.Sprintf("Snapshot %s is not ready (status: %s)", snap.Name, snap.Status))
+			return
+		}
+		err = s.provisioner.Clone(r.Context(), project, &db, snap)
+	case req.FromDatabase != nil:
+		source, ok := s.runningDatabase(w, r, project, *req.FromDatabase)
+		if !ok {
+			return
+		}
+		db.DataVersion = source.DataVersion
+		err = s.cloneDatabase(r.Context(), project, &db, source)
+	default:
+		db.DataVersion = intPtr(dataVersion(project))
+		err = s.provisioner.Create(r.Context(), project, &db)
+	}
+	if err != nil {
+		s.provisionerError(w, r, http.StatusBadRequest, fmt.Errorf("creating database %s: %w", req.Name, err))
+		return
+	}
+	if err := s.store.PutDatabase(r.Context(), db); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, db)
+}
+
+// cloneDatabase creates db with the data of source through a snapshot
+// that is only kept for the time of the copy.
+func (s *Server) cloneDatabase(ctx context.Context, project api.Project, db *api.Database, source api.Database) error {
+	snap := api.Snapshot{
+		Name:      "source-" + uuid.NewString(),
+		Database:  source.Name,
+		Project:   &project.Id,
+		Status:    api.SnapshotCreating,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.provisioner.Snapshot(ctx, project, source, &snap); err != nil {
+		return fmt.Errorf("taking a snapshot of %s: %v", source.Name, err)
+	}
+	defer s.provisioner.DeleteSnapshot(ctx, project, snap)
+	if snap.Status != api.SnapshotReady {
+		return fmt.Errorf("snapshot of %s is %s", source.Name, snap.Status)
+	}
+	return s.provisioner.Clone(ctx, project, db, snap)
+}
+
+func (s *Server) DeleteProjectsProjectIdDatabasesName(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, db, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+	if err := s.deleteDatabase(r.Context(), project, db); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Database deleted successfully"})
+}
+
+// deleteDatabase removes db along with its snapshots.
+func (s *Server) deleteDatabase(ctx context.Context, project api.Project, db api.Database) error {
+	if err := s.provisioner.Delete(ctx, project, db); err != nil {
+		return fmt.Errorf("deleting database %s: %v", db.Name, err)
+	}
+	snapshots, err := s.snapshots(ctx, project.Id, db.Name)
+	if err != nil {
+		return err
+	}
+	for _, snap := range snapshots {
+		if err := s.deleteSnapshot(ctx, project, snap); err != nil {
+			return err
+		}
+	}
+	return s.store.DeleteDatabase(ctx, project.Id, db.Name)
+}
+
+func (s *Server) GetProjectsProjectIdDatabasesName(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	_, db, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, db)
+}
+
+func (s *Server) PostProjectsProjectIdDatabasesNameReset(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, db, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+	// Snapshots are kept: they are what a reset database is often
+	// compared against
+	if err := s.provisioner.Reset(r.Context(), project, &db); err != nil {
+		s.provisionerError(w, r, http.StatusInternalServerError, fmt.Errorf("resetting database %s: %w", name, err))
+		return
+	}
+	// A reset brings a stopped database back up, with the project's
+	// current data
+	db.StoppedAt = nil
+	db.DataVersion = intPtr(dataVersion(project))
+	if err := s.store.PutDatabase(r.Context(), db); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, db)
+}
+
+func (s *Server) PostProjectsProjectIdDatabasesNameStop(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	// The body is optional
+	var req api.StopDatabaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, db, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+	if db.Status == api.Creating {
+		writeProblem(w, r, http.StatusConflict, fmt.Sprintf("Database %s is still being created", name))
+		return
+	}
+	clearExpiry := req.ClearExpiry != nil && *req.ClearExpiry && db.ExpiresAt != nil
+	if clearExpiry {
+		db.ExpiresAt = nil
+	}
+	if db.Status == api.Stopped {
+		if clearExpiry {
+			if err := s.store.PutDatabase(r.Context(), db); err != nil {
+				s.internalError(w, r, err)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, db)
+		return
+	}
+	if err := s.stopDatabase(r.Context(), project, &db); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, db)
+}
+
+// stopDatabase stops db and records when.
+func (s *Server) stopDatabase(ctx context.Context, project api.Project, db *api.Database) error {
+	if err := s.provisioner.Stop(ctx, project, db); err != nil {
+		return fmt.Errorf("stopping database %s: %v", db.Name, err)
+	}
+	now := s.now().UTC()
+	db.StoppedAt = &now
+	return s.store.PutDatabase(ctx, *db)
+}
+
+func (s *Server) PostProjectsProjectIdDatabasesNameStart(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, db, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+	if db.Status != api.Stopped {
+		writeJSON(w, http.StatusOK, db)
+		return
+	}
+	if err := s.provisioner.Start(r.Context(), project, &db); err != nil {
+		s.internalError(w, r, fmt.Errorf("starting database %s: %v", name, err))
+		return
+	}
+	db.StoppedAt = nil
+	if err := s.store.PutDatabase(r.Context(), db); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, db)
+}
+
+func (s *Server) PostProjectsProjectIdDatabasesNameExtend(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	var req api.ExtendDatabaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if req.TtlSeconds < minTTLSeconds {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("ttlSeconds must be at least %d", minTTLSeconds))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, db, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+
+	// Extending an expired database that was not reaped yet counts from
+	// now, so it doesn't expire again right away
+	from := s.now().UTC()
+	if db.ExpiresAt != nil && db.ExpiresAt.After(from) {
+		from = *db.ExpiresAt
+	}
+	expiresAt := from.Add(time.Duration(req.TtlSeconds) * time.Second)
+	db.ExpiresAt = &expiresAt
+	if err := s.store.PutDatabase(r.Context(), db); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, db)
+}
+
+func (s *Server) GetProjectsProjectIdDatabasesNameSnapshots(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	if _, _, ok := s.database(w, r, projectId, name); !ok {
+		return
+	}
+	snapshots, err := s.snapshots(r.Context(), projectId, name)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshots)
+}
+
+func (s *Server) PostProjectsProjectIdDatabasesNameSnapshots(w http.ResponseWriter, r *http.Request, projectId string, name string) {
+	var req api.CreateSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	now := s.now().UTC()
+	snapName := defaultSnapshotName(name, now)
+	if req.Name != nil && *req.Name != "" {
+		snapName = *req.Name
+	}
+	if !databaseNamePattern.MatchString(snapName) {
+		writeProblem(w, r, http.StatusBadRequest, "Snapshot name must consist of lower case letters, digits and '-', and start and end with a letter or digit")
+		return
+	}
+
+	project, ok := s.project(w, r, projectId)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, ok := s.runningDatabase(w, r, project, name)
+	if !ok {
+		return
+	}
+	if _, err := s.store.GetSnapshot(r.Context(), projectId, snapName); err == nil {
+		writeProblem(w, r, http.StatusConflict, fmt.Sprintf("Snapshot %s already exists", snapName))
+		return
+	} else if !errors.Is(err, ErrNotFound) {
+		s.internalError(w, r, err)
+		return
+	}
+
+	snap := api.Snapshot{
+		Name:      snapName,
+		Database:  db.Name,
+		Project:   &project.Id,
+		Status:    api.SnapshotCreating,
+		CreatedAt: now,
+	}
+	if err := s.provisioner.Snapshot(r.Context(), project, db, &snap); err != nil {
+		s.internalError(w, r, fmt.Errorf("taking snapshot %s: %v", snapName, err))
+		return
+	}
+	if err := s.store.PutSnapshot(r.Context(), snap); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, snap)
+}
+
+func (s *Server) DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshot(w http.ResponseWriter, r *http.Request, projectId string, name string, snapshot string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, _, ok := s.database(w, r, projectId, name)
+	if !ok {
+		return
+	}
+	snap, ok := s.snapshot(w, r, projectId, snapshot)
+	if !ok {
+		return
+	}
+	if snap.Database != name {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("Snapshot %s of database %s not found", snapshot, name))
+		return
+	}
+	if err := s.deleteSnapshot(r.Context(), project, snap); err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Snapshot deleted successfully"})
+}
+
+// defaultSnapshotName names a snapshot after its database and the time,
+// shortening the database name to keep it a valid name.
+func defaultSnapshotName(database string, t time.Time) string {
+	if len(database) > 47 {
+		database = strings.TrimRight(database[:47], "-")
+	}
+	return database + "-" + t.Format("20060102-150405")
+}
+
+// project looks up a project, answering 404 if it does not exist.
+func (s *Server) project(w http.ResponseWriter, r *http.Request, id string) (api.Project, bool) {
+	project, err := s.store.GetProject(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("Project %s not found", id))
+		return project, false
+	} else if err != nil {
+		s.internalError(w, r, err)
+		return project, false
+	}
+	return project, true
+}
+
+// database looks up a database and its project with an up to date status,
+// answering 404 if either does not exist.
+func (s *Server) database(w http.ResponseWriter, r *http.Request, projectID, name string) (api.Project, api.Database, bool) {
+	project, ok := s.project(w, r, projectID)
+	if !ok {
+		return project, api.Database{}, false
+	}
+	db, err := s.store.GetDatabase(r.Context(), projectID, name)
+	if errors.Is(err, ErrNotFound) {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("Database %s not found", name))
+		return project, db, false
+	} else if err != nil {
+		s.internalError(w, r, err)
+		return project, db, false
+	}
+	if err := s.refresh(r.Context(), project, &db); err != nil {
+		s.internalError(w, r, err)
+		return project, db, false
+	}
+	return project, db, true
+}
+
+// runningDatabase looks up a database that must be running to be copied,
+// answering 404 if it does not exist and 409 if it is not running.
+func (s *Server) runningDatabase(w http.ResponseWriter, r *http.Request, project api.Project, name string) (api.Database, bool) {
+	db, err := s.store.GetDatabase(r.Context(), project.Id, name)
+	if errors.Is(err, ErrNotFound) {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("Database %s not found", name))
+		return db, false
+	} else if err != nil {
+		s.internalError(w, r, err)
+		return db, false
+	}
+	if err := s.refresh(r.Context(), project, &db); err != nil {
+		s.internalError(w, r, err)
+		return db, false
+	}
+	if db.Status != api.Running {
+		writeProblem(w, r, http.StatusConflict, fmt.Sprintf("Database %s is not running (status: %s)", name, db.Status))
+		return db, false
+	}
+	return db, true
+}
+
+// snapshot looks up a snapshot, answering 404 if it does not exist.
+func (s *Server) snapshot(w http.ResponseWriter, r *http.Request, projectID, name string) (api.Snapshot, bool) {
+	snap, err := s.store.GetSnapshot(r.Context(), projectID, name)
+	if errors.Is(err, ErrNotFound) {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("Snapshot %s not found", name))
+		return snap, false
+	} else if err != nil {
+		s.internalError(w, r, err)
+		return snap, false
+	}
+	return snap, true
+}
+
+// snapshots lists the snapshots of a database, oldest first.
+func (s *Server) snapshots(ctx context.Context, projectID, database string) ([]api.Snapshot, error) {
+	all, err := s.store.ListSnapshots(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []api.Snapshot{}
+	for _, snap := range all {
+		if snap.Database == database {
+			snapshots = append(snapshots, snap)
+		}
+	}
+	return snapshots, nil
+}
+
+func (s *Server) deleteSnapshot(ctx context.Context, project api.Project, snap api.Snapshot) error {
+	if err := s.provisioner.DeleteSnapshot(ctx, project, snap); err != nil {
+		return fmt.Errorf("deleting snapshot %s: %v", snap.Name, err)
+	}
+	return s.store.DeleteSnapshot(ctx, project.Id, snap.Name)
+}
+
+// databases lists a project's databases with up to date statuses.
+func (s *Server) databases(ctx context.Context, project api.Project) ([]api.Database, error) {
+	databases, err := s.store.ListDatabases(ctx, project.Id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range databases {
+		if err := s.refresh(ctx, project, &databases[i]); err != nil {
+			return nil, err
+		}
+	}
+	return databases, nil
+}
+
+// refresh asks the provisioner for db's status and saves any change, such
+// as a new port.
+func (s *Server) refresh(ctx context.Context, project api.Project, db *api.Database) error {
+	if db.Status == api.Stopped {
+		// Stopped databases stay down until they are started
+		return nil
+	}
+	before := *db
+	if err := s.provisioner.Refresh(ctx, project, db); err != nil {
+		return fmt.Errorf("refreshing database %s: %v", db.Name, err)
+	}
+	if reflect.DeepEqual(before, *db) {
+		return nil
+	}
+	return s.store.PutDatabase(ctx, *db)
+}
+
+// dataVersion returns the version of a project's data. Projects stored
+// before data versions were introduced are at version 1.
+func dataVersion(project api.Project) int {
+	if project.DataVersion == nil {
+		return 1
+	}
+	return *project.DataVersion
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func (s *Server) internalError(w http.ResponseWriter, r *http.Request, err error) {
+	s.logger.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	writeProblem(w, r, http.StatusInternalServerError, "")
+}
+
+// provisionerError answers with the reason of an UnsupportedError from the
+// provisioner, with status, and with an internal error otherwise.
+func (s *Server) provisionerError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	var unsupported *UnsupportedError
+	if errors.As(err, &unsupported) {
+		writeProblem(w, r, status, unsupported.Reason)
+		return
+	}
+	s.internalError(w, r, err)
+}
+
+// withoutSecrets returns project for encoding without its secrets: its
+// default credentials and its masking policy, whose seed would let masked
+// values be guessed. The credentials are required in api.Project, so they
+// are removed from the encoded form instead of being left empty.
+func withoutSecrets(project api.Project) map[string]interface{} {
+	data, _ := json.Marshal(project)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	delete(fields, "defaultCredentials")
+	delete(fields, "maskingPolicy")
+	return fields
+}
+
+// validMaskingPolicy answers with a problem when policy is given but does
+// not parse. An empty policy is valid; it removes the project's policy.
+func validMaskingPolicy(w http.ResponseWriter, r *http.Request, policy *string) bool {
+	if policy == nil || *policy == "" {
+		return true
+	}
+	if _, err := masking.Parse([]byte(*policy)); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid masking policy: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeProblem answers with an RFC 7807 problem document.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problemType, instance := "about:blank", r.URL.Path
+	problem := api.Problem{
+		Type:     &problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: &instance,
+	}
+	if detail != "" {
+		problem.Detail = &detail
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+const passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// generatePassword returns a random password that needs no escaping in
+// connection URLs.
+func generatePassword() string {
+	b := make([]byte, 32)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b)
+}
@@ -0,0 +1,880 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+)
+
+func newTestServer(t *testing.T, opts Options, clientOpts ...api.ClientOption) *api.ClientWithResponses {
+	t.Helper()
+	ts := httptest.NewServer(New(NewMemoryStore(), NoopProvisioner{}, opts).Handler())
+	t.Cleanup(ts.Close)
+
+	client, err := api.NewClientWithResponses(ts.URL, clientOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.StatusCode() != http.StatusCreated {
+		t.Fatalf("create project: %v", api.NewError(created.HTTPResponse, created.Body))
+	}
+	project := created.JSON201
+	if project.DefaultCredentials.Password != "" {
+		t.Error("create project returned the default credentials")
+	}
+
+	conflict, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflict.JSON409 == nil {
+		t.Errorf("duplicate project: status %d, want 409 with problem details", conflict.StatusCode())
+	}
+
+	invalid, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{Owner: "alice", Name: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invalid.JSON400 == nil || invalid.JSON400.Detail == nil {
+		t.Errorf("invalid project: status %d, want 400 with problem details", invalid.StatusCode())
+	}
+
+	owner := "bob"
+	listed, err := client.GetProjectsWithResponse(ctx, &api.GetProjectsParams{Owner: &owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed.JSON200 == nil || len(*listed.JSON200) != 0 {
+		t.Errorf("projects of bob = %+v, want none", listed.JSON200)
+	}
+
+	db, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.JSON201 == nil || db.JSON201.Status != api.Running || db.JSON201.Host == nil {
+		t.Fatalf("create database: status %d, body %s", db.StatusCode(), db.Body)
+	}
+
+	badName, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, project.Id, api.CreateDatabaseRequest{Name: "Not_Valid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if badName.JSON400 == nil {
+		t.Errorf("invalid database name: status %d, want 400", badName.StatusCode())
+	}
+
+	shown, err := client.GetProjectsProjectIdWithResponse(ctx, project.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.JSON200 == nil || shown.JSON200.DefaultCredentials.Password == "" {
+		t.Error("an open server should return the default credentials")
+	}
+	if shown.JSON200.Databases == nil || len(*shown.JSON200.Databases) != 1 {
+		t.Errorf("project databases = %+v, want dev", shown.JSON200.Databases)
+	}
+
+	reset, err := client.PostProjectsProjectIdDatabasesNameResetWithResponse(ctx, project.Id, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reset.JSON200 == nil || reset.JSON200.Name != "dev" || reset.JSON200.Status != api.Running {
+		t.Errorf("reset database: status %d, body %s", reset.StatusCode(), reset.Body)
+	}
+	resetMissing, err := client.PostProjectsProjectIdDatabasesNameResetWithResponse(ctx, project.Id, "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resetMissing.JSON404 == nil {
+		t.Errorf("reset missing database: status %d, want 404", resetMissing.StatusCode())
+	}
+
+	deleted, err := client.DeleteProjectsProjectIdDatabasesNameWithResponse(ctx, project.Id, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.StatusCode() != http.StatusOK {
+		t.Errorf("delete database: status %d", deleted.StatusCode())
+	}
+
+	missing, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, project.Id, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !api.IsNotFound(api.NewError(missing.HTTPResponse, missing.Body)) || missing.JSON404 == nil {
+		t.Errorf("deleted database: status %d, want 404", missing.StatusCode())
+	}
+
+	removed, err := client.DeleteProjectWithResponse(ctx, project.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed.StatusCode() != http.StatusOK {
+		t.Errorf("delete project: status %d", removed.StatusCode())
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{Tokens: []string{"secret"}}
+
+	anonymous := newTestServer(t, opts)
+	resp, err := anonymous.GetProjectsWithResponse(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.JSON401 == nil {
+		t.Errorf("anonymous request: status %d, want 401 with problem details", resp.StatusCode())
+	}
+
+	authenticated := newTestServer(t, opts, api.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer secret")
+		return nil
+	}))
+	resp, err = authenticated.GetProjectsWithResponse(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("authenticated request: status %d, want 200", resp.StatusCode())
+	}
+}
+
+func TestServerSnapshots(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID := created.JSON201.Id
+	if _, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "before-migration"
+	snap, err := client.PostProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx, projectID, "dev", api.CreateSnapshotRequest{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.JSON201 == nil || snap.JSON201.Status != api.SnapshotReady || snap.JSON201.Database != "dev" || snap.JSON201.CreatedAt.IsZero() {
+		t.Fatalf("create snapshot: status %d, body %s", snap.StatusCode(), snap.Body)
+	}
+
+	duplicate, err := client.PostProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx, projectID, "dev", api.CreateSnapshotRequest{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplicate.JSON409 == nil {
+		t.Errorf("duplicate snapshot: status %d, want 409", duplicate.StatusCode())
+	}
+
+	generated, err := client.PostProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx, projectID, "dev", api.CreateSnapshotRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated.JSON201 == nil || !strings.HasPrefix(generated.JSON201.Name, "dev-") {
+		t.Errorf("snapshot without a name: status %d, body %s", generated.StatusCode(), generated.Body)
+	}
+
+	listed, err := client.GetProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx, projectID, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed.JSON200 == nil || len(*listed.JSON200) != 2 || (*listed.JSON200)[0].Name != name {
+		t.Errorf("list snapshots: status %d, body %s", listed.StatusCode(), listed.Body)
+	}
+
+	fromSnapshot, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "restored", FromSnapshot: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromSnapshot.JSON201 == nil {
+		t.Errorf("create database from snapshot: status %d, body %s", fromSnapshot.StatusCode(), fromSnapshot.Body)
+	}
+	source := "dev"
+	fromDatabase, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "feature-x", FromDatabase: &source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromDatabase.JSON201 == nil {
+		t.Errorf("create database from database: status %d, body %s", fromDatabase.StatusCode(), fromDatabase.Body)
+	}
+	missing := "nope"
+	fromMissing, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "other", FromSnapshot: &missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromMissing.JSON404 == nil {
+		t.Errorf("create database from a missing snapshot: status %d, want 404", fromMissing.StatusCode())
+	}
+	both, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "other", FromSnapshot: &name, FromDatabase: &source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if both.JSON400 == nil {
+		t.Errorf("create database from a snapshot and a database: status %d, want 400", both.StatusCode())
+	}
+
+	// Snapshots are deleted through the database they were taken of
+	wrongDB, err := client.DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse(ctx, projectID, "restored", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrongDB.JSON404 == nil {
+		t.Errorf("delete snapshot of another database: status %d, want 404", wrongDB.StatusCode())
+	}
+	deleted, err := client.DeleteProjectsProjectIdDatabasesNameSnapshotsSnapshotWithResponse(ctx, projectID, "dev", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.StatusCode() != http.StatusOK {
+		t.Errorf("delete snapshot: status %d", deleted.StatusCode())
+	}
+
+	// Deleting the database deletes its remaining snapshot, freeing the name
+	if _, err := client.DeleteProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, "dev"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+	listed, err = client.GetProjectsProjectIdDatabasesNameSnapshotsWithResponse(ctx, projectID, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed.JSON200 == nil || len(*listed.JSON200) != 0 {
+		t.Errorf("snapshots of the recreated database = %s, want none", listed.Body)
+	}
+}
+
+func TestServerStopStart(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID := created.JSON201.Id
+	if _, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		stopped, err := client.PostProjectsProjectIdDatabasesNameStopWithResponse(ctx, projectID, "dev", api.StopDatabaseRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stopped.JSON200 == nil || stopped.JSON200.Status != api.Stopped || stopped.JSON200.StoppedAt == nil {
+			t.Fatalf("stop database: status %d, body %s", stopped.StatusCode(), stopped.Body)
+		}
+	}
+
+	shown, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.JSON200 == nil || shown.JSON200.Status != api.Stopped {
+		t.Errorf("stopped database: status %d, body %s", shown.StatusCode(), shown.Body)
+	}
+
+	started, err := client.PostProjectsProjectIdDatabasesNameStartWithResponse(ctx, projectID, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.JSON200 == nil || started.JSON200.Status != api.Running || started.JSON200.StoppedAt != nil {
+		t.Errorf("start database: status %d, body %s", started.StatusCode(), started.Body)
+	}
+
+	missing, err := client.PostProjectsProjectIdDatabasesNameStopWithResponse(ctx, projectID, "missing", api.StopDatabaseRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing.JSON404 == nil {
+		t.Errorf("stop missing database: status %d, want 404", missing.StatusCode())
+	}
+}
+
+func TestServerRefreshProject(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID := created.JSON201.Id
+	if v := created.JSON201.DataVersion; v == nil || *v != 1 {
+		t.Errorf("new project data version = %v, want 1", v)
+	}
+	old, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := old.JSON201.DataVersion; v == nil || *v != 1 {
+		t.Errorf("database data version = %v, want 1", v)
+	}
+
+	// Without a backup there is nothing to refresh from
+	none, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, projectID, api.RefreshProjectRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if none.JSON400 == nil {
+		t.Errorf("refresh without backup: status %d, want 400", none.StatusCode())
+	}
+	invalid := "https://example.com/billing.dump"
+	bad, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, projectID, api.RefreshProjectRequest{BackupLocation: &invalid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bad.JSON400 == nil {
+		t.Errorf("refresh from a non-S3 URL: status %d, want 400", bad.StatusCode())
+	}
+
+	location := "s3://backups/billing-2.dump"
+	refreshed, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, projectID, api.RefreshProjectRequest{BackupLocation: &location})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.JSON200 == nil {
+		t.Fatalf("refresh: status %d, body %s", refreshed.StatusCode(), refreshed.Body)
+	}
+	result := refreshed.JSON200
+	if result.PreviousDataVersion != 1 || result.DataVersion != 2 || result.Project.BackupLocation != location || result.Project.DataRefreshedAt == nil {
+		t.Errorf("refresh = %+v, want version 1 to 2 from %s", result, location)
+	}
+
+	// Refreshing again reads the same backup
+	again, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, projectID, api.RefreshProjectRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.JSON200 == nil || again.JSON200.DataVersion != 3 || again.JSON200.Project.BackupLocation != location {
+		t.Errorf("second refresh: status %d, body %s", again.StatusCode(), again.Body)
+	}
+
+	created2, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := created2.JSON201.DataVersion; v == nil || *v != 3 {
+		t.Errorf("new database data version = %v, want 3", v)
+	}
+	kept, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := kept.JSON200.DataVersion; v == nil || *v != 1 {
+		t.Errorf("existing database data version = %v, want 1", v)
+	}
+	reset, err := client.PostProjectsProjectIdDatabasesNameResetWithResponse(ctx, projectID, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := reset.JSON200.DataVersion; v == nil || *v != 3 {
+		t.Errorf("reset database data version = %v, want 3", v)
+	}
+
+	missing, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, "missing", api.RefreshProjectRequest{BackupLocation: &location})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing.JSON404 == nil {
+		t.Errorf("refresh missing project: status %d, want 404", missing.StatusCode())
+	}
+}
+
+func TestServerMaskingPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	invalid := "columns:\n  email: hash\n"
+	bad, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16", MaskingPolicy: &invalid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bad.JSON400 == nil || !strings.Contains(*bad.JSON400.Detail, `invalid column "email"`) {
+		t.Errorf("invalid policy: status %d, body %s", bad.StatusCode(), bad.Body)
+	}
+
+	policy := "columns:\n  users.email: {faker: email}\n"
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16", MaskingPolicy: &policy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.JSON201 == nil {
+		t.Fatalf("create: status %d, body %s", created.StatusCode(), created.Body)
+	}
+	projectID := created.JSON201.Id
+	shown, err := client.GetProjectsProjectIdWithResponse(ctx, projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := shown.JSON200.MaskingPolicy; p == nil || *p != policy {
+		t.Errorf("masking policy = %v, want %q", p, policy)
+	}
+
+	location := "s3://backups/billing.dump"
+	refreshed, err := client.PostProjectsProjectIdRefreshWithResponse(ctx, projectID, api.RefreshProjectRequest{BackupLocation: &location, MaskingPolicy: &invalid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.JSON400 == nil {
+		t.Errorf("refresh with an invalid policy: status %d, want 400", refreshed.StatusCode())
+	}
+
+	// An empty policy removes it
+	empty := ""
+	refreshed, err = client.PostProjectsProjectIdRefreshWithResponse(ctx, projectID, api.RefreshProjectRequest{BackupLocation: &location, MaskingPolicy: &empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.JSON200 == nil || refreshed.JSON200.Project.MaskingPolicy != nil {
+		t.Errorf("refresh removing the policy: status %d, body %s", refreshed.StatusCode(), refreshed.Body)
+	}
+}
+
+func TestServerReap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	for _, action := range []ExpiredAction{DeleteExpired, StopExpired} {
+		t.Run(string(action), func(t *testing.T) {
+			srv := New(NewMemoryStore(), NoopProvisioner{}, Options{Now: clock, ExpiredAction: action})
+			ts := httptest.NewServer(srv.Handler())
+			defer ts.Close()
+			client, err := api.NewClientWithResponses(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+				Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			projectID := created.JSON201.Id
+
+			hour := int64(3600)
+			for _, name := range []string{"short", "extended", "forever"} {
+				req := api.CreateDatabaseRequest{Name: name}
+				if name != "forever" {
+					req.TtlSeconds = &hour
+				}
+				db, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if db.JSON201 == nil {
+					t.Fatalf("create database %s: status %d, body %s", name, db.StatusCode(), db.Body)
+				}
+				if name != "forever" && (db.JSON201.ExpiresAt == nil || !db.JSON201.ExpiresAt.Equal(now.Add(time.Hour))) {
+					t.Errorf("database %s expires at %v, want %v", name, db.JSON201.ExpiresAt, now.Add(time.Hour))
+				}
+			}
+
+			extended, err := client.PostProjectsProjectIdDatabasesNameExtendWithResponse(ctx, projectID, "extended", api.ExtendDatabaseRequest{TtlSeconds: 24 * hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if extended.JSON200 == nil || !extended.JSON200.ExpiresAt.Equal(now.Add(25*time.Hour)) {
+				t.Errorf("extend: status %d, body %s", extended.StatusCode(), extended.Body)
+			}
+			tooShort, err := client.PostProjectsProjectIdDatabasesNameExtendWithResponse(ctx, projectID, "extended", api.ExtendDatabaseRequest{TtlSeconds: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tooShort.JSON400 == nil {
+				t.Errorf("extend by a second: status %d, want 400", tooShort.StatusCode())
+			}
+
+			if reaped, err := srv.Reap(ctx); err != nil || len(reaped) != 0 {
+				t.Errorf("reap before expiry = %v, %v, want nothing", reaped, err)
+			}
+
+			now = now.Add(2 * time.Hour)
+			reaped, err := srv.Reap(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reaped) != 1 || reaped[0].Name != "short" {
+				t.Fatalf("reaped %+v, want short", reaped)
+			}
+
+			short, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, "short")
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch action {
+			case DeleteExpired:
+				if short.JSON404 == nil {
+					t.Errorf("expired database: status %d, want 404", short.StatusCode())
+				}
+			case StopExpired:
+				if short.JSON200 == nil || short.JSON200.Status != api.Stopped || short.JSON200.ExpiresAt != nil {
+					t.Errorf("expired database: status %d, body %s, want stopped without expiry", short.StatusCode(), short.Body)
+				}
+			}
+			if reaped, err := srv.Reap(ctx); err != nil || len(reaped) != 0 {
+				t.Errorf("second reap = %v, %v, want nothing", reaped, err)
+			}
+
+			// reap looks at the database again before acting, so one that
+			// was extended after it was listed is kept
+			project, err := srv.store.GetProject(ctx, projectID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok, err := srv.reap(ctx, project, "extended", now); err != nil || ok {
+				t.Errorf("reap of a database extended since = %v, %v, want nothing", ok, err)
+			}
+
+			// Stopping leaves databases that are already stopped alone
+			paused, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "paused", TtlSeconds: &hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if paused.JSON201 == nil {
+				t.Fatalf("create database paused: status %d, body %s", paused.StatusCode(), paused.Body)
+			}
+			if _, err := client.PostProjectsProjectIdDatabasesNameStopWithResponse(ctx, projectID, "paused", api.StopDatabaseRequest{}); err != nil {
+				t.Fatal(err)
+			}
+			now = now.Add(2 * time.Hour)
+			reaped, err = srv.Reap(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stopped, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, "paused")
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch action {
+			case DeleteExpired:
+				if len(reaped) != 1 || reaped[0].Name != "paused" || stopped.JSON404 == nil {
+					t.Errorf("reaped %+v, status %d, want the stopped database deleted", reaped, stopped.StatusCode())
+				}
+			case StopExpired:
+				if len(reaped) != 0 || stopped.JSON200 == nil || stopped.JSON200.ExpiresAt == nil {
+					t.Errorf("reaped %+v, body %s, want the stopped database left alone", reaped, stopped.Body)
+				}
+			}
+		})
+	}
+}
+
+// stallingProvisioner stops databases only once release is closed, after
+// telling stopping.
+type stallingProvisioner struct {
+	NoopProvisioner
+	stopping chan struct{}
+	release  chan struct{}
+}
+
+func (p stallingProvisioner) Stop(ctx context.Context, project api.Project, db *api.Database) error {
+	close(p.stopping)
+	<-p.release
+	return p.NoopProvisioner.Stop(ctx, project, db)
+}
+
+func TestServerReapConcurrentExtend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provisioner := stallingProvisioner{stopping: make(chan struct{}), release: make(chan struct{})}
+	srv := New(NewMemoryStore(), provisioner, Options{Now: func() time.Time { return now }, ExpiredAction: StopExpired})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	client, err := api.NewClientWithResponses(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectID := created.JSON201.Id
+	hour := int64(3600)
+	db, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, projectID, api.CreateDatabaseRequest{Name: "dev", TtlSeconds: &hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.JSON201 == nil {
+		t.Fatalf("create database: status %d, body %s", db.StatusCode(), db.Body)
+	}
+	now = now.Add(2 * time.Hour)
+
+	reaped := make(chan []api.Database)
+	go func() {
+		dbs, _ := srv.Reap(ctx)
+		reaped <- dbs
+	}()
+	<-provisioner.stopping
+
+	// The extend lands while the reaper is stopping the database, and
+	// must wait for it rather than write the running database back
+	extended := make(chan *api.PostProjectsProjectIdDatabasesNameExtendResponse)
+	go func() {
+		resp, err := client.PostProjectsProjectIdDatabasesNameExtendWithResponse(ctx, projectID, "dev", api.ExtendDatabaseRequest{TtlSeconds: hour})
+		if err != nil {
+			t.Error(err)
+		}
+		extended <- resp
+	}()
+	select {
+	case <-extended:
+		t.Fatal("extend finished while the reaper was stopping the database")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(provisioner.release)
+
+	if dbs := <-reaped; len(dbs) != 1 || dbs[0].Name != "dev" {
+		t.Errorf("reaped %+v, want dev", dbs)
+	}
+	if resp := <-extended; resp == nil || resp.JSON200 == nil {
+		t.Fatal("extend failed")
+	}
+	shown, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.JSON200 == nil || shown.JSON200.Status != api.Stopped || shown.JSON200.StoppedAt == nil || shown.JSON200.ExpiresAt == nil {
+		t.Errorf("database: status %d, body %s, want stopped with the new expiry", shown.StatusCode(), shown.Body)
+	}
+}
+
+func TestParseExpiredAction(t *testing.T) {
+	for _, s := range []string{"delete", "stop"} {
+		if a, err := ParseExpiredAction(s); err != nil || string(a) != s {
+			t.Errorf("ParseExpiredAction(%q) = %q, %v", s, a, err)
+		}
+	}
+	if _, err := ParseExpiredAction("archive"); err == nil {
+		t.Error("ParseExpiredAction accepted archive")
+	}
+}
+
+type fakeUploader struct {
+	key     string
+	size    int64
+	expires time.Duration
+}
+
+func (u *fakeUploader) PresignPut(ctx context.Context, key string, size int64, expires time.Duration) (string, string, error) {
+	u.key, u.size, u.expires = key, size, expires
+	return "https://storage.test/" + key + "?signature=x", "s3://backups/" + key, nil
+}
+
+func TestServerBackupUploads(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	disabled := newTestServer(t, Options{})
+	resp, err := disabled.PostBackupsUploadsWithResponse(ctx, api.CreateBackupUploadRequest{Filename: "billing.dump", SizeBytes: 10, Format: api.BackupFormatCustom})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.JSON400 == nil {
+		t.Errorf("upload without uploader: status %d, want 400", resp.StatusCode())
+	}
+
+	uploader := &fakeUploader{}
+	client := newTestServer(t, Options{Uploader: uploader, Now: func() time.Time { return now }})
+	for _, req := range []api.CreateBackupUploadRequest{
+		{Filename: "../billing.dump", SizeBytes: 10, Format: api.BackupFormatCustom},
+		{Filename: "..", SizeBytes: 10, Format: api.BackupFormatCustom},
+		{Filename: "billing.dump", SizeBytes: 0, Format: api.BackupFormatCustom},
+		{Filename: "billing.dump", SizeBytes: 10, Format: "tar"},
+	} {
+		resp, err := client.PostBackupsUploadsWithResponse(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.JSON400 == nil {
+			t.Errorf("upload %+v: status %d, want 400", req, resp.StatusCode())
+		}
+	}
+
+	resp, err = client.PostBackupsUploadsWithResponse(ctx, api.CreateBackupUploadRequest{Filename: "billing.dump", SizeBytes: 10, Format: api.BackupFormatCustom})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.JSON201 == nil {
+		t.Fatalf("upload: status %d, body %s", resp.StatusCode(), resp.Body)
+	}
+	if !strings.HasPrefix(uploader.key, "uploads/") || !strings.HasSuffix(uploader.key, "/billing.dump") || uploader.size != 10 {
+		t.Errorf("presigned key %q for %d bytes, want uploads/<id>/billing.dump for 10", uploader.key, uploader.size)
+	}
+	upload := resp.JSON201
+	if upload.Location != "s3://backups/"+uploader.key || !upload.ExpiresAt.Equal(now.Add(uploader.expires)) {
+		t.Errorf("upload = %+v, want the uploader's location, expiring with the URL", upload)
+	}
+}
+
+func TestServerDatabaseTypes(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "shop", DbType: api.MySQL, DbVersion: "8.4.2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.JSON201 == nil {
+		t.Fatalf("create project: %v", api.NewError(created.HTTPResponse, created.Body))
+	}
+	db, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, created.JSON201.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.JSON201 == nil || db.JSON201.Port == nil || *db.JSON201.Port != 3306 {
+		t.Errorf("create database: status %d, body %s, want port 3306", db.StatusCode(), db.Body)
+	}
+
+	created, err = client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{
+		Owner: "alice", Name: "sessions", DbType: api.Redis, DbVersion: "7.2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.JSON201 == nil {
+		t.Fatalf("create project: %v", api.NewError(created.HTTPResponse, created.Body))
+	}
+	db, err = client.PostProjectsProjectIdDatabasesWithResponse(ctx, created.JSON201.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := db.JSON201; r == nil || *r.Port != 6379 || *r.Username != "default" || *r.Database != "0" {
+		t.Errorf("create database: status %d, body %s, want port 6379 and the default user", db.StatusCode(), db.Body)
+	}
+
+	for _, req := range []api.CreateProjectRequest{
+		{Owner: "alice", Name: "ledger", DbType: "oracle", DbVersion: "23"},
+		{Owner: "alice", Name: "ledger", DbType: api.Postgres, DbVersion: "9.6"},
+	} {
+		resp, err := client.PostProjectsWithResponse(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.JSON400 == nil || resp.JSON400.Detail == nil || !strings.HasPrefix(*resp.JSON400.Detail, "Invalid database: ") {
+			t.Errorf("%s %s: status %d, body %s, want 400", req.DbType, req.DbVersion, resp.StatusCode(), resp.Body)
+		}
+	}
+}
+
+func TestServerCatalog(t *testing.T) {
+	ctx := context.Background()
+	client := newTestServer(t, Options{})
+
+	resp, err := client.GetCatalogWithResponse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.JSON200 == nil || len(resp.JSON200.Engines) != len(engine.All()) {
+		t.Fatalf("get catalog: status %d, body %s", resp.StatusCode(), resp.Body)
+	}
+	if e := resp.JSON200.Engines[0]; e.Type != api.Postgres || e.DefaultPort != 5432 || e.Versions[0] != "17" {
+		t.Errorf("first engine = %+v, want PostgreSQL", e)
+	}
+
+	// The local provisioner runs only PostgreSQL
+	ts := httptest.NewServer(New(NewMemoryStore(), &LocalProvisioner{Dir: t.TempDir()}, Options{}).Handler())
+	defer ts.Close()
+	local, err := api.NewClientWithResponses(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = local.GetCatalogWithResponse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.JSON200 == nil || len(resp.JSON200.Engines) != 1 || resp.JSON200.Engines[0].Type != api.Postgres {
+		t.Errorf("get catalog of a local server: status %d, body %s", resp.StatusCode(), resp.Body)
+	}
+	created, err := local.PostProjectsWithResponse(ctx, api.CreateProjectRequest{Owner: "alice", Name: "shop", DbType: api.MySQL, DbVersion: "8.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.JSON400 == nil || *created.JSON400.Detail != `Invalid database: unknown database type "mysql" (want postgres)` {
+		t.Errorf("create mysql project on a local server: status %d, body %s", created.StatusCode(), created.Body)
+	}
+}
+
+func TestServerLocalProvisionerErrors(t *testing.T) {
+	ctx := context.Background()
+	p := &LocalProvisioner{Dir: t.TempDir(), BinDir: fakeBinDir(t), Backups: fakeBackups{}}
+	defer p.Close()
+	ts := httptest.NewServer(New(NewMemoryStore(), p, Options{}).Handler())
+	defer ts.Close()
+	client, err := api.NewClientWithResponses(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the installed version is advertised, so projects of others are
+	// refused up front
+	old, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{Owner: "alice", Name: "legacy", DbType: api.Postgres, DbVersion: "15"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.JSON400 == nil {
+		t.Errorf("create PostgreSQL 15 project on a local server: status %d, body %s", old.StatusCode(), old.Body)
+	}
+
+	location := "s3://company-backups/billing.dump"
+	created, err := client.PostProjectsWithResponse(ctx, api.CreateProjectRequest{Owner: "alice", Name: "billing", DbType: api.Postgres, DbVersion: "16", BackupLocation: &location})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.JSON201 == nil {
+		t.Fatalf("create project: status %d, body %s", created.StatusCode(), created.Body)
+	}
+	db, err := client.PostProjectsProjectIdDatabasesWithResponse(ctx, created.JSON201.Id, api.CreateDatabaseRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "backup s3://company-backups/billing.dump of project billing is not one uploaded to this server"
+	if db.JSON400 == nil || db.JSON400.Detail == nil || *db.JSON400.Detail != want {
+		t.Errorf("create database: status %d, body %s, want 400 with %q", db.StatusCode(), db.Body, want)
+	}
+}
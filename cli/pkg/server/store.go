@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+// ErrNotFound is returned by a Store for a project, database or snapshot
+// that does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Store persists projects, databases and snapshots. Databases and
+// snapshots are keyed by their Project field and name; deleting a project
+// deletes its databases and snapshots too. Lists are sorted by name, except
+// for snapshots, which are listed oldest first.
+type Store interface {
+	CreateProject(ctx context.Context, project api.Project) error
+	GetProject(ctx context.Context, id string) (api.Project, error)
+	ListProjects(ctx context.Context) ([]api.Project, error)
+	// UpdateProject replaces a project, which must exist.
+	UpdateProject(ctx context.Context, project api.Project) error
+	DeleteProject(ctx context.Context, id string) error
+
+	PutDatabase(ctx context.Context, db api.Database) error
+	GetDatabase(ctx context.Context, projectID, name string) (api.Database, error)
+	ListDatabases(ctx context.Context, projectID string) ([]api.Database, error)
+	DeleteDatabase(ctx context.Context, projectID, name string) error
+
+	PutSnapshot(ctx context.Context, snap api.Snapshot) error
+	GetSnapshot(ctx context.Context, projectID, name string) (api.Snapshot, error)
+	ListSnapshots(ctx context.Context, projectID string) ([]api.Snapshot, error)
+	DeleteSnapshot(ctx context.Context, projectID, name string) error
+
+	Close() error
+}
+
+// MemoryStore is a Store that keeps everything in memory, for tests and
+// throwaway servers. Values are stored encoded, like BoltStore does, so
+// callers never share them.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	projects  map[string][]byte
+	databases map[string]map[string][]byte
+	snapshots map[string]map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		projects:  map[string][]byte{},
+		databases: map[string]map[string][]byte{},
+		snapshots: map[string]map[string][]byte{},
+	}
+}
+
+func (s *MemoryStore) CreateProject(ctx context.Context, project api.Project) error {
+	data, err := json.Marshal(project)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects[project.Id] = data
+	return nil
+}
+
+func (s *MemoryStore) GetProject(ctx context.Context, id string) (api.Project, error) {
+	s.mu.RLock()
+	data, ok := s.projects[id]
+	s.mu.RUnlock()
+	var project api.Project
+	if !ok {
+		return project, ErrNotFound
+	}
+	return project, json.Unmarshal(data, &project)
+}
+
+func (s *MemoryStore) ListProjects(ctx context.Context) ([]api.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	projects := make([]api.Project, 0, len(s.projects))
+	for _, data := range s.projects {
+		var project api.Project
+		if err := json.Unmarshal(data, &project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	sortProjects(projects)
+	return projects, nil
+}
+
+func (s *MemoryStore) UpdateProject(ctx context.Context, project api.Project) error {
+	data, err := json.Marshal(project)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.projects[project.Id]; !ok {
+		return ErrNotFound
+	}
+	s.projects[project.Id] = data
+	return nil
+}
+
+func (s *MemoryStore) DeleteProject(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.projects[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.projects, id)
+	delete(s.databases, id)
+	delete(s.snapshots, id)
+	return nil
+}
+
+func (s *MemoryStore) PutDatabase(ctx context.Context, db api.Database) error {
+	data, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	projectID := projectOf(db)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.databases[projectID] == nil {
+		s.databases[projectID] = map[string][]byte{}
+	}
+	s.databases[projectID][db.Name] = data
+	return nil
+}
+
+func (s *MemoryStore) GetDatabase(ctx context.Context, projectID, name string) (api.Database, error) {
+	s.mu.RLock()
+	data, ok := s.databases[projectID][name]
+	s.mu.RUnlock()
+	var db api.Database
+	if !ok {
+		return db, ErrNotFound
+	}
+	return db, json.Unmarshal(data, &db)
+}
+
+func (s *MemoryStore) ListDatabases(ctx context.Context, projectID string) ([]api.Database, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	databases := make([]api.Database, 0, len(s.databases[projectID]))
+	for _, data := range s.databases[projectID] {
+		var db api.Database
+		if err := json.Unmarshal(data, &db); err != nil {
+			return nil, err
+		}
+		databases = append(databases, db)
+	}
+	sortDatabases(databases)
+	return databases, nil
+}
+
+func (s *MemoryStore) DeleteDatabase(ctx context.Context, projectID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.databases[projectID][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.databases[projectID], name)
+	return nil
+}
+
+func (s *MemoryStore) PutSnapshot(ctx context.Context, snap api.Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	projectID := snapshotProjectOf(snap)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snapshots[projectID] == nil {
+		s.snapshots[projectID] = map[string][]byte{}
+	}
+	s.snapshots[projectID][snap.Name] = data
+	return nil
+}
+
+func (s *MemoryStore) GetSnapshot(ctx context.Context, projectID, name string) (api.Snapshot, error) {
+	s.mu.RLock()
+	data, ok := s.snapshots[projectID][name]
+	s.mu.RUnlock()
+	var snap api.Snapshot
+	if !ok {
+		return snap, ErrNotFound
+	}
+	return snap, json.Unmarshal(data, &snap)
+}
+
+func (s *MemoryStore) ListSnapshots(ctx context.Context, projectID string) ([]api.Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshots := make([]api.Snapshot, 0, len(s.snapshots[projectID]))
+	for _, data := range s.snapshots[projectID] {
+		var snap api.Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snap)
+	}
+	sortSnapshots(snapshots)
+	return snapshots, nil
+}
+
+func (s *MemoryStore) DeleteSnapshot(ctx context.Context, projectID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.snapshots[projectID][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.snapshots[projectID], name)
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func projectOf(db api.Database) string {
+	if db.Project == nil {
+		return ""
+	}
+	return *db.Project
+}
+
+func snapshotProjectOf(snap api.Snapshot) string {
+	if snap.Project == nil {
+		return ""
+	}
+	return *snap.Project
+}
+
+func sortProjects(projects []api.Project) {
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].Id < projects[j].Id
+	})
+}
+
+func sortDatabases(databases []api.Database) {
+	sort.Slice(databases, func(i, j int) bool { return databases[i].Name < databases[j].Name })
+}
+
+func sortSnapshots(snapshots []api.Snapshot) {
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+		}
+		return snapshots[i].Name < snapshots[j].Name
+	})
+}
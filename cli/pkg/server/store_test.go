@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"bolt": func(t *testing.T) Store {
+			s, err := OpenBoltStore(filepath.Join(t.TempDir(), "devdb.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+			testStore(t, s)
+		})
+	}
+}
+
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	for _, p := range []api.Project{
+		{Id: "p-2", Name: "search", Owner: "alice"},
+		{Id: "p-1", Name: "billing", Owner: "alice"},
+	} {
+		if err := s.CreateProject(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projects, err := s.ListProjects(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].Name != "billing" || projects[1].Name != "search" {
+		t.Errorf("ListProjects() = %+v, want billing and search", projects)
+	}
+	if _, err := s.GetProject(ctx, "p-3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetProject(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateProject(ctx, api.Project{Id: "p-2", Name: "search", Owner: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.GetProject(ctx, "p-2"); err != nil || p.Owner != "bob" {
+		t.Errorf("GetProject(updated) = %+v, %v, want owner bob", p, err)
+	}
+	if err := s.UpdateProject(ctx, api.Project{Id: "p-3"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateProject(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Databases of a project must not show up for a project whose ID
+	// is a prefix of it
+	p1, p10 := "p-1", "p-10"
+	for _, db := range []api.Database{
+		{Name: "b", Project: &p1, Status: api.Creating},
+		{Name: "a", Project: &p1, Status: api.Running},
+		{Name: "c", Project: &p10, Status: api.Running},
+	} {
+		if err := s.PutDatabase(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	databases, err := s.ListDatabases(ctx, "p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(databases) != 2 || databases[0].Name != "a" || databases[1].Name != "b" {
+		t.Errorf("ListDatabases() = %+v, want a and b", databases)
+	}
+
+	db, err := s.GetDatabase(ctx, "p-1", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Status = api.Running
+	if err := s.PutDatabase(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if db, _ := s.GetDatabase(ctx, "p-1", "b"); db.Status != api.Running {
+		t.Errorf("status after update = %s, want running", db.Status)
+	}
+
+	if err := s.DeleteDatabase(ctx, "p-1", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDatabase(ctx, "p-1", "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteDatabase(missing) error = %v, want ErrNotFound", err)
+	}
+
+	now := time.Now().UTC()
+	for _, snap := range []api.Snapshot{
+		{Name: "later", Database: "b", Project: &p1, Status: api.SnapshotReady, CreatedAt: now},
+		{Name: "earlier", Database: "b", Project: &p1, Status: api.SnapshotReady, CreatedAt: now.Add(-time.Hour)},
+		{Name: "other", Database: "c", Project: &p10, Status: api.SnapshotReady, CreatedAt: now},
+	} {
+		if err := s.PutSnapshot(ctx, snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := s.ListSnapshots(ctx, "p-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "earlier" || snapshots[1].Name != "later" {
+		t.Errorf("ListSnapshots() = %+v, want earlier and later", snapshots)
+	}
+	if snap, err := s.GetSnapshot(ctx, "p-1", "later"); err != nil || !snap.CreatedAt.Equal(now) {
+		t.Errorf("GetSnapshot() = %+v, %v", snap, err)
+	}
+	if err := s.DeleteSnapshot(ctx, "p-1", "earlier"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetSnapshot(ctx, "p-1", "earlier"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSnapshot(deleted) error = %v, want ErrNotFound", err)
+	}
+
+	if err := s.DeleteProject(ctx, "p-1"); err != nil {
+		t.Fatal(err)
+	}
+	if databases, _ := s.ListDatabases(ctx, "p-1"); len(databases) != 0 {
+		t.Errorf("databases left after deleting the project: %+v", databases)
+	}
+	if snapshots, _ := s.ListSnapshots(ctx, "p-1"); len(snapshots) != 0 {
+		t.Errorf("snapshots left after deleting the project: %+v", snapshots)
+	}
+	if snapshots, _ := s.ListSnapshots(ctx, "p-10"); len(snapshots) != 1 {
+		t.Errorf("snapshots of p-10 = %+v, want other", snapshots)
+	}
+	if databases, _ := s.ListDatabases(ctx, "p-10"); len(databases) != 1 {
+		t.Errorf("databases of p-10 = %+v, want c", databases)
+	}
+	if err := s.DeleteProject(ctx, "p-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteProject(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBoltStorePersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "devdb.db")
+
+	s, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateProject(ctx, api.Project{Id: "p-1", Name: "billing"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if p, err := s.GetProject(ctx, "p-1"); err != nil || p.Name != "billing" {
+		t.Errorf("GetProject() = %+v, %v after reopening", p, err)
+	}
+}
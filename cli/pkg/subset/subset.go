@@ -0,0 +1,472 @@
+// Package subset cuts a smaller, referentially intact slice out of a
+// pg_dump backup, without a database, so the first databases of a project
+// do not have to restore all of production.
+//
+// What to keep is given by a spec:
+//
+//	roots:
+//	  users: {where: "country = 'NL' AND created_at >= '2024-01-01'", percent: 10}
+//	  orders: {percent: 5}
+//	keep: [countries, currencies]
+//	children: true
+//	seed: any-text
+//
+// Roots are the tables the subset starts from: the rows that match where,
+// or a deterministic sample of percent of them, or both. keep lists tables
+// that are copied whole, such as lookup tables. Following the foreign keys
+// in the dump's schema, the subset then adds:
+//
+//   - every row a kept row references, however indirectly, so that the
+//     subset can be restored with its constraints; and
+//   - unless children is false, the rows referencing the root rows, and
+//     the rows referencing those, e.g. the orders and order lines of the
+//     users that were picked.
+//
+// The rows of other tables are left out. Tables are table or schema.table;
+// without a schema, public is assumed.
+//
+// Conditions are the part of SQL that can be evaluated on dumped values:
+// comparisons (=, <>, <, <=, >, >=), IS [NOT] NULL, [NOT] IN, [NOT] LIKE and
+// [NOT] BETWEEN, combined with AND, OR, NOT and parentheses. Values compare
+// as numbers when both sides are numbers and as text otherwise, which also
+// orders ISO dates and timestamps.
+package subset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/meido-ai/devdb/cli/pkg/pgdump"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec says what to keep of a dump.
+type Spec struct {
+	// Roots maps schema.table to the rows of it the subset starts from.
+	Roots map[string]Root
+	// Keep are the schema.table names of tables copied whole.
+	Keep []string
+	// Children says whether the rows referencing root rows are kept.
+	Children bool
+	// Seed changes which rows percent samples.
+	Seed string
+}
+
+// Root selects the rows of a root table. Without Where and Percent, every
+// row is selected.
+type Root struct {
+	Where   string
+	Percent float64
+
+	where expr
+}
+
+// Load reads and parses the spec in a file.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse parses and checks a spec. Whether the tables and columns exist is
+// only known with the dump, in Subset.
+func Parse(data []byte) (*Spec, error) {
+	var doc struct {
+		Roots map[string]*struct {
+			Where   string   `yaml:"where"`
+			Percent *float64 `yaml:"percent"`
+		} `yaml:"roots"`
+		Keep     []string `yaml:"keep"`
+		Children *bool    `yaml:"children"`
+		Seed     string   `yaml:"seed"`
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid spec: %v", err)
+	}
+	if len(doc.Roots) == 0 {
+		return nil, fmt.Errorf("invalid spec: no roots to start from")
+	}
+
+	spec := &Spec{Roots: map[string]Root{}, Children: doc.Children == nil || *doc.Children, Seed: doc.Seed}
+	for name, r := range doc.Roots {
+		table, err := qualify(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := spec.Roots[table]; ok {
+			return nil, fmt.Errorf("root %s is listed twice", table)
+		}
+		var root Root
+		if r != nil {
+			root.Where = r.Where
+			if r.Percent != nil {
+				if *r.Percent <= 0 || *r.Percent > 100 {
+					return nil, fmt.Errorf("root %s: percent must be more than 0 and at most 100", name)
+				}
+				root.Percent = *r.Percent
+			}
+		}
+		if root.Where != "" {
+			if root.where, err = parseWhere(root.Where); err != nil {
+				return nil, fmt.Errorf("root %s: invalid where: %v", name, err)
+			}
+		}
+		spec.Roots[table] = root
+	}
+	for _, name := range doc.Keep {
+		table, err := qualify(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := spec.Roots[table]; ok {
+			return nil, fmt.Errorf("%s is both a root and kept whole", table)
+		}
+		spec.Keep = append(spec.Keep, table)
+	}
+	return spec, nil
+}
+
+// qualify turns table and schema.table into the latter.
+func qualify(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return "public." + name, nil
+	case 2:
+		return name, nil
+	}
+	return "", fmt.Errorf("invalid table %q: want table or schema.table", name)
+}
+
+func qualifiedName(t pgdump.Table) string {
+	if t.Schema == "" {
+		return "public." + t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// Result is what a subset kept of each table, in the order of the dump.
+type Result struct {
+	Tables []TableResult
+}
+
+// TableResult is what a subset kept of a table.
+type TableResult struct {
+	Table string
+	Rows  int
+	Kept  int
+}
+
+// Subset writes the subset of a plain-format dump to w. The dump is read
+// several times, once per level of foreign keys the subset follows; open
+// is called for each.
+func (s *Spec) Subset(open func() (io.ReadCloser, error), w io.Writer) (*Result, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	schema, err := pgdump.ReadSchema(r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.plan(schema)
+	if err != nil {
+		return nil, err
+	}
+	sub.open = open
+
+	// The first pass picks the roots; the following ones add the rows
+	// the kept rows reference, and their children, until nothing changes
+	sub.first = true
+	for {
+		sub.added = false
+		if err := sub.pass(io.Discard, sub.visit); err != nil {
+			return nil, err
+		}
+		sub.first = false
+		if !sub.added {
+			break
+		}
+	}
+
+	err = sub.pass(w, func(t *table, i int, row []pgdump.Value) bool { return t.kept.has(i) })
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, t := range sub.order {
+		result.Tables = append(result.Tables, TableResult{Table: t.name, Rows: t.rows, Kept: t.kept.count()})
+	}
+	return result, nil
+}
+
+// table is the state of a table with data during a subset.
+type table struct {
+	pgdump.Table
+	name    string
+	root    *Root
+	keepAll bool
+	rows    int
+	next    int
+
+	// kept are the indexes of the rows kept; expanded those whose
+	// children are kept too
+	kept, expanded bitset
+
+	// references are the foreign keys of the table, referencedBy those
+	// of other tables referencing it
+	references, referencedBy []*edge
+}
+
+// edge is a foreign key between two tables with data.
+type edge struct {
+	child, parent         *table
+	childCols, parentCols []int
+	// wanted are the keys of the parent rows kept child rows reference;
+	// reached the keys of expanded parent rows, whose children are kept
+	wanted, reached map[string]bool
+}
+
+type subsetter struct {
+	spec   *Spec
+	open   func() (io.ReadCloser, error)
+	tables map[string]*table
+	order  []*table
+	first  bool
+	added  bool
+}
+
+func (s *Spec) plan(schema *pgdump.Schema) (*subsetter, error) {
+	sub := &subsetter{spec: s, tables: map[string]*table{}}
+	for _, t := range schema.Tables {
+		name := qualifiedName(t)
+		if _, ok := sub.tables[name]; ok {
+			continue
+		}
+		tt := &table{Table: t, name: name}
+		sub.tables[name] = tt
+		sub.order = append(sub.order, tt)
+	}
+
+	for name, root := range s.Roots {
+		t, ok := sub.tables[name]
+		if !ok {
+			return nil, fmt.Errorf("root %s: the dump has no data for it", name)
+		}
+		root := root
+		if root.where != nil {
+			if err := root.where.bind(columnIndexes(t.Columns)); err != nil {
+				return nil, fmt.Errorf("root %s: %v", name, err)
+			}
+		}
+		t.root = &root
+	}
+	for _, name := range s.Keep {
+		t, ok := sub.tables[name]
+		if !ok {
+			return nil, fmt.Errorf("keep %s: the dump has no data for it", name)
+		}
+		t.keepAll = true
+	}
+
+	for _, fk := range schema.ForeignKeys {
+		child, parent := sub.tables[qualifiedName(fk.Table)], sub.tables[qualifiedName(fk.RefTable)]
+		if child == nil || parent == nil {
+			// Without data on one side there is nothing to keep consistent
+			continue
+		}
+		e := &edge{child: child, parent: parent, wanted: map[string]bool{}, reached: map[string]bool{}}
+		var ok bool
+		if e.childCols, ok = indexesOf(child.Columns, fk.Columns); !ok {
+			continue
+		}
+		if e.parentCols, ok = indexesOf(parent.Columns, fk.RefColumns); !ok {
+			continue
+		}
+		child.references = append(child.references, e)
+		parent.referencedBy = append(parent.referencedBy, e)
+	}
+	return sub, nil
+}
+
+func columnIndexes(columns []string) map[string]int {
+	indexes := make(map[string]int, len(columns))
+	for i, c := range columns {
+		indexes[c] = i
+	}
+	return indexes
+}
+
+func indexesOf(columns, names []string) ([]int, bool) {
+	all := columnIndexes(columns)
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		index, ok := all[name]
+		if !ok {
+			return nil, false
+		}
+		indexes[i] = index
+	}
+	return indexes, true
+}
+
+// pass reads the dump once, writing the rows keep returns true for to w.
+func (sub *subsetter) pass(w io.Writer, keep func(t *table, i int, row []pgdump.Value) bool) error {
+	r, err := sub.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, t := range sub.order {
+		t.next = 0
+	}
+	return pgdump.Rewrite(r, w, func(pt pgdump.Table) pgdump.RowFunc {
+		t := sub.tables[qualifiedName(pt)]
+		if t == nil {
+			return nil
+		}
+		return func(row []pgdump.Value) error {
+			i := t.next
+			t.next++
+			if !keep(t, i, row) {
+				return pgdump.SkipRow
+			}
+			return nil
+		}
+	})
+}
+
+// visit decides whether to keep a row during the passes that work out
+// the subset.
+func (sub *subsetter) visit(t *table, i int, row []pgdump.Value) bool {
+	if sub.first {
+		t.rows++
+		switch {
+		case t.keepAll:
+			sub.keep(t, i, row, false)
+		case t.root != nil && sub.selects(t, row):
+			sub.keep(t, i, row, sub.spec.Children)
+		}
+	}
+	if sub.spec.Children && !t.expanded.has(i) {
+		for _, e := range t.references {
+			if k, ok := key(row, e.childCols); ok && e.reached[k] {
+				sub.keep(t, i, row, true)
+				break
+			}
+		}
+	}
+	if !t.kept.has(i) {
+		for _, e := range t.referencedBy {
+			if k, ok := key(row, e.parentCols); ok && e.wanted[k] {
+				sub.keep(t, i, row, false)
+				break
+			}
+		}
+	}
+	return false
+}
+
+// selects reports whether a row of a root table is one of its roots.
+func (sub *subsetter) selects(t *table, row []pgdump.Value) bool {
+	if t.root.where != nil && t.root.where.eval(row) != triTrue {
+		return false
+	}
+	if t.root.Percent == 0 || t.root.Percent == 100 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(sub.spec.Seed))
+	h.Write([]byte{0})
+	h.Write([]byte(t.name))
+	h.Write([]byte{0})
+	h.Write([]byte(pgdump.EncodeRow(row)))
+	return float64(h.Sum64()%1000000) < t.root.Percent*10000
+}
+
+// keep keeps a row, and with expand its children, and records the keys
+// that make other rows kept.
+func (sub *subsetter) keep(t *table, i int, row []pgdump.Value, expand bool) {
+	if !t.kept.has(i) {
+		t.kept.set(i)
+		for _, e := range t.references {
+			if k, ok := key(row, e.childCols); ok && !e.wanted[k] {
+				e.wanted[k] = true
+				sub.added = true
+			}
+		}
+	}
+	if expand && !t.expanded.has(i) {
+		t.expanded.set(i)
+		for _, e := range t.referencedBy {
+			if k, ok := key(row, e.parentCols); ok && !e.reached[k] {
+				e.reached[k] = true
+				sub.added = true
+			}
+		}
+	}
+}
+
+// key joins the values of columns; rows with a NULL in them reference
+// nothing.
+func key(row []pgdump.Value, columns []int) (string, bool) {
+	if len(columns) == 1 {
+		v := row[columns[0]]
+		return v.Text, !v.Null
+	}
+	var b strings.Builder
+	for i, c := range columns {
+		if row[c].Null {
+			return "", false
+		}
+		if i > 0 {
+			b.WriteByte(0)
+		}
+		b.WriteString(row[c].Text)
+	}
+	return b.String(), true
+}
+
+type bitset []uint64
+
+func (b bitset) has(i int) bool {
+	return i/64 < len(b) && b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b *bitset) set(i int) {
+	for i/64 >= len(*b) {
+		*b = append(*b, 0)
+	}
+	(*b)[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) count() int {
+	n := 0
+	for _, w := range b {
+		for ; w != 0; w &= w - 1 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,243 @@
+package subset
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/pgdump"
+)
+
+const shopDump = `--
+-- PostgreSQL database dump
+--
+
+CREATE TABLE public.countries (code text NOT NULL);
+CREATE TABLE public.users (id integer NOT NULL, country text, referred_by integer);
+
+COPY public.countries (code) FROM stdin;
+NL
+DE
+FR
+\.
+
+COPY public.users (id, country, referred_by) FROM stdin;
+1	NL	\N
+2	DE	\N
+3	NL	2
+\.
+
+COPY public.orders (id, user_id) FROM stdin;
+10	1
+11	2
+12	3
+\.
+
+COPY public.order_lines (id, order_id, product_id) FROM stdin;
+100	10	1000
+101	11	1001
+102	12	1002
+\.
+
+COPY public.products (id) FROM stdin;
+1000
+1001
+1002
+1003
+\.
+
+COPY public.audit (id) FROM stdin;
+1
+2
+\.
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_country_fkey FOREIGN KEY (country) REFERENCES public.countries(code);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_referred_by_fkey FOREIGN KEY (referred_by) REFERENCES public.users(id);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id);
+
+ALTER TABLE ONLY public.order_lines
+    ADD CONSTRAINT order_lines_order_id_fkey FOREIGN KEY (order_id) REFERENCES public.orders(id);
+
+ALTER TABLE ONLY public.order_lines
+    ADD CONSTRAINT order_lines_product_id_fkey FOREIGN KEY (product_id) REFERENCES public.products(id);
+`
+
+func openString(dump string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(dump)), nil }
+}
+
+// keptIDs returns the first value of every row of each table in a dump.
+func keptIDs(t *testing.T, dump string) map[string]string {
+	t.Helper()
+	ids := map[string]string{}
+	err := pgdump.Rewrite(strings.NewReader(dump), io.Discard, func(table pgdump.Table) pgdump.RowFunc {
+		ids[table.Name] = ""
+		return func(row []pgdump.Value) error {
+			ids[table.Name] = strings.TrimSpace(ids[table.Name] + " " + row[0].Text)
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestSubset(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want map[string]string
+	}{
+		{
+			name: "children",
+			spec: "roots:\n  users: {where: \"country = 'NL'\"}\n",
+			want: map[string]string{
+				"countries": "NL DE",
+				// 2 referred 3, so it is kept, but without its orders
+				"users":       "1 2 3",
+				"orders":      "10 12",
+				"order_lines": "100 102",
+				"products":    "1000 1002",
+				"audit":       "",
+			},
+		},
+		{
+			name: "no children",
+			spec: "roots:\n  users: {where: \"country = 'NL'\"}\nchildren: false\n",
+			want: map[string]string{
+				"countries":   "NL DE",
+				"users":       "1 2 3",
+				"orders":      "",
+				"order_lines": "",
+				"products":    "",
+				"audit":       "",
+			},
+		},
+		{
+			name: "kept tables",
+			spec: "roots:\n  public.order_lines: {where: id = 101}\nkeep: [countries, audit]\n",
+			want: map[string]string{
+				"countries":   "NL DE FR",
+				"users":       "2",
+				"orders":      "11",
+				"order_lines": "101",
+				"products":    "1001",
+				"audit":       "1 2",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := Parse([]byte(tc.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			result, err := spec.Subset(openString(shopDump), &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := keptIDs(t, out.String())
+			for table, want := range tc.want {
+				if got[table] != want {
+					t.Errorf("%s = %q, want %q", table, got[table], want)
+				}
+			}
+			if !strings.Contains(out.String(), "ADD CONSTRAINT orders_user_id_fkey") {
+				t.Error("the schema was not copied")
+			}
+
+			for _, r := range result.Tables {
+				if want := len(strings.Fields(tc.want[strings.TrimPrefix(r.Table, "public.")])); r.Kept != want {
+					t.Errorf("result for %s: kept %d, want %d", r.Table, r.Kept, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubsetPercent(t *testing.T) {
+	var dump strings.Builder
+	dump.WriteString("COPY public.events (id) FROM stdin;\n")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&dump, "%d\n", i)
+	}
+	dump.WriteString("\\.\n")
+
+	run := func(spec string) (*Result, string) {
+		s, err := Parse([]byte(spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out strings.Builder
+		result, err := s.Subset(openString(dump.String()), &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result, out.String()
+	}
+
+	result, first := run("roots:\n  events: {percent: 10}\n")
+	if kept := result.Tables[0].Kept; kept < 70 || kept > 130 || result.Tables[0].Rows != 1000 {
+		t.Errorf("kept %d of %d rows, want about 100 of 1000", kept, result.Tables[0].Rows)
+	}
+	if _, again := run("roots:\n  events: {percent: 10}\n"); again != first {
+		t.Error("sampling the same dump twice gave different rows")
+	}
+	if _, reseeded := run("roots:\n  events: {percent: 10}\nseed: other\n"); reseeded == first {
+		t.Error("sampling with another seed gave the same rows")
+	}
+	if result, _ := run("roots:\n  events: {percent: 10, where: id < 100}\n"); result.Tables[0].Kept > 30 {
+		t.Errorf("kept %d rows of the 100 matching where, want about 10", result.Tables[0].Kept)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"", "no roots to start from"},
+		{"root:\n  users: {}\n", "field root not found"},
+		{"roots:\n  users: {percent: 0}\n", "percent must be more than 0"},
+		{"roots:\n  users: {where: \"id = \"}\n", "root users: invalid where: expected a column or a value"},
+		{"roots:\n  a.b.c: {}\n", `invalid table "a.b.c"`},
+		{"roots:\n  users:\nkeep: [public.users]\n", "public.users is both a root and kept whole"},
+	}
+	for _, tc := range tests {
+		_, err := Parse([]byte(tc.spec))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", tc.spec, err, tc.want)
+		}
+	}
+}
+
+func TestSubsetErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		dump string
+		want string
+	}{
+		{"roots:\n  missing:\n", shopDump, "root public.missing: the dump has no data for it"},
+		{"roots:\n  users: {where: name = 'x'}\n", shopDump, "root public.users: unknown column name"},
+		{"roots:\n  users:\nkeep: [missing]\n", shopDump, "keep public.missing: the dump has no data for it"},
+		{"roots:\n  users:\n", "PGDMP\x01", pgdump.ErrNotPlain.Error()},
+	}
+	for _, tc := range tests {
+		spec, err := Parse([]byte(tc.spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = spec.Subset(openString(tc.dump), io.Discard)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Subset(%q) = %v, want an error containing %q", tc.spec, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package subset
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/meido-ai/devdb/cli/pkg/pgdump"
+)
+
+// tri is the result of an SQL condition: true, false or unknown, which is
+// what comparisons with NULL give.
+type tri int8
+
+const (
+	triFalse tri = iota
+	triTrue
+	triUnknown
+)
+
+func (t tri) not() tri {
+	switch t {
+	case triTrue:
+		return triFalse
+	case triFalse:
+		return triTrue
+	}
+	return t
+}
+
+// expr is a parsed where condition.
+type expr interface {
+	eval(row []pgdump.Value) tri
+	// bind resolves the column names in the expression to the indexes of
+	// the values in a row.
+	bind(columns map[string]int) error
+}
+
+type andExpr struct{ left, right expr }
+
+func (e *andExpr) eval(row []pgdump.Value) tri {
+	l, r := e.left.eval(row), e.right.eval(row)
+	switch {
+	case l == triFalse || r == triFalse:
+		return triFalse
+	case l == triUnknown || r == triUnknown:
+		return triUnknown
+	}
+	return triTrue
+}
+
+func (e *andExpr) bind(columns map[string]int) error { return bindAll(columns, e.left, e.right) }
+
+type orExpr struct{ left, right expr }
+
+func (e *orExpr) eval(row []pgdump.Value) tri {
+	l, r := e.left.eval(row), e.right.eval(row)
+	switch {
+	case l == triTrue || r == triTrue:
+		return triTrue
+	case l == triUnknown || r == triUnknown:
+		return triUnknown
+	}
+	return triFalse
+}
+
+func (e *orExpr) bind(columns map[string]int) error { return bindAll(columns, e.left, e.right) }
+
+type notExpr struct{ e expr }
+
+func (e *notExpr) eval(row []pgdump.Value) tri { return e.e.eval(row).not() }
+
+func (e *notExpr) bind(columns map[string]int) error { return e.e.bind(columns) }
+
+func bindAll(columns map[string]int, exprs ...expr) error {
+	for _, e := range exprs {
+		if err := e.bind(columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// operand is a column or a literal.
+type operand struct {
+	column string
+	index  int
+	value  pgdump.Value
+}
+
+func (o *operand) get(row []pgdump.Value) pgdump.Value {
+	if o.column == "" {
+		return o.value
+	}
+	return row[o.index]
+}
+
+func (o *operand) bind(columns map[string]int) error {
+	if o.column == "" {
+		return nil
+	}
+	i, ok := columns[o.column]
+	if !ok {
+		return fmt.Errorf("unknown column %s", o.column)
+	}
+	o.index = i
+	return nil
+}
+
+func bindOperands(columns map[string]int, operands ...*operand) error {
+	for _, o := range operands {
+		if err := o.bind(columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare orders two values: as numbers when both are, as text otherwise,
+// which is right for ISO dates and timestamps too.
+func compare(a, b pgdump.Value) int {
+	x, errX := strconv.ParseFloat(a.Text, 64)
+	y, errY := strconv.ParseFloat(b.Text, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a.Text, b.Text)
+}
+
+type cmpExpr struct {
+	op          string
+	left, right *operand
+}
+
+func (e *cmpExpr) eval(row []pgdump.Value) tri {
+	a, b := e.left.get(row), e.right.get(row)
+	if a.Null || b.Null {
+		return triUnknown
+	}
+	c := compare(a, b)
+	var ok bool
+	switch e.op {
+	case "=":
+		ok = c == 0
+	case "<>", "!=":
+		ok = c != 0
+	case "<":
+		ok = c < 0
+	case "<=":
+		ok = c <= 0
+	case ">":
+		ok = c > 0
+	case ">=":
+		ok = c >= 0
+	}
+	if ok {
+		return triTrue
+	}
+	return triFalse
+}
+
+func (e *cmpExpr) bind(columns map[string]int) error { return bindOperands(columns, e.left, e.right) }
+
+type isNullExpr struct{ o *operand }
+
+func (e *isNullExpr) eval(row []pgdump.Value) tri {
+	if e.o.get(row).Null {
+		return triTrue
+	}
+	return triFalse
+}
+
+func (e *isNullExpr) bind(columns map[string]int) error { return e.o.bind(columns) }
+
+type inExpr struct {
+	o    *operand
+	list []*operand
+}
+
+func (e *inExpr) eval(row []pgdump.Value) tri {
+	v := e.o.get(row)
+	if v.Null {
+		return triUnknown
+	}
+	result := triFalse
+	for _, o := range e.list {
+		switch w := o.get(row); {
+		case w.Null:
+			result = triUnknown
+		case compare(v, w) == 0:
+			return triTrue
+		}
+	}
+	return result
+}
+
+func (e *inExpr) bind(columns map[string]int) error {
+	return bindOperands(columns, append([]*operand{e.o}, e.list...)...)
+}
+
+type likeExpr struct {
+	o       *operand
+	pattern *regexp.Regexp
+}
+
+func (e *likeExpr) eval(row []pgdump.Value) tri {
+	v := e.o.get(row)
+	switch {
+	case v.Null:
+		return triUnknown
+	case e.pattern.MatchString(v.Text):
+		return triTrue
+	}
+	return triFalse
+}
+
+func (e *likeExpr) bind(columns map[string]int) error { return e.o.bind(columns) }
+
+// likePattern turns a LIKE pattern into a regular expression: % matches
+// any text, _ any character, and a backslash escapes the next one.
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '%':
+			b.WriteString(`.*`)
+		case r == '_':
+			b.WriteString(`.`)
+		case r == '\\' && i+1 < len(runes):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.MustCompile(b.String())
+}
+
+// truthExpr is an operand used as a condition, e.g. a boolean column.
+type truthExpr struct{ o *operand }
+
+func (e *truthExpr) eval(row []pgdump.Value) tri {
+	v := e.o.get(row)
+	switch {
+	case v.Null:
+		return triUnknown
+	case v.Text == "t":
+		return triTrue
+	}
+	return triFalse
+}
+
+func (e *truthExpr) bind(columns map[string]int) error { return e.o.bind(columns) }
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var keywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "IN": true,
+	"LIKE": true, "BETWEEN": true, "TRUE": true, "FALSE": true,
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			var b strings.Builder
+			for i++; ; i++ {
+				if i == len(s) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						b.WriteByte('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(s[i])
+			}
+			tokens = append(tokens, token{tokenString, b.String()})
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted column")
+			}
+			tokens = append(tokens, token{tokenIdent, s[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '.' || (c == '-' || c == '+') && i+1 < len(s) && (s[i+1] >= '0' && s[i+1] <= '9' || s[i+1] == '.'):
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE", s[j]) >= 0 || j < len(s) && (s[j] == '-' || s[j] == '+') && (s[j-1] == 'e' || s[j-1] == 'E') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(s[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %s", s[i:j])
+			}
+			tokens = append(tokens, token{tokenNumber, s[i:j]})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '$' || s[j] >= 'a' && s[j] <= 'z' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			word := s[i:j]
+			if keywords[strings.ToUpper(word)] {
+				tokens = append(tokens, token{tokenKeyword, strings.ToUpper(word)})
+			} else {
+				// Unquoted names are folded to lower case, as in SQL
+				tokens = append(tokens, token{tokenIdent, strings.ToLower(word)})
+			}
+			i = j
+		default:
+			symbol := string(c)
+			if i+1 < len(s) {
+				switch two := s[i : i+2]; two {
+				case "<>", "!=", "<=", ">=":
+					symbol = two
+				}
+			}
+			if !strings.Contains("=<>(),", symbol) && len(symbol) == 1 {
+				return nil, fmt.Errorf("unexpected %q", symbol)
+			}
+			tokens = append(tokens, token{tokenSymbol, symbol})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// parseWhere parses a where condition. The columns in it are resolved
+// later, with bind.
+func parseWhere(s string) (expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{tokens: tokens}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", t.text)
+	}
+	return e, nil
+}
+
+type whereParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *whereParser) peek() token { return p.tokens[p.pos] }
+
+func (p *whereParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the keyword or symbol s.
+func (p *whereParser) accept(s string) bool {
+	if t := p.peek(); (t.kind == tokenKeyword || t.kind == tokenSymbol) && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) expect(s string) error {
+	if !p.accept(s) {
+		return fmt.Errorf("expected %s, found %s", s, describe(p.peek()))
+	}
+	return nil
+}
+
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return "the end"
+	}
+	return t.text
+}
+
+func (p *whereParser) or() (expr, error) {
+	left, err := p.and()
+	for err == nil && p.accept("OR") {
+		var right expr
+		if right, err = p.and(); err == nil {
+			left = &orExpr{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) and() (expr, error) {
+	left, err := p.not()
+	for err == nil && p.accept("AND") {
+		var right expr
+		if right, err = p.not(); err == nil {
+			left = &andExpr{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) not() (expr, error) {
+	if p.accept("NOT") {
+		e, err := p.not()
+		return &notExpr{e}, err
+	}
+	return p.predicate()
+}
+
+func (p *whereParser) predicate() (expr, error) {
+	if p.accept("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokenSymbol && t.text != "(" && t.text != ")" && t.text != "," {
+		p.next()
+		right, err := p.operand()
+		return &cmpExpr{op: t.text, left: left, right: right}, err
+	}
+	if p.accept("IS") {
+		negate := p.accept("NOT")
+		if err := p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		return maybeNot(&isNullExpr{left}, negate), nil
+	}
+
+	negate := p.accept("NOT")
+	switch {
+	case p.accept("IN"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		e := &inExpr{o: left}
+		for {
+			o, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			e.list = append(e.list, o)
+			if !p.accept(",") {
+				break
+			}
+		}
+		return maybeNot(e, negate), p.expect(")")
+	case p.accept("LIKE"):
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("LIKE needs a string pattern, found %s", describe(t))
+		}
+		return maybeNot(&likeExpr{o: left, pattern: likePattern(t.text)}, negate), nil
+	case p.accept("BETWEEN"):
+		low, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		e := &andExpr{&cmpExpr{op: ">=", left: left, right: low}, &cmpExpr{op: "<=", left: left, right: high}}
+		return maybeNot(e, negate), nil
+	case negate:
+		return nil, fmt.Errorf("expected IN, LIKE or BETWEEN after NOT, found %s", describe(p.peek()))
+	}
+	return &truthExpr{left}, nil
+}
+
+func maybeNot(e expr, negate bool) expr {
+	if negate {
+		return &notExpr{e}
+	}
+	return e
+}
+
+func (p *whereParser) operand() (*operand, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenIdent:
+		return &operand{column: t.text}, nil
+	case t.kind == tokenString || t.kind == tokenNumber:
+		return &operand{value: pgdump.Value{Text: t.text}}, nil
+	case t.kind == tokenKeyword && t.text == "NULL":
+		return &operand{value: pgdump.Value{Null: true}}, nil
+	case t.kind == tokenKeyword && t.text == "TRUE":
+		return &operand{value: pgdump.Value{Text: "t"}}, nil
+	case t.kind == tokenKeyword && t.text == "FALSE":
+		return &operand{value: pgdump.Value{Text: "f"}}, nil
+	}
+	return nil, fmt.Errorf("expected a column or a value, found %s", describe(t))
+}
@@ -0,0 +1,90 @@
+package subset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/meido-ai/devdb/cli/pkg/pgdump"
+)
+
+func TestWhere(t *testing.T) {
+	columns := map[string]int{"id": 0, "country": 1, "created_at": 2, "active": 3, "Name": 4}
+	row := []pgdump.Value{
+		{Text: "42"},
+		{Text: "NL"},
+		{Text: "2024-03-01 10:00:00+00"},
+		{Text: "t"},
+		{Null: true},
+	}
+
+	tests := []struct {
+		where string
+		want  tri
+	}{
+		{"id = 42", triTrue},
+		{"id = 42.0", triTrue},
+		{"id > 9", triTrue},
+		{"id <> 42", triFalse},
+		{"country = 'NL'", triTrue},
+		{"COUNTRY = 'nl'", triFalse},
+		{"created_at >= '2024-01-01'", triTrue},
+		{"created_at BETWEEN '2023-01-01' AND '2023-12-31'", triFalse},
+		{"country IN ('DE', 'NL')", triTrue},
+		{"country NOT IN ('DE', 'NL')", triFalse},
+		{"country IN ('DE', NULL)", triUnknown},
+		{"country LIKE 'N_'", triTrue},
+		{"country NOT LIKE 'D%'", triTrue},
+		{`"Name" IS NULL`, triTrue},
+		{`"Name" = 'x'`, triUnknown},
+		{`NOT "Name" = 'x'`, triUnknown},
+		{`"Name" = 'x' OR id = 42`, triTrue},
+		{`"Name" = 'x' AND id = 7`, triFalse},
+		{"active", triTrue},
+		{"active = true AND NOT (id < 10 OR country = 'DE')", triTrue},
+		{"id >= -1e3", triTrue},
+		{"country = 'it''s'", triFalse},
+	}
+	for _, tc := range tests {
+		e, err := parseWhere(tc.where)
+		if err != nil {
+			t.Errorf("parseWhere(%q) = %v", tc.where, err)
+			continue
+		}
+		if err := e.bind(columns); err != nil {
+			t.Errorf("bind(%q) = %v", tc.where, err)
+			continue
+		}
+		if got := e.eval(row); got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.where, got, tc.want)
+		}
+	}
+}
+
+func TestWhereErrors(t *testing.T) {
+	tests := []struct {
+		where string
+		want  string
+	}{
+		{"id =", "expected a column or a value, found the end"},
+		{"id = 'x", "unterminated string"},
+		{"(id = 1", "expected ), found the end"},
+		{"id = 1 id", "unexpected id"},
+		{"id NOT 1", "expected IN, LIKE or BETWEEN after NOT"},
+		{"id LIKE name", "LIKE needs a string pattern"},
+		{"id ; drop", `unexpected ";"`},
+	}
+	for _, tc := range tests {
+		_, err := parseWhere(tc.where)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("parseWhere(%q) = %v, want an error containing %q", tc.where, err, tc.want)
+		}
+	}
+
+	e, err := parseWhere("missing = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.bind(map[string]int{"id": 0}); err == nil || err.Error() != "unknown column missing" {
+		t.Errorf("bind = %v", err)
+	}
+}
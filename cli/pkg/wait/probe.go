@@ -0,0 +1,172 @@
+package wait
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// probeTimeout bounds a single readiness probe.
+const probeTimeout = 5 * time.Second
+
+// sslRequestCode is the PostgreSQL SSLRequest message code. A server that
+// is accepting connections answers it with a single 'S' or 'N' byte, which
+// lets us check readiness without credentials.
+const sslRequestCode = 80877103
+
+// mysqlProtocolVersion starts the handshake a MySQL or MariaDB server
+// sends as soon as a client connects.
+const mysqlProtocolVersion = 10
+
+// mongoOpMsg is the opcode of MongoDB's OP_MSG messages, which carry
+// commands and their replies.
+const mongoOpMsg = 2013
+
+// Probe checks that a PostgreSQL server at host:port accepts connections
+// and speaks the wire protocol.
+func Probe(ctx context.Context, host string, port int) error {
+	conn, cancel, err := dial(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer conn.Close()
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint32(msg[0:4], 8)
+	binary.BigEndian.PutUint32(msg[4:8], sslRequestCode)
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 1)
+	if _, err := conn.Read(reply); err != nil {
+		return err
+	}
+	if reply[0] != 'S' && reply[0] != 'N' {
+		return fmt.Errorf("unexpected reply %q from %s:%d", reply[0], host, port)
+	}
+	return nil
+}
+
+// ProbeMySQL checks that a MySQL or MariaDB server at host:port accepts
+// connections: it greets every client with a handshake packet, or with an
+// error packet when it cannot take one, e.g. while it starts.
+func ProbeMySQL(ctx context.Context, host string, port int) error {
+	conn, cancel, err := dial(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer conn.Close()
+
+	// A 3-byte length and a sequence number precede the payload
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	switch header[4] {
+	case mysqlProtocolVersion:
+		return nil
+	case 0xff:
+		return fmt.Errorf("%s:%d refused the connection", host, port)
+	}
+	return fmt.Errorf("unexpected handshake %#x from %s:%d", header[4], host, port)
+}
+
+// ProbeRedis checks that a Redis server at host:port accepts commands. A
+// server requiring a password still answers PING, with a NOAUTH error,
+// while one loading its RDB file answers LOADING and is not ready.
+func ProbeRedis(ctx context.Context, host string, port int) error {
+	conn, cancel, err := dial(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "PING\r\n"); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	reply = strings.TrimSpace(reply)
+	if reply == "+PONG" || strings.HasPrefix(reply, "-NOAUTH") {
+		return nil
+	}
+	return fmt.Errorf("unexpected reply %q from %s:%d", reply, host, port)
+}
+
+// ProbeMongo checks that a MongoDB server at host:port answers commands
+// by sending it hello, which needs no credentials.
+func ProbeMongo(ctx context.Context, host string, port int) error {
+	conn, cancel, err := dial(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer conn.Close()
+
+	if _, err := conn.Write(mongoHello()); err != nil {
+		return err
+	}
+	// The reply starts with its length, request ID, the ID it responds
+	// to and its opcode
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if op := binary.LittleEndian.Uint32(header[12:16]); op != mongoOpMsg {
+		return fmt.Errorf("unexpected reply opcode %d from %s:%d", op, host, port)
+	}
+	return nil
+}
+
+// mongoHello returns an OP_MSG with the command {hello: 1, $db: "admin"}.
+func mongoHello() []byte {
+	// The BSON document: its length, then typed, named elements
+	var elems []byte
+	elems = append(elems, 0x10) // int32
+	elems = append(elems, "hello\x00"...)
+	elems = binary.LittleEndian.AppendUint32(elems, 1)
+	elems = append(elems, 0x02) // string
+	elems = append(elems, "$db\x00"...)
+	elems = binary.LittleEndian.AppendUint32(elems, uint32(len("admin\x00")))
+	elems = append(elems, "admin\x00"...)
+	elems = append(elems, 0x00)
+	doc := binary.LittleEndian.AppendUint32(nil, uint32(4+len(elems)))
+	doc = append(doc, elems...)
+
+	// The header, no flags and the document as a body section
+	msg := binary.LittleEndian.AppendUint32(nil, uint32(16+4+1+len(doc)))
+	msg = binary.LittleEndian.AppendUint32(msg, 1) // request ID
+	msg = binary.LittleEndian.AppendUint32(msg, 0) // responding to
+	msg = binary.LittleEndian.AppendUint32(msg, mongoOpMsg)
+	msg = binary.LittleEndian.AppendUint32(msg, 0)
+	msg = append(msg, 0) // section kind: body
+	return append(msg, doc...)
+}
+
+// dial connects to host:port with the probe's deadline. Call cancel once
+// done with the connection.
+func dial(ctx context.Context, host string, port int) (net.Conn, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return conn, cancel, nil
+}
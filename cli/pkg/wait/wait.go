@@ -0,0 +1,139 @@
+// Package wait blocks until a DevDB database reaches a desired state.
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+	"github.com/meido-ai/devdb/cli/pkg/engine"
+)
+
+// Deleted is a pseudo-status used to wait for a database to disappear.
+const Deleted api.DatabaseStatus = "deleted"
+
+// ErrFailed is returned when the database enters the error state while
+// waiting for another status.
+var ErrFailed = errors.New("database failed")
+
+// Options tune how ForStatus polls.
+type Options struct {
+	// Interval is the first delay between polls; it grows by half after
+	// every poll up to MaxInterval. Defaults are 1s and 10s.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	// AllowMissing treats a 404 as "not created yet" rather than an error,
+	// which is useful right after a create request.
+	AllowMissing bool
+
+	// Probe additionally requires a successful readiness probe against the
+	// database's host and port before a "running" database counts as ready.
+	Probe bool
+
+	// Type is the database's engine, which decides how it is probed;
+	// postgres when empty.
+	Type api.DatabaseType
+
+	// Progress, if set, is called after every poll with the latest state.
+	// db is nil while the database does not exist.
+	Progress func(db *api.Database, elapsed time.Duration)
+}
+
+// ForStatus polls the database until its status is want and returns its
+// final state. It stops with an error when ctx is done, when the database
+// fails, or when the API reports an unexpected response.
+func ForStatus(ctx context.Context, client api.ClientWithResponsesInterface, projectID, name string, want api.DatabaseStatus, opts Options) (*api.Database, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 10 * time.Second
+	}
+
+	start := time.Now()
+	var last *api.Database
+	for {
+		db, done, err := poll(ctx, client, projectID, name, want, opts)
+		if err != nil {
+			return db, err
+		}
+		if db != nil {
+			last = db
+		}
+		if opts.Progress != nil {
+			opts.Progress(db, time.Since(start))
+		}
+		if done {
+			return db, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			status := "unknown"
+			if last != nil {
+				status = string(last.Status)
+			}
+			return last, fmt.Errorf("timed out waiting for database %s to be %s (last status: %s)", name, want, status)
+		case <-time.After(interval):
+		}
+		interval = interval * 3 / 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func poll(ctx context.Context, client api.ClientWithResponsesInterface, projectID, name string, want api.DatabaseStatus, opts Options) (*api.Database, bool, error) {
+	resp, err := client.GetProjectsProjectIdDatabasesNameWithResponse(ctx, projectID, name)
+	if err != nil {
+		if ctx.Err() != nil {
+			// The deadline passed mid-request; let the caller report it
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("getting database: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusNotFound:
+		if want == Deleted {
+			return nil, true, nil
+		}
+		if opts.AllowMissing {
+			return nil, false, nil
+		}
+		// The API error keeps the status, so callers can tell a missing
+		// database from other failures
+		return nil, false, api.NewError(resp.HTTPResponse, resp.Body)
+	case resp.StatusCode() != http.StatusOK || resp.JSON200 == nil:
+		return nil, false, api.NewError(resp.HTTPResponse, resp.Body)
+	}
+
+	db := resp.JSON200
+	switch {
+	case db.Status == want && want == api.Running && opts.Probe:
+		if db.Host == nil || db.Port == nil {
+			return db, false, nil
+		}
+		probe := Probe
+		switch {
+		case engine.MySQLFamily(opts.Type):
+			probe = ProbeMySQL
+		case opts.Type == api.Redis:
+			probe = ProbeRedis
+		case opts.Type == api.MongoDB:
+			probe = ProbeMongo
+		}
+		return db, probe(ctx, *db.Host, *db.Port) == nil, nil
+	case db.Status == want:
+		return db, true, nil
+	case db.Status == api.Error:
+		return db, false, fmt.Errorf("%w: %s is in status %s", ErrFailed, name, db.Status)
+	}
+	return db, false, nil
+}
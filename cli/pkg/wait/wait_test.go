@@ -0,0 +1,286 @@
+package wait
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/meido-ai/devdb/cli/pkg/api"
+)
+
+// statusServer answers GET requests for database "db" with the given
+// statuses in order, repeating the last one. An empty status means 404.
+func statusServer(t *testing.T, host string, port int, statuses ...string) (*api.ClientWithResponses, *int32) {
+	t.Helper()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		if statuses[n] == "" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"name": "db", "status": %q, "host": %q, "port": %d}`, statuses[n], host, port)
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := api.NewClientWithResponses(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &calls
+}
+
+func fastOptions() Options {
+	return Options{Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+}
+
+func TestForStatus(t *testing.T) {
+	client, calls := statusServer(t, "localhost", 5432, "creating", "creating", "running")
+
+	var seen []string
+	opts := fastOptions()
+	opts.Progress = func(db *api.Database, elapsed time.Duration) {
+		seen = append(seen, string(db.Status))
+	}
+
+	db, err := ForStatus(context.Background(), client, "p", "db", api.Running, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Status != api.Running {
+		t.Errorf("status = %s, want running", db.Status)
+	}
+	if *calls != 3 {
+		t.Errorf("polled %d times, want 3", *calls)
+	}
+	if strings.Join(seen, ",") != "creating,creating,running" {
+		t.Errorf("progress = %v", seen)
+	}
+}
+
+func TestForStatusFailed(t *testing.T) {
+	client, _ := statusServer(t, "localhost", 5432, "creating", "error")
+
+	_, err := ForStatus(context.Background(), client, "p", "db", api.Running, fastOptions())
+	if !errors.Is(err, ErrFailed) {
+		t.Errorf("err = %v, want ErrFailed", err)
+	}
+}
+
+func TestForStatusTimeout(t *testing.T) {
+	client, _ := statusServer(t, "localhost", 5432, "creating")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := ForStatus(ctx, client, "p", "db", api.Running, fastOptions())
+	if err == nil || !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "creating") {
+		t.Errorf("err = %v, want timeout mentioning last status", err)
+	}
+}
+
+func TestForStatusMissing(t *testing.T) {
+	client, _ := statusServer(t, "localhost", 5432, "", "running")
+	if _, err := ForStatus(context.Background(), client, "p", "db", api.Running, fastOptions()); !api.IsNotFound(err) {
+		t.Errorf("err = %v, want a not found API error", err)
+	}
+
+	client, _ = statusServer(t, "localhost", 5432, "", "running")
+	opts := fastOptions()
+	opts.AllowMissing = true
+	if _, err := ForStatus(context.Background(), client, "p", "db", api.Running, opts); err != nil {
+		t.Errorf("AllowMissing: %v", err)
+	}
+}
+
+func TestForStatusDeleted(t *testing.T) {
+	client, _ := statusServer(t, "localhost", 5432, "running", "")
+	db, err := ForStatus(context.Background(), client, "p", "db", Deleted, fastOptions())
+	if err != nil || db != nil {
+		t.Errorf("ForStatus(Deleted) = %v, %v", db, err)
+	}
+}
+
+// fakePostgres accepts connections and answers SSLRequest with 'N'.
+func fakePostgres(t *testing.T) (string, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 8)
+			if _, err := conn.Read(buf); err == nil {
+				conn.Write([]byte("N"))
+			}
+			conn.Close()
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestForStatusProbe(t *testing.T) {
+	host, port := fakePostgres(t)
+	client, _ := statusServer(t, host, port, "running")
+
+	opts := fastOptions()
+	opts.Probe = true
+	if _, err := ForStatus(context.Background(), client, "p", "db", api.Running, opts); err != nil {
+		t.Errorf("probe against listening server: %v", err)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	host, port := fakePostgres(t)
+	if err := Probe(context.Background(), host, port); err != nil {
+		t.Errorf("Probe: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	if err := Probe(context.Background(), "127.0.0.1", closedPort); err == nil {
+		t.Error("expected error probing a closed port")
+	}
+}
+
+// fakeMySQL greets every connection with greeting, as a MySQL server
+// sends its handshake.
+func fakeMySQL(t *testing.T, greeting []byte) (string, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write(greeting)
+			conn.Close()
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestProbeMySQL(t *testing.T) {
+	host, port := fakeMySQL(t, []byte("\x4a\x00\x00\x00\x0a8.4.0\x00"))
+	if err := ProbeMySQL(context.Background(), host, port); err != nil {
+		t.Errorf("ProbeMySQL: %v", err)
+	}
+	client, _ := statusServer(t, host, port, "running")
+	opts := fastOptions()
+	opts.Probe = true
+	opts.Type = api.MySQL
+	if _, err := ForStatus(context.Background(), client, "p", "db", api.Running, opts); err != nil {
+		t.Errorf("probe against a listening MySQL server: %v", err)
+	}
+
+	host, port = fakeMySQL(t, []byte("\x17\x00\x00\x00\xff\x10\x04Too many connections"))
+	if err := ProbeMySQL(context.Background(), host, port); err == nil {
+		t.Error("expected error probing a server that refuses connections")
+	}
+	host, port = fakeMySQL(t, []byte("N"))
+	if err := ProbeMySQL(context.Background(), host, port); err == nil {
+		t.Error("expected error probing a server that does not speak MySQL")
+	}
+}
+
+// fakeServer answers the first message of every connection with reply.
+// It also records the message in got.
+func fakeServer(t *testing.T, reply []byte, got chan<- []byte) (string, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 512)
+			n, err := conn.Read(buf)
+			if err == nil {
+				if got != nil {
+					got <- buf[:n]
+				}
+				conn.Write(reply)
+			}
+			conn.Close()
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestProbeRedis(t *testing.T) {
+	tests := []struct {
+		reply   string
+		wantErr bool
+	}{
+		{reply: "+PONG\r\n"},
+		{reply: "-NOAUTH Authentication required.\r\n"},
+		{reply: "-LOADING Redis is loading the dataset in memory\r\n", wantErr: true},
+	}
+	for _, tc := range tests {
+		host, port := fakeServer(t, []byte(tc.reply), nil)
+		if err := ProbeRedis(context.Background(), host, port); (err != nil) != tc.wantErr {
+			t.Errorf("ProbeRedis(%q) = %v, wantErr %v", tc.reply, err, tc.wantErr)
+		}
+	}
+}
+
+func TestProbeMongo(t *testing.T) {
+	got := make(chan []byte, 1)
+	reply := []byte("\x15\x00\x00\x00\x07\x00\x00\x00\x01\x00\x00\x00\xdd\x07\x00\x00\x00\x00\x00\x00\x00")
+	host, port := fakeServer(t, reply, got)
+
+	client, _ := statusServer(t, host, port, "running")
+	opts := fastOptions()
+	opts.Probe = true
+	opts.Type = api.MongoDB
+	if _, err := ForStatus(context.Background(), client, "p", "db", api.Running, opts); err != nil {
+		t.Errorf("probe against a listening MongoDB server: %v", err)
+	}
+	msg := <-got
+	if length := binary.LittleEndian.Uint32(msg); int(length) != len(msg) {
+		t.Errorf("hello is %d bytes but says %d", len(msg), length)
+	}
+	if !bytes.Contains(msg, []byte("hello\x00\x01\x00\x00\x00")) {
+		t.Errorf("hello = %q, want a hello command", msg)
+	}
+
+	host, port = fakeServer(t, []byte("+PONG\r\n"), nil)
+	if err := ProbeMongo(context.Background(), host, port); err == nil {
+		t.Error("expected error probing a server that does not speak MongoDB")
+	}
+}